@@ -5,6 +5,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/akhdanrgya/telu-hub/config"
 	"github.com/akhdanrgya/telu-hub/internal/database"
@@ -15,6 +16,22 @@ import (
 	"github.com/midtrans/midtrans-go"
 
 	"github.com/akhdanrgya/telu-hub/internal/notification"
+	"github.com/akhdanrgya/telu-hub/internal/chat"
+	"github.com/akhdanrgya/telu-hub/internal/reservation"
+	"github.com/akhdanrgya/telu-hub/internal/orderflow"
+	"github.com/akhdanrgya/telu-hub/internal/payment"
+	"github.com/akhdanrgya/telu-hub/internal/refund"
+	"github.com/akhdanrgya/telu-hub/internal/media"
+	"github.com/akhdanrgya/telu-hub/internal/storage"
+	"github.com/akhdanrgya/telu-hub/internal/imaging"
+	"github.com/akhdanrgya/telu-hub/internal/session"
+	"github.com/akhdanrgya/telu-hub/internal/middleware"
+	"github.com/akhdanrgya/telu-hub/internal/account"
+	"github.com/akhdanrgya/telu-hub/internal/mailer"
+	"github.com/akhdanrgya/telu-hub/internal/stockalert"
+	"github.com/akhdanrgya/telu-hub/internal/voucher"
+	"github.com/akhdanrgya/telu-hub/internal/fulfilment"
+	"github.com/akhdanrgya/telu-hub/internal/guestcart"
 
 	"github.com/improbable-eng/grpc-web/go/grpcweb"
 	"google.golang.org/grpc"
@@ -35,9 +52,7 @@ func main() {
 	}
 	log.Printf("🌍 Client URL set to: %s", clientURL)
 
-	midtrans.ServerKey = config.GetMidtransServerKey()
-	midtrans.ClientKey = config.GetMidtransClientKey()
-	midtrans.Environment = midtrans.Sandbox
+	paymentGateway := newPaymentGateway()
 
 	database.InitDB()
 	db := database.DB
@@ -50,29 +65,135 @@ func main() {
 	go notifHub.Run()
 	notifService := notification.NewService(db, notifHub)
 
+	orderFlow := orderflow.NewService(db, notifService)
+
+	stockAlertService := stockalert.NewService(db, notifService)
+
+	reservationService := reservation.NewService(db, stockService, orderFlow, stockAlertService, config.GetReservationTTL())
+	go reservationService.RunExpiryWorker(config.GetReservationSweepInterval())
+
+	paymentProcessor := payment.NewProcessor(db, paymentGateway, reservationService, orderFlow)
+	reconciler := payment.NewReconciler(paymentProcessor, config.GetPaymentReconcileStaleAfter())
+	go reconciler.Run(config.GetPaymentReconcileInterval())
+
+	voucherService := voucher.NewService(db)
+	fulfilmentService := fulfilment.NewService(db)
+	guestCartService := guestcart.NewService(db, config.GetGuestCartTTL())
+	go guestCartService.RunPurgeWorker(config.GetGuestCartSweepInterval())
+
+	refundService := refund.NewService(db, paymentGateway, reservationService, orderFlow, notifService)
+
+	sessionService := session.NewService(db, config.GetAccessTokenTTL(), config.GetRefreshTokenTTL())
+	middleware.UseSessions(sessionService)
+
+	// CLIENT_URL bisa berisi beberapa origin (dipisah koma), link di email pake yang pertama
+	accountService := account.NewService(db, newMailer(), sessionService, strings.Split(clientURL, ",")[0],
+		config.GetEmailVerifyTTL(), config.GetPasswordResetTTL(), config.GetRegisterAllowedDomains(), config.GetRequireEmailVerification())
+
+	store := newStorage()
+	uploadQuotas := media.Quotas{
+		User:   int64(config.GetUploadQuotaUserMB()) << 20,
+		Seller: int64(config.GetUploadQuotaSellerMB()) << 20,
+	}
+	mediaService := media.NewService(db, store, uploadQuotas, config.GetUploadOrphanTTL(), config.GetStorageKeyPrefix(), config.GetUploadSweepUntracked())
+	go mediaService.RunSweeper(config.GetUploadSweepInterval())
+
+	chatHub := chat.NewChatHub()
+	go chatHub.Run()
+	chatService := chat.NewService(db, chatHub, notifService)
+
 	grpcServer := runGrpcServer(stockService, ":50051")
 
 	go runGrpcWebServer(grpcServer, ":8081", clientURL)
 
-	app := fiber.New()
+	uploadLimits := imaging.Limits{
+		MaxBytes:     int64(config.GetUploadMaxMB()) << 20,
+		MaxDimension: config.GetUploadMaxDimension(),
+	}
+
+	// Body limit default Fiber cuma 4 MB, dilonggarin dikit di atas batas upload (multipart overhead)
+	app := fiber.New(fiber.Config{BodyLimit: int(uploadLimits.MaxBytes) + 1<<20})
 	app.Use(logger.New())
 
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     clientURL,
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, x-grpc-web",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Cart-Token, x-grpc-web",
 		AllowCredentials: true,
 		AllowMethods:     "GET, POST, PUT, DELETE, PATCH, OPTIONS",
 	}))
 
-	app.Static("/uploads", "./uploads")
+	if local, ok := store.(*storage.Local); ok {
+		// File private (dokumen pengajuan seller) disimpen di luar local.Dir, cuma bisa dibuka lewat signed URL
+		app.Get("/uploads/"+storage.PrivatePrefix+"*", handlers.NewPrivateFileHandler(local).Serve)
+		app.Static("/uploads", local.Dir)
+	}
 
-	handlers.SetupRoutes(app, db, stockService, notifService)
+	handlers.SetupRoutes(app, db, stockService, notifService, chatService, reservationService, stockAlertService, voucherService, fulfilmentService, guestCartService, orderFlow, paymentProcessor, reconciler, refundService, mediaService, uploadLimits, sessionService, accountService)
 
 	port := config.GetAppPort()
 	log.Printf("🔥 Server Fiber jalan di port %s", port)
 	app.Listen(port)
 }
 
+func newPaymentGateway() payment.Gateway {
+	if config.GetPaymentGateway() == "fake" {
+		webhookURL := config.GetPaymentWebhookURL()
+		baseURL := strings.TrimSuffix(webhookURL, "/webhook") + "/fake"
+		log.Printf("🧪 Payment gateway: FAKE (webhook ditembak ke %s) — jangan dipake di production!", webhookURL)
+		return payment.NewFakeGateway(config.GetFakePaymentServerKey(), webhookURL, baseURL)
+	}
+
+	env := midtrans.Sandbox
+	if config.GetMidtransEnv() == "production" {
+		env = midtrans.Production
+	}
+	midtrans.ServerKey = config.GetMidtransServerKey()
+	midtrans.ClientKey = config.GetMidtransClientKey()
+	midtrans.Environment = env
+	log.Printf("💳 Payment gateway: Midtrans (%s)", config.GetMidtransEnv())
+	return payment.NewMidtransGateway(config.GetMidtransServerKey(), env)
+}
+
+func newMailer() mailer.Mailer {
+	if config.GetMailDriver() == "smtp" {
+		log.Printf("✉️ Email dikirim lewat SMTP %s:%d", config.GetSMTPHost(), config.GetSMTPPort())
+		return mailer.NewSMTPMailer(config.GetSMTPHost(), config.GetSMTPPort(), config.GetSMTPUsername(), config.GetSMTPPassword(), config.GetMailFrom())
+	}
+
+	m, err := mailer.NewFileMailer(config.GetMailDir(), config.GetMailFrom())
+	if err != nil {
+		log.Fatalf("ERROR: Gagal nyiapin folder email: %v", err)
+	}
+	log.Printf("✉️ Email gak dikirim beneran, cuma di-log & disimpen di %s", m.Dir)
+	return m
+}
+
+func newStorage() storage.Storage {
+	if config.GetStorageDriver() == "s3" {
+		store, err := storage.NewS3(storage.S3Config{
+			Endpoint:  config.GetS3Endpoint(),
+			Region:    config.GetS3Region(),
+			Bucket:    config.GetS3Bucket(),
+			AccessKey: config.GetS3AccessKey(),
+			SecretKey: config.GetS3SecretKey(),
+			PathStyle: config.GetS3PathStyle(),
+			PublicURL: config.GetStoragePublicURL(),
+		})
+		if err != nil {
+			log.Fatalf("ERROR: Gagal nyiapin storage S3: %v", err)
+		}
+		log.Printf("🪣 Storage upload: S3 %s/%s (URL publik %s)", config.GetS3Endpoint(), config.GetS3Bucket(), store.PublicURL)
+		return store
+	}
+
+	store, err := storage.NewLocal(config.GetUploadDir(), config.GetUploadPrivateDir(), config.GetStoragePublicURL(), []byte(config.GetJWTSecret()))
+	if err != nil {
+		log.Fatalf("ERROR: Gagal nyiapin folder upload: %v", err)
+	}
+	log.Printf("📁 Storage upload: disk lokal %s, file private di %s (URL publik %s)", store.Dir, store.PrivateDir, store.PublicURL)
+	return store
+}
+
 func runGrpcServer(stockSvc *grpc_service.StockService, port string) *grpc.Server {
 	lis, err := net.Listen("tcp", port)
 	if err != nil {
@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -19,6 +23,57 @@ type configStruct struct {
 	JWTSecret         string
 	MidtransServerKey string
 	MidtransClientKey string
+	MidtransEnv       string
+
+	PaymentGateway       string
+	PaymentWebhookURL    string
+	FakePaymentServerKey string
+
+	PaymentReconcileInterval   time.Duration
+	PaymentReconcileStaleAfter time.Duration
+
+	ReservationTTL           time.Duration
+	ReservationSweepInterval time.Duration
+
+	GuestCartTTL           time.Duration
+	GuestCartSweepInterval time.Duration
+
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	RequireEmailVerification bool
+	RegisterAllowedDomains   []string
+	EmailVerifyTTL           time.Duration
+	PasswordResetTTL         time.Duration
+
+	MailDriver   string
+	MailFrom     string
+	MailDir      string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+
+	UploadOrphanTTL      time.Duration
+	UploadSweepInterval  time.Duration
+	UploadSweepUntracked bool
+
+	StorageDriver    string
+	StoragePublicURL string
+	StorageKeyPrefix string
+	UploadDir        string
+	UploadPrivateDir string
+	S3Endpoint       string
+	S3Region         string
+	S3Bucket         string
+	S3AccessKey      string
+	S3SecretKey      string
+	S3PathStyle      bool
+
+	UploadMaxMB        int
+	UploadMaxDimension int
+	UploadQuotaUserMB   int
+	UploadQuotaSellerMB int
 }
 
 var Config *configStruct
@@ -40,6 +95,127 @@ func LoadConfig() error {
 	jwtSecret := os.Getenv("JWT_SECRET")
 	serverKey := os.Getenv("MIDTRANS_SERVER_KEY")
 	clientKey := os.Getenv("MIDTRANS_CLIENT_KEY")
+	midtransEnv := os.Getenv("MIDTRANS_ENV")
+	paymentGateway := os.Getenv("PAYMENT_GATEWAY")
+	paymentWebhookURL := os.Getenv("PAYMENT_WEBHOOK_URL")
+	fakePaymentKey := os.Getenv("FAKE_PAYMENT_SERVER_KEY")
+	storageDriver := os.Getenv("STORAGE_DRIVER")
+	storagePublicURL := os.Getenv("STORAGE_PUBLIC_URL")
+	storageKeyPrefix := os.Getenv("STORAGE_KEY_PREFIX")
+	uploadSweepUntracked := os.Getenv("UPLOAD_SWEEP_UNTRACKED") == "true"
+	uploadDir := os.Getenv("UPLOAD_DIR")
+	uploadPrivateDir := os.Getenv("UPLOAD_PRIVATE_DIR")
+	s3Endpoint := os.Getenv("S3_ENDPOINT")
+	s3Region := os.Getenv("S3_REGION")
+	s3Bucket := os.Getenv("S3_BUCKET")
+	s3AccessKey := os.Getenv("S3_ACCESS_KEY")
+	s3SecretKey := os.Getenv("S3_SECRET_KEY")
+	s3PathStyle := os.Getenv("S3_PATH_STYLE") != "false"
+	requireEmailVerification := os.Getenv("REQUIRE_EMAIL_VERIFICATION") != "false"
+	registerAllowedDomains := os.Getenv("REGISTER_ALLOWED_DOMAINS")
+	mailDriver := os.Getenv("MAIL_DRIVER")
+	mailFrom := os.Getenv("MAIL_FROM")
+	mailDir := os.Getenv("MAIL_DIR")
+	smtpHost := os.Getenv("SMTP_HOST")
+	smtpUsername := os.Getenv("SMTP_USERNAME")
+	smtpPassword := os.Getenv("SMTP_PASSWORD")
+
+	reservationTTL, err := parseDurationEnv("RESERVATION_TTL", 30*time.Minute)
+	if err != nil {
+		return err
+	}
+	sweepInterval, err := parseDurationEnv("RESERVATION_SWEEP_INTERVAL", time.Minute)
+	if err != nil {
+		return err
+	}
+	guestCartTTL, err := parseDurationEnv("GUEST_CART_TTL", 7*24*time.Hour)
+	if err != nil {
+		return err
+	}
+	guestCartSweepInterval, err := parseDurationEnv("GUEST_CART_SWEEP_INTERVAL", time.Hour)
+	if err != nil {
+		return err
+	}
+	reconcileInterval, err := parseDurationEnv("PAYMENT_RECONCILE_INTERVAL", 5*time.Minute)
+	if err != nil {
+		return err
+	}
+	reconcileStaleAfter, err := parseDurationEnv("PAYMENT_RECONCILE_STALE_AFTER", 10*time.Minute)
+	if err != nil {
+		return err
+	}
+
+	accessTokenTTL, err := parseDurationEnv("ACCESS_TOKEN_TTL", 15*time.Minute)
+	if err != nil {
+		return err
+	}
+	refreshTokenTTL, err := parseDurationEnv("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+	if err != nil {
+		return err
+	}
+
+	emailVerifyTTL, err := parseDurationEnv("EMAIL_VERIFY_TTL", 48*time.Hour)
+	if err != nil {
+		return err
+	}
+	passwordResetTTL, err := parseDurationEnv("PASSWORD_RESET_TTL", time.Hour)
+	if err != nil {
+		return err
+	}
+	smtpPort, err := parseIntEnv("SMTP_PORT", 587)
+	if err != nil {
+		return err
+	}
+
+	if mailDriver == "" {
+		mailDriver = "file"
+	}
+	if mailDriver != "file" && mailDriver != "smtp" {
+		return fmt.Errorf("ERROR: MAIL_DRIVER harus 'file' atau 'smtp', bukan %q", mailDriver)
+	}
+	if mailDriver == "smtp" && smtpHost == "" {
+		return fmt.Errorf("ERROR: SMTP_HOST wajib diset kalau MAIL_DRIVER=smtp")
+	}
+	if mailFrom == "" {
+		mailFrom = "TelU-Hub <no-reply@telu-hub.local>"
+	}
+	if mailDir == "" {
+		mailDir = "./mail-outbox"
+	}
+
+	// Domain email yang boleh daftar, misal "telkomuniversity.ac.id,student.telkomuniversity.ac.id"
+	var allowedDomains []string
+	for _, d := range strings.Split(registerAllowedDomains, ",") {
+		if d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@")); d != "" {
+			allowedDomains = append(allowedDomains, d)
+		}
+	}
+
+	uploadOrphanTTL, err := parseDurationEnv("UPLOAD_ORPHAN_TTL", 24*time.Hour)
+	if err != nil {
+		return err
+	}
+	uploadSweepInterval, err := parseDurationEnv("UPLOAD_SWEEP_INTERVAL", time.Hour)
+	if err != nil {
+		return err
+	}
+
+	uploadMaxMB, err := parseIntEnv("UPLOAD_MAX_MB", 10)
+	if err != nil {
+		return err
+	}
+	uploadMaxDimension, err := parseIntEnv("UPLOAD_MAX_DIMENSION", 8000)
+	if err != nil {
+		return err
+	}
+	uploadQuotaUserMB, err := parseIntEnv("UPLOAD_QUOTA_USER_MB", 20)
+	if err != nil {
+		return err
+	}
+	uploadQuotaSellerMB, err := parseIntEnv("UPLOAD_QUOTA_SELLER_MB", 500)
+	if err != nil {
+		return err
+	}
 
 	if appPort == "" {
 		appPort = ":8080"
@@ -67,6 +243,72 @@ func LoadConfig() error {
 		return fmt.Errorf("ERROR: JWT_SECRET tidak diset di environment")
 	}
 
+	if paymentGateway == "" {
+		paymentGateway = "midtrans"
+	}
+	if paymentGateway != "midtrans" && paymentGateway != "fake" {
+		return fmt.Errorf("ERROR: PAYMENT_GATEWAY harus 'midtrans' atau 'fake', bukan %q", paymentGateway)
+	}
+	if midtransEnv == "" {
+		midtransEnv = "sandbox"
+	}
+	if midtransEnv != "sandbox" && midtransEnv != "production" {
+		return fmt.Errorf("ERROR: MIDTRANS_ENV harus 'sandbox' atau 'production', bukan %q", midtransEnv)
+	}
+	if paymentWebhookURL == "" {
+		paymentWebhookURL = "http://localhost" + appPort + "/api/v1/payments/webhook"
+	}
+	if fakePaymentKey == "" {
+		fakePaymentKey = "fake-server-key"
+	}
+
+	if storageDriver == "" {
+		storageDriver = "local"
+	}
+	if uploadDir == "" {
+		uploadDir = "./uploads"
+	}
+	// File private wajib di luar folder yang disajiin app.Static
+	if uploadPrivateDir == "" {
+		uploadPrivateDir = filepath.Clean(uploadDir) + "-private"
+	}
+	publicAbs, _ := filepath.Abs(uploadDir)
+	privateAbs, _ := filepath.Abs(uploadPrivateDir)
+	if rel, err := filepath.Rel(publicAbs, privateAbs); err != nil || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+		return fmt.Errorf("ERROR: UPLOAD_PRIVATE_DIR harus di luar UPLOAD_DIR, bukan %q", uploadPrivateDir)
+	}
+	switch storageDriver {
+	case "local":
+		// URL file dibangun dari sini, bukan dari host request (biar gak ikut host internal/proxy)
+		if storagePublicURL == "" {
+			if serverURL := os.Getenv("SERVER_URL"); serverURL != "" {
+				storagePublicURL = strings.TrimSuffix(serverURL, "/") + "/uploads"
+			} else {
+				storagePublicURL = "http://localhost" + appPort + "/uploads"
+			}
+		}
+	case "s3":
+		if s3Endpoint == "" || s3Bucket == "" || s3AccessKey == "" || s3SecretKey == "" {
+			return fmt.Errorf("ERROR: STORAGE_DRIVER=s3 butuh S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY & S3_SECRET_KEY")
+		}
+	default:
+		return fmt.Errorf("ERROR: STORAGE_DRIVER harus 'local' atau 's3', bukan %q", storageDriver)
+	}
+
+	// Folder upload lokal udah khusus buat upload, bucket S3 bisa aja dipake bareng app lain
+	if storageKeyPrefix == "" && storageDriver == "s3" {
+		storageKeyPrefix = "uploads/"
+	}
+	storageKeyPrefix = strings.Trim(storageKeyPrefix, "/")
+	if storageKeyPrefix != "" {
+		for _, part := range strings.Split(storageKeyPrefix, "/") {
+			if part == "" || part == "." || part == ".." {
+				return fmt.Errorf("ERROR: STORAGE_KEY_PREFIX tidak valid: %q", storageKeyPrefix)
+			}
+		}
+		storageKeyPrefix += "/"
+	}
+
 	Config = &configStruct{
 		AppPort:           appPort,
 		DBHost:            dbHost,
@@ -78,6 +320,57 @@ func LoadConfig() error {
 		JWTSecret:         jwtSecret,
 		MidtransServerKey: serverKey,
 		MidtransClientKey: clientKey,
+		MidtransEnv:       midtransEnv,
+
+		PaymentGateway:       paymentGateway,
+		PaymentWebhookURL:    paymentWebhookURL,
+		FakePaymentServerKey: fakePaymentKey,
+
+		PaymentReconcileInterval:   reconcileInterval,
+		PaymentReconcileStaleAfter: reconcileStaleAfter,
+
+		ReservationTTL:           reservationTTL,
+		ReservationSweepInterval: sweepInterval,
+
+		GuestCartTTL:           guestCartTTL,
+		GuestCartSweepInterval: guestCartSweepInterval,
+
+		AccessTokenTTL:  accessTokenTTL,
+		RefreshTokenTTL: refreshTokenTTL,
+
+		RequireEmailVerification: requireEmailVerification,
+		RegisterAllowedDomains:   allowedDomains,
+		EmailVerifyTTL:           emailVerifyTTL,
+		PasswordResetTTL:         passwordResetTTL,
+
+		MailDriver:   mailDriver,
+		MailFrom:     mailFrom,
+		MailDir:      mailDir,
+		SMTPHost:     smtpHost,
+		SMTPPort:     smtpPort,
+		SMTPUsername: smtpUsername,
+		SMTPPassword: smtpPassword,
+
+		UploadOrphanTTL:      uploadOrphanTTL,
+		UploadSweepInterval:  uploadSweepInterval,
+		UploadSweepUntracked: uploadSweepUntracked,
+
+		StorageDriver:    storageDriver,
+		StoragePublicURL: strings.TrimSuffix(storagePublicURL, "/"),
+		StorageKeyPrefix: storageKeyPrefix,
+		UploadDir:        uploadDir,
+		UploadPrivateDir: uploadPrivateDir,
+		S3Endpoint:       s3Endpoint,
+		S3Region:         s3Region,
+		S3Bucket:         s3Bucket,
+		S3AccessKey:      s3AccessKey,
+		S3SecretKey:      s3SecretKey,
+		S3PathStyle:      s3PathStyle,
+
+		UploadMaxMB:        uploadMaxMB,
+		UploadMaxDimension: uploadMaxDimension,
+		UploadQuotaUserMB:   uploadQuotaUserMB,
+		UploadQuotaSellerMB: uploadQuotaSellerMB,
 	}
 
 	return nil
@@ -119,3 +412,316 @@ func GetMidtransClientKey() string {
     if Config == nil { log.Fatal() }
     return Config.MidtransClientKey
 }
+
+func GetMidtransEnv() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.MidtransEnv
+}
+
+func GetPaymentGateway() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.PaymentGateway
+}
+
+func GetPaymentWebhookURL() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.PaymentWebhookURL
+}
+
+func GetFakePaymentServerKey() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.FakePaymentServerKey
+}
+
+// parseDurationEnv baca durasi format Go (misal "15m", "1h"), pake fallback kalau kosong.
+func parseDurationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("ERROR: %s tidak valid (contoh: 15m, 1h): %q", key, raw)
+	}
+	return d, nil
+}
+
+// parseIntEnv baca angka positif, pake fallback kalau kosong.
+func parseIntEnv(key string, fallback int) (int, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("ERROR: %s harus angka positif: %q", key, raw)
+	}
+	return n, nil
+}
+
+func GetReservationTTL() time.Duration {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.ReservationTTL
+}
+
+func GetReservationSweepInterval() time.Duration {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.ReservationSweepInterval
+}
+
+func GetGuestCartTTL() time.Duration {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.GuestCartTTL
+}
+
+func GetGuestCartSweepInterval() time.Duration {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.GuestCartSweepInterval
+}
+
+func GetPaymentReconcileInterval() time.Duration {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.PaymentReconcileInterval
+}
+
+func GetPaymentReconcileStaleAfter() time.Duration {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.PaymentReconcileStaleAfter
+}
+
+func GetAccessTokenTTL() time.Duration {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.AccessTokenTTL
+}
+
+func GetRefreshTokenTTL() time.Duration {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.RefreshTokenTTL
+}
+
+func GetRequireEmailVerification() bool {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.RequireEmailVerification
+}
+
+func GetRegisterAllowedDomains() []string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.RegisterAllowedDomains
+}
+
+func GetEmailVerifyTTL() time.Duration {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.EmailVerifyTTL
+}
+
+func GetPasswordResetTTL() time.Duration {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.PasswordResetTTL
+}
+
+func GetMailDriver() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.MailDriver
+}
+
+func GetMailFrom() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.MailFrom
+}
+
+func GetMailDir() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.MailDir
+}
+
+func GetSMTPHost() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.SMTPHost
+}
+
+func GetSMTPPort() int {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.SMTPPort
+}
+
+func GetSMTPUsername() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.SMTPUsername
+}
+
+func GetSMTPPassword() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.SMTPPassword
+}
+
+func GetUploadOrphanTTL() time.Duration {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.UploadOrphanTTL
+}
+
+func GetUploadSweepInterval() time.Duration {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.UploadSweepInterval
+}
+
+func GetUploadSweepUntracked() bool {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.UploadSweepUntracked
+}
+
+func GetStorageDriver() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.StorageDriver
+}
+
+func GetStoragePublicURL() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.StoragePublicURL
+}
+
+func GetStorageKeyPrefix() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.StorageKeyPrefix
+}
+
+func GetUploadDir() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.UploadDir
+}
+
+func GetUploadPrivateDir() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.UploadPrivateDir
+}
+
+func GetS3Endpoint() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.S3Endpoint
+}
+
+func GetS3Region() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.S3Region
+}
+
+func GetS3Bucket() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.S3Bucket
+}
+
+func GetS3AccessKey() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.S3AccessKey
+}
+
+func GetS3SecretKey() string {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.S3SecretKey
+}
+
+func GetS3PathStyle() bool {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.S3PathStyle
+}
+
+func GetUploadMaxMB() int {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.UploadMaxMB
+}
+
+func GetUploadMaxDimension() int {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.UploadMaxDimension
+}
+
+func GetUploadQuotaUserMB() int {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.UploadQuotaUserMB
+}
+
+func GetUploadQuotaSellerMB() int {
+	if Config == nil {
+		log.Fatal("Config belom di-load!")
+	}
+	return Config.UploadQuotaSellerMB
+}
@@ -0,0 +1,289 @@
+package account
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/akhdanrgya/telu-hub/internal/mailer"
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/session"
+	"github.com/akhdanrgya/telu-hub/internal/utils"
+	"gorm.io/gorm"
+)
+
+const minPasswordLength = 6
+
+// resendCooldown = jeda minimal antar email verifikasi/reset ke user yang sama.
+const resendCooldown = time.Minute
+
+var (
+	ErrEmailDomainNotAllowed = errors.New("domain email ini gak bisa dipake buat daftar")
+	ErrWeakPassword          = fmt.Errorf("password minimal %d karakter", minPasswordLength)
+	ErrWrongPassword         = errors.New("password lama salah")
+	ErrInvalidToken          = errors.New("link sudah tidak berlaku, minta link baru")
+)
+
+// Service ngurus alur akun lewat email: verifikasi email, lupa/reset password, ganti password.
+type Service struct {
+	DB             *gorm.DB
+	Mailer         mailer.Mailer
+	Sessions       *session.Service
+	ClientURL      string // base URL frontend buat link di email
+	VerifyTTL      time.Duration
+	ResetTTL       time.Duration
+	AllowedDomains []string // kosong = semua domain boleh daftar
+	// RequireVerification = user yang email-nya belum diverifikasi gak bisa login
+	RequireVerification bool
+}
+
+func NewService(db *gorm.DB, m mailer.Mailer, sessions *session.Service, clientURL string, verifyTTL, resetTTL time.Duration, allowedDomains []string, requireVerification bool) *Service {
+	return &Service{
+		DB:             db,
+		Mailer:         m,
+		Sessions:       sessions,
+		ClientURL:      strings.TrimSuffix(clientURL, "/"),
+		VerifyTTL:      verifyTTL,
+		ResetTTL:       resetTTL,
+		AllowedDomains: allowedDomains,
+
+		RequireVerification: requireVerification,
+	}
+}
+
+// CheckEmailDomain nolak email di luar AllowedDomains. Subdomain ikut boleh, jadi
+// "telkomuniversity.ac.id" juga nerima "student.telkomuniversity.ac.id".
+func (s *Service) CheckEmailDomain(email string) error {
+	if len(s.AllowedDomains) == 0 {
+		return nil
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ErrEmailDomainNotAllowed
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range s.AllowedDomains {
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w, pake email %s", ErrEmailDomainNotAllowed, strings.Join(s.AllowedDomains, " / "))
+}
+
+func ValidatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return ErrWeakPassword
+	}
+	return nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueToken bikin token baru; token lama dengan tujuan sama yang belum kepake ikut dimatiin.
+func (s *Service) issueToken(userID uint, purpose models.UserTokenPurpose, ttl time.Duration) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	raw := base64.RawURLEncoding.EncodeToString(buf)
+	now := time.Now()
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.UserToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+			Update("used_at", now).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&models.UserToken{
+			UserID:    userID,
+			Purpose:   purpose,
+			TokenHash: hashToken(raw),
+			ExpiresAt: now.Add(ttl),
+		}).Error
+	})
+	return raw, err
+}
+
+// recentlySent = udah ada token dengan tujuan sama yang dibikin barusan (anti spam email).
+func (s *Service) recentlySent(userID uint, purpose models.UserTokenPurpose) bool {
+	var count int64
+	s.DB.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND created_at > ?", userID, purpose, time.Now().Add(-resendCooldown)).
+		Count(&count)
+	return count > 0
+}
+
+// consumeToken nandain token kepake dalam satu UPDATE, jadi token yang sama gak bisa dipake dua kali.
+func consumeToken(tx *gorm.DB, raw string, purpose models.UserTokenPurpose) (*models.UserToken, error) {
+	if raw == "" {
+		return nil, ErrInvalidToken
+	}
+	now := time.Now()
+	result := tx.Model(&models.UserToken{}).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", hashToken(raw), purpose, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidToken
+	}
+	var token models.UserToken
+	if err := tx.Where("token_hash = ?", hashToken(raw)).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (s *Service) link(path, token string) string {
+	return s.ClientURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// SendVerification ngirim link verifikasi ke email user.
+func (s *Service) SendVerification(user *models.User) error {
+	token, err := s.issueToken(user.ID, models.TokenVerifyEmail, s.VerifyTTL)
+	if err != nil {
+		return err
+	}
+	return s.Mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verifikasi email akun TelU-Hub kamu",
+		Body: fmt.Sprintf("Halo %s,\n\nKlik link ini buat verifikasi email kamu:\n%s\n\nLink berlaku %s. Kalau kamu gak ngerasa daftar, abaikan aja email ini.\n",
+			user.Username, s.link("/verify-email", token), s.VerifyTTL),
+	})
+}
+
+// ResendVerification ngirim ulang link verifikasi. Email yang gak terdaftar / udah terverifikasi
+// diem-diem dilewatin biar gak bisa dipake ngecek email siapa aja yang terdaftar.
+func (s *Service) ResendVerification(email string) error {
+	var user models.User
+	if err := s.DB.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil
+	}
+	if user.EmailVerifiedAt != nil || s.recentlySent(user.ID, models.TokenVerifyEmail) {
+		return nil
+	}
+	return s.SendVerification(&user)
+}
+
+// VerifyEmail nandain email user terverifikasi pake token dari email.
+func (s *Service) VerifyEmail(raw string) (*models.User, error) {
+	var user models.User
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		token, err := consumeToken(tx, raw, models.TokenVerifyEmail)
+		if err != nil {
+			return err
+		}
+		if err := tx.First(&user, token.UserID).Error; err != nil {
+			return ErrInvalidToken
+		}
+		if user.EmailVerifiedAt != nil {
+			return nil
+		}
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		return tx.Model(&user).Update("email_verified_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// RequestPasswordReset ngirim link reset password. Sama kayak ResendVerification, email
+// yang gak terdaftar gak dikasih tau.
+func (s *Service) RequestPasswordReset(email string) error {
+	var user models.User
+	if err := s.DB.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil
+	}
+	if s.recentlySent(user.ID, models.TokenResetPassword) {
+		return nil
+	}
+	token, err := s.issueToken(user.ID, models.TokenResetPassword, s.ResetTTL)
+	if err != nil {
+		return err
+	}
+	return s.Mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset password akun TelU-Hub",
+		Body: fmt.Sprintf("Halo %s,\n\nAda permintaan reset password buat akun kamu. Klik link ini buat bikin password baru:\n%s\n\nLink berlaku %s dan cuma bisa dipake sekali. Kalau bukan kamu yang minta, abaikan aja email ini, password kamu gak berubah.\n",
+			user.Username, s.link("/reset-password", token), s.ResetTTL),
+	})
+}
+
+// ResetPassword ganti password pake token dari email. Semua session user di-logout.
+func (s *Service) ResetPassword(raw, newPassword string) error {
+	if err := ValidatePassword(newPassword); err != nil {
+		return err
+	}
+	hashed, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	var userID uint
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		token, err := consumeToken(tx, raw, models.TokenResetPassword)
+		if err != nil {
+			return err
+		}
+		userID = token.UserID
+		now := time.Now()
+		// Bisa buka link dari email = email-nya kebukti punya dia
+		err = tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"password":          hashed,
+			"email_verified_at": gorm.Expr("COALESCE(email_verified_at, ?)", now),
+		}).Error
+		if err != nil {
+			return err
+		}
+		if _, err := s.Sessions.RevokeAll(tx, userID, 0); err != nil {
+			return err
+		}
+		return s.Sessions.InvalidateAccessTokens(tx, userID)
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[AUTH] Password user #%d di-reset lewat email, semua session di-logout", userID)
+	return nil
+}
+
+// ChangePassword ganti password dari halaman profil. Session lain (device lain) di-logout,
+// session yang lagi dipake tetep jalan.
+func (s *Service) ChangePassword(userID, currentSessionID uint, currentPassword, newPassword string) error {
+	if err := ValidatePassword(newPassword); err != nil {
+		return err
+	}
+	var user models.User
+	if err := s.DB.First(&user, userID).Error; err != nil {
+		return err
+	}
+	if !utils.CheckPasswordHash(currentPassword, user.Password) {
+		return ErrWrongPassword
+	}
+	hashed, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("password", hashed).Error; err != nil {
+			return err
+		}
+		_, err := s.Sessions.RevokeAll(tx, userID, currentSessionID)
+		return err
+	})
+}
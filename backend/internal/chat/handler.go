@@ -0,0 +1,233 @@
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+)
+
+type Handler struct {
+	Service *Service
+}
+
+func NewHandler(s *Service) *Handler {
+	return &Handler{Service: s}
+}
+
+type ChatUserResponse struct {
+	ID              uint   `json:"id"`
+	Username        string `json:"username"`
+	ProfileImageURL string `json:"profile_image_url"`
+}
+
+type ChatRoomResponse struct {
+	ID            uint             `json:"id"`
+	BuyerID       uint             `json:"buyer_id"`
+	SellerID      uint             `json:"seller_id"`
+	ProductID     uint             `json:"product_id"`
+	OrderID       uint             `json:"order_id"`
+	Partner       ChatUserResponse `json:"partner"`
+	UnreadCount   int64            `json:"unread_count"`
+	LastMessageAt *time.Time       `json:"last_message_at"`
+	CreatedAt     time.Time        `json:"created_at"`
+}
+
+type sendMessageInput struct {
+	Content string `json:"content"`
+}
+
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, ErrRoomNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, ErrNotParticipant):
+		return fiber.StatusForbidden
+	case errors.Is(err, ErrInvalidRoom), errors.Is(err, ErrEmptyMessage), errors.Is(err, ErrMessageTooLong):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+func (h *Handler) CreateRoom(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var input RoomInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+
+	room, err := h.Service.GetOrCreateRoom(userID, input)
+	if err != nil {
+		return c.Status(statusFromError(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(room)
+}
+
+func (h *Handler) GetRooms(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	rooms, err := h.Service.GetUserRooms(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil daftar chat"})
+	}
+
+	response := make([]ChatRoomResponse, 0, len(rooms))
+	for _, room := range rooms {
+		partner := room.Seller
+		if room.SellerID == userID {
+			partner = room.Buyer
+		}
+
+		item := ChatRoomResponse{
+			ID:            room.ID,
+			BuyerID:       room.BuyerID,
+			SellerID:      room.SellerID,
+			ProductID:     room.ProductID,
+			OrderID:       room.OrderID,
+			UnreadCount:   h.Service.CountUnread(room.ID, userID),
+			LastMessageAt: room.LastMessageAt,
+			CreatedAt:     room.CreatedAt,
+		}
+		if partner != nil {
+			item.Partner = ChatUserResponse{
+				ID:              partner.ID,
+				Username:        partner.Username,
+				ProfileImageURL: partner.ProfileImageURL,
+			}
+		}
+		response = append(response, item)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// GetMessages: GET /chat/rooms/:id/messages?before_id=<cursor>&limit=<n>
+func (h *Handler) GetMessages(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID room tidak valid"})
+	}
+
+	room, err := h.Service.GetRoomForUser(uint(roomID), userID)
+	if err != nil {
+		return c.Status(statusFromError(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	beforeID, _ := strconv.ParseUint(c.Query("before_id"), 10, 64)
+	// Dibatesin di sini juga biar has_more dibandingin sama limit yang beneran dipake service
+	limit := ClampMessageLimit(c.QueryInt("limit", DefaultMessageLimit))
+
+	messages, err := h.Service.GetMessages(room.ID, uint(beforeID), limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil riwayat chat"})
+	}
+
+	var nextCursor uint
+	if len(messages) > 0 {
+		nextCursor = messages[len(messages)-1].ID
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data":        messages,
+		"next_cursor": nextCursor,
+		"has_more":    len(messages) > 0 && len(messages) >= limit,
+	})
+}
+
+func (h *Handler) SendMessage(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID room tidak valid"})
+	}
+
+	var input sendMessageInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+
+	room, err := h.Service.GetRoomForUser(uint(roomID), userID)
+	if err != nil {
+		return c.Status(statusFromError(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	msg, err := h.Service.SendMessage(room, userID, input.Content)
+	if err != nil {
+		return c.Status(statusFromError(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(msg)
+}
+
+func (h *Handler) MarkRead(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID room tidak valid"})
+	}
+
+	room, err := h.Service.GetRoomForUser(uint(roomID), userID)
+	if err != nil {
+		return c.Status(statusFromError(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.Service.MarkRoomRead(room.ID, userID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal update status"})
+	}
+	return c.JSON(fiber.Map{"message": "Chat ditandai sudah dibaca"})
+}
+
+// HandleWSConnection: /ws/chat/:channel_id, channel_id = ID ChatRoom.
+// Client ngirim {"content": "..."}, server nyebarin ChatMessage ke semua device di room.
+func (h *Handler) HandleWSConnection(c *websocket.Conn) {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok || userID == 0 {
+		log.Println("[CHAT-WS] Gagal konek: user tidak terautentikasi")
+		c.Close()
+		return
+	}
+
+	roomIDU64, err := strconv.ParseUint(c.Params("channel_id"), 10, 64)
+	if err != nil {
+		c.WriteJSON(fiber.Map{"error": "ID room tidak valid"})
+		c.Close()
+		return
+	}
+
+	room, err := h.Service.GetRoomForUser(uint(roomIDU64), userID)
+	if err != nil {
+		log.Printf("[CHAT-WS] User %d ditolak masuk Room %d: %v", userID, roomIDU64, err)
+		c.WriteJSON(fiber.Map{"error": err.Error()})
+		c.Close()
+		return
+	}
+
+	h.Service.Hub.Register(room.ID, userID, c)
+	defer h.Service.Hub.Unregister(room.ID, c)
+
+	for {
+		_, raw, err := c.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("[CHAT-WS] Koneksi User %d di Room %d putus: %v", userID, room.ID, err)
+			}
+			break
+		}
+
+		var input sendMessageInput
+		if err := json.Unmarshal(raw, &input); err != nil {
+			log.Printf("[CHAT-WS] Format pesan dari User %d tidak valid, skip", userID)
+			continue
+		}
+
+		if _, err := h.Service.SendMessage(room, userID, input.Content); err != nil {
+			log.Printf("[CHAT-WS] Gagal kirim pesan dari User %d: %v", userID, err)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package chat
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gofiber/websocket/v2"
+)
+
+const (
+	// clientBuffer = jumlah pesan yang boleh ngantri per koneksi sebelum koneksinya dianggep kelambatan.
+	clientBuffer = 32
+	// writeWait = batas waktu satu kali kirim ke socket.
+	writeWait = 10 * time.Second
+)
+
+// client = satu koneksi di room. Pesan dikirim lewat channel send yang dibaca goroutine
+// writePump-nya sendiri, jadi satu socket yang lemot gak nahan room lain.
+type client struct {
+	conn   *websocket.Conn
+	userID uint
+	send   chan interface{}
+}
+
+type ChatHub struct {
+	// RoomID -> koneksi -> client pemilik koneksi
+	Rooms map[uint]map[*websocket.Conn]*client
+
+	mu sync.RWMutex
+
+	Send chan RoomMessage
+}
+
+type RoomMessage struct {
+	RoomID  uint
+	Payload interface{}
+}
+
+func NewChatHub() *ChatHub {
+	return &ChatHub{
+		Rooms: make(map[uint]map[*websocket.Conn]*client),
+		Send:  make(chan RoomMessage),
+	}
+}
+
+func (h *ChatHub) Run() {
+	log.Println("[CHAT-HUB] Ruang obrolan siap beroperasi...")
+	for {
+		msg := <-h.Send
+
+		var slow []*websocket.Conn
+		h.mu.RLock()
+		for conn, cl := range h.Rooms[msg.RoomID] {
+			select {
+			case cl.send <- msg.Payload:
+			default:
+				slow = append(slow, conn)
+			}
+		}
+		h.mu.RUnlock()
+
+		// Antriannya penuh = socket-nya gak kebaca, diputus biar client konek ulang
+		for _, conn := range slow {
+			log.Printf("[CHAT-HUB] Salah satu device di Room %d kelambatan, koneksinya diputus", msg.RoomID)
+			h.Unregister(msg.RoomID, conn)
+		}
+	}
+}
+
+// writePump ngirim isi antrian client ke socket sampai antriannya ditutup Unregister.
+func (h *ChatHub) writePump(roomID uint, cl *client) {
+	for payload := range cl.send {
+		cl.conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := cl.conn.WriteJSON(payload); err != nil {
+			log.Printf("[CHAT-HUB] Gagal kirim ke salah satu device di Room %d: %v", roomID, err)
+			h.Unregister(roomID, cl.conn)
+			return
+		}
+	}
+}
+
+func (h *ChatHub) Register(roomID, userID uint, conn *websocket.Conn) {
+	cl := &client{conn: conn, userID: userID, send: make(chan interface{}, clientBuffer)}
+	h.mu.Lock()
+	if _, ok := h.Rooms[roomID]; !ok {
+		h.Rooms[roomID] = make(map[*websocket.Conn]*client)
+	}
+	h.Rooms[roomID][conn] = cl
+	h.mu.Unlock()
+	go h.writePump(roomID, cl)
+
+	log.Printf("[CHAT-HUB] User %d masuk Room %d", userID, roomID)
+}
+
+// Unregister aman dipanggil berkali-kali (dari handler, Run, atau writePump).
+func (h *ChatHub) Unregister(roomID uint, conn *websocket.Conn) {
+	h.mu.Lock()
+	if conns, ok := h.Rooms[roomID]; ok {
+		if cl, exists := conns[conn]; exists {
+			delete(conns, conn)
+			close(cl.send)
+			conn.Close()
+			log.Printf("[CHAT-HUB] User %d keluar dari Room %d", cl.userID, roomID)
+		}
+		if len(conns) == 0 {
+			delete(h.Rooms, roomID)
+		}
+	}
+	h.mu.Unlock()
+}
+
+// IsOnline ngecek apakah user punya minimal satu koneksi yang lagi buka room ini.
+func (h *ChatHub) IsOnline(roomID, userID uint) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, cl := range h.Rooms[roomID] {
+		if cl.userID == userID {
+			return true
+		}
+	}
+	return false
+}
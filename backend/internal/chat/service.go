@@ -0,0 +1,216 @@
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/notification"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultMessageLimit = 30
+	MaxMessageLimit     = 100
+	MaxMessageLength    = 2000
+)
+
+var (
+	ErrRoomNotFound   = errors.New("room chat tidak ditemukan")
+	ErrNotParticipant = errors.New("kamu bukan peserta room chat ini")
+	ErrInvalidRoom    = errors.New("data room chat tidak valid")
+	ErrEmptyMessage   = errors.New("pesan tidak boleh kosong")
+	ErrMessageTooLong = errors.New("pesan terlalu panjang")
+)
+
+type Service struct {
+	DB           *gorm.DB
+	Hub          *ChatHub
+	NotifService *notification.Service
+}
+
+func NewService(db *gorm.DB, hub *ChatHub, notifService *notification.Service) *Service {
+	return &Service{DB: db, Hub: hub, NotifService: notifService}
+}
+
+type RoomInput struct {
+	SellerID  uint `json:"seller_id"`
+	ProductID uint `json:"product_id"`
+	OrderID   uint `json:"order_id"`
+}
+
+// GetOrCreateRoom nyari room antara buyer & seller (opsional per produk/order),
+// kalau belum ada langsung dibikinin.
+func (s *Service) GetOrCreateRoom(buyerID uint, input RoomInput) (*models.ChatRoom, error) {
+	sellerID := input.SellerID
+
+	if input.ProductID != 0 {
+		var product models.Product
+		if err := s.DB.First(&product, input.ProductID).Error; err != nil {
+			return nil, fmt.Errorf("%w: produk tidak ditemukan", ErrInvalidRoom)
+		}
+		if sellerID != 0 && sellerID != product.SellerID {
+			return nil, fmt.Errorf("%w: produk bukan milik seller ini", ErrInvalidRoom)
+		}
+		sellerID = product.SellerID
+	}
+
+	if input.OrderID != 0 {
+		var order models.Order
+		if err := s.DB.Where("id = ? AND user_id = ?", input.OrderID, buyerID).First(&order).Error; err != nil {
+			return nil, fmt.Errorf("%w: order tidak ditemukan", ErrInvalidRoom)
+		}
+	}
+
+	if sellerID == 0 {
+		return nil, fmt.Errorf("%w: seller_id wajib diisi", ErrInvalidRoom)
+	}
+	if sellerID == buyerID {
+		return nil, fmt.Errorf("%w: tidak bisa chat dengan diri sendiri", ErrInvalidRoom)
+	}
+
+	var seller models.User
+	if err := s.DB.First(&seller, sellerID).Error; err != nil {
+		return nil, fmt.Errorf("%w: seller tidak ditemukan", ErrInvalidRoom)
+	}
+
+	room := models.ChatRoom{
+		BuyerID:   buyerID,
+		SellerID:  sellerID,
+		ProductID: input.ProductID,
+		OrderID:   input.OrderID,
+	}
+	// Pake kondisi string (bukan struct) biar product_id/order_id = 0 tetep ikut di-WHERE
+	err := s.DB.Where("buyer_id = ? AND seller_id = ? AND product_id = ? AND order_id = ?",
+		buyerID, sellerID, input.ProductID, input.OrderID).
+		FirstOrCreate(&room).Error
+	if err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+func (s *Service) GetUserRooms(userID uint) ([]models.ChatRoom, error) {
+	var rooms []models.ChatRoom
+	err := s.DB.Preload("Buyer").Preload("Seller").
+		Where("buyer_id = ? OR seller_id = ?", userID, userID).
+		Order("last_message_at desc nulls last, created_at desc").
+		Find(&rooms).Error
+	return rooms, err
+}
+
+// GetRoomForUser ngambil room sekaligus mastiin user-nya peserta room itu.
+func (s *Service) GetRoomForUser(roomID, userID uint) (*models.ChatRoom, error) {
+	var room models.ChatRoom
+	if err := s.DB.First(&room, roomID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoomNotFound
+		}
+		return nil, err
+	}
+	if !room.IsParticipant(userID) {
+		return nil, ErrNotParticipant
+	}
+	return &room, nil
+}
+
+// ClampMessageLimit = jumlah pesan per halaman yang beneran dipake (default kalau <= 0, maks MaxMessageLimit).
+func ClampMessageLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultMessageLimit
+	}
+	if limit > MaxMessageLimit {
+		return MaxMessageLimit
+	}
+	return limit
+}
+
+// GetMessages ngambil riwayat pesan terbaru dulu. beforeID dipake sebagai cursor
+// buat halaman berikutnya (pesan yang ID-nya lebih kecil dari beforeID).
+func (s *Service) GetMessages(roomID, beforeID uint, limit int) ([]models.ChatMessage, error) {
+	limit = ClampMessageLimit(limit)
+
+	query := s.DB.Where("room_id = ?", roomID)
+	if beforeID != 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	var messages []models.ChatMessage
+	err := query.Order("id desc").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+// SendMessage nyimpen pesan, nyebarin ke semua device di room, dan ngirim
+// notifikasi kalau lawan bicara lagi gak buka room-nya.
+func (s *Service) SendMessage(room *models.ChatRoom, senderID uint, content string) (*models.ChatMessage, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, ErrEmptyMessage
+	}
+	if len(content) > MaxMessageLength {
+		return nil, ErrMessageTooLong
+	}
+
+	msg := models.ChatMessage{
+		RoomID:   room.ID,
+		SenderID: senderID,
+		Content:  content,
+	}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&msg).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.ChatRoom{}).Where("id = ?", room.ID).
+			Update("last_message_at", msg.CreatedAt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.Hub.Send <- RoomMessage{RoomID: room.ID, Payload: msg}
+
+	recipientID := room.OtherParticipant(senderID)
+	if !s.Hub.IsOnline(room.ID, recipientID) {
+		go s.notifyOffline(room, recipientID, senderID, content)
+	}
+
+	return &msg, nil
+}
+
+func (s *Service) notifyOffline(room *models.ChatRoom, recipientID, senderID uint, content string) {
+	var sender models.User
+	s.DB.Select("id", "username").First(&sender, senderID)
+
+	preview := content
+	if len([]rune(preview)) > 80 {
+		preview = string([]rune(preview)[:80]) + "..."
+	}
+
+	if err := s.NotifService.CreateAndSend(
+		recipientID,
+		models.NotificationTypeChat,
+		"Pesan baru dari "+sender.Username,
+		preview,
+		room.ID,
+	); err != nil {
+		log.Printf("[CHAT] WARNING: Gagal kirim notif chat ke user %d: %v", recipientID, err)
+	}
+}
+
+// MarkRoomRead nandain semua pesan dari lawan bicara di room ini sebagai sudah dibaca.
+func (s *Service) MarkRoomRead(roomID, userID uint) error {
+	return s.DB.Model(&models.ChatMessage{}).
+		Where("room_id = ? AND sender_id <> ? AND is_read = ?", roomID, userID, false).
+		Update("is_read", true).Error
+}
+
+func (s *Service) CountUnread(roomID, userID uint) int64 {
+	var count int64
+	s.DB.Model(&models.ChatMessage{}).
+		Where("room_id = ? AND sender_id <> ? AND is_read = ?", roomID, userID, false).
+		Count(&count)
+	return count
+}
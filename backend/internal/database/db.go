@@ -24,19 +24,196 @@ func InitDB() {
 
 	log.Println("Database connection successful! 🐘")
 
+	// Dicek sebelum migrate: kalau kolomnya belum ada, user yang udah terdaftar dianggep terverifikasi
+	hadEmailVerification := DB.Migrator().HasColumn(&models.User{}, "EmailVerifiedAt")
+
 	log.Println("Menjalankan Auto Migration")
 	err = DB.AutoMigrate(
 		&models.User{}, 
 		&models.Product{},
+		&models.ProductVariant{},
+		&models.ProductImage{},
+		&models.Upload{},
+		&models.Session{},
+		&models.UserToken{},
+		&models.SellerApplication{},
+		&models.SellerApplicationDocument{},
+		&models.Store{},
+		&models.FulfilmentMethod{},
+		&models.Address{},
 		&models.Cart{},
 		&models.CartItem{},
+		&models.GuestCart{},
+		&models.GuestCartItem{},
 		&models.Order{},
 		&models.OrderItem{},
+		&models.Voucher{},
+		&models.VoucherRedemption{},
+		&models.Review{},
+		&models.ReviewPhoto{},
+		&models.WishlistItem{},
+		&models.StockAlert{},
 		&models.Notification{},
+		&models.ChatRoom{},
+		&models.ChatMessage{},
+		&models.StockReservation{},
+		&models.OrderStatusHistory{},
+		&models.SellerOrder{},
+		&models.PaymentEvent{},
+		&models.Refund{},
+		&models.RefundItem{},
 	)
 	if err != nil {
 		log.Fatalf("ERROR: Gagal nge-migrate tabel User: %v", err)
 	}
 
 	log.Println("Migrasi tabel sukses!")
+
+	createSearchIndexes()
+	if !hadEmailVerification {
+		backfillEmailVerification()
+	}
+	backfillStores()
+	backfillProductImages()
+	backfillSellerOrders()
+}
+
+// createSearchIndexes bikin index yang gak bisa dideklarasiin lewat tag gorm.
+// Expression-nya harus sama persis dengan models.ProductSearchExpr.
+func createSearchIndexes() {
+	err := DB.Exec("CREATE INDEX IF NOT EXISTS idx_products_search ON products USING GIN (" + models.ProductSearchExpr + ")").Error
+	if err != nil {
+		log.Printf("WARNING: Gagal bikin index pencarian produk: %v", err)
+	}
+
+	// SKU varian cuma unik di antara varian yang belum dihapus, biar SKU varian yang udah dihapus bisa dipake lagi.
+	// Unique index lama (dari tag uniqueIndex) ikut nahan SKU varian yang udah di-soft-delete, jadi dibuang.
+	err = DB.Exec("DROP INDEX IF EXISTS idx_product_variants_sku").Error
+	if err != nil {
+		log.Printf("WARNING: Gagal hapus index SKU varian lama: %v", err)
+	}
+	err = DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_product_variants_sku_active ON product_variants (sku) WHERE deleted_at IS NULL").Error
+	if err != nil {
+		log.Printf("WARNING: Gagal bikin index SKU varian: %v", err)
+	}
+
+	// Satu user cuma boleh punya satu pengajuan seller yang masih pending
+	err = DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_seller_applications_one_pending ON seller_applications (user_id) WHERE status = 'pending'").Error
+	if err != nil {
+		log.Printf("WARNING: Gagal bikin index pengajuan seller: %v", err)
+	}
+}
+
+// backfillEmailVerification nandain semua user lama (daftar sebelum ada verifikasi email)
+// udah terverifikasi, biar mereka gak tiba-tiba gak bisa login.
+func backfillEmailVerification() {
+	result := DB.Model(&models.User{}).Where("email_verified_at IS NULL").Update("email_verified_at", gorm.Expr("created_at"))
+	if result.Error != nil {
+		log.Printf("WARNING: Gagal nandain user lama terverifikasi: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("%d user lama ditandain udah verifikasi email", result.RowsAffected)
+	}
+}
+
+// backfillStores bikin toko buat seller lama (sebelum ada etalase toko) dan siapa aja yang
+// udah punya produk, nama tokonya default ke username.
+func backfillStores() {
+	var sellers []models.User
+	err := DB.Where("(role = ? OR EXISTS (SELECT 1 FROM products WHERE products.seller_id = users.id AND products.deleted_at IS NULL))", "seller").
+		Where("NOT EXISTS (SELECT 1 FROM stores WHERE stores.seller_id = users.id)").
+		Find(&sellers).Error
+	if err != nil {
+		log.Printf("WARNING: Gagal ngecek seller tanpa toko: %v", err)
+		return
+	}
+
+	for i := range sellers {
+		if _, err := models.EnsureStore(DB, &sellers[i], "", ""); err != nil {
+			log.Printf("WARNING: Gagal bikin toko buat seller %d: %v", sellers[i].ID, err)
+		}
+	}
+	if len(sellers) > 0 {
+		log.Printf("Toko %d seller lama berhasil dibikin", len(sellers))
+	}
+}
+
+// backfillProductImages bikin galeri buat produk lama (sebelum ada galeri): image_url-nya
+// dijadiin foto utama satu-satunya.
+func backfillProductImages() {
+	var products []models.Product
+	err := DB.Where("image_url <> '' AND NOT EXISTS (SELECT 1 FROM product_images WHERE product_images.product_id = products.id)").
+		Find(&products).Error
+	if err != nil {
+		log.Printf("WARNING: Gagal ngecek produk tanpa galeri: %v", err)
+		return
+	}
+
+	for _, p := range products {
+		image := models.ProductImage{ProductID: p.ID, URL: p.ImageURL, AltText: p.Name, IsPrimary: true}
+		if err := DB.Create(&image).Error; err != nil {
+			log.Printf("WARNING: Gagal bikin galeri produk #%d: %v", p.ID, err)
+		}
+	}
+	if len(products) > 0 {
+		log.Printf("Galeri %d produk lama berhasil dibikin dari image_url", len(products))
+	}
+}
+
+// backfillSellerOrders mecah order lama (sebelum ada sub-order) jadi sub-order per seller.
+// Item yang seller_order_id-nya masih kosong dikelompokin per seller, status ikut order induk.
+func backfillSellerOrders() {
+	// Produk yang udah di-soft-delete tetep dimuat, item-nya tetep harus masuk sub-order seller-nya
+	var items []models.OrderItem
+	err := DB.Preload("Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).Preload("Order").
+		Where("seller_order_id IS NULL").
+		Order("order_id asc, id asc").
+		Find(&items).Error
+	if err != nil {
+		log.Printf("WARNING: Gagal cek order lama buat dipecah per seller: %v", err)
+		return
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	type key struct{ orderID, sellerID uint }
+	grouped := make(map[key][]models.OrderItem)
+	var keys []key
+	for _, item := range items {
+		if item.Product == nil || item.Order == nil {
+			continue
+		}
+		k := key{item.OrderID, item.Product.SellerID}
+		if _, ok := grouped[k]; !ok {
+			keys = append(keys, k)
+		}
+		grouped[k] = append(grouped[k], item)
+	}
+
+	created := 0
+	for _, k := range keys {
+		lines := grouped[k]
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			sub := models.SellerOrder{OrderID: k.orderID, SellerID: k.sellerID, Status: lines[0].Order.Status}
+			for _, line := range lines {
+				sub.Subtotal += line.PriceAtTime * float64(line.Quantity)
+			}
+			if err := tx.Create(&sub).Error; err != nil {
+				return err
+			}
+			ids := make([]uint, 0, len(lines))
+			for _, line := range lines {
+				ids = append(ids, line.ID)
+			}
+			return tx.Model(&models.OrderItem{}).Where("id IN ?", ids).Update("seller_order_id", sub.ID).Error
+		})
+		if err != nil {
+			log.Printf("WARNING: Gagal bikin sub-order Order %d seller %d: %v", k.orderID, k.sellerID, err)
+			continue
+		}
+		created++
+	}
+	log.Printf("Order lama dipecah per seller: %d dari %d sub-order dibuat", created, len(keys))
 }
\ No newline at end of file
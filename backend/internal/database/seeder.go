@@ -3,8 +3,9 @@ package database
 import (
 	"log"
 	"strings"
-	"os"
+	"time"
 
+	"github.com/akhdanrgya/telu-hub/config"
 	"github.com/akhdanrgya/telu-hub/internal/models"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -14,6 +15,8 @@ func SeedAll(db *gorm.DB) {
 	seedUsers(db)
 
 	seedCategoriesAndProducts(db)
+
+	seedVariants(db)
 }
 
 func seedUsers(db *gorm.DB) {
@@ -42,18 +45,25 @@ func seedUsers(db *gorm.DB) {
 		}
 
 		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+		verifiedAt := time.Now()
 		
 		newUser := models.User{
 			Username: u.Username,
 			Email:    u.Email,
 			Password: string(hashedPassword),
 			Role:     u.Role,
+			EmailVerifiedAt: &verifiedAt,
 		}
 
 		if err := db.Create(&newUser).Error; err != nil {
 			log.Printf("❌ Gagal bikin user %s: %v", u.Username, err)
 		} else {
 			log.Printf("✅ User %s (%s) berhasil dibuat! 🚀", u.Username, u.Role)
+			if u.Role == "seller" {
+				if _, err := models.EnsureStore(db, &newUser, "", ""); err != nil {
+					log.Printf("❌ Gagal bikin toko %s: %v", u.Username, err)
+				}
+			}
 		}
 	}
 }
@@ -89,9 +99,7 @@ func seedCategoriesAndProducts(db *gorm.DB) {
 	db.Where("slug = ?", "electronics").First(&catElek)
 	db.Where("slug = ?", "clothing").First(&catCloth)
 
-	clientURL := os.Getenv("SERVER_URL")
-
-	baseURL := clientURL + "/uploads/"
+	baseURL := config.GetStoragePublicURL() + "/"
 
 	products := []models.Product{
 		{
@@ -130,10 +138,52 @@ func seedCategoriesAndProducts(db *gorm.DB) {
 			continue
 		}
 
+		p.Images = []models.ProductImage{{URL: p.ImageURL, AltText: p.Name, IsPrimary: true}}
 		if err := db.Create(&p).Error; err != nil {
 			log.Printf("❌ Gagal: %v", err)
 		} else {
 			log.Printf("✅ Produk %s created!", p.Name)
 		}
 	}
-}
\ No newline at end of file
+}
+
+// seedVariants ngasih ukuran ke produk pakaian yang belum punya varian.
+func seedVariants(db *gorm.DB) {
+	size := func(s string, price float64, stock int) models.ProductVariant {
+		return models.ProductVariant{Name: s, Options: models.VariantOptions{"ukuran": s}, Price: price, Stock: stock}
+	}
+
+	catalogue := map[string][]models.ProductVariant{
+		"kaos-polos-hitam": {size("S", 75000, 25), size("M", 75000, 25), size("L", 75000, 25), size("XL", 80000, 25)},
+		"hoodie-tel-u":     {size("M", 150000, 10), size("L", 150000, 10), size("XL", 160000, 10)},
+		"celana-chino":     {size("28", 120000, 10), size("30", 120000, 12), size("32", 120000, 12), size("34", 125000, 11)},
+	}
+
+	for productSlug, variants := range catalogue {
+		var product models.Product
+		if err := db.Where("slug = ?", productSlug).First(&product).Error; err != nil {
+			continue
+		}
+		var count int64
+		db.Model(&models.ProductVariant{}).Where("product_id = ?", product.ID).Count(&count)
+		if count > 0 {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, v := range variants {
+				v.ProductID = product.ID
+				v.SKU = strings.ToUpper(product.Slug + "-" + v.Name)
+				if err := tx.Create(&v).Error; err != nil {
+					return err
+				}
+			}
+			return models.SyncVariantTotals(tx, product.ID)
+		})
+		if err != nil {
+			log.Printf("❌ Gagal bikin varian %s: %v", product.Name, err)
+		} else {
+			log.Printf("✅ Varian %s dibuat (%d ukuran)!", product.Name, len(variants))
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package fulfilment
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMethodNotFound  = errors.New("metode pengiriman tidak ditemukan")
+	ErrAddressRequired = errors.New("pengiriman kurir butuh alamat, pilih alamat dulu")
+	ErrAddressNotFound = errors.New("alamat tidak ditemukan")
+	ErrCodeMismatch    = errors.New("kode serah terima salah")
+	ErrNoHandover      = errors.New("sub-order ini gak pake serah terima langsung")
+	ErrHandoverLocked  = errors.New("kode serah terima udah kebanyakan salah, hubungi admin buat konfirmasi serah terima")
+)
+
+// MaxHandoverAttempts = batas kode serah terima salah sebelum sub-order dikunci (kode cuma 6 digit).
+const MaxHandoverAttempts = 5
+
+// Selection = pilihan pengiriman buyer buat satu seller. MethodID 0 = pake default seller.
+type Selection struct {
+	SellerID  uint `json:"seller_id"`
+	MethodID  uint `json:"method_id"`
+	AddressID uint `json:"address_id"`
+}
+
+// Option = satu metode pengiriman seller lengkap sama ongkir buat berat keranjang sekarang.
+type Option struct {
+	Method    models.FulfilmentMethod `json:"method"`
+	Fee       float64                 `json:"fee"`
+	Available bool                    `json:"available"` // false = paket kelewat berat buat tabel ongkirnya
+}
+
+// Choice = hasil resolve pilihan buyer, siap disalin ke sub-order.
+type Choice struct {
+	Method  models.FulfilmentMethod
+	Fee     float64
+	Address string
+}
+
+type Service struct {
+	DB *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{DB: db}
+}
+
+// Methods = metode aktif milik seller. Seller yang belum ngatur apa-apa dapet metode default
+// (ketemuan gratis di titik ambil tokonya) biar tetep bisa dibeli.
+func (s *Service) Methods(db *gorm.DB, sellerID uint) ([]models.FulfilmentMethod, error) {
+	var methods []models.FulfilmentMethod
+	if err := db.Where("seller_id = ? AND is_active = ?", sellerID, true).Order("id asc").Find(&methods).Error; err != nil {
+		return nil, err
+	}
+	if len(methods) > 0 {
+		return methods, nil
+	}
+
+	var store models.Store
+	if err := db.Where("seller_id = ?", sellerID).First(&store).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		store.SellerID = sellerID
+	}
+	return []models.FulfilmentMethod{models.DefaultFulfilmentMethod(&store)}, nil
+}
+
+// Options = metode seller plus ongkirnya buat paket seberat weightGrams.
+func (s *Service) Options(sellerID uint, weightGrams int) ([]Option, error) {
+	methods, err := s.Methods(s.DB, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	options := make([]Option, 0, len(methods))
+	for _, m := range methods {
+		fee, err := m.Fee(weightGrams)
+		options = append(options, Option{Method: m, Fee: fee, Available: err == nil})
+	}
+	return options, nil
+}
+
+// Resolve nentuin metode, ongkir, dan alamat buat satu seller waktu checkout. Kalau buyer
+// gak milih, dipake metode pertama yang gak butuh alamat (atau default seller).
+func (s *Service) Resolve(tx *gorm.DB, userID, sellerID uint, sel *Selection, weightGrams int) (*Choice, error) {
+	methods, err := s.Methods(tx, sellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var method *models.FulfilmentMethod
+	if sel != nil && sel.MethodID != 0 {
+		for i := range methods {
+			if methods[i].ID == sel.MethodID {
+				method = &methods[i]
+				break
+			}
+		}
+		if method == nil {
+			return nil, ErrMethodNotFound
+		}
+	} else {
+		for i := range methods {
+			if !methods[i].Type.NeedsAddress() {
+				method = &methods[i]
+				break
+			}
+		}
+		if method == nil {
+			return nil, ErrAddressRequired
+		}
+	}
+
+	fee, err := method.Fee(weightGrams)
+	if err != nil {
+		return nil, err
+	}
+	choice := &Choice{Method: *method, Fee: fee}
+
+	if method.Type.NeedsAddress() {
+		if sel == nil || sel.AddressID == 0 {
+			return nil, ErrAddressRequired
+		}
+		var address models.Address
+		if err := tx.Where("id = ? AND user_id = ?", sel.AddressID, userID).First(&address).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrAddressNotFound
+			}
+			return nil, err
+		}
+		choice.Address = address.Format()
+	}
+	return choice, nil
+}
+
+// Assign nyalin pilihan pengiriman ke sub-order. Meetup / pickup dapet kode serah terima.
+func Assign(so *models.SellerOrder, choice *Choice) error {
+	if choice.Method.ID != 0 {
+		id := choice.Method.ID
+		so.FulfilmentMethodID = &id
+	}
+	so.FulfilmentType = choice.Method.Type
+	so.FulfilmentName = choice.Method.Name
+	so.ShippingFee = choice.Fee
+	so.ShippingAddress = choice.Address
+	if choice.Method.Type.UsesHandoverCode() {
+		so.MeetupPoint = choice.Method.Location
+		code, err := models.NewHandoverCode()
+		if err != nil {
+			return fmt.Errorf("gagal bikin kode serah terima: %w", err)
+		}
+		so.HandoverCode = code
+	}
+	return nil
+}
+
+// VerifyHandover ngecek kode yang ditunjukin buyer waktu barang diserahin. Yang manggil wajib
+// nambah HandoverAttempts tiap dapet ErrCodeMismatch; checkLock = false cuma buat admin.
+func VerifyHandover(so *models.SellerOrder, code string, checkLock bool) error {
+	if !so.FulfilmentType.UsesHandoverCode() || so.HandoverCode == "" {
+		return ErrNoHandover
+	}
+	if checkLock && so.HandoverAttempts >= MaxHandoverAttempts {
+		return ErrHandoverLocked
+	}
+	if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(code)), []byte(so.HandoverCode)) != 1 {
+		if remaining := MaxHandoverAttempts - so.HandoverAttempts - 1; checkLock && remaining > 0 {
+			return fmt.Errorf("%w (sisa %d percobaan)", ErrCodeMismatch, remaining)
+		}
+		return ErrCodeMismatch
+	}
+	return nil
+}
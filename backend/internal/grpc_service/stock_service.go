@@ -7,37 +7,44 @@ import (
 	pb "github.com/akhdanrgya/telu-hub/proto/stock"
 )
 
+// stockKey = satu channel siaran: produk (VariantID 0 = stok total) atau satu varian.
+type stockKey struct {
+	ProductID uint32
+	VariantID uint32
+}
+
 type StockService struct {
 	pb.UnimplementedStockServiceServer
 	mu sync.Mutex 
-	Hub map[uint32][]chan *pb.StockUpdateResponse 
+	Hub map[stockKey][]chan *pb.StockUpdateResponse 
 }
 
 func NewStockService() *StockService {
 	return &StockService{
-		Hub: make(map[uint32][]chan *pb.StockUpdateResponse),
+		Hub: make(map[stockKey][]chan *pb.StockUpdateResponse),
 	}
 }
 
 
 func (s *StockService) TrackStock(req *pb.TrackStockRequest, stream pb.StockService_TrackStockServer) error {
 	productID := req.GetProductId()
-	log.Printf("[gRPC] Penonton BARU terhubung untuk Produk ID: %d", productID)
+	key := stockKey{ProductID: productID, VariantID: req.GetVariantId()}
+	log.Printf("[gRPC] Penonton BARU terhubung untuk Produk ID: %d (varian %d)", productID, key.VariantID)
 
 	ch := make(chan *pb.StockUpdateResponse, 5)
 
 	s.mu.Lock()
-	s.Hub[productID] = append(s.Hub[productID], ch)
+	s.Hub[key] = append(s.Hub[key], ch)
 	s.mu.Unlock()
 
 	defer func() {
-		log.Printf("[gRPC] Penonton Produk ID: %d PUTUS", productID)
+		log.Printf("[gRPC] Penonton Produk ID: %d (varian %d) PUTUS", productID, key.VariantID)
 		s.mu.Lock()
 		defer s.mu.Unlock()
-		subscribers := s.Hub[productID]
+		subscribers := s.Hub[key]
 		for i, sub := range subscribers {
 			if sub == ch {
-				s.Hub[productID] = append(subscribers[:i], subscribers[i+1:]...)
+				s.Hub[key] = append(subscribers[:i], subscribers[i+1:]...)
 				break
 			}
 		}
@@ -57,17 +64,22 @@ func (s *StockService) TrackStock(req *pb.TrackStockRequest, stream pb.StockServ
 	}
 }
 
-func (s *StockService) BroadcastStockUpdate(productID uint, newStock int) {
+// BroadcastStockUpdate nyiarin stok fisik & stok yang lagi di-hold ke semua penonton produk.
+// variantID 0 = stok total produk, selain itu cuma ke penonton varian tersebut.
+func (s *StockService) BroadcastStockUpdate(productID uint, variantID uint, newStock int, reserved int) {
 	update := &pb.StockUpdateResponse{
 		ProductId: uint32(productID),
 		NewStock:  int32(newStock),
+		Available: int32(newStock - reserved),
+		Reserved:  int32(reserved),
+		VariantId: uint32(variantID),
 	}
-	log.Printf("[gRPC] SIARAN DIMULAI: Produk ID %d, Stok Baru %d", productID, newStock)
+	log.Printf("[gRPC] SIARAN DIMULAI: Produk ID %d varian %d, Stok %d (tersedia %d, di-hold %d)", productID, variantID, newStock, newStock-reserved, reserved)
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	subscribers, ok := s.Hub[uint32(productID)]
+	subscribers, ok := s.Hub[stockKey{ProductID: uint32(productID), VariantID: uint32(variantID)}]
 	if !ok {
 		return
 	}
@@ -0,0 +1,311 @@
+package guestcart
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidToken      = errors.New("cart token tidak valid")
+	ErrCartNotFound      = errors.New("keranjang tamu tidak ditemukan")
+	ErrItemNotFound      = errors.New("item di keranjang tidak ditemukan")
+	ErrProductNotFound   = errors.New("produk tidak ditemukan")
+	ErrVariantRequired   = errors.New("pilih varian produk dulu")
+	ErrVariantNotFound   = errors.New("varian produk tidak ditemukan")
+	ErrNoVariants        = errors.New("produk ini tidak punya varian")
+	ErrInsufficientStock = errors.New("stok tidak cukup")
+	ErrInvalidQuantity   = errors.New("quantity minimal 1")
+)
+
+// Adjustment = item tamu yang gak bisa digabung utuh ke keranjang user.
+type Adjustment struct {
+	ProductID uint   `json:"product_id"`
+	Name      string `json:"name"`
+	Message   string `json:"message"`
+}
+
+// MergeResult = ringkasan penggabungan keranjang tamu waktu login/daftar.
+type MergeResult struct {
+	Merged   int          `json:"merged"` // Baris tamu yang masuk ke keranjang user
+	Adjusted []Adjustment `json:"adjusted"`
+}
+
+type Service struct {
+	DB  *gorm.DB
+	TTL time.Duration // Keranjang tamu yang gak disentuh selama ini dianggap ditinggal
+}
+
+func NewService(db *gorm.DB, ttl time.Duration) *Service {
+	return &Service{DB: db, TTL: ttl}
+}
+
+// Load ngambil keranjang tamu dari cart token, lengkap sama produk/varian (termasuk yang udah dihapus).
+func (s *Service) Load(token string) (*models.GuestCart, error) {
+	id, err := utils.ParseCartToken(token)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var cart models.GuestCart
+	if err := models.UnscopedGuestCartItems(s.DB).Order("id asc").First(&cart, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCartNotFound
+		}
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// Create bikin keranjang tamu kosong + token-nya.
+func (s *Service) Create() (*models.GuestCart, string, error) {
+	cart := models.GuestCart{LastActiveAt: time.Now(), Items: []models.GuestCartItem{}}
+	if err := s.DB.Create(&cart).Error; err != nil {
+		return nil, "", err
+	}
+	token, err := utils.GenerateCartToken(cart.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	return &cart, token, nil
+}
+
+// Touch nandain keranjang masih dipake biar gak kesapu PurgeAbandoned.
+func (s *Service) Touch(cart *models.GuestCart) error {
+	cart.LastActiveAt = time.Now()
+	return s.DB.Model(cart).UpdateColumn("last_active_at", cart.LastActiveAt).Error
+}
+
+// AddItem nambah produk ke keranjang tamu. Produk yang sama dijumlahin, stok dicek kayak keranjang user.
+func (s *Service) AddItem(cart *models.GuestCart, productID uint, variantID *uint, quantity int) error {
+	if quantity <= 0 {
+		quantity = 1
+	}
+
+	var product models.Product
+	if err := s.DB.Preload("Variants").First(&product, productID).Error; err != nil {
+		return ErrProductNotFound
+	}
+	available := product.Available()
+	price := product.Price
+	if len(product.Variants) > 0 {
+		if variantID == nil {
+			return ErrVariantRequired
+		}
+		var variant *models.ProductVariant
+		for i := range product.Variants {
+			if product.Variants[i].ID == *variantID {
+				variant = &product.Variants[i]
+			}
+		}
+		if variant == nil {
+			return ErrVariantNotFound
+		}
+		available = variant.Available()
+		price = variant.Price
+	} else if variantID != nil {
+		return ErrNoVariants
+	}
+
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		if item.ProductID != productID || !sameVariant(item.VariantID, variantID) {
+			continue
+		}
+		if item.Quantity+quantity > available {
+			return ErrInsufficientStock
+		}
+		item.Quantity += quantity
+		item.PriceAtAdd = price
+		return s.DB.Model(item).Updates(map[string]interface{}{"quantity": item.Quantity, "price_at_add": price}).Error
+	}
+
+	if quantity > available {
+		return ErrInsufficientStock
+	}
+	return s.DB.Create(&models.GuestCartItem{
+		GuestCartID: cart.ID,
+		ProductID:   productID,
+		VariantID:   variantID,
+		Quantity:    quantity,
+		PriceAtAdd:  price,
+	}).Error
+}
+
+// UpdateItem ngeganti jumlah satu baris keranjang tamu.
+func (s *Service) UpdateItem(cart *models.GuestCart, itemID uint, quantity int) error {
+	if quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+	item := findItem(cart, itemID)
+	if item == nil {
+		return ErrItemNotFound
+	}
+	ci := item.AsCartItem()
+	if ci.Unavailable() || quantity > ci.Available() {
+		return ErrInsufficientStock
+	}
+	item.Quantity = quantity
+	return s.DB.Model(item).Update("quantity", quantity).Error
+}
+
+// RemoveItem ngapus satu baris keranjang tamu.
+func (s *Service) RemoveItem(cart *models.GuestCart, itemID uint) error {
+	if findItem(cart, itemID) == nil {
+		return ErrItemNotFound
+	}
+	return s.DB.Unscoped().Delete(&models.GuestCartItem{}, itemID).Error
+}
+
+// Merge mindahin isi keranjang tamu ke keranjang user. Produk yang udah ada dijumlahin,
+// jumlahnya dipotong ke stok yang ada, dan barang yang udah gak dijual / stoknya habis dilewatin.
+// Keranjang tamunya dihapus setelah itu, jadi token yang sama gak bisa digabung dua kali.
+func (s *Service) Merge(token string, userID uint) (*MergeResult, error) {
+	guestID, err := utils.ParseCartToken(token)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	result := &MergeResult{Adjusted: []Adjustment{}}
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		var guest models.GuestCart
+		if err := models.UnscopedGuestCartItems(tx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&guest, guestID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCartNotFound
+			}
+			return err
+		}
+
+		var cart models.Cart
+		if err := tx.Preload("CartItems").Where(models.Cart{UserID: userID}).FirstOrCreate(&cart).Error; err != nil {
+			return err
+		}
+
+		for i := range guest.Items {
+			item := guest.Items[i].AsCartItem()
+			name := itemName(&item)
+			if item.Unavailable() {
+				result.Adjusted = append(result.Adjusted, Adjustment{ProductID: item.ProductID, Name: name, Message: "Gak dipindah karena udah gak dijual"})
+				continue
+			}
+
+			var existing *models.CartItem
+			for j := range cart.CartItems {
+				if cart.CartItems[j].ProductID == item.ProductID && sameVariant(cart.CartItems[j].VariantID, item.VariantID) {
+					existing = &cart.CartItems[j]
+				}
+			}
+			wanted := item.Quantity
+			if existing != nil {
+				wanted += existing.Quantity
+			}
+			quantity := min(wanted, item.Available())
+			if quantity <= 0 {
+				result.Adjusted = append(result.Adjusted, Adjustment{ProductID: item.ProductID, Name: name, Message: "Gak dipindah karena stoknya habis"})
+				continue
+			}
+			if quantity < wanted {
+				result.Adjusted = append(result.Adjusted, Adjustment{ProductID: item.ProductID, Name: name, Message: fmt.Sprintf("Jumlah diturunin dari %d jadi %d sesuai stok", wanted, quantity)})
+			}
+
+			if existing != nil {
+				// Snapshot harga ikut yang dari keranjang tamu, itu yang terakhir dilihat buyer
+				if err := tx.Model(existing).Updates(map[string]interface{}{"quantity": quantity, "price_at_add": item.PriceAtAdd}).Error; err != nil {
+					return err
+				}
+				existing.Quantity = quantity
+			} else {
+				newItem := models.CartItem{
+					CartID:     cart.ID,
+					ProductID:  item.ProductID,
+					VariantID:  item.VariantID,
+					Quantity:   quantity,
+					PriceAtAdd: item.PriceAtAdd,
+				}
+				if err := tx.Create(&newItem).Error; err != nil {
+					return err
+				}
+				cart.CartItems = append(cart.CartItems, newItem)
+			}
+			result.Merged++
+		}
+
+		return deleteCarts(tx, []uint{guest.ID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PurgeAbandoned ngapus permanen keranjang tamu yang udah lewat TTL sejak terakhir dipake.
+func (s *Service) PurgeAbandoned() (int, error) {
+	var ids []uint
+	cutoff := time.Now().Add(-s.TTL)
+	if err := s.DB.Model(&models.GuestCart{}).Where("last_active_at < ?", cutoff).Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	if err := s.DB.Transaction(func(tx *gorm.DB) error { return deleteCarts(tx, ids) }); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// RunPurgeWorker jalan terus di background, nyapu keranjang tamu yang ditinggal tiap interval.
+func (s *Service) RunPurgeWorker(interval time.Duration) {
+	log.Printf("[GUESTCART] Worker keranjang tamu jalan (TTL %s, cek tiap %s)", s.TTL, interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		count, err := s.PurgeAbandoned()
+		if err != nil {
+			log.Printf("[GUESTCART] WARNING: Gagal nyapu keranjang tamu: %v", err)
+			continue
+		}
+		if count > 0 {
+			log.Printf("[GUESTCART] %d keranjang tamu yang ditinggal dihapus", count)
+		}
+	}
+}
+
+func deleteCarts(tx *gorm.DB, ids []uint) error {
+	if err := tx.Unscoped().Where("guest_cart_id IN ?", ids).Delete(&models.GuestCartItem{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Delete(&models.GuestCart{}, ids).Error
+}
+
+func findItem(cart *models.GuestCart, itemID uint) *models.GuestCartItem {
+	for i := range cart.Items {
+		if cart.Items[i].ID == itemID {
+			return &cart.Items[i]
+		}
+	}
+	return nil
+}
+
+func itemName(item *models.CartItem) string {
+	if item.Product == nil {
+		return "Barang yang udah dihapus"
+	}
+	if item.Variant != nil {
+		return item.Product.Name + " - " + item.Variant.Name
+	}
+	return item.Product.Name
+}
+
+func sameVariant(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -1,20 +1,49 @@
 package handlers
 
 import (
+	"errors"
 	"log"
+	"strings"
+	"time"
 
+	"github.com/akhdanrgya/telu-hub/internal/account"
+	"github.com/akhdanrgya/telu-hub/internal/guestcart"
 	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/session"
 	"github.com/akhdanrgya/telu-hub/internal/utils"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
 type AuthHandler struct {
-	DB *gorm.DB
+	DB         *gorm.DB
+	Sessions   *session.Service
+	Accounts   *account.Service
+	GuestCarts *guestcart.Service
 }
 
-func NewAuthHandler(db *gorm.DB) *AuthHandler {
-	return &AuthHandler{DB: db}
+func NewAuthHandler(db *gorm.DB, sessions *session.Service, accounts *account.Service, guestCarts *guestcart.Service) *AuthHandler {
+	return &AuthHandler{DB: db, Sessions: sessions, Accounts: accounts, GuestCarts: guestCarts}
+}
+
+// mergeGuestCart mindahin keranjang tamu (token dari body atau header X-Cart-Token) ke keranjang user.
+// Gagal gabung gak ngebatalin login/daftar, paling isi keranjang tamunya aja yang gak kebawa.
+func (h *AuthHandler) mergeGuestCart(c *fiber.Ctx, token string, userID uint) *guestcart.MergeResult {
+	if token == "" {
+		token = c.Get(CartTokenHeader)
+	}
+	if token == "" {
+		return nil
+	}
+	result, err := h.GuestCarts.Merge(token, userID)
+	if err != nil {
+		if !errors.Is(err, guestcart.ErrInvalidToken) && !errors.Is(err, guestcart.ErrCartNotFound) {
+			log.Printf("[AUTH] WARNING: Gagal gabung keranjang tamu ke user %d: %v", userID, err)
+		}
+		return nil
+	}
+	log.Printf("[AUTH] Keranjang tamu digabung ke user %d (%d barang, %d disesuaikan)", userID, result.Merged, len(result.Adjusted))
+	return result
 }
 
 type UserResponse struct {
@@ -23,13 +52,15 @@ type UserResponse struct {
 	Email     string `json:"email"`
 	Role      string `json:"role"`
 	ProfileImageURL string `json:"profile_image_url"`
+	EmailVerified   bool   `json:"email_verified"`
 }
 
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	type RegisterInput struct {
-		Username string `json:"username" validate:"required,min=3"`
-		Email    string `json:"email" validate:"required,email"`
-		Password string `json:"password" validate:"required,min=6"`
+		Username  string `json:"username" validate:"required,min=3"`
+		Email     string `json:"email" validate:"required,email"`
+		Password  string `json:"password" validate:"required,min=6"`
+		CartToken string `json:"cart_token"` // Keranjang tamu yang mau dibawa
 	}
 	
 	input := new(RegisterInput)
@@ -40,6 +71,14 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		})
 	}
 
+	input.Email = strings.TrimSpace(input.Email)
+	if err := h.Accounts.CheckEmailDomain(input.Email); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := account.ValidatePassword(input.Password); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
 	hashedPassword, err := utils.HashPassword(input.Password)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -69,12 +108,19 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	if err := h.DB.Create(&cart).Error; err != nil {
 		log.Printf("Gagal bikin cart buat user %d: %v", user.ID, err)
 	}
+	h.mergeGuestCart(c, input.CartToken, user.ID)
+
+	// Gagal kirim email gak ngebatalin daftar, user bisa minta kirim ulang
+	if err := h.Accounts.SendVerification(&user); err != nil {
+		log.Printf("[AUTH] WARNING: Gagal kirim email verifikasi ke user %d: %v", user.ID, err)
+	}
 
 	response := UserResponse{
 		ID:       user.ID,
 		Username: user.Username,
 		Email:    user.Email,
 		Role:     user.Role,
+		EmailVerified: user.EmailVerifiedAt != nil,
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(response)
@@ -83,8 +129,9 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	type LoginInput struct {
-		Email    string `json:"email" validate:"required,email"`
-		Password string `json:"password" validate:"required"`
+		Email     string `json:"email" validate:"required,email"`
+		Password  string `json:"password" validate:"required"`
+		CartToken string `json:"cart_token"` // Keranjang tamu yang mau dibawa
 	}
 	input := new(LoginInput)
 	if err := c.BodyParser(input); err != nil { /*... error ...*/ }
@@ -95,28 +142,259 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	if !utils.CheckPasswordHash(input.Password, user.Password) {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Email atau Password salah"})
 	}
+	if h.Accounts.RequireVerification && user.EmailVerifiedAt == nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Email kamu belum diverifikasi, cek inbox buat link verifikasinya",
+			"code":  "email_not_verified",
+		})
+	}
 
-	tokenString, err := utils.GenerateToken(user.ID, user.Role)
+	tokens, err := h.Sessions.Start(&user, c.Get("User-Agent"), c.IP())
 	if err != nil {
+		log.Printf("[AUTH] ERROR: Gagal bikin session user %d: %v", user.ID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal membuat token"})
 	}
+	cartMerge := h.mergeGuestCart(c, input.CartToken, user.ID)
 	response := UserResponse{
 		ID:       user.ID,
 		Username: user.Username,
 		Email:    user.Email,
 		Role:     user.Role,
+		EmailVerified: user.EmailVerifiedAt != nil,
 	}
 
 	// 🚨 KIRIM TOKENNYA DI JSON 🚨
+	// "token" = access token juga, dipertahanin buat client lama
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":            "Login berhasil",
+		"token":              tokens.AccessToken,
+		"access_token":       tokens.AccessToken,
+		"expires_at":         tokens.ExpiresAt,
+		"refresh_token":      tokens.RefreshToken,
+		"refresh_expires_at": tokens.RefreshExpiresAt,
+		"user":               response,
+		"cart_merge":         cartMerge, // null kalau gak ada keranjang tamu
+	})
+}
+
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// POST /auth/refresh
+// Refresh token cuma bisa dipake sekali, response-nya bawa refresh token pengganti.
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	input := new(RefreshInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+
+	tokens, user, err := h.Sessions.Rotate(input.RefreshToken, c.Get("User-Agent"), c.IP())
+	if err != nil {
+		if errors.Is(err, session.ErrInvalidRefresh) || errors.Is(err, session.ErrRefreshReused) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		}
+		log.Printf("[AUTH] ERROR: Gagal refresh token: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal membuat token"})
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Login berhasil",
-		"token":   tokenString,
-		"user":    response,
+		"token":              tokens.AccessToken,
+		"access_token":       tokens.AccessToken,
+		"expires_at":         tokens.ExpiresAt,
+		"refresh_token":      tokens.RefreshToken,
+		"refresh_expires_at": tokens.RefreshExpiresAt,
+		"user": UserResponse{
+			ID:              user.ID,
+			Username:        user.Username,
+			Email:           user.Email,
+			Role:            user.Role,
+			EmailVerified:   user.EmailVerifiedAt != nil,
+			ProfileImageURL: user.ProfileImageURL,
+		},
 	})
 }
 
+// POST /auth/logout
+// Pake refresh token di body, jadi tetep bisa logout walaupun access token-nya udah expired.
 func (h *AuthHandler) Logout(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Logout diproses"})
+	input := new(RefreshInput)
+	if err := c.BodyParser(input); err != nil || input.RefreshToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "refresh_token wajib diisi"})
+	}
+
+	if err := h.Sessions.RevokeToken(input.RefreshToken); err != nil && !errors.Is(err, session.ErrSessionNotFound) {
+		log.Printf("[AUTH] ERROR: Gagal logout: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal logout"})
+	}
+	// Token yang gak dikenal dianggep udah logout
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Logout berhasil"})
+}
+
+type EmailInput struct {
+	Email string `json:"email"`
+}
+
+type TokenInput struct {
+	Token string `json:"token"`
+}
+
+type ResetPasswordInput struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// POST /auth/verify-email
+func (h *AuthHandler) VerifyEmail(c *fiber.Ctx) error {
+	input := new(TokenInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+
+	user, err := h.Accounts.VerifyEmail(input.Token)
+	if err != nil {
+		if errors.Is(err, account.ErrInvalidToken) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		log.Printf("[AUTH] ERROR: Gagal verifikasi email: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal verifikasi email"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Email berhasil diverifikasi, silakan login", "email": user.Email})
+}
+
+// POST /auth/verify-email/resend
+// Selalu 200 biar gak bisa dipake nebak email yang terdaftar.
+func (h *AuthHandler) ResendVerification(c *fiber.Ctx) error {
+	input := new(EmailInput)
+	if err := c.BodyParser(input); err != nil || strings.TrimSpace(input.Email) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "email wajib diisi"})
+	}
+
+	if err := h.Accounts.ResendVerification(strings.TrimSpace(input.Email)); err != nil {
+		log.Printf("[AUTH] WARNING: Gagal kirim ulang email verifikasi: %v", err)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Kalau email itu terdaftar dan belum diverifikasi, link verifikasi udah dikirim"})
+}
+
+// POST /auth/password/forgot
+// Selalu 200 biar gak bisa dipake nebak email yang terdaftar.
+func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	input := new(EmailInput)
+	if err := c.BodyParser(input); err != nil || strings.TrimSpace(input.Email) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "email wajib diisi"})
+	}
+
+	if err := h.Accounts.RequestPasswordReset(strings.TrimSpace(input.Email)); err != nil {
+		log.Printf("[AUTH] WARNING: Gagal kirim email reset password: %v", err)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Kalau email itu terdaftar, link reset password udah dikirim"})
+}
+
+// POST /auth/password/reset
+func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	input := new(ResetPasswordInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+
+	if err := h.Accounts.ResetPassword(input.Token, input.Password); err != nil {
+		if errors.Is(err, account.ErrInvalidToken) || errors.Is(err, account.ErrWeakPassword) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		log.Printf("[AUTH] ERROR: Gagal reset password: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal reset password"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Password berhasil diganti, silakan login lagi"})
+}
+
+// PUT /me/password
+func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	sessionID, _ := c.Locals("session_id").(uint)
+
+	input := new(ChangePasswordInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+
+	if err := h.Accounts.ChangePassword(userID, sessionID, input.CurrentPassword, input.NewPassword); err != nil {
+		if errors.Is(err, account.ErrWrongPassword) || errors.Is(err, account.ErrWeakPassword) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		log.Printf("[AUTH] ERROR: Gagal ganti password user %d: %v", userID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal ganti password"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Password berhasil diganti, device lain udah di-logout"})
+}
+
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// GET /me/sessions
+func (h *AuthHandler) GetMySessions(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	currentID, _ := c.Locals("session_id").(uint)
+
+	sessions, err := h.Sessions.List(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil daftar session"})
+	}
+
+	response := make([]SessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		response = append(response, SessionResponse{
+			ID:         s.ID,
+			UserAgent:  s.UserAgent,
+			IPAddress:  s.IPAddress,
+			CreatedAt:  s.CreatedAt,
+			LastUsedAt: s.LastUsedAt,
+			ExpiresAt:  s.ExpiresAt,
+			Current:    s.ID == currentID,
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// DELETE /me/sessions/:id
+func (h *AuthHandler) RevokeMySession(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	sessionID, err := c.ParamsInt("id")
+	if err != nil || sessionID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID session tidak valid"})
+	}
+
+	if err := h.Sessions.Revoke(userID, uint(sessionID)); err != nil {
+		if errors.Is(err, session.ErrSessionNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal logout session"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Session berhasil di-logout"})
+}
+
+// DELETE /me/sessions
+// Logout dari semua device lain, session yang lagi dipake gak ikut.
+func (h *AuthHandler) RevokeOtherSessions(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	currentID, _ := c.Locals("session_id").(uint)
+
+	count, err := h.Sessions.RevokeAll(h.DB, userID, currentID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal logout session lain"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Session lain berhasil di-logout", "revoked": count})
 }
 
 
@@ -136,6 +414,7 @@ func (h *AuthHandler) GetUserData(c *fiber.Ctx) error {
 		Username:  user.Username,
 		Email:     user.Email,
 		Role:      user.Role,
+		EmailVerified: user.EmailVerifiedAt != nil,
 		ProfileImageURL: user.ProfileImageURL, 
 	}
 
@@ -165,8 +444,24 @@ func (h *AuthHandler) PromoteUser(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User target tidak ditemukan"})
 	}
 
+	roleChanged := user.Role != input.Role
 	user.Role = input.Role
-	if err := h.DB.Save(&user).Error; err != nil {
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		if !roleChanged {
+			return nil
+		}
+		if user.Role == "seller" {
+			if _, err := models.EnsureStore(tx, &user, "", ""); err != nil {
+				return err
+			}
+		}
+		// Access token lama masih bawa role lama, paksa client refresh
+		return h.Sessions.InvalidateAccessTokens(tx, user.ID)
+	})
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengupdate role user"})
 	}
 
@@ -175,6 +470,7 @@ func (h *AuthHandler) PromoteUser(c *fiber.Ctx) error {
 		Username: user.Username,
 		Email:    user.Email,
 		Role:     user.Role,
+		EmailVerified: user.EmailVerifiedAt != nil,
 		ProfileImageURL: user.ProfileImageURL,
 	}
 	
@@ -194,6 +490,7 @@ func (h *AuthHandler) GetAllUsers(c *fiber.Ctx) error {
 			Username:        user.Username,
 			Email:           user.Email,
 			Role:            user.Role,
+			EmailVerified:   user.EmailVerifiedAt != nil,
 			ProfileImageURL: user.ProfileImageURL,
 		})
 	}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"log"
 
 	"github.com/akhdanrgya/telu-hub/internal/models"
@@ -17,8 +18,9 @@ func NewCartHandler(db *gorm.DB) *CartHandler {
 }
 
 type AddItemInput struct {
-	ProductID uint `json:"product_id" validate:"required"`
-	Quantity  int  `json:"quantity" validate:"required,min=1"`
+	ProductID uint  `json:"product_id" validate:"required"`
+	VariantID *uint `json:"variant_id"` // Wajib kalau produknya punya varian
+	Quantity  int   `json:"quantity" validate:"required,min=1"`
 }
 
 type CartProductResponse struct {
@@ -29,24 +31,105 @@ type CartProductResponse struct {
 	ImageURL string  `json:"image_url"`
 }
 
+type CartVariantResponse struct {
+	ID   uint   `json:"id"`
+	SKU  string `json:"sku"`
+	Name string `json:"name"`
+}
+
 type CartItemResponse struct {
-	ID        uint                `json:"id"`
-	Quantity  int                 `json:"quantity"`
-	ProductID uint                `json:"product_id"`
-	Product   CartProductResponse `json:"Product"`
+	ID          uint                 `json:"id"`
+	Quantity    int                  `json:"quantity"`
+	ProductID   uint                 `json:"product_id"`
+	VariantID   *uint                `json:"variant_id"`
+	PriceAtAdd  float64              `json:"price_at_add"` // Harga terakhir yang dilihat buyer
+	Available   int                  `json:"available"`
+	Unavailable bool                 `json:"unavailable"` // Produk / varian udah dihapus seller
+	Warnings    []models.CartWarning `json:"warnings"`
+	Product     CartProductResponse  `json:"Product"`
+	Variant     *CartVariantResponse `json:"Variant,omitempty"`
+}
+
+// CartChange = satu perubahan yang dilakuin POST /cart/refresh.
+type CartChange struct {
+	CartItemID uint   `json:"cart_item_id"`
+	Name       string `json:"name"`
+	Action     string `json:"action"` // removed | quantity_reduced | price_updated
+	Message    string `json:"message"`
 }
 
 type CartResponse struct {
-	ID        uint               `json:"id"`
-	UserID    uint               `json:"user_id"`
-	CartItems []CartItemResponse `json:"CartItems"`
+	ID          uint               `json:"id"`
+	UserID      uint               `json:"user_id"`
+	CartItems   []CartItemResponse `json:"CartItems"`
+	HasWarnings bool               `json:"has_warnings"`
+	Changes     []CartChange       `json:"changes,omitempty"` // Cuma diisi POST /cart/refresh
+}
+
+// loadCart ngambil keranjang user lengkap sama produk/varian (termasuk yang udah dihapus).
+// Item lama yang belum punya snapshot harga langsung diisi harga sekarang.
+func (h *CartHandler) loadCart(userID uint) (*models.Cart, error) {
+	var cart models.Cart
+	if err := models.UnscopedCartItems(h.DB).Where("user_id = ?", userID).First(&cart).Error; err != nil {
+		return nil, err
+	}
+	for i := range cart.CartItems {
+		item := &cart.CartItems[i]
+		if item.PriceAtAdd == 0 && !item.Unavailable() {
+			item.PriceAtAdd = item.CurrentPrice()
+			if err := h.DB.Model(item).Update("price_at_add", item.PriceAtAdd).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &cart, nil
+}
+
+func toCartResponse(cart *models.Cart) CartResponse {
+	cartItemsResponse := []CartItemResponse{}
+	hasWarnings := false
+	for _, item := range cart.CartItems {
+		res := CartItemResponse{
+			ID:          item.ID,
+			Quantity:    item.Quantity,
+			ProductID:   item.ProductID,
+			VariantID:   item.VariantID,
+			PriceAtAdd:  item.PriceAtAdd,
+			Available:   max(item.Available(), 0),
+			Unavailable: item.Unavailable(),
+			Warnings:    item.Warnings(),
+		}
+		if res.Warnings == nil {
+			res.Warnings = make([]models.CartWarning, 0)
+		}
+		hasWarnings = hasWarnings || len(res.Warnings) > 0
+		if item.Product != nil {
+			res.Product = CartProductResponse{
+				ID:       item.Product.ID,
+				Name:     item.Product.Name,
+				Slug:     item.Product.Slug,
+				Price:    item.CurrentPrice(), // Harga varian kalau pake varian
+				ImageURL: item.Product.ImageURL,
+			}
+		}
+		if item.Variant != nil {
+			res.Variant = &CartVariantResponse{ID: item.Variant.ID, SKU: item.Variant.SKU, Name: item.Variant.Name}
+		}
+		cartItemsResponse = append(cartItemsResponse, res)
+	}
+
+	return CartResponse{
+		ID:          cart.ID,
+		UserID:      cart.UserID,
+		CartItems:   cartItemsResponse,
+		HasWarnings: hasWarnings,
+	}
 }
 
 func (h *CartHandler) GetCart(c *fiber.Ctx) error {
 	userID, _ := c.Locals("user_id").(uint)
 
-	var cart models.Cart
-	err := h.DB.Preload("CartItems.Product").Where("user_id = ?", userID).First(&cart).Error
+	cart, err := h.loadCart(userID)
 	
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -60,38 +143,85 @@ func (h *CartHandler) GetCart(c *fiber.Ctx) error {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal membuat keranjang baru"})
 			}
 			
-			cart = newCart 
+			cart = &newCart 
 		
 		} else {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil keranjang"})
 		}
 	}
-	cartItemsResponse := []CartItemResponse{}
-	for _, item := range cart.CartItems {
-		cartItemsResponse = append(cartItemsResponse, CartItemResponse{
-			ID:        item.ID,
-			Quantity:  item.Quantity,
-			ProductID: item.ProductID,
-			Product: CartProductResponse{ 
-				ID:       item.Product.ID,
-				Name:     item.Product.Name,
-				Slug:     item.Product.Slug,
-				Price:    item.Product.Price,
-				ImageURL: item.Product.ImageURL,
-			},
-		})
+
+	return c.Status(fiber.StatusOK).JSON(toCartResponse(cart))
+}
+
+// POST /cart/refresh — beresin keranjang: barang yang udah gak dijual / stoknya habis dihapus,
+// jumlah yang kelebihan diturunin ke stok yang ada, dan perubahan harga dianggap udah dilihat
+// buyer (snapshot harga diupdate) biar bisa lanjut checkout.
+func (h *CartHandler) RefreshCart(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(uint)
+
+	cart, err := h.loadCart(userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Keranjang user tidak ditemukan"})
 	}
-	
-	response := CartResponse{
-		ID:        cart.ID,
-		UserID:    cart.UserID,
-		CartItems: cartItemsResponse, 
+
+	var changes []CartChange
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		kept := cart.CartItems[:0]
+		for _, item := range cart.CartItems {
+			name := "Barang yang udah dihapus"
+			if item.Product != nil {
+				name = item.Product.Name
+				if item.Variant != nil {
+					name += " - " + item.Variant.Name
+				}
+			}
+
+			available := item.Available()
+			if item.Unavailable() || available <= 0 {
+				if err := tx.Delete(&models.CartItem{}, item.ID).Error; err != nil {
+					return err
+				}
+				message := "Dihapus karena stoknya habis"
+				if item.Unavailable() {
+					message = "Dihapus karena udah gak dijual"
+				}
+				changes = append(changes, CartChange{CartItemID: item.ID, Name: name, Action: "removed", Message: message})
+				continue
+			}
+
+			updates := map[string]interface{}{}
+			if item.Quantity > available {
+				changes = append(changes, CartChange{CartItemID: item.ID, Name: name, Action: "quantity_reduced", Message: fmt.Sprintf("Jumlah diturunin dari %d jadi %d sesuai stok", item.Quantity, available)})
+				item.Quantity = available
+				updates["quantity"] = available
+			}
+			if item.PriceChanged() {
+				changes = append(changes, CartChange{CartItemID: item.ID, Name: name, Action: "price_updated", Message: fmt.Sprintf("Harga sekarang Rp %.0f (sebelumnya Rp %.0f)", item.CurrentPrice(), item.PriceAtAdd)})
+				item.PriceAtAdd = item.CurrentPrice()
+				updates["price_at_add"] = item.PriceAtAdd
+			}
+			if len(updates) > 0 {
+				if err := tx.Model(&models.CartItem{}).Where("id = ?", item.ID).Updates(updates).Error; err != nil {
+					return err
+				}
+			}
+			kept = append(kept, item)
+		}
+		cart.CartItems = kept
+		return nil
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal memperbarui keranjang"})
 	}
 
+	response := toCartResponse(cart)
+	response.Changes = changes
+	if response.Changes == nil {
+		response.Changes = make([]CartChange, 0)
+	}
 	return c.Status(fiber.StatusOK).JSON(response)
 }
 
-
 func (h *CartHandler) AddItemToCart(c *fiber.Ctx) error {
 	userID, _ := c.Locals("user_id").(uint)
 	input := new(AddItemInput)
@@ -101,9 +231,25 @@ func (h *CartHandler) AddItemToCart(c *fiber.Ctx) error {
 	if input.Quantity <= 0 { input.Quantity = 1 }
 
 	var product models.Product
-	if err := h.DB.First(&product, input.ProductID).Error; err != nil {
+	if err := h.DB.Preload("Variants").First(&product, input.ProductID).Error; err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produk tidak ditemukan"})
 	}
+
+	available := product.Available()
+	price := product.Price
+	if len(product.Variants) > 0 {
+		if input.VariantID == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Pilih varian produk dulu"})
+		}
+		variant := findVariant(product.Variants, *input.VariantID)
+		if variant == nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Varian produk tidak ditemukan"})
+		}
+		available = variant.Available()
+		price = variant.Price
+	} else if input.VariantID != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Produk ini tidak punya varian"})
+	}
 	
 	var cart models.Cart
 	if err := h.DB.Where("user_id = ?", userID).First(&cart).Error; err != nil {
@@ -111,16 +257,24 @@ func (h *CartHandler) AddItemToCart(c *fiber.Ctx) error {
 	}
 
 	var existingItem models.CartItem
-	err := h.DB.Where("cart_id = ? AND product_id = ?", cart.ID, input.ProductID).First(&existingItem).Error
+	query := h.DB.Where("cart_id = ? AND product_id = ?", cart.ID, input.ProductID)
+	if input.VariantID != nil {
+		query = query.Where("variant_id = ?", *input.VariantID)
+	} else {
+		query = query.Where("variant_id IS NULL")
+	}
+	err := query.First(&existingItem).Error
 
 	if err == gorm.ErrRecordNotFound {
-		if input.Quantity > product.Stock {
+		if input.Quantity > available {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Stok tidak cukup!"})
 		}
 		newItem := models.CartItem{
-			CartID:    cart.ID,
-			ProductID: input.ProductID,
-			Quantity:  input.Quantity,
+			CartID:     cart.ID,
+			ProductID:  input.ProductID,
+			VariantID:  input.VariantID,
+			Quantity:   input.Quantity,
+			PriceAtAdd: price,
 		}
 		if err := h.DB.Create(&newItem).Error; err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menambah item ke keranjang"})
@@ -129,10 +283,11 @@ func (h *CartHandler) AddItemToCart(c *fiber.Ctx) error {
 	
 	} else if err == nil {
 		newQuantity := existingItem.Quantity + input.Quantity
-		if newQuantity > product.Stock {
+		if newQuantity > available {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Stok tidak cukup!"})
 		}
 		existingItem.Quantity = newQuantity
+		existingItem.PriceAtAdd = price // Buyer baru aja liat harga ini di halaman produk
 		if err := h.DB.Save(&existingItem).Error; err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengupdate quantity item"})
 		}
@@ -162,11 +317,15 @@ func (h *CartHandler) UpdateCartItem(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Keranjang user tidak ditemukan"})
 	}
 	var cartItem models.CartItem
-	err := h.DB.Preload("Product").Where("id = ? AND cart_id = ?", cartItemID, cart.ID).First(&cartItem).Error
+	err := h.DB.Preload("Product").Preload("Variant").Where("id = ? AND cart_id = ?", cartItemID, cart.ID).First(&cartItem).Error
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Item di keranjang tidak ditemukan"})
 	}
-	if input.Quantity > cartItem.Product.Stock {
+	available := cartItem.Product.Available()
+	if cartItem.Variant != nil {
+		available = cartItem.Variant.Available()
+	}
+	if input.Quantity > available {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Stok tidak cukup!"})
 	}
 	cartItem.Quantity = input.Quantity
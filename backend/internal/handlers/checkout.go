@@ -0,0 +1,448 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/akhdanrgya/telu-hub/internal/fulfilment"
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/payment"
+	"github.com/akhdanrgya/telu-hub/internal/voucher"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// CheckoutItemInput = satu baris keranjang yang mau dibayar sekarang. Quantity 0 = semua
+// jumlah yang ada di keranjang, kurang dari itu = sisanya tetep di keranjang.
+type CheckoutItemInput struct {
+	CartItemID uint `json:"cart_item_id"`
+	Quantity   int  `json:"quantity"`
+}
+
+// BuyNowInput = beli langsung satu produk tanpa nyentuh keranjang.
+type BuyNowInput struct {
+	ProductID uint  `json:"product_id"`
+	VariantID *uint `json:"variant_id"`
+	Quantity  int   `json:"quantity"` // 0 = 1
+}
+
+// CheckoutInput dipake bareng sama POST /checkout dan POST /checkout/preview.
+type CheckoutInput struct {
+	VoucherCode string                 `json:"voucher_code"`
+	Fulfilment  []fulfilment.Selection `json:"fulfilment"` // Satu per seller, yang gak dikirim pake default
+	Items       []CheckoutItemInput    `json:"items"`      // Kosong = seluruh keranjang
+	BuyNow      *BuyNowInput           `json:"buy_now"`    // Diisi = items & keranjang diabaikan
+}
+
+// CheckoutProblem = alasan checkout gak bisa jalan. Di preview dikumpulin semua,
+// waktu checkout beneran yang pertama langsung jadi error.
+type CheckoutProblem struct {
+	Type       string `json:"type"` // stock | variant | unavailable | price | voucher | fulfilment
+	Message    string `json:"message"`
+	CartItemID uint   `json:"cart_item_id,omitempty"`
+	ProductID  uint   `json:"product_id,omitempty"`
+	SellerID   uint   `json:"seller_id,omitempty"`
+	status     int
+}
+
+// checkoutLine = satu barang yang mau di-checkout, dari keranjang atau beli langsung.
+type checkoutLine struct {
+	CartItemID   uint // 0 = beli langsung
+	CartQuantity int  // Jumlah di keranjang sebelum checkout
+	Quantity     int
+	Product      *models.Product
+	VariantID    *uint
+	Variant      *models.ProductVariant
+	PriceAtAdd   float64 // Harga yang terakhir dilihat buyer di keranjang, 0 = gak dicek
+}
+
+// checkoutPlan = hasil ngitung checkout: item order, item Midtrans, voucher, ongkir, dan total.
+// Lines & OrderItems cuma berisi baris yang bisa dihargain (varian yang udah dihapus di-skip).
+type checkoutPlan struct {
+	Lines         []checkoutLine
+	OrderItems    []models.OrderItem
+	Products      []*models.Product
+	PaymentItems  []payment.Item
+	Subtotal      float64
+	Voucher       *voucher.Quote
+	SellerIDs     []uint
+	Weights       map[uint]int
+	Choices       map[uint]*fulfilment.Choice
+	ShippingTotal float64
+	Total         float64
+	Problems      []CheckoutProblem
+}
+
+func (p *checkoutPlan) problem(pr CheckoutProblem) {
+	if pr.status == 0 {
+		pr.status = fiber.StatusBadRequest
+	}
+	p.Problems = append(p.Problems, pr)
+}
+
+// loadCheckoutLines ngumpulin barang yang mau dibayar: produk beli langsung, baris keranjang
+// yang dipilih, atau seluruh keranjang.
+func loadCheckoutLines(db *gorm.DB, userID uint, input *CheckoutInput) ([]checkoutLine, error) {
+	if input.BuyNow != nil {
+		qty := input.BuyNow.Quantity
+		if qty == 0 {
+			qty = 1
+		}
+		if qty < 0 {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Jumlah barang harus lebih dari 0")
+		}
+		var product models.Product
+		if err := db.Preload("Variants").First(&product, input.BuyNow.ProductID).Error; err != nil {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Produk tidak ditemukan")
+		}
+		line := checkoutLine{Quantity: qty, Product: &product, VariantID: input.BuyNow.VariantID}
+		if line.VariantID != nil {
+			for i := range product.Variants {
+				if product.Variants[i].ID == *line.VariantID {
+					line.Variant = &product.Variants[i]
+				}
+			}
+			if line.Variant == nil {
+				return nil, fiber.NewError(fiber.StatusNotFound, "Varian tidak ditemukan")
+			}
+		}
+		return []checkoutLine{line}, nil
+	}
+
+	var cart models.Cart
+	// Produk yang udah dihapus seller tetep dimuat biar jadi masalah "unavailable", bukan nil
+	unscoped := func(db *gorm.DB) *gorm.DB { return db.Unscoped() }
+	if err := db.Preload("CartItems.Product", unscoped).Preload("CartItems.Product.Variants").Preload("CartItems.Variant").Where("user_id = ?", userID).First(&cart).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Keranjang tidak ditemukan")
+	}
+	if len(cart.CartItems) == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Keranjang kosong")
+	}
+
+	toLine := func(item models.CartItem, qty int) checkoutLine {
+		return checkoutLine{
+			CartItemID:   item.ID,
+			CartQuantity: item.Quantity,
+			Quantity:     qty,
+			Product:      item.Product,
+			VariantID:    item.VariantID,
+			Variant:      item.Variant,
+			PriceAtAdd:   item.PriceAtAdd,
+		}
+	}
+
+	var lines []checkoutLine
+	if len(input.Items) == 0 {
+		for _, item := range cart.CartItems {
+			lines = append(lines, toLine(item, item.Quantity))
+		}
+		return lines, nil
+	}
+
+	byID := make(map[uint]models.CartItem, len(cart.CartItems))
+	for _, item := range cart.CartItems {
+		byID[item.ID] = item
+	}
+	seen := make(map[uint]bool, len(input.Items))
+	for _, sel := range input.Items {
+		item, ok := byID[sel.CartItemID]
+		if !ok {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Item keranjang "+strconv.FormatUint(uint64(sel.CartItemID), 10)+" tidak ditemukan")
+		}
+		if seen[sel.CartItemID] {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Item keranjang gak boleh dipilih dua kali")
+		}
+		seen[sel.CartItemID] = true
+
+		qty := sel.Quantity
+		if qty == 0 {
+			qty = item.Quantity
+		}
+		if qty < 0 || qty > item.Quantity {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Jumlah "+item.Product.Name+" harus 1 sampai "+strconv.Itoa(item.Quantity))
+		}
+		lines = append(lines, toLine(item, qty))
+	}
+	return lines, nil
+}
+
+// planCheckout ngitung harga, voucher, dan ongkir buat lines. lock = checkout beneran
+// (voucher dikunci & kuotanya dicek dalam tx), false = preview doang.
+func (h *OrderHandler) planCheckout(tx *gorm.DB, userID uint, input *CheckoutInput, lines []checkoutLine, lock bool) (*checkoutPlan, error) {
+	plan := &checkoutPlan{
+		Weights: make(map[uint]int),
+		Choices: make(map[uint]*fulfilment.Choice),
+	}
+	var voucherLines []voucher.Line
+
+	for _, line := range lines {
+		product := line.Product
+		orderItem := models.OrderItem{ProductID: product.ID, Quantity: line.Quantity}
+		itemID := strconv.FormatUint(uint64(product.ID), 10)
+		name := product.Name
+		available := product.Available()
+		price := product.Price
+
+		if product.DeletedAt.Valid {
+			plan.problem(CheckoutProblem{Type: "unavailable", Message: product.Name + " udah gak dijual, hapus dari keranjang dulu", CartItemID: line.CartItemID, ProductID: product.ID, status: fiber.StatusConflict})
+			continue
+		}
+
+		switch {
+		case line.VariantID != nil:
+			if line.Variant == nil {
+				plan.problem(CheckoutProblem{Type: "variant", Message: "Varian " + product.Name + " udah gak dijual, hapus dari keranjang dulu", CartItemID: line.CartItemID, ProductID: product.ID})
+				continue
+			}
+			orderItem.VariantID = line.VariantID
+			orderItem.VariantName = line.Variant.Name
+			itemID = line.Variant.SKU
+			name += " - " + line.Variant.Name
+			available = line.Variant.Available()
+			price = line.Variant.Price
+		case len(product.Variants) > 0:
+			plan.problem(CheckoutProblem{Type: "variant", Message: "Pilih varian untuk " + product.Name + " dulu", CartItemID: line.CartItemID, ProductID: product.ID})
+			continue
+		}
+
+		// Harga berubah sejak buyer terakhir liat keranjang: harus di-acknowledge lewat POST /cart/refresh
+		if line.PriceAtAdd > 0 && line.PriceAtAdd != price {
+			plan.problem(CheckoutProblem{Type: "price", Message: fmt.Sprintf("Harga %s berubah dari Rp %.0f jadi Rp %.0f, perbarui keranjang dulu", name, line.PriceAtAdd, price), CartItemID: line.CartItemID, ProductID: product.ID, status: fiber.StatusConflict})
+		}
+		if line.Quantity > available {
+			plan.problem(CheckoutProblem{Type: "stock", Message: "Stok untuk " + name + " tidak cukup (sisa " + strconv.Itoa(max(available, 0)) + ")", CartItemID: line.CartItemID, ProductID: product.ID})
+		}
+		orderItem.PriceAtTime = price
+		amount := float64(line.Quantity) * price
+		plan.Subtotal += amount
+
+		plan.Lines = append(plan.Lines, line)
+		plan.OrderItems = append(plan.OrderItems, orderItem)
+		plan.Products = append(plan.Products, product)
+		voucherLines = append(voucherLines, voucher.Line{SellerID: product.SellerID, Amount: amount})
+		if _, seen := plan.Weights[product.SellerID]; !seen {
+			plan.SellerIDs = append(plan.SellerIDs, product.SellerID)
+		}
+		plan.Weights[product.SellerID] += product.WeightGrams * line.Quantity
+		plan.PaymentItems = append(plan.PaymentItems, payment.Item{
+			ID:    itemID,
+			Price: int64(price),
+			Qty:   int32(line.Quantity),
+			Name:  name,
+		})
+	}
+	plan.Total = plan.Subtotal
+
+	// Potongan voucher dibagi ke tiap baris (buat refund) dan dikirim ke gateway sebagai item minus
+	if input.VoucherCode != "" && len(voucherLines) > 0 {
+		var q *voucher.Quote
+		var err error
+		if lock {
+			q, err = h.Vouchers.Apply(tx, input.VoucherCode, userID, voucherLines)
+		} else {
+			q, err = h.Vouchers.Preview(input.VoucherCode, userID, voucherLines)
+		}
+		if err != nil {
+			status := voucherErrorStatus(err)
+			if status == fiber.StatusInternalServerError {
+				return nil, fiber.NewError(status, "Gagal ngecek voucher")
+			}
+			plan.problem(CheckoutProblem{Type: "voucher", Message: err.Error(), status: status})
+		} else {
+			plan.Voucher = q
+			for i := range plan.OrderItems {
+				plan.OrderItems[i].DiscountAmount = q.Allocations[i]
+			}
+			plan.Total -= q.Discount
+			plan.PaymentItems = append(plan.PaymentItems, payment.Item{
+				ID:    "VOUCHER-" + q.Voucher.Code,
+				Price: -int64(q.Discount),
+				Qty:   1,
+				Name:  "Voucher " + q.Voucher.Code,
+			})
+		}
+	}
+
+	// Ongkir dihitung per seller dari berat barangnya, voucher gak motong ongkir
+	selections := make(map[uint]*fulfilment.Selection, len(input.Fulfilment))
+	for i := range input.Fulfilment {
+		selections[input.Fulfilment[i].SellerID] = &input.Fulfilment[i]
+	}
+	for _, sellerID := range plan.SellerIDs {
+		choice, err := h.Fulfilment.Resolve(tx, userID, sellerID, selections[sellerID], plan.Weights[sellerID])
+		if err != nil {
+			status := fulfilmentErrorStatus(err)
+			if status == fiber.StatusInternalServerError {
+				return nil, fiber.NewError(status, "Gagal ngecek metode pengiriman")
+			}
+			plan.problem(CheckoutProblem{Type: "fulfilment", Message: err.Error(), SellerID: sellerID, status: status})
+			continue
+		}
+		plan.Choices[sellerID] = choice
+		if choice.Fee > 0 {
+			plan.ShippingTotal += choice.Fee
+			plan.PaymentItems = append(plan.PaymentItems, payment.Item{
+				ID:    "SHIP-" + strconv.FormatUint(uint64(sellerID), 10),
+				Price: int64(choice.Fee),
+				Qty:   1,
+				Name:  "Ongkir " + choice.Method.Name,
+			})
+		}
+	}
+	plan.Total += plan.ShippingTotal
+
+	if len(plan.OrderItems) == 0 && len(plan.Problems) == 0 {
+		plan.problem(CheckoutProblem{Type: "stock", Message: "Gak ada barang yang bisa di-checkout"})
+	}
+	return plan, nil
+}
+
+// clearCheckedOutLines ngurangin keranjang sesuai yang udah di-checkout: baris yang dibayar
+// semua dihapus, yang dibayar sebagian sisanya tetep di keranjang.
+func clearCheckedOutLines(tx *gorm.DB, lines []checkoutLine) error {
+	for _, line := range lines {
+		if line.CartItemID == 0 {
+			continue
+		}
+		var err error
+		if line.Quantity >= line.CartQuantity {
+			err = tx.Delete(&models.CartItem{}, line.CartItemID).Error
+		} else {
+			err = tx.Model(&models.CartItem{}).Where("id = ?", line.CartItemID).
+				Update("quantity", gorm.Expr("quantity - ?", line.Quantity)).Error
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type CheckoutPreviewItem struct {
+	CartItemID     uint    `json:"cart_item_id,omitempty"`
+	ProductID      uint    `json:"product_id"`
+	VariantID      *uint   `json:"variant_id,omitempty"`
+	Name           string  `json:"name"`
+	Quantity       int     `json:"quantity"`
+	Price          float64 `json:"price"`
+	Subtotal       float64 `json:"subtotal"`
+	DiscountAmount float64 `json:"discount_amount"`
+	Available      int     `json:"available"`
+}
+
+type CheckoutPreviewSeller struct {
+	SellerID    uint                  `json:"seller_id"`
+	SellerName  string                `json:"seller_name"`
+	WeightGrams int                   `json:"weight_grams"`
+	MethodID    uint                  `json:"method_id"`
+	MethodName  string                `json:"method_name,omitempty"`
+	Type        models.FulfilmentType `json:"type,omitempty"`
+	ShippingFee float64               `json:"shipping_fee"`
+	Options     []fulfilment.Option   `json:"options"`
+}
+
+type CheckoutPreviewResponse struct {
+	Items          []CheckoutPreviewItem   `json:"items"`
+	Sellers        []CheckoutPreviewSeller `json:"sellers"`
+	Addresses      []models.Address        `json:"addresses"`
+	Subtotal       float64                 `json:"subtotal"`
+	DiscountAmount float64                 `json:"discount_amount"`
+	VoucherCode    string                  `json:"voucher_code,omitempty"`
+	ShippingTotal  float64                 `json:"shipping_total"`
+	Total          float64                 `json:"total"`
+	Problems       []CheckoutProblem       `json:"problems"`
+	CanCheckout    bool                    `json:"can_checkout"`
+}
+
+// POST /checkout/preview — body sama kayak POST /checkout, tapi cuma ngitung total, ongkir,
+// dan masalah stok/voucher/pengiriman tanpa bikin order atau transaksi Midtrans.
+func (h *OrderHandler) PreviewCheckout(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(uint)
+
+	var input CheckoutInput
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+		}
+	}
+
+	lines, err := loadCheckoutLines(h.DB, userID, &input)
+	if err == nil {
+		var plan *checkoutPlan
+		plan, err = h.planCheckout(h.DB, userID, &input, lines, false)
+		if err == nil {
+			return h.previewResponse(c, userID, plan)
+		}
+	}
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		return c.Status(fe.Code).JSON(fiber.Map{"error": fe.Message})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal ngitung checkout"})
+}
+
+func (h *OrderHandler) previewResponse(c *fiber.Ctx, userID uint, plan *checkoutPlan) error {
+	res := CheckoutPreviewResponse{
+		Items:         make([]CheckoutPreviewItem, 0, len(plan.Lines)),
+		Sellers:       make([]CheckoutPreviewSeller, 0, len(plan.SellerIDs)),
+		Addresses:     make([]models.Address, 0),
+		Subtotal:      plan.Subtotal,
+		ShippingTotal: plan.ShippingTotal,
+		Total:         plan.Total,
+		Problems:      plan.Problems,
+		CanCheckout:   len(plan.Problems) == 0,
+	}
+	if res.Problems == nil {
+		res.Problems = make([]CheckoutProblem, 0)
+	}
+	if plan.Voucher != nil {
+		res.DiscountAmount = plan.Voucher.Discount
+		res.VoucherCode = plan.Voucher.Voucher.Code
+	}
+
+	for i, line := range plan.Lines {
+		item := plan.OrderItems[i]
+		preview := CheckoutPreviewItem{
+			CartItemID:     line.CartItemID,
+			ProductID:      line.Product.ID,
+			VariantID:      item.VariantID,
+			Name:           line.Product.Name,
+			Quantity:       item.Quantity,
+			Price:          item.PriceAtTime,
+			Subtotal:       item.PriceAtTime * float64(item.Quantity),
+			DiscountAmount: item.DiscountAmount,
+			Available:      line.Product.Available(),
+		}
+		if line.Variant != nil {
+			preview.Name += " - " + line.Variant.Name
+			preview.Available = line.Variant.Available()
+		}
+		res.Items = append(res.Items, preview)
+	}
+
+	for _, sellerID := range plan.SellerIDs {
+		seller := CheckoutPreviewSeller{SellerID: sellerID, WeightGrams: plan.Weights[sellerID]}
+		var user models.User
+		if err := h.DB.Select("id", "username").First(&user, sellerID).Error; err == nil {
+			seller.SellerName = user.Username
+		}
+		if choice := plan.Choices[sellerID]; choice != nil {
+			seller.MethodID = choice.Method.ID
+			seller.MethodName = choice.Method.Name
+			seller.Type = choice.Method.Type
+			seller.ShippingFee = choice.Fee
+		}
+		options, err := h.Fulfilment.Options(sellerID, seller.WeightGrams)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil metode pengiriman"})
+		}
+		seller.Options = options
+		res.Sellers = append(res.Sellers, seller)
+	}
+
+	if err := h.DB.Where("user_id = ?", userID).Order("is_default desc, created_at asc").Find(&res.Addresses).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil alamat"})
+	}
+	return c.JSON(res)
+}
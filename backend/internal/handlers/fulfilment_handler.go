@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/akhdanrgya/telu-hub/internal/fulfilment"
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type FulfilmentHandler struct {
+	DB         *gorm.DB
+	Fulfilment *fulfilment.Service
+}
+
+func NewFulfilmentHandler(db *gorm.DB, fulfilments *fulfilment.Service) *FulfilmentHandler {
+	return &FulfilmentHandler{DB: db, Fulfilment: fulfilments}
+}
+
+// AddressInput dipake buat bikin & ngubah alamat. Waktu ngubah, field yang gak dikirim gak diubah.
+type AddressInput struct {
+	Label         *string `json:"label"`
+	RecipientName *string `json:"recipient_name"`
+	Phone         *string `json:"phone"`
+	Street        *string `json:"street"`
+	City          *string `json:"city"`
+	PostalCode    *string `json:"postal_code"`
+	Notes         *string `json:"notes"`
+	IsDefault     *bool   `json:"is_default"`
+}
+
+// FulfilmentMethodInput dipake buat bikin & ngubah metode pengiriman seller.
+type FulfilmentMethodInput struct {
+	Type        *models.FulfilmentType `json:"type"`
+	Name        *string                `json:"name"`
+	Description *string                `json:"description"`
+	Location    *string                `json:"location"`
+	FeeType     *models.FeeType        `json:"fee_type"`
+	FlatFee     *float64               `json:"flat_fee"`
+	WeightRates *models.WeightRates    `json:"weight_rates"`
+	IsActive    *bool                  `json:"is_active"`
+}
+
+type CheckoutSellerOptions struct {
+	SellerID    uint                `json:"seller_id"`
+	SellerName  string              `json:"seller_name"`
+	WeightGrams int                 `json:"weight_grams"`
+	Options     []fulfilment.Option `json:"options"`
+}
+
+type CheckoutOptionsResponse struct {
+	Sellers   []CheckoutSellerOptions `json:"sellers"`
+	Addresses []models.Address        `json:"addresses"`
+}
+
+// fulfilmentErrorStatus nerjemahin error pengiriman ke status HTTP (500 = bukan salah input).
+func fulfilmentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, fulfilment.ErrMethodNotFound), errors.Is(err, fulfilment.ErrAddressNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, fulfilment.ErrAddressRequired), errors.Is(err, models.ErrTooHeavy):
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
+
+func trimmed(dst *string, src *string) {
+	if src != nil {
+		*dst = strings.TrimSpace(*src)
+	}
+}
+
+func (in *AddressInput) apply(a *models.Address) {
+	trimmed(&a.Label, in.Label)
+	trimmed(&a.RecipientName, in.RecipientName)
+	trimmed(&a.Phone, in.Phone)
+	trimmed(&a.Street, in.Street)
+	trimmed(&a.City, in.City)
+	trimmed(&a.PostalCode, in.PostalCode)
+	trimmed(&a.Notes, in.Notes)
+	if in.IsDefault != nil {
+		a.IsDefault = *in.IsDefault
+	}
+}
+
+func (in *FulfilmentMethodInput) apply(m *models.FulfilmentMethod) {
+	if in.Type != nil {
+		m.Type = *in.Type
+	}
+	trimmed(&m.Name, in.Name)
+	trimmed(&m.Description, in.Description)
+	trimmed(&m.Location, in.Location)
+	if in.FeeType != nil {
+		m.FeeType = *in.FeeType
+	}
+	if in.FlatFee != nil {
+		m.FlatFee = *in.FlatFee
+	}
+	if in.WeightRates != nil {
+		m.WeightRates = *in.WeightRates
+	}
+	if in.IsActive != nil {
+		m.IsActive = *in.IsActive
+	}
+}
+
+// saveAddress nyimpen alamat. Alamat default cuma boleh satu, alamat pertama otomatis jadi default.
+func (h *FulfilmentHandler) saveAddress(c *fiber.Ctx, a *models.Address, status int) error {
+	if a.RecipientName == "" || a.Phone == "" || a.Street == "" || a.City == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Nama penerima, no. HP, alamat, dan kota wajib diisi"})
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var others int64
+		if err := tx.Model(&models.Address{}).Where("user_id = ? AND id <> ?", a.UserID, a.ID).Count(&others).Error; err != nil {
+			return err
+		}
+		if others == 0 {
+			a.IsDefault = true
+		}
+		if err := tx.Save(a).Error; err != nil {
+			return err
+		}
+		if a.IsDefault {
+			return tx.Model(&models.Address{}).Where("user_id = ? AND id <> ?", a.UserID, a.ID).Update("is_default", false).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menyimpan alamat"})
+	}
+	return c.Status(status).JSON(a)
+}
+
+// GET /me/addresses
+func (h *FulfilmentHandler) GetMyAddresses(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var addresses []models.Address
+	if err := h.DB.Where("user_id = ?", userID).Order("is_default desc, created_at asc").Find(&addresses).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil alamat"})
+	}
+	return c.JSON(addresses)
+}
+
+// POST /me/addresses
+func (h *FulfilmentHandler) CreateMyAddress(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	input := new(AddressInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	a := models.Address{UserID: userID}
+	input.apply(&a)
+	return h.saveAddress(c, &a, fiber.StatusCreated)
+}
+
+// PUT /me/addresses/:id
+func (h *FulfilmentHandler) UpdateMyAddress(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var a models.Address
+	if err := h.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).First(&a).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Alamat tidak ditemukan"})
+	}
+	input := new(AddressInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	input.apply(&a)
+	return h.saveAddress(c, &a, fiber.StatusOK)
+}
+
+// DELETE /me/addresses/:id — order lama aman karena alamatnya udah disalin ke sub-order
+func (h *FulfilmentHandler) DeleteMyAddress(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var a models.Address
+	if err := h.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).First(&a).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Alamat tidak ditemukan"})
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&a).Error; err != nil {
+			return err
+		}
+		if !a.IsDefault {
+			return nil
+		}
+		// Default pindah ke alamat paling lama yang tersisa
+		var next models.Address
+		if err := tx.Where("user_id = ?", userID).Order("created_at asc").First(&next).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menghapus alamat"})
+	}
+	return c.JSON(fiber.Map{"message": "Alamat dihapus"})
+}
+
+func (h *FulfilmentHandler) saveMethod(c *fiber.Ctx, m *models.FulfilmentMethod, status int) error {
+	if !m.Type.UsesHandoverCode() {
+		m.Location = ""
+	}
+	if err := m.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if m.FeeType == models.FeeFlat {
+		m.WeightRates = models.WeightRates{}
+	}
+	if err := h.DB.Save(m).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menyimpan metode pengiriman"})
+	}
+	return c.Status(status).JSON(m)
+}
+
+// GET /me/fulfilment-methods — termasuk yang lagi dinonaktifin
+func (h *FulfilmentHandler) GetMyFulfilmentMethods(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var methods []models.FulfilmentMethod
+	if err := h.DB.Where("seller_id = ?", userID).Order("id asc").Find(&methods).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil metode pengiriman"})
+	}
+	return c.JSON(methods)
+}
+
+// POST /me/fulfilment-methods
+func (h *FulfilmentHandler) CreateMyFulfilmentMethod(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	input := new(FulfilmentMethodInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	m := models.FulfilmentMethod{SellerID: userID, FeeType: models.FeeFlat, IsActive: true}
+	input.apply(&m)
+	return h.saveMethod(c, &m, fiber.StatusCreated)
+}
+
+// PUT /me/fulfilment-methods/:id
+func (h *FulfilmentHandler) UpdateMyFulfilmentMethod(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var m models.FulfilmentMethod
+	if err := h.DB.Where("id = ? AND seller_id = ?", c.Params("id"), userID).First(&m).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Metode pengiriman tidak ditemukan"})
+	}
+	input := new(FulfilmentMethodInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	input.apply(&m)
+	return h.saveMethod(c, &m, fiber.StatusOK)
+}
+
+// DELETE /me/fulfilment-methods/:id — sub-order lama tetep nyimpen salinan nama & ongkirnya
+func (h *FulfilmentHandler) DeleteMyFulfilmentMethod(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	result := h.DB.Where("id = ? AND seller_id = ?", c.Params("id"), userID).Delete(&models.FulfilmentMethod{})
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menghapus metode pengiriman"})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Metode pengiriman tidak ditemukan"})
+	}
+	return c.JSON(fiber.Map{"message": "Metode pengiriman dihapus"})
+}
+
+// GET /checkout/options — pilihan pengiriman per seller buat isi keranjang sekarang + alamat buyer
+func (h *FulfilmentHandler) GetCheckoutOptions(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	response := CheckoutOptionsResponse{
+		Sellers:   make([]CheckoutSellerOptions, 0),
+		Addresses: make([]models.Address, 0),
+	}
+
+	var cart models.Cart
+	err := h.DB.Preload("CartItems.Product.Seller").Where("user_id = ?", userID).First(&cart).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil keranjang"})
+	}
+
+	index := make(map[uint]int)
+	for _, item := range cart.CartItems {
+		if item.Product == nil {
+			continue
+		}
+		sellerID := item.Product.SellerID
+		pos, ok := index[sellerID]
+		if !ok {
+			pos = len(response.Sellers)
+			index[sellerID] = pos
+			entry := CheckoutSellerOptions{SellerID: sellerID}
+			if item.Product.Seller != nil {
+				entry.SellerName = item.Product.Seller.Username
+			}
+			response.Sellers = append(response.Sellers, entry)
+		}
+		response.Sellers[pos].WeightGrams += item.Product.WeightGrams * item.Quantity
+	}
+
+	for i := range response.Sellers {
+		options, err := h.Fulfilment.Options(response.Sellers[i].SellerID, response.Sellers[i].WeightGrams)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil metode pengiriman"})
+		}
+		response.Sellers[i].Options = options
+	}
+
+	if err := h.DB.Where("user_id = ?", userID).Order("is_default desc, created_at asc").Find(&response.Addresses).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil alamat"})
+	}
+	return c.JSON(response)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/akhdanrgya/telu-hub/internal/guestcart"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// CartTokenHeader = header tempat client ngirim cart token keranjang tamu.
+const CartTokenHeader = "X-Cart-Token"
+
+type GuestCartHandler struct {
+	DB         *gorm.DB
+	GuestCarts *guestcart.Service
+}
+
+func NewGuestCartHandler(db *gorm.DB, guestCarts *guestcart.Service) *GuestCartHandler {
+	return &GuestCartHandler{DB: db, GuestCarts: guestCarts}
+}
+
+// GuestCartResponse = format keranjang biasa + token yang harus disimpen client.
+type GuestCartResponse struct {
+	CartResponse
+	CartToken string `json:"cart_token"`
+}
+
+func guestCartErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, guestcart.ErrCartNotFound), errors.Is(err, guestcart.ErrItemNotFound),
+		errors.Is(err, guestcart.ErrProductNotFound), errors.Is(err, guestcart.ErrVariantNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, guestcart.ErrInvalidToken):
+		return fiber.StatusUnauthorized
+	case errors.Is(err, guestcart.ErrVariantRequired), errors.Is(err, guestcart.ErrNoVariants),
+		errors.Is(err, guestcart.ErrInsufficientStock), errors.Is(err, guestcart.ErrInvalidQuantity):
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
+
+// respond balikin isi terbaru keranjang tamu sama token-nya.
+func (h *GuestCartHandler) respond(c *fiber.Ctx, status int, token string) error {
+	cart, err := h.GuestCarts.Load(token)
+	if err != nil {
+		return c.Status(guestCartErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(status).JSON(GuestCartResponse{CartResponse: toCartResponse(cart.AsCart()), CartToken: token})
+}
+
+// GET /guest-cart — token kosong / keranjangnya udah disapu = keranjang kosong tanpa token.
+func (h *GuestCartHandler) GetGuestCart(c *fiber.Ctx) error {
+	token := c.Get(CartTokenHeader)
+	cart, err := h.GuestCarts.Load(token)
+	if err != nil {
+		if errors.Is(err, guestcart.ErrInvalidToken) || errors.Is(err, guestcart.ErrCartNotFound) {
+			return c.Status(fiber.StatusOK).JSON(GuestCartResponse{CartResponse: CartResponse{CartItems: []CartItemResponse{}}})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil keranjang"})
+	}
+	if err := h.GuestCarts.Touch(cart); err != nil {
+		log.Printf("[GUESTCART] WARNING: Gagal update last_active_at keranjang tamu %d: %v", cart.ID, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(GuestCartResponse{CartResponse: toCartResponse(cart.AsCart()), CartToken: token})
+}
+
+// POST /guest-cart/items — bikin keranjang tamu baru kalau belum punya token (atau token lama udah gak berlaku).
+func (h *GuestCartHandler) AddGuestCartItem(c *fiber.Ctx) error {
+	input := new(AddItemInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+
+	token := c.Get(CartTokenHeader)
+	cart, err := h.GuestCarts.Load(token)
+	if errors.Is(err, guestcart.ErrInvalidToken) || errors.Is(err, guestcart.ErrCartNotFound) {
+		cart, token, err = h.GuestCarts.Create()
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal membuat keranjang tamu"})
+	}
+
+	if err := h.GuestCarts.AddItem(cart, input.ProductID, input.VariantID, input.Quantity); err != nil {
+		return c.Status(guestCartErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.GuestCarts.Touch(cart); err != nil {
+		log.Printf("[GUESTCART] WARNING: Gagal update last_active_at keranjang tamu %d: %v", cart.ID, err)
+	}
+	return h.respond(c, fiber.StatusOK, token)
+}
+
+// PUT /guest-cart/items/:id
+func (h *GuestCartHandler) UpdateGuestCartItem(c *fiber.Ctx) error {
+	itemID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID item keranjang tidak valid"})
+	}
+	var input struct {
+		Quantity int `json:"quantity"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+
+	token := c.Get(CartTokenHeader)
+	cart, err := h.GuestCarts.Load(token)
+	if err != nil {
+		return c.Status(guestCartErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.GuestCarts.UpdateItem(cart, uint(itemID), input.Quantity); err != nil {
+		return c.Status(guestCartErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.GuestCarts.Touch(cart); err != nil {
+		log.Printf("[GUESTCART] WARNING: Gagal update last_active_at keranjang tamu %d: %v", cart.ID, err)
+	}
+	return h.respond(c, fiber.StatusOK, token)
+}
+
+// DELETE /guest-cart/items/:id
+func (h *GuestCartHandler) RemoveGuestCartItem(c *fiber.Ctx) error {
+	itemID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID item keranjang tidak valid"})
+	}
+
+	token := c.Get(CartTokenHeader)
+	cart, err := h.GuestCarts.Load(token)
+	if err != nil {
+		return c.Status(guestCartErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.GuestCarts.RemoveItem(cart, uint(itemID)); err != nil {
+		return c.Status(guestCartErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.GuestCarts.Touch(cart); err != nil {
+		log.Printf("[GUESTCART] WARNING: Gagal update last_active_at keranjang tamu %d: %v", cart.ID, err)
+	}
+	return h.respond(c, fiber.StatusOK, token)
+}
@@ -1,30 +1,35 @@
 package handlers
 
 import (
-	"crypto/sha512"
-	"encoding/hex"
-	"fmt"
+	"errors"
 	"log"
 	"strconv"
-	"strings"
 	"time"
 
-	"github.com/akhdanrgya/telu-hub/config"
+	"github.com/akhdanrgya/telu-hub/internal/fulfilment"
 	"github.com/akhdanrgya/telu-hub/internal/models"
 	"github.com/gofiber/fiber/v2"
-	"github.com/midtrans/midtrans-go"
-	"github.com/midtrans/midtrans-go/snap"
 	"gorm.io/gorm"
 	"github.com/akhdanrgya/telu-hub/internal/notification"
+	"github.com/akhdanrgya/telu-hub/internal/orderflow"
+	"github.com/akhdanrgya/telu-hub/internal/payment"
+	"github.com/akhdanrgya/telu-hub/internal/refund"
+	"github.com/akhdanrgya/telu-hub/internal/reservation"
+	"github.com/akhdanrgya/telu-hub/internal/voucher"
 
 	grpc_service "github.com/akhdanrgya/telu-hub/internal/grpc_service"
 )
 
 type OrderHandler struct {
 	DB           *gorm.DB
-	SnapClient   snap.Client
+	Payments     payment.Gateway
 	StockService *grpc_service.StockService
 	NotifService *notification.Service
+	Reservations *reservation.Service
+	Orders       *orderflow.Service
+	Refunds      *refund.Service
+	Vouchers     *voucher.Service
+	Fulfilment   *fulfilment.Service
 }
 
 type OrderProductResponse struct {
@@ -37,102 +42,141 @@ type OrderItemResponse struct {
 	ID          uint                 `json:"id"`
 	Quantity    int                  `json:"quantity"`
 	PriceAtTime float64              `json:"price_at_time"`
+	VariantID   *uint                `json:"variant_id,omitempty"`
+	VariantName string               `json:"variant_name,omitempty"`
+	DiscountAmount float64           `json:"discount_amount,omitempty"`
 	Product     OrderProductResponse `json:"Product"`
 }
 type OrderResponse struct {
 	ID          uint                `json:"id"`
 	TotalAmount float64             `json:"total_amount"`
-	Status      string              `json:"status"`
+	DiscountAmount float64          `json:"discount_amount"`
+	VoucherCode string              `json:"voucher_code,omitempty"`
+	ShippingTotal float64           `json:"shipping_total"`
+	Status      models.OrderStatus  `json:"status"`
 	CreatedAt   time.Time           `json:"created_at"`
 	OrderItems  []OrderItemResponse `json:"OrderItems"`
+	SellerOrders []SellerOrderResponse `json:"seller_orders"`
 }
 
-func NewOrderHandler(db *gorm.DB, stockService *grpc_service.StockService, notifService *notification.Service) *OrderHandler {
+func NewOrderHandler(db *gorm.DB, stockService *grpc_service.StockService, notifService *notification.Service, reservations *reservation.Service, orders *orderflow.Service, payments payment.Gateway, refunds *refund.Service, vouchers *voucher.Service, fulfilments *fulfilment.Service) *OrderHandler {
 	var handler OrderHandler
 	handler.DB = db
-	handler.SnapClient.New(midtrans.ServerKey, midtrans.Sandbox)
+	handler.Payments = payments
 	handler.StockService = stockService
 	handler.NotifService = notifService
+	handler.Reservations = reservations
+	handler.Orders = orders
+	handler.Refunds = refunds
+	handler.Vouchers = vouchers
+	handler.Fulfilment = fulfilments
 	return &handler
 }
 
 func (h *OrderHandler) CreateOrderAndPay(c *fiber.Ctx) error {
 	userID, _ := c.Locals("user_id").(uint)
 
+	var input CheckoutInput
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+		}
+	}
+
 	var snapToken string
+	var redirectURL string
 	var orderIDGorm uint
+	var heldProductIDs []uint
+	var expiresAt time.Time
 
 	err := h.DB.Transaction(func(tx *gorm.DB) error {
-		var cart models.Cart
-		if err := tx.Preload("CartItems.Product").Where("user_id = ?", userID).First(&cart).Error; err != nil {
-			return fiber.NewError(fiber.StatusNotFound, "Keranjang tidak ditemukan")
+		lines, err := loadCheckoutLines(tx, userID, &input)
+		if err != nil {
+			return err
 		}
-
-		if len(cart.CartItems) == 0 {
-			return fiber.NewError(fiber.StatusBadRequest, "Keranjang kosong")
+		plan, err := h.planCheckout(tx, userID, &input, lines, true)
+		if err != nil {
+			return err
 		}
-
-		var totalAmount float64
-		var orderItems []models.OrderItem
-		var midtransItems []midtrans.ItemDetails
-
-		for _, item := range cart.CartItems {
-			if item.Quantity > item.Product.Stock {
-				return fiber.NewError(fiber.StatusBadRequest, "Stok untuk "+item.Product.Name+" tidak cukup")
-			}
-			price := item.Product.Price
-			totalAmount += (float64(item.Quantity) * price)
-
-			orderItems = append(orderItems, models.OrderItem{
-				ProductID:   item.ProductID,
-				Quantity:    item.Quantity,
-				PriceAtTime: price,
-			})
-
-			midtransItems = append(midtransItems, midtrans.ItemDetails{
-				ID:    strconv.FormatUint(uint64(item.ProductID), 10),
-				Price: int64(price),
-				Qty:   int32(item.Quantity),
-				Name:  item.Product.Name,
-			})
+		if len(plan.Problems) > 0 {
+			return fiber.NewError(plan.Problems[0].status, plan.Problems[0].Message)
 		}
 
+		quote := plan.Voucher
 		order := models.Order{
-			UserID:      userID,
-			TotalAmount: totalAmount,
-			Status:      "pending",
-			OrderItems:  orderItems,
+			UserID:        userID,
+			TotalAmount:   plan.Total,
+			ShippingTotal: plan.ShippingTotal,
+			Status:        models.OrderStatusPending,
+		}
+		if quote != nil {
+			order.DiscountAmount = quote.Discount
+			order.VoucherCode = quote.Voucher.Code
 		}
 		if err := tx.Create(&order).Error; err != nil {
 			return fiber.NewError(fiber.StatusInternalServerError, "Gagal membuat order")
 		}
+		if quote != nil {
+			if err := h.Vouchers.Redeem(tx, quote, userID, order.ID); err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, "Gagal nyatet pemakaian voucher")
+			}
+		}
+		if err := h.Orders.RecordCreated(tx, &order, orderflow.Actor{UserID: userID, Role: orderflow.ActorBuyer}); err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Gagal mencatat riwayat order")
+		}
+
+		// Pecah order per seller, tiap seller dapet sub-order sendiri buat diproses
+		sellerOrders := buildSellerOrders(order.ID, plan.OrderItems, plan.Products)
+		for i := range sellerOrders {
+			if err := fulfilment.Assign(&sellerOrders[i], plan.Choices[sellerOrders[i].SellerID]); err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, "Gagal nyimpen data pengiriman")
+			}
+		}
+		if err := tx.Create(&sellerOrders).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Gagal membuat sub-order seller")
+		}
+		order.OrderItems = flattenSellerOrderItems(sellerOrders, plan.OrderItems, plan.Products)
+
+		// Hold stok sekarang juga, biar unit terakhir gak kebeli dua orang
+		for _, item := range order.OrderItems {
+			heldProductIDs = append(heldProductIDs, item.ProductID)
+		}
+		holdUntil, err := h.Reservations.Hold(tx, order.ID, order.OrderItems)
+		if err != nil {
+			if errors.Is(err, reservation.ErrInsufficientStock) {
+				return fiber.NewError(fiber.StatusConflict, err.Error())
+			}
+			return fiber.NewError(fiber.StatusInternalServerError, "Gagal nge-hold stok")
+		}
+		expiresAt = holdUntil
 
 		var user models.User
 		tx.First(&user, userID)
 
 		orderIDStr := "TELUHUB-" + strconv.FormatUint(uint64(order.ID), 10) + "-" + strconv.FormatInt(time.Now().Unix(), 10)
-
-		snapReq := &snap.Request{
-			TransactionDetails: midtrans.TransactionDetails{
-				OrderID:  orderIDStr,
-				GrossAmt: int64(totalAmount),
-			},
-			CustomerDetail: &midtrans.CustomerDetails{
-				FName: user.Username,
-				Email: user.Email,
-			},
-			Items: &midtransItems,
+		if err := tx.Model(&order).Update("payment_ref", orderIDStr).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Gagal menyimpan referensi pembayaran")
 		}
 
-		snapResp, snapErr := h.SnapClient.CreateTransaction(snapReq)
-		if snapErr != nil {
-			return fiber.NewError(fiber.StatusInternalServerError, "Gagal membuat transaksi Midtrans")
+		charge, chargeErr := h.Payments.CreateTransaction(payment.ChargeRequest{
+			OrderRef:      orderIDStr,
+			Amount:        int64(plan.Total),
+			CustomerName:  user.Username,
+			CustomerEmail: user.Email,
+			Items:         plan.PaymentItems,
+			// Samain batas waktu bayar dengan TTL hold stok
+			Expiry: h.Reservations.TTL,
+		})
+		if chargeErr != nil {
+			log.Printf("[CHECKOUT] ERROR: Gagal bikin transaksi %s: %v", h.Payments.Name(), chargeErr)
+			return fiber.NewError(fiber.StatusInternalServerError, "Gagal membuat transaksi pembayaran")
 		}
 
-		snapToken = snapResp.Token
+		snapToken = charge.Token
+		redirectURL = charge.RedirectURL
 		orderIDGorm = order.ID
 
-		if err := tx.Where("cart_id = ?", cart.ID).Delete(&models.CartItem{}).Error; err != nil {
+		if err := clearCheckedOutLines(tx, plan.Lines); err != nil {
 			return fiber.NewError(fiber.StatusInternalServerError, "Gagal mengosongkan keranjang")
 		}
 
@@ -146,168 +190,22 @@ func (h *OrderHandler) CreateOrderAndPay(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	h.Reservations.Broadcast(heldProductIDs)
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"snap_token": snapToken,
-		"order_id":   orderIDGorm,
+		"snap_token":   snapToken,
+		"redirect_url": redirectURL,
+		"gateway":      h.Payments.Name(),
+		"order_id":     orderIDGorm,
+		"expires_at":   expiresAt,
 	})
 }
 
-type MidtransNotification struct {
-	TransactionStatus string `json:"transaction_status"`
-	TransactionID     string `json:"transaction_id"`
-	OrderID           string `json:"order_id"`
-	StatusCode        string `json:"status_code"`
-	SignatureKey      string `json:"signature_key"`
-	PaymentType       string `json:"payment_type"`
-	GrossAmount       string `json:"gross_amount"`
-}
-
-func (h *OrderHandler) HandleWebhook(c *fiber.Ctx) error {
-	var notification MidtransNotification
-
-	if err := c.BodyParser(&notification); err != nil {
-		log.Printf("[WEBHOOK] 400 Bad Request: Gagal parse body: %v", err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse request"})
-	}
-
-	serverKey := config.GetMidtransServerKey()
-	strToHash := notification.OrderID + notification.StatusCode + notification.GrossAmount + serverKey
-	hasher := sha512.New()
-	hasher.Write([]byte(strToHash))
-	ourHash := hex.EncodeToString(hasher.Sum(nil))
-	if ourHash != notification.SignatureKey {
-		log.Printf("[WEBHOOK] 401 Unauthorized: Signature key salah!")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid signature"})
-	}
-
-	if !strings.HasPrefix(notification.OrderID, "TELUHUB-") {
-		log.Printf("[WEBHOOK] INFO: Notifikasi Tes (OrderID: %s) diterima dan di-skip.", notification.OrderID)
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Webhook Test Received and Skipped"})
-	}
-
-	log.Printf("[WEBHOOK] 200 OK: Notifikasi ASLI diterima untuk Order ID: %s, Status: %s", notification.OrderID, notification.TransactionStatus)
-
-	orderIDParts := strings.Split(notification.OrderID, "-")
-	if len(orderIDParts) < 3 {
-		log.Printf("[WEBHOOK] 400 Bad Request: Format Order ID asli salah: %s", notification.OrderID)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Order ID format"})
-	}
-
-	realOrderID, err := strconv.ParseUint(orderIDParts[1], 10, 64)
-	if err != nil {
-		log.Printf("[WEBHOOK] 400 Bad Request: Gagal parse Order ID asli: %v", err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Order ID"})
-	}
-
-	var order models.Order
-	if err := h.DB.First(&order, realOrderID).Error; err != nil {
-		log.Printf("[WEBHOOK] 404 Not Found: Order %d tidak ditemukan di DB", realOrderID)
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order not found"})
-	}
-
-	if notification.TransactionStatus == "settlement" || notification.TransactionStatus == "capture" {
-		if order.Status == "paid" {
-			log.Printf("[WEBHOOK] INFO: Order %d udah 'paid', skip", realOrderID)
-			return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Already processed"})
-		}
-
-		// Map untuk menyimpan seller yang terlibat dan produknya
-		sellerProductsMap := make(map[uint][]string)
-
-		err := h.DB.Transaction(func(tx *gorm.DB) error {
-			var orderItems []models.OrderItem
-			// Preload Product.Seller biar kita tau siapa penjualnya
-			if err := tx.Where("order_id = ?", realOrderID).Preload("Product.Seller").Find(&orderItems).Error; err != nil {
-				return fmt.Errorf("gagal ambil order items: %v", err)
-			}
-
-			if len(orderItems) == 0 {
-				return fmt.Errorf("order %d gak punya item", realOrderID)
-			}
-
-			for _, item := range orderItems {
-				if item.Quantity > item.Product.Stock {
-					return fmt.Errorf("stok tidak cukup untuk produk %s", item.Product.Name)
-				}
-
-				newStock := item.Product.Stock - item.Quantity
-				if err := tx.Model(&item.Product).Where("id = ?", item.ProductID).Update("stock", newStock).Error; err != nil {
-					return fmt.Errorf("gagal update stok: %v", err)
-				}
-				h.StockService.BroadcastStockUpdate(item.ProductID, newStock)
-
-				// Catat seller dan produk yang terjual
-				sellerID := item.Product.SellerID
-				productName := fmt.Sprintf("%s (x%d)", item.Product.Name, item.Quantity)
-				sellerProductsMap[sellerID] = append(sellerProductsMap[sellerID], productName)
-			}
-
-			order.Status = "paid"
-			if err := tx.Save(&order).Error; err != nil {
-				return fmt.Errorf("gagal update status order: %v", err)
-			}
-
-			return nil
-		})
-
-		if err != nil {
-			log.Printf("[WEBHOOK] 500 Server Error: Gagal proses transaksi 'paid': %v", err)
-			order.Status = "failed"
-			h.DB.Save(&order)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
-		}
-
-		log.Printf("[WEBHOOK] SUKSES: Order %d statusnya di-update jadi 'paid'", realOrderID)
-
-		// 1. Kirim notifikasi ke Buyer (Gunakan goroutine agar tidak blocking)
-		go func() {
-			err := h.NotifService.CreateAndSend(
-				order.UserID, // ID Buyer
-				models.NotificationTypeOrder,
-				"Pembayaran Berhasil!",
-				fmt.Sprintf("Order #%d kamu sudah lunas dan akan segera diproses.", order.ID),
-				order.ID,
-			)
-			if err != nil {
-				log.Printf("[WEBHOOK] WARNING: Gagal kirim notif ke buyer %d: %v", order.UserID, err)
-			}
-		}()
-
-		// 2. Kirim notifikasi ke semua Seller yang terlibat
-		for sellerID, productNames := range sellerProductsMap {
-			// Gunakan goroutine terpisah untuk setiap seller
-			go func(sID uint, pNames []string) {
-				productListStr := strings.Join(pNames, ", ")
-				err := h.NotifService.CreateAndSend(
-					sID, // ID Seller
-					models.NotificationTypeOrder,
-					"Produk Anda Terjual! 🎉",
-					fmt.Sprintf("Ada pesanan baru untuk produk: %s. Segera proses ya!", productListStr),
-					order.ID, // Reference ID sama ke Order ID
-				)
-				if err != nil {
-					log.Printf("[WEBHOOK] WARNING: Gagal kirim notif ke seller %d: %v", sID, err)
-				} else {
-					log.Printf("[WEBHOOK] INFO: Notifikasi penjualan terkirim ke seller %d", sID)
-				}
-			}(sellerID, productNames)
-		}
-	}
-
-	if notification.TransactionStatus == "expire" || notification.TransactionStatus == "failure" || notification.TransactionStatus == "deny" {
-		order.Status = "failed"
-		h.DB.Save(&order)
-		log.Printf("[WEBHOOK] INFO: Order %d statusnya di-update jadi 'failed'", realOrderID)
-	}
-
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Webhook received and processed"})
-}
-
 func (h *OrderHandler) GetMyOrders(c *fiber.Ctx) error {
 	userID, _ := c.Locals("user_id").(uint)
 
 	var orders []models.Order
-	err := h.DB.Preload("OrderItems.Product").
+	err := h.DB.Preload("OrderItems.Product").Preload("SellerOrders.OrderItems.Product").Preload("SellerOrders.Seller").
 		Where("user_id = ?", userID).
 		Order("created_at desc").
 		Find(&orders).Error
@@ -324,6 +222,9 @@ func (h *OrderHandler) GetMyOrders(c *fiber.Ctx) error {
 				ID:          item.ID,
 				Quantity:    item.Quantity,
 				PriceAtTime: item.PriceAtTime,
+				VariantID:   item.VariantID,
+				VariantName: item.VariantName,
+				DiscountAmount: item.DiscountAmount,
 				Product: OrderProductResponse{
 					ID:       item.Product.ID,
 					Name:     item.Product.Name,
@@ -336,9 +237,13 @@ func (h *OrderHandler) GetMyOrders(c *fiber.Ctx) error {
 		response = append(response, OrderResponse{
 			ID:          order.ID,
 			TotalAmount: order.TotalAmount,
+			DiscountAmount: order.DiscountAmount,
+			VoucherCode: order.VoucherCode,
+			ShippingTotal: order.ShippingTotal,
 			Status:      order.Status,
 			CreatedAt:   order.CreatedAt,
 			OrderItems:  orderItemsResponse,
+			SellerOrders: toSellerOrderResponses(order.SellerOrders),
 		})
 	}
 
@@ -354,7 +259,7 @@ func (h *OrderHandler) GetOrderByID(c *fiber.Ctx) error {
 	orderID := c.Params("id")
 
 	var order models.Order
-	err := h.DB.Preload("OrderItems.Product").
+	err := h.DB.Preload("OrderItems.Product").Preload("SellerOrders.OrderItems.Product").Preload("SellerOrders.Seller").
 		Where("id = ? AND user_id = ?", orderID, userID).
 		First(&order).Error
 
@@ -371,6 +276,9 @@ func (h *OrderHandler) GetOrderByID(c *fiber.Ctx) error {
 			ID:          item.ID,
 			Quantity:    item.Quantity,
 			PriceAtTime: item.PriceAtTime,
+			VariantID:   item.VariantID,
+			VariantName: item.VariantName,
+			DiscountAmount: item.DiscountAmount,
 			Product: OrderProductResponse{
 				ID:       item.Product.ID,
 				Name:     item.Product.Name,
@@ -383,9 +291,13 @@ func (h *OrderHandler) GetOrderByID(c *fiber.Ctx) error {
 	response := OrderResponse{
 		ID:          order.ID,
 		TotalAmount: order.TotalAmount,
+		DiscountAmount: order.DiscountAmount,
+		VoucherCode: order.VoucherCode,
+		ShippingTotal: order.ShippingTotal,
 		Status:      order.Status,
 		CreatedAt:   order.CreatedAt,
 		OrderItems:  orderItemsResponse,
+		SellerOrders: toSellerOrderResponses(order.SellerOrders),
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response)
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/orderflow"
+	"github.com/akhdanrgya/telu-hub/internal/reservation"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+var (
+	errHoldUnavailable = errors.New("stok order ini udah gak cukup buat di-hold ulang, gak bisa ditandain paid manual")
+	errUseAdminRefunds = errors.New("order yang udah dibayar dibatalin/di-refund lewat /admin/orders/:id/refunds biar dana & stoknya ikut dibalikin")
+)
+
+type UpdateOrderStatusInput struct {
+	Status models.OrderStatus `json:"status"`
+	Reason string             `json:"reason"`
+}
+
+func orderFlowErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, orderflow.ErrIllegalTransition), errors.Is(err, orderflow.ErrStaleOrder),
+		errors.Is(err, errHoldUnavailable), errors.Is(err, errUseAdminRefunds):
+		return fiber.StatusConflict
+	case errors.Is(err, orderflow.ErrActorNotAllowed):
+		return fiber.StatusForbidden
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// applyTransition jalanin transisi status dalam satu transaksi. Kalau order yang
+// belum dibayar dibatalin, stok yang di-hold ikut dilepas; kalau ditandain paid, hold-nya di-commit
+// (di-hold ulang dulu kalau udah kelepas, mis. order failed yang ditandain paid admin).
+func (h *OrderHandler) applyTransition(order *models.Order, to models.OrderStatus, actor orderflow.Actor, reason string) error {
+	from := order.Status
+	var changedProductIDs []uint
+	var history *models.OrderStatusHistory
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if from == models.OrderStatusPending && (to == models.OrderStatusCancelled || to == models.OrderStatusFailed) {
+			productIDs, err := h.Reservations.Release(tx, order.ID, string(to))
+			if err != nil {
+				return err
+			}
+			changedProductIDs = productIDs
+		}
+		if to == models.OrderStatusPaid {
+			// Sama kayak webhook settlement: stok yang di-hold baru beneran dikurangin di sini
+			productIDs, err := h.Reservations.Commit(tx, order.ID)
+			if errors.Is(err, reservation.ErrNoActiveHold) {
+				var items []models.OrderItem
+				if err := tx.Where("order_id = ?", order.ID).Preload("Product").Find(&items).Error; err != nil {
+					return err
+				}
+				if len(items) == 0 {
+					return fmt.Errorf("order %d gak punya item", order.ID)
+				}
+				if _, err := h.Reservations.Hold(tx, order.ID, items); err != nil {
+					if errors.Is(err, reservation.ErrInsufficientStock) {
+						return fmt.Errorf("%w: %v", errHoldUnavailable, err)
+					}
+					return err
+				}
+				productIDs, err = h.Reservations.Commit(tx, order.ID)
+			}
+			if err != nil {
+				return err
+			}
+			changedProductIDs = productIDs
+		}
+
+		hist, err := h.Orders.Transition(tx, order, to, actor, reason)
+		history = hist
+		return err
+	})
+	if err != nil {
+		order.Status = from
+		return err
+	}
+
+	h.Reservations.Broadcast(changedProductIDs)
+	h.Orders.Notify(order, history)
+	return nil
+}
+
+func (h *OrderHandler) findBuyerOrder(c *fiber.Ctx) (*models.Order, error) {
+	userID, _ := c.Locals("user_id").(uint)
+	var order models.Order
+	if err := h.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).First(&order).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// sellerHasItems ngecek apakah seller punya minimal satu produk di order ini.
+func (h *OrderHandler) sellerHasItems(orderID, sellerID uint) bool {
+	var count int64
+	h.DB.Model(&models.OrderItem{}).
+		Joins("JOIN products ON products.id = order_items.product_id").
+		Where("order_items.order_id = ? AND products.seller_id = ?", orderID, sellerID).
+		Count(&count)
+	return count > 0
+}
+
+// POST /orders/:id/cancel — buyer batalin order. Yang belum dibayar tinggal dilepas hold-nya,
+// yang udah dibayar (tapi belum dikirim) di-refund dan stoknya dibalikin.
+func (h *OrderHandler) CancelOrder(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(uint)
+	order, err := h.findBuyerOrder(c)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order tidak ditemukan"})
+	}
+
+	var input UpdateOrderStatusInput
+	c.BodyParser(&input)
+
+	actor := orderflow.Actor{UserID: userID, Role: orderflow.ActorBuyer}
+	if order.Status != models.OrderStatusPending {
+		result, err := h.Refunds.CancelOrder(order, actor, input.Reason)
+		if err != nil {
+			return c.Status(refundErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+		h.DB.First(order, order.ID)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Order dibatalkan, dana dikembalikan", "status": order.Status, "refund": result})
+	}
+	if err := h.applyTransition(order, models.OrderStatusCancelled, actor, input.Reason); err != nil {
+		return c.Status(orderFlowErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Order berhasil dibatalkan", "status": order.Status})
+}
+
+// POST /orders/:id/complete — buyer konfirmasi pesanan udah diterima
+func (h *OrderHandler) CompleteOrder(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(uint)
+	order, err := h.findBuyerOrder(c)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order tidak ditemukan"})
+	}
+
+	actor := orderflow.Actor{UserID: userID, Role: orderflow.ActorBuyer}
+	if err := h.applyTransition(order, models.OrderStatusCompleted, actor, "Dikonfirmasi buyer"); err != nil {
+		return c.Status(orderFlowErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Pesanan selesai", "status": order.Status})
+}
+
+// PATCH /admin/orders/:id/status
+func (h *OrderHandler) AdminUpdateStatus(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(uint)
+
+	var input UpdateOrderStatusInput
+	if err := c.BodyParser(&input); err != nil || !input.Status.IsValid() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Status tidak valid"})
+	}
+
+	var order models.Order
+	if err := h.DB.First(&order, c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order tidak ditemukan"})
+	}
+
+	// Batal/refund order yang udah dibayar butuh refund ke gateway + balikin stok, jadi gak boleh lewat sini
+	if order.Status != models.OrderStatusPending && (input.Status == models.OrderStatusCancelled || input.Status == models.OrderStatusRefunded) {
+		return c.Status(orderFlowErrorStatus(errUseAdminRefunds)).JSON(fiber.Map{"error": errUseAdminRefunds.Error()})
+	}
+
+	actor := orderflow.Actor{UserID: userID, Role: orderflow.ActorAdmin}
+	if err := h.applyTransition(&order, input.Status, actor, input.Reason); err != nil {
+		return c.Status(orderFlowErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Status order diperbarui", "status": order.Status})
+}
+
+// GET /orders/:id/history — bisa dilihat buyer, seller yang produknya ada di order, atau admin
+func (h *OrderHandler) GetOrderHistory(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(uint)
+	role, _ := c.Locals("user_role").(string)
+
+	orderID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID order tidak valid"})
+	}
+
+	var order models.Order
+	if err := h.DB.First(&order, orderID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order tidak ditemukan"})
+	}
+
+	if order.UserID != userID && role != "admin" && !h.sellerHasItems(order.ID, userID) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order tidak ditemukan"})
+	}
+
+	history, err := h.Orders.GetHistory(order.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil riwayat status"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"order_id":      order.ID,
+		"status":        order.Status,
+		"next_statuses": order.Status.NextStatuses(),
+		"history":       history,
+	})
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/akhdanrgya/telu-hub/internal/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+// PrivateFileHandler nyajiin file private storage lokal (dokumen pengajuan seller). File-nya
+// ada di luar folder app.Static, jadi satu-satunya jalan ke sana lewat sini.
+type PrivateFileHandler struct {
+	Storage *storage.Local
+}
+
+func NewPrivateFileHandler(local *storage.Local) *PrivateFileHandler {
+	return &PrivateFileHandler{Storage: local}
+}
+
+// GET /uploads/private/* — wajib bawa expires & signature dari SignedURL.
+func (h *PrivateFileHandler) Serve(c *fiber.Ctx) error {
+	// Key diambil dari path yang udah di-decode & dibersihin, jadi yang dicek signature-nya
+	// sama persis dengan file yang dibuka
+	raw, err := url.PathUnescape(c.Params("*"))
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": storage.ErrInvalidKey.Error()})
+	}
+	key := storage.PrivatePrefix + strings.TrimPrefix(path.Clean("/"+raw), "/")
+	if err := h.Storage.Verify(key, c.Query("expires"), c.Query("signature")); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	file, err := h.Storage.Get(key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "File tidak ditemukan"})
+		}
+		log.Printf("[MEDIA] ERROR: Gagal buka file private %s: %v", key, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal membuka file"})
+	}
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		c.Set(fiber.HeaderContentType, contentType)
+	}
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	return c.SendStream(file)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akhdanrgya/telu-hub/internal/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+func newPrivateFileApp(t *testing.T) (*fiber.App, *storage.Local) {
+	t.Helper()
+	root := t.TempDir()
+	local, err := storage.NewLocal(filepath.Join(root, "uploads"), filepath.Join(root, "uploads-private"), "http://localhost/uploads", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Get("/uploads/"+storage.PrivatePrefix+"*", NewPrivateFileHandler(local).Serve)
+	app.Static("/uploads", local.Dir)
+	return app, local
+}
+
+func putTestFile(t *testing.T, local *storage.Local, key, body string) {
+	t.Helper()
+	if err := local.Put(key, strings.NewReader(body), int64(len(body)), "image/jpeg"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func getTestPath(t *testing.T, app *fiber.App, target string) (int, string) {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest("GET", target, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestPrivateFilesNeedSignature(t *testing.T) {
+	app, local := newPrivateFileApp(t)
+	putTestFile(t, local, "private/documents/ktm-full.jpg", "ktm")
+	putTestFile(t, local, "kaos-full.jpg", "kaos")
+
+	paths := []string{
+		"/uploads/private/documents/ktm-full.jpg",
+		"/uploads/%70rivate/documents/ktm-full.jpg",
+		"/uploads/private/documents/%6btm-full.jpg",
+		"/uploads//private/documents/ktm-full.jpg",
+		"/uploads/./private/documents/ktm-full.jpg",
+		"/uploads/documents/../private/documents/ktm-full.jpg",
+		"/uploads/PRIVATE/documents/ktm-full.jpg",
+		"/uploads-private/documents/ktm-full.jpg",
+	}
+	for _, p := range paths {
+		if status, body := getTestPath(t, app, p); status == fiber.StatusOK || body == "ktm" {
+			t.Errorf("GET %s tanpa signature: status %d, body %q", p, status, body)
+		}
+	}
+
+	if status, body := getTestPath(t, app, "/uploads/kaos-full.jpg"); status != fiber.StatusOK || body != "kaos" {
+		t.Errorf("file publik harus tetep kebuka: status %d, body %q", status, body)
+	}
+}
+
+func TestPrivateFileSignedURL(t *testing.T) {
+	app, local := newPrivateFileApp(t)
+	putTestFile(t, local, "private/documents/ktm-full.jpg", "ktm")
+
+	signed, err := local.SignedURL("private/documents/ktm-full.jpg", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(signed)
+	if status, body := getTestPath(t, app, u.RequestURI()); status != fiber.StatusOK || body != "ktm" {
+		t.Fatalf("signed URL harus kebuka: status %d, body %q", status, body)
+	}
+
+	// Signature satu file gak boleh dipake buat file lain
+	putTestFile(t, local, "private/documents/selfie-full.jpg", "selfie")
+	other := strings.Replace(u.RequestURI(), "ktm-full", "selfie-full", 1)
+	if status, _ := getTestPath(t, app, other); status != fiber.StatusForbidden {
+		t.Errorf("signature file lain: status %d, mau 403", status)
+	}
+
+	expired, _ := local.SignedURL("private/documents/ktm-full.jpg", -time.Minute)
+	u, _ = url.Parse(expired)
+	if status, _ := getTestPath(t, app, u.RequestURI()); status != fiber.StatusForbidden {
+		t.Errorf("signed URL kedaluwarsa: status %d, mau 403", status)
+	}
+}
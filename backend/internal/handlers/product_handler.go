@@ -1,20 +1,29 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 
+	"github.com/akhdanrgya/telu-hub/internal/media"
 	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/notification"
+	"github.com/akhdanrgya/telu-hub/internal/stockalert"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gosimple/slug"
 	"gorm.io/gorm"
 )
 
 type ProductHandler struct {
-	DB *gorm.DB
+	DB           *gorm.DB
+	Media        *media.Service
+	NotifService *notification.Service
+	StockAlerts  *stockalert.Service
 }
 
-func NewProductHandler(db *gorm.DB) *ProductHandler {
-	return &ProductHandler{DB: db}
+func NewProductHandler(db *gorm.DB, mediaService *media.Service, notifService *notification.Service, stockAlerts *stockalert.Service) *ProductHandler {
+	return &ProductHandler{DB: db, Media: mediaService, NotifService: notifService, StockAlerts: stockAlerts}
 }
 
 type CreateProductInput struct {
@@ -22,8 +31,11 @@ type CreateProductInput struct {
 	Description string  `json:"description"`
 	Price       float64 `json:"price" validate:"required,gt=0"`
 	Stock       int     `json:"stock" validate:"required,gte=0"`
+	WeightGrams int     `json:"weight_grams"` // Berat per item (gram) buat ongkir kurir
 	ImageURL    string  `json:"image_url"`
 	CategoryID  uint    `json:"category_id" validate:"required"`
+	Variants    []VariantInput `json:"variants,omitempty" gorm:"-"` // Opsional, cuma dibaca waktu create
+	Images      []ProductImageInput `json:"images,omitempty" gorm:"-"` // Opsional, cuma dibaca waktu create (default: image_url jadi foto utama)
 }
 
 type CategoryResponse struct {
@@ -39,9 +51,18 @@ type ProductResponse struct {
 	Description string           `json:"description"`
 	Price       float64          `json:"price"`
 	Stock       int              `json:"stock"`
+	AvailableStock int           `json:"available_stock"`
+	ReservedStock  int           `json:"reserved_stock"`
+	WeightGrams    int           `json:"weight_grams"`
 	ImageURL    string           `json:"image_url"`
+	ThumbnailURL string          `json:"thumbnail_url"` // Versi kecil image_url buat kartu produk
+	SoldCount   int              `json:"sold_count,omitempty"` // Cuma diisi di GET /products
+	RatingAverage float64        `json:"rating_average"`
+	RatingCount   int            `json:"rating_count"`
 	Seller      UserResponse     `json:"seller"`
 	Category    CategoryResponse `json:"category"` // 🔥 AKHIRNYA ADA!
+	Variants    []VariantResponse `json:"variants,omitempty"`
+	Images      []ProductImageResponse `json:"images,omitempty"` // Galeri, urut sesuai position
 }
 
 func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
@@ -51,6 +72,10 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
 	}
 
+	if input.WeightGrams < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Berat produk gak boleh negatif"})
+	}
+
 	productSlug := slug.Make(input.Name)
 
 	product := models.Product{
@@ -59,52 +84,87 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 		Description: input.Description,
 		Price:       input.Price,
 		Stock:       input.Stock,
+		WeightGrams: input.WeightGrams,
 		ImageURL:    input.ImageURL,
 		SellerID:    sellerID,
 		CategoryID:  input.CategoryID,
 	}
 
-	if err := h.DB.Create(&product).Error; err != nil {
+	imageInputs := input.Images
+	if len(imageInputs) == 0 && input.ImageURL != "" {
+		imageInputs = []ProductImageInput{{URL: input.ImageURL}}
+	}
+	images, err := buildImages(imageInputs)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	for _, img := range images {
+		if err := h.checkOwnedImages(c, img.URL); err != nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	var variants []models.ProductVariant
+	for i := range input.Variants {
+		variant, err := buildVariant(&product, &input.Variants[i])
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		variants = append(variants, *variant)
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&product).Error; err != nil {
+			return err
+		}
+		if len(images) > 0 {
+			for i := range images {
+				images[i].ProductID = product.ID
+			}
+			if err := tx.Create(&images).Error; err != nil {
+				return err
+			}
+			if err := models.SyncPrimaryImage(tx, product.ID); err != nil {
+				return err
+			}
+		}
+		if len(variants) == 0 {
+			return nil
+		}
+		for i := range variants {
+			variants[i].ProductID = product.ID
+		}
+		if err := tx.Create(&variants).Error; err != nil {
+			return err
+		}
+		return models.SyncVariantTotals(tx, product.ID)
+	})
+	if err != nil {
 		if gorm.ErrDuplicatedKey == err {
 			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Nama produk ini sudah ada (slug duplikat)"})
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menyimpan produk"})
 	}
+	if len(variants) > 0 || len(images) > 0 {
+		h.DB.Preload("Variants").Preload("Images", models.OrderedImages).First(&product, product.ID)
+	}
 
 	return c.Status(fiber.StatusCreated).JSON(product)
 }
 
+// GET /products?q=&category=&seller=&min_price=&max_price=&in_stock=&sort=&limit=&cursor=
 func (h *ProductHandler) GetAllProducts(c *fiber.Ctx) error {
-	var products []models.Product
-	if err := h.DB.Preload("Seller").Preload("Category").Find(&products).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil data produk"})
-	}
-
-	var response []ProductResponse
-	for _, p := range products {
-		response = append(response, ProductResponse{
-			ID:          p.ID,
-			Name:        p.Name,
-			Slug:        p.Slug,
-			Description: p.Description,
-			Price:       p.Price,
-			Stock:       p.Stock,
-			ImageURL:    p.ImageURL,
-			Seller: UserResponse{
-				ID:       p.Seller.ID,
-				Username: p.Seller.Username,
-				Email:    p.Seller.Email,
-			},
-			Category: CategoryResponse{
-				ID:   p.Category.ID,
-				Name: p.Category.Name,
-				Slug: p.Category.Slug,
-			},
-		})
+	q, msg := parseProductQuery(c)
+	if msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": msg})
 	}
 
-	if response == nil {
-		response = make([]ProductResponse, 0)
+	response, err := listProducts(h.DB, q)
+	if err != nil {
+		if errors.Is(err, errInvalidCursor) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil data produk"})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response)
@@ -120,9 +180,9 @@ func (h *ProductHandler) GetProductBySlug(c *fiber.Ctx) error {
     var err error
 
     if id, parseErr := strconv.Atoi(identifier); parseErr == nil {
-        err = h.DB.Preload("Seller").Preload("Category").First(&product, id).Error
+        err = h.DB.Preload("Seller").Preload("Category").Preload("Variants").Preload("Images", models.OrderedImages).First(&product, id).Error
     } else {
-        err = h.DB.Preload("Seller").Preload("Category").Where("slug = ?", identifier).First(&product).Error
+        err = h.DB.Preload("Seller").Preload("Category").Preload("Variants").Preload("Images", models.OrderedImages).Where("slug = ?", identifier).First(&product).Error
     }
 
     if err != nil {
@@ -139,7 +199,13 @@ func (h *ProductHandler) GetProductBySlug(c *fiber.Ctx) error {
         Description: product.Description,
         Price:       product.Price,
         Stock:       product.Stock,
+        AvailableStock: product.Available(),
+        ReservedStock:  product.Reserved,
+        WeightGrams:    product.WeightGrams,
         ImageURL:    product.ImageURL,
+        ThumbnailURL: imageVariantURL(product.ImageURL, "thumbnail"),
+        RatingAverage: product.RatingAverage,
+        RatingCount:   product.RatingCount,
         Seller: UserResponse{
             ID:       product.Seller.ID,
             Username: product.Seller.Username,
@@ -150,6 +216,8 @@ func (h *ProductHandler) GetProductBySlug(c *fiber.Ctx) error {
             Name: product.Category.Name,
             Slug: product.Category.Slug,
         },
+        Variants: toVariantResponses(product.Variants),
+        Images:   toProductImageResponses(product.Images),
     }
 
     return c.Status(fiber.StatusOK).JSON(response)
@@ -164,7 +232,7 @@ func (h *ProductHandler) GetProductByID(c *fiber.Ctx) error {
 
 	var product models.Product
 
-	if err := h.DB.Preload("Seller").Where("id = ?", id).First(&product).Error; err != nil {
+	if err := h.DB.Preload("Seller").Preload("Variants").Preload("Images", models.OrderedImages).Where("id = ?", id).First(&product).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produk tidak ditemukan"})
 		}
@@ -178,7 +246,13 @@ func (h *ProductHandler) GetProductByID(c *fiber.Ctx) error {
 		Description: product.Description,
 		Price:       product.Price,
 		Stock:       product.Stock,
+		AvailableStock: product.Available(),
+		ReservedStock:  product.Reserved,
+		WeightGrams:    product.WeightGrams,
 		ImageURL:    product.ImageURL,
+		ThumbnailURL: imageVariantURL(product.ImageURL, "thumbnail"),
+		RatingAverage: product.RatingAverage,
+		RatingCount:   product.RatingCount,
 		Seller: UserResponse{
 			ID:       product.Seller.ID,
 			Username: product.Seller.Username,
@@ -189,6 +263,8 @@ func (h *ProductHandler) GetProductByID(c *fiber.Ctx) error {
 			Name: product.Category.Name,
 			Slug: product.Category.Slug,
 		},
+		Variants: toVariantResponses(product.Variants),
+		Images:   toProductImageResponses(product.Images),
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response)
@@ -216,9 +292,70 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
 	}
 
-	if err := h.DB.Model(&product).Updates(input).Error; err != nil {
+	var variantCount int64
+	h.DB.Model(&models.ProductVariant{}).Where("product_id = ?", product.ID).Count(&variantCount)
+	if variantCount > 0 {
+		// Harga & stok produk bervarian diitung dari variannya, ubahnya lewat endpoint varian
+		input.Price = 0
+		input.Stock = 0
+	}
+
+	if input.WeightGrams < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Berat produk gak boleh negatif"})
+	}
+
+	if input.Stock != 0 && input.Stock < product.Reserved {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Stok tidak boleh kurang dari %d unit yang lagi di-hold order pending", product.Reserved),
+		})
+	}
+
+	// image_url di sini = ganti foto utama, galeri sisanya gak diubah
+	newImageURL := strings.TrimSpace(input.ImageURL)
+	input.ImageURL = ""
+	input.Images = nil
+	if newImageURL != "" && newImageURL != product.ImageURL {
+		if err := validateImageURL(newImageURL); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if err := h.checkOwnedImages(c, newImageURL); err != nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
+	} else {
+		newImageURL = ""
+	}
+	oldImageURL := product.ImageURL
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&product).Updates(input).Error; err != nil {
+			return err
+		}
+		if newImageURL == "" {
+			return nil
+		}
+		var primary models.ProductImage
+		err := tx.Where("product_id = ? AND is_primary", product.ID).First(&primary).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			primary = models.ProductImage{ProductID: product.ID, URL: newImageURL, AltText: product.Name, IsPrimary: true}
+			err = tx.Create(&primary).Error
+		} else if err == nil {
+			err = tx.Model(&primary).Update("url", newImageURL).Error
+		}
+		if err != nil {
+			return err
+		}
+		return models.SyncPrimaryImage(tx, product.ID)
+	})
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengupdate produk"})
 	}
+	if newImageURL != "" {
+		h.Media.Release(oldImageURL)
+	}
+	// Restock dari seller: kabarin yang nungguin produk ini
+	h.StockAlerts.Notify([]uint{product.ID})
+
+	h.DB.Preload("Images", models.OrderedImages).First(&product, product.ID)
 	return c.Status(fiber.StatusOK).JSON(product)
 }
 
@@ -239,9 +376,20 @@ func (h *ProductHandler) DeleteProduct(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Forbidden", "message": "Anda tidak punya izin untuk menghapus produk ini"})
 	}
 
-	if err := h.DB.Delete(&product).Error; err != nil {
+	var imageURLs []string
+	h.DB.Model(&models.ProductImage{}).Where("product_id = ?", product.ID).Pluck("url", &imageURLs)
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&product).Error; err != nil {
+			return err
+		}
+		// Galeri produk yang dihapus gak dipake lagi, file-nya dibersihin habis commit
+		return tx.Where("product_id = ?", product.ID).Delete(&models.ProductImage{}).Error
+	})
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menghapus produk"})
 	}
+	h.Media.Release(append(imageURLs, product.ImageURL)...)
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Produk berhasil dihapus"})
 }
 
@@ -252,7 +400,7 @@ func (h *ProductHandler) GetMyProducts(c *fiber.Ctx) error {
 	}
 
 	var products []models.Product
-	if err := h.DB.Preload("Category").Where("seller_id = ?", userID).Find(&products).Error; err != nil {
+	if err := h.DB.Preload("Category").Preload("Images", models.OrderedImages).Where("seller_id = ?", userID).Find(&products).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil data produk"})
 	}
 
@@ -265,12 +413,17 @@ func (h *ProductHandler) GetMyProducts(c *fiber.Ctx) error {
 			Description: p.Description,
 			Price:       p.Price,
 			Stock:       p.Stock,
+			AvailableStock: p.Available(),
+			ReservedStock:  p.Reserved,
+			WeightGrams:    p.WeightGrams,
 			ImageURL:    p.ImageURL,
+			ThumbnailURL: imageVariantURL(p.ImageURL, "thumbnail"),
 			Category: CategoryResponse{
 				ID:   p.Category.ID,
 				Name: p.Category.Name,
 				Slug: p.Category.Slug,
 			},
+			Images: toProductImageResponses(p.Images),
 		})
 	}
 
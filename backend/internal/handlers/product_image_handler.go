@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/akhdanrgya/telu-hub/internal/imaging"
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// maxProductImages = batas jumlah foto di galeri satu produk.
+const maxProductImages = 10
+
+type ProductImageInput struct {
+	URL       string  `json:"url"`
+	AltText   *string `json:"alt_text"`
+	IsPrimary *bool   `json:"is_primary"`
+}
+
+type ReorderImagesInput struct {
+	ImageIDs []uint `json:"image_ids"`
+}
+
+type ProductImageResponse struct {
+	ID           uint   `json:"id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	MediumURL    string `json:"medium_url"`
+	AltText      string `json:"alt_text"`
+	Position     int    `json:"position"`
+	IsPrimary    bool   `json:"is_primary"`
+}
+
+// imageVariantURL = URL ukuran lain (thumbnail/medium) dari gambar hasil upload.
+// Gambar lama / link luar yang gak punya ukuran lain balik ke URL aslinya.
+func imageVariantURL(url, name string) string {
+	if v := imaging.VariantURL(url, name); v != "" {
+		return v
+	}
+	return url
+}
+
+func toProductImageResponses(images []models.ProductImage) []ProductImageResponse {
+	responses := make([]ProductImageResponse, 0, len(images))
+	for _, img := range images {
+		responses = append(responses, ProductImageResponse{
+			ID:           img.ID,
+			URL:          img.URL,
+			ThumbnailURL: imageVariantURL(img.URL, "thumbnail"),
+			MediumURL:    imageVariantURL(img.URL, "medium"),
+			AltText:      img.AltText,
+			Position:     img.Position,
+			IsPrimary:    img.IsPrimary,
+		})
+	}
+	return responses
+}
+
+// validateImageURL nerima URL http(s) lengkap atau path upload lokal (/uploads/...).
+func validateImageURL(raw string) error {
+	if raw == "" {
+		return errors.New("url gambar wajib diisi")
+	}
+	if len(raw) > 255 {
+		return errors.New("url gambar kepanjangan (maks 255 karakter)")
+	}
+	if strings.HasPrefix(raw, "/uploads/") {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url gambar %q tidak valid", raw)
+	}
+	return nil
+}
+
+// buildImages ngecek input galeri waktu bikin produk. Kalau gak ada yang ditandain primary,
+// foto pertama yang jadi foto utama (diberesin SyncPrimaryImage).
+func buildImages(inputs []ProductImageInput) ([]models.ProductImage, error) {
+	if len(inputs) > maxProductImages {
+		return nil, fmt.Errorf("maksimal %d gambar per produk", maxProductImages)
+	}
+	images := make([]models.ProductImage, 0, len(inputs))
+	hasPrimary := false
+	for i, in := range inputs {
+		in.URL = strings.TrimSpace(in.URL)
+		if err := validateImageURL(in.URL); err != nil {
+			return nil, err
+		}
+		img := models.ProductImage{URL: in.URL, Position: i}
+		if in.AltText != nil {
+			img.AltText = strings.TrimSpace(*in.AltText)
+		}
+		if in.IsPrimary != nil && *in.IsPrimary && !hasPrimary {
+			img.IsPrimary = true
+			hasPrimary = true
+		}
+		images = append(images, img)
+	}
+	return images, nil
+}
+
+// checkOwnedImages mastiin tiap URL gambar hasil upload si pemanggil sendiri (admin bebas).
+func (h *ProductHandler) checkOwnedImages(c *fiber.Ctx, urls ...string) error {
+	userID, _ := c.Locals("user_id").(uint)
+	role, _ := c.Locals("user_role").(string)
+	for _, u := range urls {
+		if _, err := h.Media.OwnedUpload(u, userID, role == "admin"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setPrimaryImage jadiin satu foto sebagai foto utama (yang lain otomatis bukan).
+func setPrimaryImage(tx *gorm.DB, productID, imageID uint) error {
+	err := tx.Model(&models.ProductImage{}).Where("product_id = ?", productID).
+		Update("is_primary", gorm.Expr("id = ?", imageID)).Error
+	if err != nil {
+		return err
+	}
+	return models.SyncPrimaryImage(tx, productID)
+}
+
+func (h *ProductHandler) findProductImage(c *fiber.Ctx, productID uint) (*models.ProductImage, error) {
+	var image models.ProductImage
+	if err := h.DB.Where("id = ? AND product_id = ?", c.Params("imageId"), productID).First(&image).Error; err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+func (h *ProductHandler) productImages(productID uint) ([]models.ProductImage, error) {
+	var images []models.ProductImage
+	err := models.OrderedImages(h.DB.Where("product_id = ?", productID)).Find(&images).Error
+	return images, err
+}
+
+// GET /products/:id/images
+func (h *ProductHandler) GetImages(c *fiber.Ctx) error {
+	var images []models.ProductImage
+	if err := models.OrderedImages(h.DB.Where("product_id = ?", c.Params("id"))).Find(&images).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil galeri produk"})
+	}
+	return c.JSON(toProductImageResponses(images))
+}
+
+// POST /products/:id/images
+func (h *ProductHandler) AttachImage(c *fiber.Ctx) error {
+	product, err := h.findOwnedProduct(c)
+	if err != nil {
+		return productAccessError(c, err)
+	}
+
+	input := new(ProductImageInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	images, err := buildImages([]ProductImageInput{*input})
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	image := images[0]
+	if err := h.checkOwnedImages(c, image.URL); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+	image.ProductID = product.ID
+
+	var count int64
+	h.DB.Model(&models.ProductImage{}).Where("product_id = ?", product.ID).Count(&count)
+	if count >= maxProductImages {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fmt.Sprintf("Maksimal %d gambar per produk", maxProductImages)})
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var last struct{ Position int }
+		if err := tx.Model(&models.ProductImage{}).Select("COALESCE(MAX(position), -1) AS position").
+			Where("product_id = ?", product.ID).Scan(&last).Error; err != nil {
+			return err
+		}
+		image.Position = last.Position + 1
+		if err := tx.Create(&image).Error; err != nil {
+			return err
+		}
+		if image.IsPrimary {
+			return setPrimaryImage(tx, product.ID, image.ID)
+		}
+		return models.SyncPrimaryImage(tx, product.ID)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menambah gambar produk"})
+	}
+
+	h.DB.First(&image, image.ID)
+	return c.Status(fiber.StatusCreated).JSON(toProductImageResponses([]models.ProductImage{image})[0])
+}
+
+// PUT /products/:id/images/order
+// Body: {"image_ids": [3, 1, 2]} — harus berisi semua foto di galeri, urutan baru dari depan.
+func (h *ProductHandler) ReorderImages(c *fiber.Ctx) error {
+	product, err := h.findOwnedProduct(c)
+	if err != nil {
+		return productAccessError(c, err)
+	}
+
+	input := new(ReorderImagesInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+
+	images, err := h.productImages(product.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil galeri produk"})
+	}
+	owned := make(map[uint]bool, len(images))
+	for _, img := range images {
+		owned[img.ID] = true
+	}
+	seen := make(map[uint]bool, len(input.ImageIDs))
+	for _, id := range input.ImageIDs {
+		if !owned[id] || seen[id] {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fmt.Sprintf("Gambar #%d bukan bagian galeri produk ini (atau dobel)", id)})
+		}
+		seen[id] = true
+	}
+	if len(seen) != len(images) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "image_ids harus berisi semua gambar di galeri produk"})
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		for i, id := range input.ImageIDs {
+			if err := tx.Model(&models.ProductImage{}).Where("id = ?", id).Update("position", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengurutkan gambar produk"})
+	}
+
+	images, _ = h.productImages(product.ID)
+	return c.JSON(toProductImageResponses(images))
+}
+
+// PATCH /products/:id/images/:imageId
+// Body: {"alt_text": "...", "is_primary": true} — dua-duanya opsional.
+func (h *ProductHandler) UpdateImage(c *fiber.Ctx) error {
+	product, err := h.findOwnedProduct(c)
+	if err != nil {
+		return productAccessError(c, err)
+	}
+	image, err := h.findProductImage(c, product.ID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Gambar produk tidak ditemukan"})
+	}
+
+	input := new(ProductImageInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	if input.IsPrimary != nil && !*input.IsPrimary && image.IsPrimary {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Pilih gambar lain sebagai gambar utama dulu"})
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if input.AltText != nil {
+			if err := tx.Model(image).Update("alt_text", strings.TrimSpace(*input.AltText)).Error; err != nil {
+				return err
+			}
+		}
+		if input.IsPrimary != nil && *input.IsPrimary {
+			return setPrimaryImage(tx, product.ID, image.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengupdate gambar produk"})
+	}
+
+	h.DB.First(image, image.ID)
+	return c.JSON(toProductImageResponses([]models.ProductImage{*image})[0])
+}
+
+// DELETE /products/:id/images/:imageId
+// Kalau yang dilepas foto utama, foto berikutnya di urutan yang jadi utama. File-nya dihapus
+// dari disk kalau udah gak dipake produk lain.
+func (h *ProductHandler) DetachImage(c *fiber.Ctx) error {
+	product, err := h.findOwnedProduct(c)
+	if err != nil {
+		return productAccessError(c, err)
+	}
+	image, err := h.findProductImage(c, product.ID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Gambar produk tidak ditemukan"})
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(image).Error; err != nil {
+			return err
+		}
+		return models.SyncPrimaryImage(tx, product.ID)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menghapus gambar produk"})
+	}
+
+	h.Media.Release(image.URL)
+	return c.JSON(fiber.Map{"message": "Gambar berhasil dihapus dari produk"})
+}
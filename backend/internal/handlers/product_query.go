@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultProductLimit = 20
+	maxProductLimit     = 100
+)
+
+// Kunci urutan yang didukung GET /products?sort=
+const (
+	SortNewest      = "newest"
+	SortPriceAsc    = "price_asc"
+	SortPriceDesc   = "price_desc"
+	SortBestSelling = "best_selling"
+	SortNameAsc     = "name_asc"
+	SortTopRated    = "top_rated"
+)
+
+// soldExpr = jumlah unit terjual (order yang udah dibayar, dikurangi yang di-refund).
+const soldExpr = "COALESCE(sales.sold, 0)"
+
+var errInvalidCursor = errors.New("cursor tidak valid")
+
+// soldStatuses = status order yang dihitung sebagai penjualan.
+var soldStatuses = []models.OrderStatus{
+	models.OrderStatusPaid, models.OrderStatusProcessing, models.OrderStatusShipped,
+	models.OrderStatusDelivered, models.OrderStatusCompleted,
+}
+
+type ProductQuery struct {
+	Search   string
+	Category string
+	Seller   string
+	MinPrice *float64
+	MaxPrice *float64
+	InStock  bool
+	Sort     string
+	Limit    int
+	Cursor   *productCursor
+}
+
+// productCursor = posisi terakhir halaman sebelumnya (keyset), dikirim ke client dalam bentuk base64.
+type productCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+type ProductListResponse struct {
+	Data       []ProductResponse `json:"data"`
+	Total      int64             `json:"total"`
+	Limit      int               `json:"limit"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	HasMore    bool              `json:"has_more"`
+}
+
+func encodeProductCursor(c productCursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeProductCursor(s string) (*productCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var c productCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == 0 {
+		return nil, errInvalidCursor
+	}
+	return &c, nil
+}
+
+// parseProductQuery baca query string GET /products, balikin pesan error kalau ada yang gak valid.
+func parseProductQuery(c *fiber.Ctx) (*ProductQuery, string) {
+	q := &ProductQuery{
+		Search:   strings.TrimSpace(c.Query("q")),
+		Category: strings.TrimSpace(c.Query("category")),
+		Seller:   strings.TrimSpace(c.Query("seller")),
+		InStock:  c.QueryBool("in_stock", false),
+		Sort:     c.Query("sort", SortNewest),
+		Limit:    c.QueryInt("limit", defaultProductLimit),
+	}
+
+	switch q.Sort {
+	case SortNewest, SortPriceAsc, SortPriceDesc, SortBestSelling, SortNameAsc, SortTopRated:
+	default:
+		return nil, "sort harus salah satu dari newest, price_asc, price_desc, best_selling, name_asc, top_rated"
+	}
+
+	if q.Limit <= 0 || q.Limit > maxProductLimit {
+		q.Limit = defaultProductLimit
+	}
+
+	for _, p := range []struct {
+		key string
+		dst **float64
+	}{{"min_price", &q.MinPrice}, {"max_price", &q.MaxPrice}} {
+		raw := c.Query(p.key)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 {
+			return nil, p.key + " tidak valid"
+		}
+		*p.dst = &v
+	}
+	if q.MinPrice != nil && q.MaxPrice != nil && *q.MinPrice > *q.MaxPrice {
+		return nil, "min_price tidak boleh lebih besar dari max_price"
+	}
+
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := decodeProductCursor(raw)
+		if err != nil || cursor.Sort != q.Sort {
+			return nil, "cursor tidak valid (atau dipake buat sort yang beda)"
+		}
+		q.Cursor = cursor
+	}
+
+	return q, ""
+}
+
+// prefixTSQuery ngubah input pencarian jadi tsquery prefix ("lapt gam" -> "lapt:* & gam:*"),
+// biar hasil muncul walau user belum selesai ngetik. Karakter selain huruf/angka dibuang.
+func prefixTSQuery(search string) string {
+	words := strings.FieldsFunc(search, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, strings.ToLower(w)+":*")
+	}
+	return strings.Join(terms, " & ")
+}
+
+// soldSubquery = total unit terjual per produk.
+func soldSubquery(db *gorm.DB) *gorm.DB {
+	return db.Table("order_items").
+		Select("order_items.product_id, SUM(order_items.quantity - order_items.refunded_quantity) AS sold").
+		Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL").
+		Where("order_items.deleted_at IS NULL AND orders.status IN ?", soldStatuses).
+		Group("order_items.product_id")
+}
+
+// filter nerapin semua filter (tanpa cursor & urutan), dipake buat query data sekaligus hitung total.
+func (q *ProductQuery) filter(db *gorm.DB) *gorm.DB {
+	query := db.Model(&models.Product{})
+
+	if q.Search != "" {
+		if tsq := prefixTSQuery(q.Search); tsq != "" {
+			query = query.Where(models.ProductSearchExpr+" @@ to_tsquery('simple', ?)", tsq)
+		}
+	}
+	if q.Category != "" {
+		query = query.Where("products.category_id IN (?)",
+			db.Model(&models.Category{}).Select("id").Where("slug = ?", q.Category))
+	}
+	if q.Seller != "" {
+		if id, err := strconv.Atoi(q.Seller); err == nil {
+			query = query.Where("products.seller_id = ?", id)
+		} else {
+			query = query.Where("products.seller_id IN (?)",
+				db.Model(&models.User{}).Select("id").Where("username = ?", q.Seller))
+		}
+	}
+	if q.MinPrice != nil {
+		query = query.Where("products.price >= ?", *q.MinPrice)
+	}
+	if q.MaxPrice != nil {
+		query = query.Where("products.price <= ?", *q.MaxPrice)
+	}
+	if q.InStock {
+		query = query.Where("products.stock - products.reserved > 0")
+	}
+	return query
+}
+
+// page nambahin urutan + posisi cursor. Semua urutan pake products.id sebagai pemecah seri
+// biar keyset-nya stabil walau harga/jumlah terjualnya sama.
+func (q *ProductQuery) page(db *gorm.DB, query *gorm.DB) (*gorm.DB, error) {
+	var column, dir string
+	var value interface{}
+
+	switch q.Sort {
+	case SortPriceAsc, SortPriceDesc:
+		column, dir = "products.price", "ASC"
+		if q.Sort == SortPriceDesc {
+			dir = "DESC"
+		}
+		if q.Cursor != nil {
+			v, err := strconv.ParseFloat(q.Cursor.Value, 64)
+			if err != nil {
+				return nil, errInvalidCursor
+			}
+			value = v
+		}
+	case SortBestSelling:
+		query = query.Joins("LEFT JOIN (?) AS sales ON sales.product_id = products.id", soldSubquery(db))
+		column, dir = soldExpr, "DESC"
+		if q.Cursor != nil {
+			v, err := strconv.ParseInt(q.Cursor.Value, 10, 64)
+			if err != nil {
+				return nil, errInvalidCursor
+			}
+			value = v
+		}
+	case SortNameAsc:
+		column, dir = "products.name", "ASC"
+		if q.Cursor != nil {
+			value = q.Cursor.Value
+		}
+	case SortTopRated:
+		column, dir = "products.rating_average", "DESC"
+		if q.Cursor != nil {
+			v, err := strconv.ParseFloat(q.Cursor.Value, 64)
+			if err != nil {
+				return nil, errInvalidCursor
+			}
+			value = v
+		}
+	default:
+		column, dir = "products.created_at", "DESC"
+		if q.Cursor != nil {
+			v, err := time.Parse(time.RFC3339Nano, q.Cursor.Value)
+			if err != nil {
+				return nil, errInvalidCursor
+			}
+			value = v
+		}
+	}
+
+	if q.Cursor != nil {
+		op := ">"
+		if dir == "DESC" {
+			op = "<"
+		}
+		query = query.Where("("+column+" "+op+" ?) OR ("+column+" = ? AND products.id "+op+" ?)",
+			value, value, q.Cursor.ID)
+	}
+	return query.Order(column + " " + dir).Order("products.id " + dir), nil
+}
+
+// cursorFor bikin cursor dari produk terakhir di halaman ini.
+func (q *ProductQuery) cursorFor(p models.Product, sold int) string {
+	c := productCursor{Sort: q.Sort, ID: p.ID}
+	switch q.Sort {
+	case SortPriceAsc, SortPriceDesc:
+		c.Value = strconv.FormatFloat(p.Price, 'f', -1, 64)
+	case SortBestSelling:
+		c.Value = strconv.Itoa(sold)
+	case SortNameAsc:
+		c.Value = p.Name
+	case SortTopRated:
+		c.Value = strconv.FormatFloat(p.RatingAverage, 'f', -1, 64)
+	default:
+		c.Value = p.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return encodeProductCursor(c)
+}
+
+// soldCounts ngambil jumlah terjual buat produk-produk di satu halaman.
+func soldCounts(db *gorm.DB, productIDs []uint) (map[uint]int, error) {
+	counts := make(map[uint]int, len(productIDs))
+	if len(productIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		ProductID uint
+		Sold      int
+	}
+	err := db.Table("(?) AS sales", soldSubquery(db)).
+		Where("sales.product_id IN ?", productIDs).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		counts[r.ProductID] = r.Sold
+	}
+	return counts, nil
+}
+
+// listProducts ngejalanin ProductQuery: hitung total, ambil satu halaman + jumlah terjualnya,
+// terus bikin cursor halaman berikutnya. Dipake GET /products dan katalog toko.
+func listProducts(db *gorm.DB, q *ProductQuery) (*ProductListResponse, error) {
+	var total int64
+	if err := q.filter(db).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	query, err := q.page(db, q.filter(db))
+	if err != nil {
+		return nil, err
+	}
+
+	// Ambil satu lebih buat tau masih ada halaman berikutnya atau nggak
+	var products []models.Product
+	if err := query.Select("products.*").Preload("Seller").Preload("Category").Preload("Images", models.OrderedImages).Limit(q.Limit + 1).Find(&products).Error; err != nil {
+		return nil, err
+	}
+
+	hasMore := len(products) > q.Limit
+	if hasMore {
+		products = products[:q.Limit]
+	}
+
+	ids := make([]uint, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	sold, err := soldCounts(db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ProductListResponse{Data: make([]ProductResponse, 0, len(products)), Total: total, Limit: q.Limit, HasMore: hasMore}
+	for _, p := range products {
+		item := toProductResponse(p)
+		item.SoldCount = sold[p.ID]
+		response.Data = append(response.Data, item)
+	}
+	if hasMore {
+		last := products[len(products)-1]
+		response.NextCursor = q.cursorFor(last, sold[last.ID])
+	}
+	return response, nil
+}
+
+func toProductResponse(p models.Product) ProductResponse {
+	response := ProductResponse{
+		ID:             p.ID,
+		Name:           p.Name,
+		Slug:           p.Slug,
+		Description:    p.Description,
+		Price:          p.Price,
+		Stock:          p.Stock,
+		AvailableStock: p.Available(),
+		ReservedStock:  p.Reserved,
+		ImageURL:       p.ImageURL,
+		ThumbnailURL:   imageVariantURL(p.ImageURL, "thumbnail"),
+		RatingAverage:  p.RatingAverage,
+		RatingCount:    p.RatingCount,
+		Images:         toProductImageResponses(p.Images),
+		Category: CategoryResponse{
+			ID:   p.Category.ID,
+			Name: p.Category.Name,
+			Slug: p.Category.Slug,
+		},
+	}
+	if p.Seller != nil {
+		response.Seller = UserResponse{
+			ID:       p.Seller.ID,
+			Username: p.Seller.Username,
+			Email:    p.Seller.Email,
+		}
+	}
+	return response
+}
@@ -0,0 +1,580 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	maxReviewPhotos    = 5
+	maxReviewComment   = 2000
+	defaultReviewLimit = 10
+	maxReviewLimit     = 50
+)
+
+var (
+	errNotReviewable   = errors.New("produk ini cuma bisa diulas dari pesanan kamu yang udah selesai")
+	errAlreadyReviewed = errors.New("pesanan ini udah kamu ulas")
+)
+
+type ReviewInput struct {
+	OrderItemID uint     `json:"order_item_id"` // Cuma dibaca waktu bikin ulasan
+	Rating      int      `json:"rating"`
+	Comment     string   `json:"comment"`
+	Photos      []string `json:"photos"`
+}
+
+type ReviewReplyInput struct {
+	Reply string `json:"reply"`
+}
+
+type ReviewVisibilityInput struct {
+	Hidden bool   `json:"hidden"`
+	Reason string `json:"reason"`
+}
+
+type ReviewerResponse struct {
+	ID              uint   `json:"id"`
+	Username        string `json:"username"`
+	ProfileImageURL string `json:"profile_image_url,omitempty"`
+}
+
+type ReviewPhotoResponse struct {
+	ID           uint   `json:"id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	MediumURL    string `json:"medium_url"`
+}
+
+type ReviewResponse struct {
+	ID              uint                  `json:"id"`
+	ProductID       uint                  `json:"product_id"`
+	OrderItemID     uint                  `json:"order_item_id"`
+	Rating          int                   `json:"rating"`
+	Comment         string                `json:"comment"`
+	VariantName     string                `json:"variant_name,omitempty"`
+	Reviewer        ReviewerResponse      `json:"reviewer"`
+	Photos          []ReviewPhotoResponse `json:"photos"`
+	SellerReply     string                `json:"seller_reply,omitempty"`
+	SellerRepliedAt *time.Time            `json:"seller_replied_at,omitempty"`
+	IsHidden        bool                  `json:"is_hidden"`
+	HiddenReason    string                `json:"hidden_reason,omitempty"`
+	CreatedAt       time.Time             `json:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at"`
+}
+
+// ReviewSummary = ringkasan rating produk, Distribution[bintang] = jumlah ulasan.
+type ReviewSummary struct {
+	Average      float64     `json:"average"`
+	Count        int         `json:"count"`
+	Distribution map[int]int `json:"distribution"`
+}
+
+type ReviewListResponse struct {
+	Data       []ReviewResponse `json:"data"`
+	Summary    *ReviewSummary   `json:"summary,omitempty"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+	HasMore    bool             `json:"has_more"`
+}
+
+// ReviewableItemResponse = baris pesanan selesai yang belum diulas.
+type ReviewableItemResponse struct {
+	OrderItemID  uint      `json:"order_item_id"`
+	OrderID      uint      `json:"order_id"`
+	ProductID    uint      `json:"product_id"`
+	ProductName  string    `json:"product_name"`
+	ProductSlug  string    `json:"product_slug"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	VariantName  string    `json:"variant_name,omitempty"`
+	PurchasedAt  time.Time `json:"purchased_at"`
+}
+
+func toReviewResponse(r *models.Review) ReviewResponse {
+	response := ReviewResponse{
+		ID:              r.ID,
+		ProductID:       r.ProductID,
+		OrderItemID:     r.OrderItemID,
+		Rating:          r.Rating,
+		Comment:         r.Comment,
+		Photos:          make([]ReviewPhotoResponse, 0, len(r.Photos)),
+		SellerReply:     r.SellerReply,
+		SellerRepliedAt: r.SellerRepliedAt,
+		IsHidden:        r.IsHidden,
+		HiddenReason:    r.HiddenReason,
+		CreatedAt:       r.CreatedAt,
+		UpdatedAt:       r.UpdatedAt,
+	}
+	if r.User != nil {
+		response.Reviewer = ReviewerResponse{ID: r.User.ID, Username: r.User.Username, ProfileImageURL: r.User.ProfileImageURL}
+	}
+	if r.OrderItem != nil {
+		response.VariantName = r.OrderItem.VariantName
+	}
+	for _, p := range r.Photos {
+		response.Photos = append(response.Photos, ReviewPhotoResponse{
+			ID:           p.ID,
+			URL:          p.URL,
+			ThumbnailURL: imageVariantURL(p.URL, "thumbnail"),
+			MediumURL:    imageVariantURL(p.URL, "medium"),
+		})
+	}
+	return response
+}
+
+// reviewableItems = baris pesanan user yang boleh diulas: sub-order (atau order lama tanpa
+// sub-order) udah completed dan gak di-refund semua.
+func reviewableItems(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Model(&models.OrderItem{}).
+		Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL").
+		Joins("LEFT JOIN seller_orders ON seller_orders.id = order_items.seller_order_id AND seller_orders.deleted_at IS NULL").
+		Where("orders.user_id = ?", userID).
+		Where("(seller_orders.status = ? OR (seller_orders.id IS NULL AND orders.status = ?))", models.OrderStatusCompleted, models.OrderStatusCompleted).
+		Where("order_items.quantity > order_items.refunded_quantity")
+}
+
+func validateReviewInput(input *ReviewInput) error {
+	input.Comment = strings.TrimSpace(input.Comment)
+	if input.Rating < 1 || input.Rating > 5 {
+		return errors.New("rating harus 1 sampai 5")
+	}
+	if len(input.Comment) > maxReviewComment {
+		return fmt.Errorf("ulasan kepanjangan (maks %d karakter)", maxReviewComment)
+	}
+	if len(input.Photos) > maxReviewPhotos {
+		return fmt.Errorf("maksimal %d foto per ulasan", maxReviewPhotos)
+	}
+	for i := range input.Photos {
+		input.Photos[i] = strings.TrimSpace(input.Photos[i])
+		if err := validateImageURL(input.Photos[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func buildReviewPhotos(urls []string) []models.ReviewPhoto {
+	photos := make([]models.ReviewPhoto, 0, len(urls))
+	for i, u := range urls {
+		photos = append(photos, models.ReviewPhoto{URL: u, Position: i})
+	}
+	return photos
+}
+
+func (h *ProductHandler) loadReview(id uint) (*models.Review, error) {
+	var review models.Review
+	err := h.DB.Preload("User").Preload("OrderItem").Preload("Photos", models.OrderedReviewPhotos).
+		First(&review, id).Error
+	return &review, err
+}
+
+// findProductReview ngambil ulasan :reviewId yang emang punya produk :id.
+func (h *ProductHandler) findProductReview(c *fiber.Ctx) (*models.Review, error) {
+	reviewID, err := strconv.ParseUint(c.Params("reviewId"), 10, 64)
+	if err != nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	review, err := h.loadReview(uint(reviewID))
+	if err != nil {
+		return nil, err
+	}
+	if strconv.FormatUint(uint64(review.ProductID), 10) != c.Params("id") {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return review, nil
+}
+
+func (h *ProductHandler) notifyReview(userID uint, title, message string, productID uint) {
+	if err := h.NotifService.CreateAndSend(userID, models.NotificationTypeReview, title, message, productID); err != nil {
+		log.Printf("[REVIEW] WARNING: Gagal kirim notifikasi ulasan produk %d ke user %d: %v", productID, userID, err)
+	}
+}
+
+// GET /products/:id/reviews?rating=&with_photos=&limit=&cursor=
+// Ulasan yang disembunyiin admin gak ikut tampil. Urut terbaru, cursor = ID ulasan terakhir.
+func (h *ProductHandler) GetReviews(c *fiber.Ctx) error {
+	var product models.Product
+	if err := h.DB.First(&product, c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produk tidak ditemukan"})
+	}
+
+	limit := c.QueryInt("limit", defaultReviewLimit)
+	if limit <= 0 || limit > maxReviewLimit {
+		limit = defaultReviewLimit
+	}
+
+	query := h.DB.Where("product_id = ? AND is_hidden = ?", product.ID, false)
+	if rating := c.QueryInt("rating", 0); rating != 0 {
+		if rating < 1 || rating > 5 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "rating harus 1 sampai 5"})
+		}
+		query = query.Where("rating = ?", rating)
+	}
+	if c.QueryBool("with_photos", false) {
+		query = query.Where("EXISTS (SELECT 1 FROM review_photos WHERE review_photos.review_id = reviews.id)")
+	}
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": errInvalidCursor.Error()})
+		}
+		query = query.Where("id < ?", cursor)
+	}
+
+	var reviews []models.Review
+	err := query.Preload("User").Preload("OrderItem").Preload("Photos", models.OrderedReviewPhotos).
+		Order("id desc").Limit(limit + 1).Find(&reviews).Error
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil ulasan"})
+	}
+
+	response := ReviewListResponse{Data: make([]ReviewResponse, 0, len(reviews))}
+	if len(reviews) > limit {
+		reviews = reviews[:limit]
+		response.HasMore = true
+		response.NextCursor = strconv.FormatUint(uint64(reviews[limit-1].ID), 10)
+	}
+	for i := range reviews {
+		response.Data = append(response.Data, toReviewResponse(&reviews[i]))
+	}
+
+	// Ringkasan cuma di halaman pertama
+	if c.Query("cursor") == "" {
+		summary := &ReviewSummary{Average: product.RatingAverage, Count: product.RatingCount, Distribution: map[int]int{1: 0, 2: 0, 3: 0, 4: 0, 5: 0}}
+		var rows []struct {
+			Rating int
+			Count  int
+		}
+		h.DB.Model(&models.Review{}).Select("rating, COUNT(*) AS count").
+			Where("product_id = ? AND is_hidden = ?", product.ID, false).
+			Group("rating").Scan(&rows)
+		for _, row := range rows {
+			summary.Distribution[row.Rating] = row.Count
+		}
+		response.Summary = summary
+	}
+
+	return c.JSON(response)
+}
+
+// POST /products/:id/reviews
+func (h *ProductHandler) CreateReview(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	input := new(ReviewInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	if err := validateReviewInput(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.checkOwnedImages(c, input.Photos...); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var product models.Product
+	if err := h.DB.First(&product, c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produk tidak ditemukan"})
+	}
+
+	var item models.OrderItem
+	err := reviewableItems(h.DB, userID).
+		Where("order_items.id = ? AND order_items.product_id = ?", input.OrderItemID, product.ID).
+		First(&item).Error
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": errNotReviewable.Error()})
+	}
+
+	review := models.Review{
+		ProductID:   product.ID,
+		UserID:      userID,
+		OrderItemID: item.ID,
+		Rating:      input.Rating,
+		Comment:     input.Comment,
+		Photos:      buildReviewPhotos(input.Photos),
+	}
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.Review{}).Where("order_item_id = ?", item.ID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errAlreadyReviewed
+		}
+		if err := tx.Create(&review).Error; err != nil {
+			return err
+		}
+		return models.SyncProductRating(tx, product.ID)
+	})
+	if err != nil {
+		if errors.Is(err, errAlreadyReviewed) || strings.Contains(err.Error(), "idx_reviews_order_item_id") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": errAlreadyReviewed.Error()})
+		}
+		log.Printf("[REVIEW] ERROR: Gagal nyimpen ulasan produk %d dari user %d: %v", product.ID, userID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menyimpan ulasan"})
+	}
+
+	saved, err := h.loadReview(review.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil ulasan"})
+	}
+	h.notifyReview(product.SellerID, "Ulasan baru ⭐",
+		fmt.Sprintf("%s ngasih bintang %d buat %s.", saved.User.Username, review.Rating, product.Name), product.ID)
+
+	return c.Status(fiber.StatusCreated).JSON(toReviewResponse(saved))
+}
+
+// PUT /products/:id/reviews/:reviewId
+// Pengulas boleh ngubah rating, komentar, dan fotonya sendiri.
+func (h *ProductHandler) UpdateReview(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	review, err := h.findProductReview(c)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Ulasan tidak ditemukan"})
+	}
+	if review.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Kamu cuma bisa ngubah ulasan sendiri"})
+	}
+
+	input := new(ReviewInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	if err := validateReviewInput(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	existing := make(map[string]bool, len(review.Photos))
+	for _, p := range review.Photos {
+		existing[p.URL] = true
+	}
+	kept := make(map[string]bool, len(input.Photos))
+	for _, u := range input.Photos {
+		// Foto lama boleh dipertahanin, foto baru harus upload sendiri
+		if !existing[u] {
+			if err := h.checkOwnedImages(c, u); err != nil {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+			}
+		}
+		kept[u] = true
+	}
+	var removed []string
+	for _, p := range review.Photos {
+		if !kept[p.URL] {
+			removed = append(removed, p.URL)
+		}
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Review{}).Where("id = ?", review.ID).Updates(map[string]interface{}{
+			"rating":  input.Rating,
+			"comment": input.Comment,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("review_id = ?", review.ID).Delete(&models.ReviewPhoto{}).Error; err != nil {
+			return err
+		}
+		if photos := buildReviewPhotos(input.Photos); len(photos) > 0 {
+			for i := range photos {
+				photos[i].ReviewID = review.ID
+			}
+			if err := tx.Create(&photos).Error; err != nil {
+				return err
+			}
+		}
+		return models.SyncProductRating(tx, review.ProductID)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengupdate ulasan"})
+	}
+	h.Media.Release(removed...)
+
+	saved, err := h.loadReview(review.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil ulasan"})
+	}
+	return c.JSON(toReviewResponse(saved))
+}
+
+// POST /products/:id/reviews/:reviewId/reply
+// Seller pemilik produk (atau admin) cuma boleh bales sekali, balasannya publik.
+func (h *ProductHandler) ReplyReview(c *fiber.Ctx) error {
+	product, err := h.findOwnedProduct(c)
+	if err != nil {
+		return productAccessError(c, err)
+	}
+	review, err := h.findProductReview(c)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Ulasan tidak ditemukan"})
+	}
+
+	input := new(ReviewReplyInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	input.Reply = strings.TrimSpace(input.Reply)
+	if input.Reply == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Balasan tidak boleh kosong"})
+	}
+	if len(input.Reply) > maxReviewComment {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fmt.Sprintf("balasan kepanjangan (maks %d karakter)", maxReviewComment)})
+	}
+
+	// Dicek di WHERE biar dua balasan barengan gak saling nimpa
+	now := time.Now()
+	result := h.DB.Model(&models.Review{}).
+		Where("id = ? AND (seller_reply IS NULL OR seller_reply = '')", review.ID).
+		Updates(map[string]interface{}{"seller_reply": input.Reply, "seller_replied_at": now})
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menyimpan balasan"})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Ulasan ini udah dibales"})
+	}
+
+	review.SellerReply = input.Reply
+	review.SellerRepliedAt = &now
+	h.notifyReview(review.UserID, "Ulasan kamu dibales",
+		fmt.Sprintf("Seller bales ulasan kamu buat %s.", product.Name), product.ID)
+
+	return c.JSON(toReviewResponse(review))
+}
+
+// GET /me/reviewable-items
+// Barang dari pesanan selesai yang belum diulas.
+func (h *ProductHandler) GetReviewableItems(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var items []models.OrderItem
+	err := reviewableItems(h.DB, userID).
+		Where("NOT EXISTS (SELECT 1 FROM reviews WHERE reviews.order_item_id = order_items.id)").
+		Preload("Product").Preload("Order").
+		Order("order_items.id desc").
+		Find(&items).Error
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil pesanan yang bisa diulas"})
+	}
+
+	response := make([]ReviewableItemResponse, 0, len(items))
+	for _, item := range items {
+		if item.Product == nil || item.Order == nil {
+			continue
+		}
+		response = append(response, ReviewableItemResponse{
+			OrderItemID:  item.ID,
+			OrderID:      item.OrderID,
+			ProductID:    item.ProductID,
+			ProductName:  item.Product.Name,
+			ProductSlug:  item.Product.Slug,
+			ThumbnailURL: imageVariantURL(item.Product.ImageURL, "thumbnail"),
+			VariantName:  item.VariantName,
+			PurchasedAt:  item.Order.CreatedAt,
+		})
+	}
+	return c.JSON(response)
+}
+
+// GET /admin/reviews?hidden=&rating=&limit=&cursor=
+// Semua ulasan termasuk yang disembunyiin, buat moderasi.
+func (h *ProductHandler) AdminListReviews(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", defaultReviewLimit)
+	if limit <= 0 || limit > maxReviewLimit {
+		limit = defaultReviewLimit
+	}
+
+	query := h.DB.Model(&models.Review{})
+	switch c.Query("hidden") {
+	case "true":
+		query = query.Where("is_hidden = ?", true)
+	case "false":
+		query = query.Where("is_hidden = ?", false)
+	}
+	if rating := c.QueryInt("rating", 0); rating != 0 {
+		query = query.Where("rating = ?", rating)
+	}
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": errInvalidCursor.Error()})
+		}
+		query = query.Where("id < ?", cursor)
+	}
+
+	var reviews []models.Review
+	err := query.Preload("User").Preload("OrderItem").Preload("Photos", models.OrderedReviewPhotos).
+		Order("id desc").Limit(limit + 1).Find(&reviews).Error
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil ulasan"})
+	}
+
+	response := ReviewListResponse{Data: make([]ReviewResponse, 0, len(reviews))}
+	if len(reviews) > limit {
+		reviews = reviews[:limit]
+		response.HasMore = true
+		response.NextCursor = strconv.FormatUint(uint64(reviews[limit-1].ID), 10)
+	}
+	for i := range reviews {
+		response.Data = append(response.Data, toReviewResponse(&reviews[i]))
+	}
+	return c.JSON(response)
+}
+
+// PATCH /admin/reviews/:id/visibility
+// Nyembunyiin (wajib pake alasan) atau nampilin lagi ulasan. Rating produk ikut dihitung ulang.
+func (h *ProductHandler) SetReviewVisibility(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	reviewID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Ulasan tidak ditemukan"})
+	}
+	review, err := h.loadReview(uint(reviewID))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Ulasan tidak ditemukan"})
+	}
+
+	input := new(ReviewVisibilityInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	if input.Hidden && len(input.Reason) < 5 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Alasan nyembunyiin ulasan wajib diisi (min 5 karakter)"})
+	}
+
+	updates := map[string]interface{}{"is_hidden": false, "hidden_reason": "", "hidden_by_id": nil, "hidden_at": nil}
+	if input.Hidden {
+		updates = map[string]interface{}{"is_hidden": true, "hidden_reason": input.Reason, "hidden_by_id": adminID, "hidden_at": time.Now()}
+	}
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Review{}).Where("id = ?", review.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		return models.SyncProductRating(tx, review.ProductID)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengubah status ulasan"})
+	}
+
+	if input.Hidden && !review.IsHidden {
+		log.Printf("[REVIEW] Ulasan #%d disembunyiin admin %d: %s", review.ID, adminID, input.Reason)
+		h.notifyReview(review.UserID, "Ulasan kamu disembunyiin",
+			fmt.Sprintf("Ulasan kamu disembunyiin admin karena: %s", input.Reason), review.ProductID)
+	}
+
+	saved, err := h.loadReview(review.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil ulasan"})
+	}
+	return c.JSON(toReviewResponse(saved))
+}
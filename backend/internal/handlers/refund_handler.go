@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/orderflow"
+	"github.com/akhdanrgya/telu-hub/internal/refund"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type RefundInput struct {
+	Items   []refund.ItemRequest `json:"items"` // Kosong = semua sisa item
+	Reason  string               `json:"reason"`
+	Restock *bool                `json:"restock"` // Default true: quantity yang di-refund balik ke stok
+}
+
+func refundErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, refund.ErrInvalidItem), errors.Is(err, refund.ErrNothingToRefund):
+		return fiber.StatusBadRequest
+	case errors.Is(err, refund.ErrNotRefundable), errors.Is(err, refund.ErrAlreadyShipped),
+		errors.Is(err, refund.ErrUseOrderCancel), errors.Is(err, refund.ErrNoPaymentRef):
+		return fiber.StatusConflict
+	case errors.Is(err, refund.ErrGatewayRefund):
+		return fiber.StatusBadGateway
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.StatusNotFound
+	default:
+		return orderFlowErrorStatus(err)
+	}
+}
+
+func (in RefundInput) restock() bool {
+	return in.Restock == nil || *in.Restock
+}
+
+// POST /orders/:id/sub-orders/:sid/cancel — buyer batalin pesanan dari satu seller yang udah dibayar tapi belum dikirim
+func (h *OrderHandler) CancelSellerOrder(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(uint)
+	order, err := h.findBuyerOrder(c)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order tidak ditemukan"})
+	}
+
+	var sub models.SellerOrder
+	if err := h.DB.Where("id = ? AND order_id = ?", c.Params("sid"), order.ID).First(&sub).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Sub-order tidak ditemukan"})
+	}
+
+	var input RefundInput
+	c.BodyParser(&input)
+
+	actor := orderflow.Actor{UserID: userID, Role: orderflow.ActorBuyer}
+	result, err := h.Refunds.CancelSellerOrder(&sub, actor, input.Reason)
+	if err != nil {
+		return c.Status(refundErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Pesanan dibatalkan, dana dikembalikan", "refund": result})
+}
+
+// POST /me/sales/:id/refunds — seller refund sebagian/semua item di sub-order-nya
+func (h *OrderHandler) RefundSale(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(uint)
+	role, _ := c.Locals("user_role").(string)
+
+	sub, err := h.findSale(c)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Penjualan tidak ditemukan"})
+	}
+
+	var input RefundInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	actor := orderflow.Actor{UserID: userID, Role: orderflow.ActorSeller}
+	if role == "admin" {
+		actor.Role = orderflow.ActorAdmin
+	}
+
+	result, err := h.Refunds.Refund(refund.Request{
+		OrderID:       sub.OrderID,
+		SellerOrderID: sub.ID,
+		Items:         input.Items,
+		Reason:        input.Reason,
+		Restock:       input.restock(),
+		Actor:         actor,
+	})
+	if err != nil {
+		return c.Status(refundErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Refund berhasil diproses", "refund": result})
+}
+
+// POST /admin/orders/:id/refunds — admin refund item dari seller mana aja
+func (h *OrderHandler) AdminRefundOrder(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(uint)
+
+	var order models.Order
+	if err := h.DB.First(&order, c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order tidak ditemukan"})
+	}
+
+	var input RefundInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	result, err := h.Refunds.Refund(refund.Request{
+		OrderID: order.ID,
+		Items:   input.Items,
+		Reason:  input.Reason,
+		Restock: input.restock(),
+		Actor:   orderflow.Actor{UserID: userID, Role: orderflow.ActorAdmin},
+	})
+	if err != nil {
+		return c.Status(refundErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Refund berhasil diproses", "refund": result})
+}
+
+// GET /orders/:id/refunds — buyer, seller yang produknya ada di order, atau admin
+func (h *OrderHandler) GetOrderRefunds(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(uint)
+	role, _ := c.Locals("user_role").(string)
+
+	var order models.Order
+	if err := h.DB.First(&order, c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order tidak ditemukan"})
+	}
+	if order.UserID != userID && role != "admin" && !h.sellerHasItems(order.ID, userID) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order tidak ditemukan"})
+	}
+
+	refunds, err := h.Refunds.GetOrderRefunds(order.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil data refund"})
+	}
+	return c.Status(fiber.StatusOK).JSON(refunds)
+}
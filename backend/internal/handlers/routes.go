@@ -1,27 +1,46 @@
 package handlers
 
 import (
-	"log"
-
 	"github.com/akhdanrgya/telu-hub/internal/middleware" 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
 	"gorm.io/gorm"
 	"github.com/akhdanrgya/telu-hub/internal/notification"
+	"github.com/akhdanrgya/telu-hub/internal/chat"
+	"github.com/akhdanrgya/telu-hub/internal/reservation"
+	"github.com/akhdanrgya/telu-hub/internal/orderflow"
+	"github.com/akhdanrgya/telu-hub/internal/payment"
+	"github.com/akhdanrgya/telu-hub/internal/refund"
+	"github.com/akhdanrgya/telu-hub/internal/media"
+	"github.com/akhdanrgya/telu-hub/internal/imaging"
+	"github.com/akhdanrgya/telu-hub/internal/session"
+	"github.com/akhdanrgya/telu-hub/internal/account"
+	"github.com/akhdanrgya/telu-hub/internal/stockalert"
+	"github.com/akhdanrgya/telu-hub/internal/voucher"
+	"github.com/akhdanrgya/telu-hub/internal/fulfilment"
+	"github.com/akhdanrgya/telu-hub/internal/guestcart"
 
 	grpc_service "github.com/akhdanrgya/telu-hub/internal/grpc_service"
 )
 
-func SetupRoutes(app *fiber.App, db *gorm.DB, stockService *grpc_service.StockService, notifService *notification.Service) {
+func SetupRoutes(app *fiber.App, db *gorm.DB, stockService *grpc_service.StockService, notifService *notification.Service, chatService *chat.Service, reservationService *reservation.Service, stockAlertService *stockalert.Service, voucherService *voucher.Service, fulfilmentService *fulfilment.Service, guestCartService *guestcart.Service, orderFlow *orderflow.Service, paymentProcessor *payment.Processor, reconciler *payment.Reconciler, refundService *refund.Service, mediaService *media.Service, uploadLimits imaging.Limits, sessionService *session.Service, accountService *account.Service) {
 
-	authHandler := NewAuthHandler(db)
-	productHandler := NewProductHandler(db)
+	authHandler := NewAuthHandler(db, sessionService, accountService, guestCartService)
+	productHandler := NewProductHandler(db, mediaService, notifService, stockAlertService)
 	cartHandler := NewCartHandler(db)
-	uploadHandler := NewUploadHandler()
-	userHandler := NewUserHandler(db)
-	orderHandler := NewOrderHandler(db, stockService, notifService)
+	guestCartHandler := NewGuestCartHandler(db, guestCartService)
+	uploadHandler := NewUploadHandler(db, mediaService, uploadLimits)
+	userHandler := NewUserHandler(db, mediaService)
+	orderHandler := NewOrderHandler(db, stockService, notifService, reservationService, orderFlow, paymentProcessor.Gateway, refundService, voucherService, fulfilmentService)
+	paymentHandler := payment.NewHandler(paymentProcessor, reconciler)
 	notifHandler := notification.NewHandler(notifService)
 	categoryHandler := NewCategoryHandler(db)
+	chatHandler := chat.NewHandler(chatService)
+	sellerApplicationHandler := NewSellerApplicationHandler(db, notifService, mediaService, sessionService)
+	storeHandler := NewStoreHandler(db, mediaService)
+	wishlistHandler := NewWishlistHandler(db, stockAlertService)
+	voucherHandler := NewVoucherHandler(db, voucherService)
+	fulfilmentHandler := NewFulfilmentHandler(db, fulfilmentService)
 
 
 	api := app.Group("/api/v1")
@@ -33,17 +52,71 @@ func SetupRoutes(app *fiber.App, db *gorm.DB, stockService *grpc_service.StockSe
 	auth := api.Group("/auth")
 		auth.Post("/register", authHandler.Register)
 		auth.Post("/login", authHandler.Login)
+		auth.Post("/refresh", authHandler.Refresh)
 		auth.Post("/logout", authHandler.Logout)
+		auth.Post("/verify-email", authHandler.VerifyEmail)
+		auth.Post("/verify-email/resend", authHandler.ResendVerification)
+		auth.Post("/password/forgot", authHandler.ForgotPassword)
+		auth.Post("/password/reset", authHandler.ResetPassword)
 
 	me := api.Group("/me", middleware.Protected())
 		me.Get("/", authHandler.GetUserData)
 		me.Get("/products", productHandler.GetMyProducts)
 		me.Get("/", authHandler.GetUserData)
 		me.Put("/", userHandler.UpdateUserProfile)
+		me.Put("/password", authHandler.ChangePassword)
+		me.Get("/sessions", authHandler.GetMySessions)
+		me.Delete("/sessions", authHandler.RevokeOtherSessions)
+		me.Delete("/sessions/:id", authHandler.RevokeMySession)
+		me.Get("/seller-application", sellerApplicationHandler.GetMine)
+		me.Post("/seller-application", sellerApplicationHandler.Submit)
+		me.Post("/seller-application/documents", uploadHandler.UploadDocument)
+		me.Get("/store", middleware.RoleRequired("seller", "admin"), storeHandler.GetMyStore)
+		me.Put("/store", middleware.RoleRequired("seller", "admin"), storeHandler.UpdateMyStore)
+		me.Get("/reviewable-items", productHandler.GetReviewableItems)
+		me.Get("/wishlist", wishlistHandler.GetWishlist)
+		me.Post("/wishlist", wishlistHandler.AddToWishlist)
+		me.Delete("/wishlist/:productId", wishlistHandler.RemoveFromWishlist)
+		me.Get("/stock-alerts", wishlistHandler.GetStockAlerts)
+		me.Get("/vouchers", middleware.RoleRequired("seller", "admin"), voucherHandler.GetMyVouchers)
+		me.Post("/vouchers", middleware.RoleRequired("seller", "admin"), voucherHandler.CreateMyVoucher)
+		me.Put("/vouchers/:id", middleware.RoleRequired("seller", "admin"), voucherHandler.UpdateMyVoucher)
+		me.Get("/addresses", fulfilmentHandler.GetMyAddresses)
+		me.Post("/addresses", fulfilmentHandler.CreateMyAddress)
+		me.Put("/addresses/:id", fulfilmentHandler.UpdateMyAddress)
+		me.Delete("/addresses/:id", fulfilmentHandler.DeleteMyAddress)
+		me.Get("/fulfilment-methods", middleware.RoleRequired("seller", "admin"), fulfilmentHandler.GetMyFulfilmentMethods)
+		me.Post("/fulfilment-methods", middleware.RoleRequired("seller", "admin"), fulfilmentHandler.CreateMyFulfilmentMethod)
+		me.Put("/fulfilment-methods/:id", middleware.RoleRequired("seller", "admin"), fulfilmentHandler.UpdateMyFulfilmentMethod)
+		me.Delete("/fulfilment-methods/:id", middleware.RoleRequired("seller", "admin"), fulfilmentHandler.DeleteMyFulfilmentMethod)
+		me.Get("/uploads", uploadHandler.GetMyUploads)
+		me.Delete("/uploads/:id", uploadHandler.DeleteMyUpload)
+		me.Get("/sales", middleware.RoleRequired("seller", "admin"), orderHandler.GetMySales)
+		me.Get("/sales/:id", middleware.RoleRequired("seller", "admin"), orderHandler.GetMySaleByID)
+		me.Patch("/sales/:id/status", middleware.RoleRequired("seller", "admin"), orderHandler.UpdateMySaleStatus)
+		me.Put("/sales/:id/fulfilment", middleware.RoleRequired("seller", "admin"), orderHandler.UpdateMySaleFulfilment)
+		me.Post("/sales/:id/handover", middleware.RoleRequired("seller", "admin"), orderHandler.ConfirmMySaleHandover)
+		me.Post("/sales/:id/refunds", middleware.RoleRequired("seller", "admin"), orderHandler.RefundSale)
 
 	admin := api.Group("/admin", middleware.Protected(), middleware.RoleRequired("admin"))
 		admin.Post("/promote/:id", authHandler.PromoteUser)
 		admin.Get("/users", authHandler.GetAllUsers)
+		admin.Put("/users/:id/upload-quota", uploadHandler.SetUserQuota)
+		admin.Get("/seller-applications", sellerApplicationHandler.List)
+		admin.Get("/seller-applications/:id", sellerApplicationHandler.Get)
+		admin.Post("/seller-applications/:id/approve", sellerApplicationHandler.Approve)
+		admin.Post("/seller-applications/:id/reject", sellerApplicationHandler.Reject)
+		admin.Get("/reviews", productHandler.AdminListReviews)
+		admin.Patch("/reviews/:id/visibility", productHandler.SetReviewVisibility)
+		admin.Get("/vouchers", voucherHandler.AdminListVouchers)
+		admin.Post("/vouchers", voucherHandler.AdminCreateVoucher)
+		admin.Put("/vouchers/:id", voucherHandler.AdminUpdateVoucher)
+		admin.Patch("/orders/:id/status", orderHandler.AdminUpdateStatus)
+		admin.Post("/orders/:id/refunds", orderHandler.AdminRefundOrder)
+		admin.Get("/payment-events", paymentHandler.ListEvents)
+		admin.Get("/payment-events/:id", paymentHandler.GetEvent)
+		admin.Post("/payment-events/:id/replay", paymentHandler.ReplayEvent)
+		admin.Post("/payments/reconcile", paymentHandler.Reconcile)
 
 	products := api.Group("/products")
 		products.Get("/", productHandler.GetAllProducts)
@@ -52,6 +125,21 @@ func SetupRoutes(app *fiber.App, db *gorm.DB, stockService *grpc_service.StockSe
 		products.Post("/", middleware.Protected(), middleware.RoleRequired("seller", "admin"), productHandler.CreateProduct)
 		products.Put("/:id", middleware.Protected(), middleware.RoleRequired("seller", "admin"), productHandler.UpdateProduct)
 		products.Delete("/:id", middleware.Protected(), middleware.RoleRequired("seller", "admin"), productHandler.DeleteProduct)
+		products.Get("/:id/variants", productHandler.GetVariants)
+		products.Post("/:id/variants", middleware.Protected(), middleware.RoleRequired("seller", "admin"), productHandler.CreateVariant)
+		products.Put("/:id/variants/:variantId", middleware.Protected(), middleware.RoleRequired("seller", "admin"), productHandler.UpdateVariant)
+		products.Delete("/:id/variants/:variantId", middleware.Protected(), middleware.RoleRequired("seller", "admin"), productHandler.DeleteVariant)
+		products.Get("/:id/images", productHandler.GetImages)
+		products.Post("/:id/images", middleware.Protected(), middleware.RoleRequired("seller", "admin"), productHandler.AttachImage)
+		products.Put("/:id/images/order", middleware.Protected(), middleware.RoleRequired("seller", "admin"), productHandler.ReorderImages)
+		products.Patch("/:id/images/:imageId", middleware.Protected(), middleware.RoleRequired("seller", "admin"), productHandler.UpdateImage)
+		products.Delete("/:id/images/:imageId", middleware.Protected(), middleware.RoleRequired("seller", "admin"), productHandler.DetachImage)
+		products.Get("/:id/reviews", productHandler.GetReviews)
+		products.Post("/:id/reviews", middleware.Protected(), productHandler.CreateReview)
+		products.Put("/:id/reviews/:reviewId", middleware.Protected(), productHandler.UpdateReview)
+		products.Post("/:id/reviews/:reviewId/reply", middleware.Protected(), middleware.RoleRequired("seller", "admin"), productHandler.ReplyReview)
+		products.Post("/:id/stock-alert", middleware.Protected(), wishlistHandler.SubscribeStockAlert)
+		products.Delete("/:id/stock-alert", middleware.Protected(), wishlistHandler.UnsubscribeStockAlert)
 	
 	api.Get("/categories", categoryHandler.GetAllCategories)
 		
@@ -61,25 +149,58 @@ func SetupRoutes(app *fiber.App, db *gorm.DB, stockService *grpc_service.StockSe
 		cart.Post("/items", cartHandler.AddItemToCart)
 		cart.Put("/items/:id", cartHandler.UpdateCartItem)
 		cart.Delete("/items/:id", cartHandler.RemoveCartItem)
+		cart.Post("/refresh", cartHandler.RefreshCart)
+
+	// Keranjang pengunjung yang belum login, dipegang lewat header X-Cart-Token
+	guestCart := api.Group("/guest-cart")
+		guestCart.Get("/", guestCartHandler.GetGuestCart)
+		guestCart.Post("/items", guestCartHandler.AddGuestCartItem)
+		guestCart.Put("/items/:id", guestCartHandler.UpdateGuestCartItem)
+		guestCart.Delete("/items/:id", guestCartHandler.RemoveGuestCartItem)
 	
-	api.Post("/upload/image", uploadHandler.UploadImage)
+	api.Post("/upload/image", middleware.Protected(), uploadHandler.UploadImage)
 	api.Get("/users/:username", userHandler.GetUserPublicProfile)
 	api.Get("/users/:username/products", userHandler.GetUserProducts)
+	api.Get("/stores/:slug", storeHandler.GetStore)
+	api.Get("/stores/:slug/products", storeHandler.GetStoreProducts)
 
 	checkout := api.Group("/checkout", middleware.Protected())
 	checkout.Post("/", orderHandler.CreateOrderAndPay)
+	checkout.Get("/options", fulfilmentHandler.GetCheckoutOptions)
+	checkout.Post("/preview", orderHandler.PreviewCheckout)
+
+	api.Post("/vouchers/check", middleware.Protected(), voucherHandler.CheckVoucher)
 	
 	webhook := api.Group("/payments")
-	webhook.Post("/webhook", orderHandler.HandleWebhook)
+	webhook.Post("/webhook", paymentHandler.HandleWebhook)
+
+	// Gateway bohongan buat dev/CI: tembak webhook settlement/expire sendiri
+	if fakeGateway, ok := paymentProcessor.Gateway.(*payment.FakeGateway); ok {
+		fakeHandler := payment.NewFakeHandler(fakeGateway)
+		fakePayments := webhook.Group("/fake")
+		fakePayments.Get("/transactions", fakeHandler.ListTransactions)
+		fakePayments.Get("/transactions/:ref", fakeHandler.GetTransaction)
+		fakePayments.Post("/transactions/:ref/:status", fakeHandler.FireWebhook)
+	}
 
 	orders := api.Group("/orders", middleware.Protected())
 		orders.Get("/", orderHandler.GetMyOrders) 
 		orders.Get("/:id", orderHandler.GetOrderByID)
-
-	app.Get("/ws/chat/:channel_id", middleware.Protected(), websocket.New(func(c *websocket.Conn) {
-		log.Println("Koneksi WebSocket baru!")
-        c.WriteJSON(fiber.Map{"message": "Welcome to Chat!"})
-	}))
+		orders.Get("/:id/history", orderHandler.GetOrderHistory)
+		orders.Post("/:id/cancel", orderHandler.CancelOrder)
+		orders.Post("/:id/complete", orderHandler.CompleteOrder)
+		orders.Post("/:id/sub-orders/:sid/complete", orderHandler.CompleteSellerOrder)
+		orders.Post("/:id/sub-orders/:sid/cancel", orderHandler.CancelSellerOrder)
+		orders.Get("/:id/refunds", orderHandler.GetOrderRefunds)
+
+	chatRoutes := api.Group("/chat", middleware.Protected())
+		chatRoutes.Get("/rooms", chatHandler.GetRooms)
+		chatRoutes.Post("/rooms", chatHandler.CreateRoom)
+		chatRoutes.Get("/rooms/:id/messages", chatHandler.GetMessages)
+		chatRoutes.Post("/rooms/:id/messages", chatHandler.SendMessage)
+		chatRoutes.Put("/rooms/:id/read", chatHandler.MarkRead)
+
+	app.Get("/ws/chat/:channel_id", middleware.Protected(), websocket.New(chatHandler.HandleWSConnection))
 
 	notifRoutes := api.Group("/notifications", middleware.Protected())
 	notifRoutes.Get("/", notifHandler.GetNotifications)
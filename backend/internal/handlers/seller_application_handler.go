@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/akhdanrgya/telu-hub/internal/media"
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/notification"
+	"github.com/akhdanrgya/telu-hub/internal/session"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const maxApplicationDocuments = 5
+
+var studentIDPattern = regexp.MustCompile(`^[0-9A-Za-z]{5,30}$`)
+
+var errApplicationReviewed = errors.New("pengajuan ini udah direview")
+
+type SellerApplicationHandler struct {
+	DB           *gorm.DB
+	NotifService *notification.Service
+	Media        *media.Service
+	Sessions     *session.Service
+}
+
+func NewSellerApplicationHandler(db *gorm.DB, notifService *notification.Service, mediaService *media.Service, sessions *session.Service) *SellerApplicationHandler {
+	return &SellerApplicationHandler{DB: db, NotifService: notifService, Media: mediaService, Sessions: sessions}
+}
+
+type ApplicationDocumentInput struct {
+	URL   string `json:"url"`
+	Label string `json:"label"`
+}
+
+type SellerApplicationInput struct {
+	StoreName   string                     `json:"store_name"`
+	Description string                     `json:"description"`
+	StudentID   string                     `json:"student_id"`
+	Documents   []ApplicationDocumentInput `json:"documents"`
+}
+
+type RejectApplicationInput struct {
+	Reason string `json:"reason"`
+}
+
+type SellerApplicationResponse struct {
+	models.SellerApplication
+	Applicant *UserResponse `json:"applicant,omitempty"`
+}
+
+// toApplicationResponse ngganti URL dokumen jadi signed URL, file aslinya private.
+func (h *SellerApplicationHandler) toApplicationResponse(app *models.SellerApplication) SellerApplicationResponse {
+	response := SellerApplicationResponse{SellerApplication: *app}
+	response.Documents = make([]models.SellerApplicationDocument, 0, len(app.Documents))
+	for _, doc := range app.Documents {
+		doc.URL = h.Media.ViewURL(doc.URL)
+		response.Documents = append(response.Documents, doc)
+	}
+	if app.User != nil {
+		response.Applicant = &UserResponse{
+			ID:              app.User.ID,
+			Username:        app.User.Username,
+			Email:           app.User.Email,
+			Role:            app.User.Role,
+			ProfileImageURL: app.User.ProfileImageURL,
+			EmailVerified:   app.User.EmailVerifiedAt != nil,
+		}
+	}
+	return response
+}
+
+// notify ngirim notifikasi soal pengajuan, gagal cuma di-log.
+func (h *SellerApplicationHandler) notify(userID uint, title, message string, applicationID uint) {
+	if err := h.NotifService.CreateAndSend(userID, models.NotificationTypeSeller, title, message, applicationID); err != nil {
+		log.Printf("[SELLER] WARNING: Gagal kirim notifikasi pengajuan #%d ke user %d: %v", applicationID, userID, err)
+	}
+}
+
+func (h *SellerApplicationHandler) findApplication(id string) (*models.SellerApplication, error) {
+	var app models.SellerApplication
+	if err := h.DB.Preload("Documents").Preload("User").First(&app, id).Error; err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+func validateApplication(input *SellerApplicationInput) error {
+	input.StoreName = strings.TrimSpace(input.StoreName)
+	input.Description = strings.TrimSpace(input.Description)
+	input.StudentID = strings.TrimSpace(input.StudentID)
+
+	if len(input.StoreName) < 3 || len(input.StoreName) > 100 {
+		return errors.New("nama toko harus 3-100 karakter")
+	}
+	if !studentIDPattern.MatchString(input.StudentID) {
+		return errors.New("NIM tidak valid")
+	}
+	if len(input.Documents) == 0 {
+		return errors.New("minimal upload 1 dokumen pendukung (misal foto KTM)")
+	}
+	if len(input.Documents) > maxApplicationDocuments {
+		return fmt.Errorf("maksimal %d dokumen pendukung", maxApplicationDocuments)
+	}
+	for i := range input.Documents {
+		input.Documents[i].URL = strings.TrimSpace(input.Documents[i].URL)
+		input.Documents[i].Label = strings.TrimSpace(input.Documents[i].Label)
+		if err := validateImageURL(input.Documents[i].URL); err != nil {
+			return err
+		}
+		if len(input.Documents[i].Label) > 100 {
+			return errors.New("label dokumen kepanjangan (maks 100 karakter)")
+		}
+	}
+	return nil
+}
+
+// POST /me/seller-application
+func (h *SellerApplicationHandler) Submit(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	if role, _ := c.Locals("user_role").(string); role != "user" {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Akun kamu udah bisa jualan"})
+	}
+
+	input := new(SellerApplicationInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	if err := validateApplication(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	// Dokumen harus hasil upload private si pengaju sendiri
+	for _, doc := range input.Documents {
+		if _, err := h.Media.OwnedDocument(doc.URL, userID); err != nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	var pending int64
+	h.DB.Model(&models.SellerApplication{}).Where("user_id = ? AND status = ?", userID, models.ApplicationPending).Count(&pending)
+	if pending > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Kamu masih punya pengajuan yang lagi direview"})
+	}
+
+	app := models.SellerApplication{
+		UserID:      userID,
+		StoreName:   input.StoreName,
+		Description: input.Description,
+		StudentID:   input.StudentID,
+		Status:      models.ApplicationPending,
+	}
+	for _, doc := range input.Documents {
+		app.Documents = append(app.Documents, models.SellerApplicationDocument{URL: doc.URL, Label: doc.Label})
+	}
+	if err := h.DB.Create(&app).Error; err != nil {
+		// Kena unique index pending (dua submit barengan)
+		if strings.Contains(err.Error(), "idx_seller_applications_one_pending") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Kamu masih punya pengajuan yang lagi direview"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menyimpan pengajuan"})
+	}
+
+	log.Printf("[SELLER] Pengajuan seller #%d dari user %d (%s) masuk", app.ID, userID, app.StoreName)
+	h.notify(userID, "Pengajuan seller diterima",
+		fmt.Sprintf("Pengajuan toko \"%s\" udah masuk dan lagi nunggu direview admin.", app.StoreName), app.ID)
+
+	var adminIDs []uint
+	h.DB.Model(&models.User{}).Where("role = ?", "admin").Pluck("id", &adminIDs)
+	for _, adminID := range adminIDs {
+		h.notify(adminID, "Pengajuan seller baru",
+			fmt.Sprintf("Ada pengajuan toko \"%s\" yang nunggu direview.", app.StoreName), app.ID)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(h.toApplicationResponse(&app))
+}
+
+// GET /me/seller-application
+// Pengajuan terakhir user (buat nampilin status / alasan ditolak).
+func (h *SellerApplicationHandler) GetMine(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var app models.SellerApplication
+	if err := h.DB.Preload("Documents").Where("user_id = ?", userID).Order("created_at desc").First(&app).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Kamu belum pernah ngajuin jadi seller"})
+	}
+	return c.JSON(h.toApplicationResponse(&app))
+}
+
+// GET /admin/seller-applications?status=pending|approved|rejected|all
+// Antrian review, yang paling lama nunggu duluan.
+func (h *SellerApplicationHandler) List(c *fiber.Ctx) error {
+	status := c.Query("status", string(models.ApplicationPending))
+
+	query := h.DB.Preload("Documents").Preload("User").Order("created_at asc")
+	switch models.SellerApplicationStatus(status) {
+	case models.ApplicationPending, models.ApplicationApproved, models.ApplicationRejected:
+		query = query.Where("status = ?", status)
+	default:
+		if status != "all" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "status harus pending, approved, rejected, atau all"})
+		}
+	}
+
+	var apps []models.SellerApplication
+	if err := query.Find(&apps).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil daftar pengajuan"})
+	}
+
+	response := make([]SellerApplicationResponse, 0, len(apps))
+	for i := range apps {
+		response = append(response, h.toApplicationResponse(&apps[i]))
+	}
+	return c.JSON(response)
+}
+
+// GET /admin/seller-applications/:id
+func (h *SellerApplicationHandler) Get(c *fiber.Ctx) error {
+	app, err := h.findApplication(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Pengajuan tidak ditemukan"})
+	}
+	return c.JSON(h.toApplicationResponse(app))
+}
+
+// review ngubah status pengajuan pending. Dicek ulang di WHERE biar dua admin gak
+// nge-review pengajuan yang sama barengan.
+func (h *SellerApplicationHandler) review(tx *gorm.DB, app *models.SellerApplication, status models.SellerApplicationStatus, reviewerID uint, reason string) error {
+	now := time.Now()
+	result := tx.Model(&models.SellerApplication{}).
+		Where("id = ? AND status = ?", app.ID, models.ApplicationPending).
+		Updates(map[string]interface{}{
+			"status":           status,
+			"rejection_reason": reason,
+			"reviewed_by_id":   reviewerID,
+			"reviewed_at":      now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errApplicationReviewed
+	}
+	app.Status = status
+	app.RejectionReason = reason
+	app.ReviewedByID = &reviewerID
+	app.ReviewedAt = &now
+	return nil
+}
+
+// POST /admin/seller-applications/:id/approve
+// Role pengaju jadi seller; access token lamanya ditolak biar client refresh dapet role baru.
+func (h *SellerApplicationHandler) Approve(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+	app, err := h.findApplication(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Pengajuan tidak ditemukan"})
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := h.review(tx, app, models.ApplicationApproved, adminID, ""); err != nil {
+			return err
+		}
+		// Etalase toko langsung dibikin dari data pengajuan
+		if app.User == nil {
+			return gorm.ErrRecordNotFound
+		}
+		if _, err := models.EnsureStore(tx, app.User, app.StoreName, app.Description); err != nil {
+			return err
+		}
+		// Admin yang iseng ngajuin gak ikut turun jadi seller
+		result := tx.Model(&models.User{}).Where("id = ? AND role = ?", app.UserID, "user").Update("role", "seller")
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return h.Sessions.InvalidateAccessTokens(tx, app.UserID)
+	})
+	if err != nil {
+		if errors.Is(err, errApplicationReviewed) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		log.Printf("[SELLER] ERROR: Gagal approve pengajuan #%d: %v", app.ID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menyetujui pengajuan"})
+	}
+
+	log.Printf("[SELLER] Pengajuan #%d disetujui admin %d, user %d sekarang seller", app.ID, adminID, app.UserID)
+	h.notify(app.UserID, "Pengajuan seller disetujui 🎉",
+		fmt.Sprintf("Selamat! Toko \"%s\" udah disetujui, sekarang kamu bisa mulai jualan.", app.StoreName), app.ID)
+
+	if app.User != nil && app.User.Role == "user" {
+		app.User.Role = "seller"
+	}
+	return c.JSON(h.toApplicationResponse(app))
+}
+
+// POST /admin/seller-applications/:id/reject
+func (h *SellerApplicationHandler) Reject(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	input := new(RejectApplicationInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	if len(input.Reason) < 5 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Alasan penolakan wajib diisi (minimal 5 karakter)"})
+	}
+
+	app, err := h.findApplication(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Pengajuan tidak ditemukan"})
+	}
+
+	if err := h.review(h.DB, app, models.ApplicationRejected, adminID, input.Reason); err != nil {
+		if errors.Is(err, errApplicationReviewed) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		log.Printf("[SELLER] ERROR: Gagal tolak pengajuan #%d: %v", app.ID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menolak pengajuan"})
+	}
+
+	log.Printf("[SELLER] Pengajuan #%d ditolak admin %d", app.ID, adminID)
+	h.notify(app.UserID, "Pengajuan seller ditolak",
+		fmt.Sprintf("Pengajuan toko \"%s\" ditolak: %s. Kamu bisa perbaiki dan ngajuin lagi.", app.StoreName, input.Reason), app.ID)
+
+	return c.JSON(h.toApplicationResponse(app))
+}
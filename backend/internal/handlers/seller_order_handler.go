@@ -0,0 +1,408 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akhdanrgya/telu-hub/internal/fulfilment"
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/orderflow"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SellerOrderResponse struct {
+	ID         uint                `json:"id"`
+	SellerID   uint                `json:"seller_id"`
+	SellerName string              `json:"seller_name"`
+	Status     models.OrderStatus  `json:"status"`
+	Subtotal   float64             `json:"subtotal"`
+	DiscountAmount float64         `json:"discount_amount"`
+	Fulfilment FulfilmentResponse  `json:"fulfilment"`
+	OrderItems []OrderItemResponse `json:"OrderItems"`
+}
+
+// FulfilmentResponse = info pengiriman satu sub-order. HandoverCode cuma dikirim ke buyer.
+type FulfilmentResponse struct {
+	MethodID        *uint                 `json:"method_id,omitempty"`
+	Type            models.FulfilmentType `json:"type"`
+	Name            string                `json:"name"`
+	ShippingFee     float64               `json:"shipping_fee"`
+	ShippingAddress string                `json:"shipping_address,omitempty"`
+	MeetupPoint     string                `json:"meetup_point,omitempty"`
+	TrackingNumber  string                `json:"tracking_number,omitempty"`
+	HandoverCode    string                `json:"handover_code,omitempty"`
+	HandedOverAt    *time.Time            `json:"handed_over_at,omitempty"`
+}
+
+type SaleBuyerResponse struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// SaleResponse = sub-order dari sisi seller: cuma item miliknya + info buyer.
+type SaleResponse struct {
+	ID           uint                 `json:"id"`
+	OrderID      uint                 `json:"order_id"`
+	Status       models.OrderStatus   `json:"status"`
+	OrderStatus  models.OrderStatus   `json:"order_status"`
+	Subtotal     float64              `json:"subtotal"`
+	DiscountAmount float64            `json:"discount_amount"`
+	Fulfilment   FulfilmentResponse   `json:"fulfilment"`
+	NextStatuses []models.OrderStatus `json:"next_statuses"`
+	Buyer        SaleBuyerResponse    `json:"buyer"`
+	CreatedAt    time.Time            `json:"created_at"`
+	OrderItems   []OrderItemResponse  `json:"OrderItems"`
+}
+
+// buildSellerOrders ngelompokin item checkout per seller (urutan seller ngikutin urutan cart).
+func buildSellerOrders(orderID uint, items []models.OrderItem, products []*models.Product) []models.SellerOrder {
+	var sellerOrders []models.SellerOrder
+	index := make(map[uint]int)
+
+	for i, item := range items {
+		sellerID := products[i].SellerID
+		pos, ok := index[sellerID]
+		if !ok {
+			pos = len(sellerOrders)
+			index[sellerID] = pos
+			sellerOrders = append(sellerOrders, models.SellerOrder{
+				OrderID:  orderID,
+				SellerID: sellerID,
+				Status:   models.OrderStatusPending,
+			})
+		}
+
+		item.OrderID = orderID
+		sellerOrders[pos].Subtotal += item.PriceAtTime * float64(item.Quantity)
+		sellerOrders[pos].DiscountAmount += item.DiscountAmount
+		sellerOrders[pos].OrderItems = append(sellerOrders[pos].OrderItems, item)
+	}
+	return sellerOrders
+}
+
+// flattenSellerOrderItems ngumpulin lagi item yang udah kesimpen (udah punya ID) lengkap sama produknya.
+func flattenSellerOrderItems(sellerOrders []models.SellerOrder, items []models.OrderItem, products []*models.Product) []models.OrderItem {
+	productByID := make(map[uint]*models.Product, len(items))
+	for i, item := range items {
+		productByID[item.ProductID] = products[i]
+	}
+
+	var flat []models.OrderItem
+	for _, sub := range sellerOrders {
+		for _, item := range sub.OrderItems {
+			item.Product = productByID[item.ProductID]
+			flat = append(flat, item)
+		}
+	}
+	return flat
+}
+
+func toOrderItemResponses(items []models.OrderItem) []OrderItemResponse {
+	responses := make([]OrderItemResponse, 0, len(items))
+	for _, item := range items {
+		res := OrderItemResponse{
+			ID:          item.ID,
+			Quantity:    item.Quantity,
+			PriceAtTime: item.PriceAtTime,
+			VariantID:   item.VariantID,
+			VariantName: item.VariantName,
+			DiscountAmount: item.DiscountAmount,
+		}
+		if item.Product != nil {
+			res.Product = OrderProductResponse{
+				ID:       item.Product.ID,
+				Name:     item.Product.Name,
+				Price:    item.Product.Price,
+				ImageURL: item.Product.ImageURL,
+			}
+		}
+		responses = append(responses, res)
+	}
+	return responses
+}
+
+func toFulfilmentResponse(sub models.SellerOrder) FulfilmentResponse {
+	return FulfilmentResponse{
+		MethodID:        sub.FulfilmentMethodID,
+		Type:            sub.FulfilmentType,
+		Name:            sub.FulfilmentName,
+		ShippingFee:     sub.ShippingFee,
+		ShippingAddress: sub.ShippingAddress,
+		MeetupPoint:     sub.MeetupPoint,
+		TrackingNumber:  sub.TrackingNumber,
+		HandedOverAt:    sub.HandedOverAt,
+	}
+}
+
+func toSellerOrderResponses(sellerOrders []models.SellerOrder) []SellerOrderResponse {
+	responses := make([]SellerOrderResponse, 0, len(sellerOrders))
+	for _, sub := range sellerOrders {
+		res := SellerOrderResponse{
+			ID:         sub.ID,
+			SellerID:   sub.SellerID,
+			Status:     sub.Status,
+			Subtotal:   sub.Subtotal,
+			DiscountAmount: sub.DiscountAmount,
+			Fulfilment: toFulfilmentResponse(sub),
+			OrderItems: toOrderItemResponses(sub.OrderItems),
+		}
+		// Kode serah terima ditunjukin buyer ke seller, jadi cuma muncul di sisi buyer
+		if sub.HandedOverAt == nil {
+			res.Fulfilment.HandoverCode = sub.HandoverCode
+		}
+		if sub.Seller != nil {
+			res.SellerName = sub.Seller.Username
+		}
+		responses = append(responses, res)
+	}
+	return responses
+}
+
+func toSaleResponse(sub models.SellerOrder) SaleResponse {
+	res := SaleResponse{
+		ID:           sub.ID,
+		OrderID:      sub.OrderID,
+		Status:       sub.Status,
+		Subtotal:     sub.Subtotal,
+		DiscountAmount: sub.DiscountAmount,
+		Fulfilment:   toFulfilmentResponse(sub),
+		NextStatuses: sub.Status.NextStatuses(),
+		CreatedAt:    sub.CreatedAt,
+		OrderItems:   toOrderItemResponses(sub.OrderItems),
+	}
+	if res.NextStatuses == nil {
+		res.NextStatuses = make([]models.OrderStatus, 0)
+	}
+	if sub.Order != nil {
+		res.OrderStatus = sub.Order.Status
+		if sub.Order.User != nil {
+			res.Buyer = SaleBuyerResponse{
+				ID:       sub.Order.User.ID,
+				Username: sub.Order.User.Username,
+				Email:    sub.Order.User.Email,
+			}
+		}
+	}
+	return res
+}
+
+// findSale ngambil sub-order milik seller yang lagi login (admin boleh liat semua).
+func (h *OrderHandler) findSale(c *fiber.Ctx) (*models.SellerOrder, error) {
+	userID, _ := c.Locals("user_id").(uint)
+	role, _ := c.Locals("user_role").(string)
+
+	query := h.DB.Preload("OrderItems.Product").Preload("Order.User").Where("id = ?", c.Params("id"))
+	if role != "admin" {
+		query = query.Where("seller_id = ?", userID)
+	}
+
+	var sub models.SellerOrder
+	if err := query.First(&sub).Error; err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// GET /me/sales — daftar sub-order milik seller, bisa difilter ?status=paid
+func (h *OrderHandler) GetMySales(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(uint)
+
+	query := h.DB.Preload("OrderItems.Product").Preload("Order.User").
+		Where("seller_id = ?", userID).
+		Order("created_at desc")
+
+	if status := models.OrderStatus(c.Query("status")); status != "" {
+		if !status.IsValid() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Status tidak valid"})
+		}
+		query = query.Where("status = ?", status)
+	}
+
+	var sales []models.SellerOrder
+	if err := query.Find(&sales).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil data penjualan"})
+	}
+
+	response := make([]SaleResponse, 0, len(sales))
+	for _, sub := range sales {
+		response = append(response, toSaleResponse(sub))
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// GET /me/sales/:id
+func (h *OrderHandler) GetMySaleByID(c *fiber.Ctx) error {
+	sub, err := h.findSale(c)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Penjualan tidak ditemukan"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil data penjualan"})
+	}
+	return c.Status(fiber.StatusOK).JSON(toSaleResponse(*sub))
+}
+
+// PATCH /me/sales/:id/status — seller majuin sub-order-nya (processing, shipped, delivered)
+func (h *OrderHandler) UpdateMySaleStatus(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(uint)
+	role, _ := c.Locals("user_role").(string)
+
+	var input UpdateOrderStatusInput
+	if err := c.BodyParser(&input); err != nil || !input.Status.IsValid() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Status tidak valid"})
+	}
+
+	// Refund & pembatalan harus lewat refund biar dananya balik & stoknya disesuaiin
+	if input.Status == models.OrderStatusRefunded || input.Status == models.OrderStatusCancelled {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Pakai endpoint /me/sales/:id/refunds untuk refund"})
+	}
+
+	sub, err := h.findSale(c)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Penjualan tidak ditemukan"})
+	}
+	// Ketemuan / ambil sendiri cuma bisa dianggap terkirim lewat kode serah terima dari buyer
+	if sub.FulfilmentType.UsesHandoverCode() && (input.Status == models.OrderStatusShipped || input.Status == models.OrderStatusDelivered) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Serah terima langsung dikonfirmasi lewat /me/sales/:id/handover pakai kode dari buyer"})
+	}
+
+	actor := orderflow.Actor{UserID: userID, Role: orderflow.ActorSeller}
+	if role == "admin" {
+		actor.Role = orderflow.ActorAdmin
+	}
+
+	if err := h.Orders.TransitionSellerOrderAndNotify(sub, input.Status, actor, input.Reason); err != nil {
+		return c.Status(orderFlowErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Status penjualan diperbarui", "status": sub.Status})
+}
+
+// POST /orders/:id/sub-orders/:sid/complete — buyer konfirmasi paket dari satu seller udah diterima
+func (h *OrderHandler) CompleteSellerOrder(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(uint)
+	order, err := h.findBuyerOrder(c)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order tidak ditemukan"})
+	}
+
+	var sub models.SellerOrder
+	if err := h.DB.Where("id = ? AND order_id = ?", c.Params("sid"), order.ID).First(&sub).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Sub-order tidak ditemukan"})
+	}
+
+	actor := orderflow.Actor{UserID: userID, Role: orderflow.ActorBuyer}
+	if err := h.Orders.TransitionSellerOrderAndNotify(&sub, models.OrderStatusCompleted, actor, "Dikonfirmasi buyer"); err != nil {
+		return c.Status(orderFlowErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Pesanan dari seller ini selesai", "status": sub.Status})
+}
+
+// PUT /me/sales/:id/fulfilment — seller ngisi nomor resi buat pengiriman kurir
+func (h *OrderHandler) UpdateMySaleFulfilment(c *fiber.Ctx) error {
+	var input struct {
+		TrackingNumber string `json:"tracking_number"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	tracking := strings.TrimSpace(input.TrackingNumber)
+	if tracking == "" || len(tracking) > 100 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Nomor resi wajib diisi (maks 100 karakter)"})
+	}
+
+	sub, err := h.findSale(c)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Penjualan tidak ditemukan"})
+	}
+	if sub.FulfilmentType != models.FulfilmentCourier {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Nomor resi cuma buat pengiriman kurir"})
+	}
+	switch sub.Status {
+	case models.OrderStatusPaid, models.OrderStatusProcessing, models.OrderStatusShipped:
+	default:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Nomor resi cuma bisa diisi sebelum paket sampai"})
+	}
+
+	if err := h.DB.Model(sub).Update("tracking_number", tracking).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menyimpan nomor resi"})
+	}
+	return c.Status(fiber.StatusOK).JSON(toSaleResponse(*sub))
+}
+
+// POST /me/sales/:id/handover — seller masukin kode dari buyer waktu ketemuan / barang diambil.
+// Kalau kodenya cocok, sub-order langsung dianggap terkirim (delivered).
+func (h *OrderHandler) ConfirmMySaleHandover(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(uint)
+	role, _ := c.Locals("user_role").(string)
+
+	var input struct {
+		Code string `json:"code"`
+	}
+	if err := c.BodyParser(&input); err != nil || strings.TrimSpace(input.Code) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Kode serah terima wajib diisi"})
+	}
+
+	sub, err := h.findSale(c)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Penjualan tidak ditemukan"})
+	}
+
+	actor := orderflow.Actor{UserID: userID, Role: orderflow.ActorSeller}
+	if role == "admin" {
+		actor.Role = orderflow.ActorAdmin
+	}
+
+	var history *models.OrderStatusHistory
+	var verifyErr error
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(sub, sub.ID).Error; err != nil {
+			return err
+		}
+		// Kode cuma 6 digit: seller dibatesin MaxHandoverAttempts kali salah, admin bebas
+		if err := fulfilment.VerifyHandover(sub, input.Code, actor.Role != orderflow.ActorAdmin); err != nil {
+			if !errors.Is(err, fulfilment.ErrCodeMismatch) {
+				return err
+			}
+			// Percobaan salah tetep di-commit, error-nya dibalikin setelah transaksi
+			verifyErr = err
+			return tx.Model(sub).UpdateColumn("handover_attempts", gorm.Expr("handover_attempts + 1")).Error
+		}
+		if sub.Status.FlowRank() < models.OrderStatusPaid.FlowRank() || sub.Status.FlowRank() >= models.OrderStatusDelivered.FlowRank() {
+			return fmt.Errorf("%w: %s -> %s", orderflow.ErrIllegalTransition, sub.Status, models.OrderStatusDelivered)
+		}
+
+		// Serah terima langsung = dikirim & diterima sekaligus, jadi status dijalanin sampai delivered
+		for sub.Status != models.OrderStatusDelivered {
+			hist, err := h.Orders.TransitionSellerOrder(tx, sub, sub.Status.NextInFlow(), actor, "Serah terima dikonfirmasi pakai kode")
+			if err != nil {
+				return err
+			}
+			history = hist
+		}
+		now := time.Now()
+		sub.HandedOverAt = &now
+		return tx.Model(sub).Update("handed_over_at", now).Error
+	})
+	if err == nil {
+		err = verifyErr
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, fulfilment.ErrCodeMismatch), errors.Is(err, fulfilment.ErrNoHandover):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, fulfilment.ErrHandoverLocked):
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, orderflow.ErrIllegalTransition), errors.Is(err, orderflow.ErrActorNotAllowed):
+			return c.Status(orderFlowErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal konfirmasi serah terima"})
+	}
+
+	h.Orders.NotifySellerOrder(sub, history)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Serah terima berhasil, barang udah diterima buyer", "status": sub.Status})
+}
@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akhdanrgya/telu-hub/internal/media"
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gosimple/slug"
+	"gorm.io/gorm"
+)
+
+// responseTimeWindow = rentang chat yang dihitung buat rata-rata waktu balas seller.
+const responseTimeWindow = 90 * 24 * time.Hour
+
+// storeTimezone = zona waktu jam buka toko (kampus di Bandung).
+var storeTimezone = loadStoreTimezone()
+
+func loadStoreTimezone() *time.Location {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		return time.FixedZone("WIB", 7*60*60)
+	}
+	return loc
+}
+
+type StoreHandler struct {
+	DB    *gorm.DB
+	Media *media.Service
+}
+
+func NewStoreHandler(db *gorm.DB, mediaService *media.Service) *StoreHandler {
+	return &StoreHandler{DB: db, Media: mediaService}
+}
+
+// StoreStats = angka ringkasan yang ditampilin di halaman toko.
+type StoreStats struct {
+	ProductCount        int64    `json:"product_count"`
+	SoldCount           int64    `json:"sold_count"`
+	AverageRating       *float64 `json:"average_rating"` // nil = belum ada rating
+	RatingCount         int64    `json:"rating_count"`
+	ResponseTimeMinutes *int     `json:"response_time_minutes"` // nil = belum pernah bales chat
+}
+
+type StoreResponse struct {
+	models.Store
+	IsOpen bool                  `json:"is_open"`
+	Seller PublicProfileResponse `json:"seller"`
+	Stats  *StoreStats           `json:"stats,omitempty"`
+}
+
+type UpdateStoreInput struct {
+	Name           *string                `json:"name"`
+	Slug           *string                `json:"slug"`
+	Description    *string                `json:"description"`
+	BannerURL      *string                `json:"banner_url"`
+	PickupLocation *string                `json:"pickup_location"`
+	ShippingOrigin *string                `json:"shipping_origin"`
+	OperatingHours *models.OperatingHours `json:"operating_hours"`
+}
+
+func toStoreResponse(store *models.Store) StoreResponse {
+	response := StoreResponse{Store: *store, IsOpen: store.OperatingHours.IsOpen(time.Now().In(storeTimezone))}
+	if response.OperatingHours == nil {
+		response.OperatingHours = models.OperatingHours{}
+	}
+	if store.Seller != nil {
+		response.Seller = PublicProfileResponse{
+			ID:              store.Seller.ID,
+			Username:        store.Seller.Username,
+			ProfileImageURL: store.Seller.ProfileImageURL,
+			Role:            store.Seller.Role,
+			JoinedAt:        store.Seller.CreatedAt,
+		}
+	}
+	return response
+}
+
+func (h *StoreHandler) findStore(storeSlug string) (*models.Store, error) {
+	var store models.Store
+	if err := h.DB.Preload("Seller").Where("slug = ?", storeSlug).First(&store).Error; err != nil {
+		return nil, err
+	}
+	return &store, nil
+}
+
+// stats ngitung ringkasan toko langsung dari tabel produk, order, ulasan, dan chat.
+func (h *StoreHandler) stats(sellerID uint) (*StoreStats, error) {
+	stats := &StoreStats{}
+	if err := h.DB.Model(&models.Product{}).Where("seller_id = ?", sellerID).Count(&stats.ProductCount).Error; err != nil {
+		return nil, err
+	}
+
+	err := h.DB.Table("(?) AS sales", soldSubquery(h.DB)).
+		Joins("JOIN products ON products.id = sales.product_id").
+		Where("products.seller_id = ?", sellerID).
+		Select("COALESCE(SUM(sales.sold), 0)").
+		Scan(&stats.SoldCount).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var rating struct {
+		Average *float64
+		Count   int64
+	}
+	err = h.DB.Model(&models.Review{}).
+		Joins("JOIN products ON products.id = reviews.product_id AND products.deleted_at IS NULL").
+		Where("products.seller_id = ? AND reviews.is_hidden = ?", sellerID, false).
+		Select("AVG(reviews.rating) AS average, COUNT(*) AS count").
+		Scan(&rating).Error
+	if err != nil {
+		return nil, err
+	}
+	stats.AverageRating, stats.RatingCount = rating.Average, rating.Count
+
+	// Waktu balas = jarak chat pertama pembeli di tiap room ke balasan pertama seller setelahnya
+	var avgSeconds *float64
+	err = h.DB.Raw(`
+		SELECT AVG(EXTRACT(EPOCH FROM reply.at - ask.asked_at))
+		FROM (
+			SELECT cm.room_id, MIN(cm.created_at) AS asked_at
+			FROM chat_messages cm
+			JOIN chat_rooms cr ON cr.id = cm.room_id
+			WHERE cr.seller_id = ? AND cm.sender_id = cr.buyer_id AND cm.created_at > ?
+			GROUP BY cm.room_id
+		) ask
+		JOIN LATERAL (
+			SELECT MIN(cm.created_at) AS at
+			FROM chat_messages cm
+			WHERE cm.room_id = ask.room_id AND cm.sender_id = ? AND cm.created_at > ask.asked_at
+		) reply ON reply.at IS NOT NULL`,
+		sellerID, time.Now().Add(-responseTimeWindow), sellerID).
+		Scan(&avgSeconds).Error
+	if err != nil {
+		return nil, err
+	}
+	if avgSeconds != nil {
+		minutes := int(*avgSeconds/60 + 0.5)
+		stats.ResponseTimeMinutes = &minutes
+	}
+	return stats, nil
+}
+
+// GET /stores/:slug
+func (h *StoreHandler) GetStore(c *fiber.Ctx) error {
+	store, err := h.findStore(c.Params("slug"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Toko tidak ditemukan"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil data toko"})
+	}
+
+	stats, err := h.stats(store.SellerID)
+	if err != nil {
+		log.Printf("[STORE] ERROR: Gagal ngitung statistik toko %s: %v", store.Slug, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil statistik toko"})
+	}
+
+	response := toStoreResponse(store)
+	response.Stats = stats
+	return c.JSON(response)
+}
+
+// GET /stores/:slug/products?q=&category=&min_price=&max_price=&in_stock=&sort=&limit=&cursor=
+// Katalog toko, filter & paginasinya sama persis kayak GET /products.
+func (h *StoreHandler) GetStoreProducts(c *fiber.Ctx) error {
+	var store models.Store
+	if err := h.DB.Where("slug = ?", c.Params("slug")).First(&store).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Toko tidak ditemukan"})
+	}
+
+	q, msg := parseProductQuery(c)
+	if msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": msg})
+	}
+	q.Seller = strconv.FormatUint(uint64(store.SellerID), 10)
+
+	response, err := listProducts(h.DB, q)
+	if err != nil {
+		if errors.Is(err, errInvalidCursor) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil produk toko"})
+	}
+	return c.JSON(response)
+}
+
+// myStore ngambil toko user yang login, dibikin dulu kalau belum ada (mis. admin yang mau jualan).
+func (h *StoreHandler) myStore(userID uint) (*models.Store, error) {
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	store, err := models.EnsureStore(h.DB, &user, "", "")
+	if err != nil {
+		return nil, err
+	}
+	store.Seller = &user
+	return store, nil
+}
+
+// GET /me/store
+func (h *StoreHandler) GetMyStore(c *fiber.Ctx) error {
+	store, err := h.myStore(c.Locals("user_id").(uint))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil data toko"})
+	}
+	return c.JSON(toStoreResponse(store))
+}
+
+func validateStoreInput(input *UpdateStoreInput) error {
+	trim := func(p *string) {
+		if p != nil {
+			*p = strings.TrimSpace(*p)
+		}
+	}
+	trim(input.Name)
+	trim(input.Slug)
+	trim(input.Description)
+	trim(input.BannerURL)
+	trim(input.PickupLocation)
+	trim(input.ShippingOrigin)
+
+	if input.Name != nil && (len(*input.Name) < 3 || len(*input.Name) > 100) {
+		return errors.New("nama toko harus 3-100 karakter")
+	}
+	if input.Slug != nil {
+		*input.Slug = slug.Make(*input.Slug)
+		if len(*input.Slug) < 3 || len(*input.Slug) > 120 {
+			return errors.New("slug toko harus 3-120 karakter (huruf, angka, strip)")
+		}
+	}
+	if input.Description != nil && len(*input.Description) > 2000 {
+		return errors.New("deskripsi toko kepanjangan (maks 2000 karakter)")
+	}
+	if input.BannerURL != nil && *input.BannerURL != "" {
+		if err := validateImageURL(*input.BannerURL); err != nil {
+			return err
+		}
+	}
+	if input.PickupLocation != nil && len(*input.PickupLocation) > 255 {
+		return errors.New("lokasi ambil kepanjangan (maks 255 karakter)")
+	}
+	if input.ShippingOrigin != nil && len(*input.ShippingOrigin) > 100 {
+		return errors.New("asal pengiriman kepanjangan (maks 100 karakter)")
+	}
+	if input.OperatingHours != nil {
+		if err := input.OperatingHours.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PUT /me/store
+// Cuma field yang dikirim yang diubah. Banner harus hasil upload sendiri (string kosong = hapus banner).
+func (h *StoreHandler) UpdateMyStore(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	role, _ := c.Locals("user_role").(string)
+
+	input := new(UpdateStoreInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	if err := validateStoreInput(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	store, err := h.myStore(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil data toko"})
+	}
+
+	if input.Slug != nil && *input.Slug != store.Slug {
+		var taken int64
+		h.DB.Model(&models.Store{}).Where("slug = ? AND id <> ?", *input.Slug, store.ID).Count(&taken)
+		if taken > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Slug toko udah dipake toko lain"})
+		}
+		store.Slug = *input.Slug
+	}
+	oldBannerURL := store.BannerURL
+	if input.BannerURL != nil && *input.BannerURL != store.BannerURL {
+		if *input.BannerURL != "" {
+			if _, err := h.Media.OwnedUpload(*input.BannerURL, userID, role == "admin"); err != nil {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+			}
+		}
+		store.BannerURL = *input.BannerURL
+	}
+	if input.Name != nil {
+		store.Name = *input.Name
+	}
+	if input.Description != nil {
+		store.Description = *input.Description
+	}
+	if input.PickupLocation != nil {
+		store.PickupLocation = *input.PickupLocation
+	}
+	if input.ShippingOrigin != nil {
+		store.ShippingOrigin = *input.ShippingOrigin
+	}
+	if input.OperatingHours != nil {
+		store.OperatingHours = *input.OperatingHours
+	}
+
+	if err := h.DB.Omit("Seller").Save(store).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "idx_stores_slug") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Slug toko udah dipake toko lain"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menyimpan data toko"})
+	}
+	if oldBannerURL != store.BannerURL {
+		h.Media.Release(oldBannerURL)
+	}
+
+	return c.JSON(toStoreResponse(store))
+}
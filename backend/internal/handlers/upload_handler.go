@@ -1,48 +1,332 @@
 package handlers
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/akhdanrgya/telu-hub/internal/imaging"
+	"github.com/akhdanrgya/telu-hub/internal/media"
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/storage"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gosimple/slug"
+	"gorm.io/gorm"
 )
 
-type UploadHandler struct{}
+type UploadHandler struct {
+	DB     *gorm.DB
+	Media  *media.Service
+	Limits imaging.Limits
+}
+
+func NewUploadHandler(db *gorm.DB, mediaService *media.Service, limits imaging.Limits) *UploadHandler {
+	return &UploadHandler{DB: db, Media: mediaService, Limits: limits}
+}
+
+type ImageVariantResponse struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
 
-func NewUploadHandler() *UploadHandler {
-	return &UploadHandler{}
+type UploadResponse struct {
+	ID           uint      `json:"id"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	MediumURL    string    `json:"medium_url"`
+	Size         int64     `json:"size"`
+	Hash         string    `json:"hash"`
+	ContentType  string    `json:"content_type"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	InUse        bool      `json:"in_use"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type UploadListResponse struct {
+	Data       []UploadResponse `json:"data"`
+	UsedBytes  int64            `json:"used_bytes"`
+	QuotaBytes int64            `json:"quota_bytes"` // 0 = tanpa batas
+}
+
+// toUploadResponse pake ViewURL biar upload private (dokumen pengajuan) tetep bisa dipreview.
+func (h *UploadHandler) toUploadResponse(u *models.Upload, inUse bool) UploadResponse {
+	return UploadResponse{
+		ID:           u.ID,
+		URL:          h.Media.ViewURL(u.URL),
+		ThumbnailURL: h.Media.ViewURL(imageVariantURL(u.URL, "thumbnail")),
+		MediumURL:    h.Media.ViewURL(imageVariantURL(u.URL, "medium")),
+		Size:         u.Size,
+		Hash:         u.Hash,
+		ContentType:  u.ContentType,
+		Width:        u.Width,
+		Height:       u.Height,
+		InUse:        inUse,
+		CreatedAt:    u.CreatedAt,
+	}
 }
 
+// uploadBaseName = awalan key file upload: "<unix nano>-<nama file asli di-slug>".
+func uploadBaseName(fileName string) string {
+	name := slug.Make(strings.TrimSuffix(fileName, filepath.Ext(fileName)))
+	if len(name) > 50 {
+		name = strings.Trim(name[:50], "-")
+	}
+	if name == "" {
+		name = "image"
+	}
+	return fmt.Sprintf("%d-%s", time.Now().UnixNano(), name)
+}
+
+func formatMB(bytes int64) string {
+	return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+}
+
+// POST /upload/image (multipart, field "image")
+// Format dicek dari isi file (bukan Content-Type client), lalu diolah ulang jadi
+// thumbnail/medium/full tanpa metadata EXIF. imageUrl = versi full.
+// File yang sama persis (hash-nya sama) yang pernah diupload user ini gak disimpen dua kali.
 func (h *UploadHandler) UploadImage(c *fiber.Ctx) error {
+	return h.upload(c, false)
+}
+
+// POST /me/seller-application/documents (multipart, field "image")
+// Sama kayak /upload/image, tapi file-nya disimpen private (gak bisa dibuka lewat URL publik).
+// imageUrl dipake buat dikirim di pengajuan, preview-nya lewat variants (signed URL).
+func (h *UploadHandler) UploadDocument(c *fiber.Ctx) error {
+	return h.upload(c, true)
+}
+
+func (h *UploadHandler) upload(c *fiber.Ctx, private bool) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
 	file, err := c.FormFile("image")
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Gagal mengambil file gambar", "details": err.Error()})
 	}
+	if h.Limits.MaxBytes > 0 && file.Size > h.Limits.MaxBytes {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error": fmt.Sprintf("Ukuran gambar maksimal %d MB", h.Limits.MaxBytes>>20),
+		})
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Gagal membaca file gambar", "details": err.Error()})
+	}
+	defer src.Close()
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Gagal membaca file gambar", "details": err.Error()})
+	}
 
-	allowedTypes := map[string]bool{
-		"image/jpeg": true,
-		"image/png":  true,
-		"image/gif":  true,
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	// Dedup cuma sesama publik / sesama private, biar dokumen gak nyasar jadi gambar publik (dan sebaliknya)
+	keyPrefix := h.Media.KeyPrefix
+	dedup := h.DB.Where("user_id = ? AND hash = ?", userID, hash)
+	if private {
+		keyPrefix = storage.PrivatePrefix + "documents/"
+		dedup = dedup.Where("key LIKE ?", storage.PrivatePrefix+"%")
+	} else {
+		dedup = dedup.Where("key NOT LIKE ?", storage.PrivatePrefix+"%")
 	}
-	if !allowedTypes[file.Header.Get("Content-Type")] {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Tipe file tidak didukung. Hanya JPEG, PNG, GIF."})
+
+	var existing models.Upload
+	if err := dedup.First(&existing).Error; err == nil {
+		variants := make(map[string]ImageVariantResponse, len(imaging.Variants))
+		for _, spec := range imaging.Variants {
+			variants[spec.Name] = ImageVariantResponse{URL: h.Media.ViewURL(imageVariantURL(existing.URL, spec.Name))}
+		}
+		variants["full"] = ImageVariantResponse{URL: h.Media.ViewURL(existing.URL), Width: existing.Width, Height: existing.Height}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message":      "Gambar ini udah pernah kamu upload, pake yang lama",
+			"imageUrl":     existing.URL,
+			"variants":     variants,
+			"upload":       h.toUploadResponse(&existing, false),
+			"deduplicated": true,
+		})
 	}
 
-	ext := filepath.Ext(file.Filename)
-	fileName := fmt.Sprintf("%d-%s%s", time.Now().UnixNano(), strings.TrimSuffix(file.Filename, ext), ext)
-	
-	fileName = strings.ReplaceAll(fileName, " ", "-") 
-	fileName = strings.ToLower(fileName)
+	result, err := imaging.Process(data, h.Limits)
+	if err != nil {
+		if errors.Is(err, imaging.ErrTooLarge) {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"error": fmt.Sprintf("Ukuran gambar maksimal %d MB", h.Limits.MaxBytes>>20),
+			})
+		}
+		if errors.Is(err, imaging.ErrUnsupportedFormat) || errors.Is(err, imaging.ErrTooManyPixels) || errors.Is(err, imaging.ErrCorrupt) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		log.Printf("[UPLOAD] ERROR: Gagal ngolah gambar %s: %v", file.Filename, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal memproses file gambar"})
+	}
 
-	uploadDir := "./uploads"
-	filePath := filepath.Join(uploadDir, fileName)
+	var total int64
+	for _, out := range result.Outputs {
+		total += int64(len(out.Data))
+	}
 
-	if err := c.SaveFile(file, filePath); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menyimpan file gambar", "details": err.Error()})
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+	if quota := h.Media.QuotaFor(&user); quota > 0 {
+		used, err := h.Media.UsedBytes(userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal ngecek kuota upload"})
+		}
+		if used+total > quota {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"error": fmt.Sprintf("Kuota upload kamu gak cukup (kepake %s dari %s), hapus upload lama di /me/uploads dulu",
+					formatMB(used), formatMB(quota)),
+			})
+		}
+	}
+
+	store := h.Media.Storage
+	base := keyPrefix + uploadBaseName(file.Filename)
+	variants := make(map[string]ImageVariantResponse, len(result.Outputs))
+	var saved []string
+	cleanup := func() {
+		for _, k := range saved {
+			store.Delete(k)
+		}
+	}
+	for _, out := range result.Outputs {
+		key := imaging.VariantKey(base, out.Suffix, result.Ext)
+		if err := store.Put(key, bytes.NewReader(out.Data), int64(len(out.Data)), result.ContentType); err != nil {
+			log.Printf("[UPLOAD] ERROR: Gagal nyimpen %s ke storage %s: %v", key, store.Name(), err)
+			// Jangan ninggalin ukuran yang setengah jadi
+			cleanup()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menyimpan file gambar"})
+		}
+		saved = append(saved, key)
+		variants[out.Name] = ImageVariantResponse{URL: h.Media.ViewURL(store.URL(key)), Width: out.Width, Height: out.Height}
 	}
 
-	imageURL := fmt.Sprintf("%s/uploads/%s", c.BaseURL(), fileName)
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Gambar berhasil diupload", "imageUrl": imageURL})
-}
\ No newline at end of file
+	fullKey := imaging.VariantKey(base, "full", result.Ext)
+	upload := models.Upload{
+		UserID:      userID,
+		Key:         fullKey,
+		URL:         store.URL(fullKey),
+		Hash:        hash,
+		Size:        total,
+		ContentType: result.ContentType,
+		Width:       result.Width,
+		Height:      result.Height,
+	}
+	if err := h.DB.Create(&upload).Error; err != nil {
+		cleanup()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menyimpan data upload"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":  "Gambar berhasil diupload",
+		"imageUrl": upload.URL,
+		"variants": variants,
+		"format":   result.SourceFormat,
+		"width":    result.Width,
+		"height":   result.Height,
+		"upload":   h.toUploadResponse(&upload, false),
+	})
+}
+
+// GET /me/uploads
+func (h *UploadHandler) GetMyUploads(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+
+	var uploads []models.Upload
+	if err := h.DB.Where("user_id = ?", userID).Order("created_at desc").Find(&uploads).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil daftar upload"})
+	}
+	inUse, err := h.Media.InUseKeys()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal ngecek pemakaian upload"})
+	}
+
+	response := UploadListResponse{Data: make([]UploadResponse, 0, len(uploads)), QuotaBytes: h.Media.QuotaFor(&user)}
+	for i := range uploads {
+		response.Data = append(response.Data, h.toUploadResponse(&uploads[i], inUse[uploads[i].Key]))
+		response.UsedBytes += uploads[i].Size
+	}
+	return c.JSON(response)
+}
+
+// DELETE /me/uploads/:id
+func (h *UploadHandler) DeleteMyUpload(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var upload models.Upload
+	if err := h.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).First(&upload).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Upload tidak ditemukan"})
+	}
+
+	inUse, err := h.Media.InUseKeys()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal ngecek pemakaian upload"})
+	}
+	if inUse[upload.Key] {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Gambar ini masih dipake di produk atau foto profil, lepas dulu dari sana"})
+	}
+
+	if err := h.Media.DeleteUpload(&upload); err != nil {
+		log.Printf("[UPLOAD] ERROR: Gagal hapus upload #%d: %v", upload.ID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menghapus upload"})
+	}
+	return c.JSON(fiber.Map{"message": "Upload berhasil dihapus"})
+}
+
+type UploadQuotaInput struct {
+	QuotaMB *int `json:"quota_mb"` // null = balik ke default role
+}
+
+// PUT /admin/users/:id/upload-quota
+func (h *UploadHandler) SetUserQuota(c *fiber.Ctx) error {
+	input := new(UploadQuotaInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Body request tidak valid"})
+	}
+	if input.QuotaMB != nil && *input.QuotaMB < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "quota_mb tidak boleh minus (0 = tanpa batas)"})
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User target tidak ditemukan"})
+	}
+	if err := h.DB.Model(&user).Update("upload_quota_mb", input.QuotaMB).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengupdate kuota upload"})
+	}
+	user.UploadQuotaMB = input.QuotaMB
+
+	used, _ := h.Media.UsedBytes(user.ID)
+	return c.JSON(fiber.Map{
+		"user_id":     user.ID,
+		"quota_bytes": h.Media.QuotaFor(&user),
+		"used_bytes":  used,
+	})
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"time"
 
+	"github.com/akhdanrgya/telu-hub/internal/media"
 	"github.com/akhdanrgya/telu-hub/internal/models"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
@@ -14,16 +15,18 @@ type PublicProfileResponse struct {
 	ProfileImageURL string    `json:"profile_image_url,omitempty"`
 	Role            string    `json:"role"`
 	JoinedAt        time.Time `json:"joined_at"`
+	StoreSlug       string    `json:"store_slug,omitempty"` // Diisi kalau user punya toko
 }
 
 
 
 type UserHandler struct {
-	DB *gorm.DB
+	DB    *gorm.DB
+	Media *media.Service
 }
 
-func NewUserHandler(db *gorm.DB) *UserHandler {
-	return &UserHandler{DB: db}
+func NewUserHandler(db *gorm.DB, mediaService *media.Service) *UserHandler {
+	return &UserHandler{DB: db, Media: mediaService}
 }
 
 type UpdateProfileInput struct {
@@ -49,19 +52,28 @@ func (h *UserHandler) UpdateUserProfile(c *fiber.Ctx) error {
 	if input.Username != "" {
 		user.Username = input.Username
 	}
-	if input.ProfileImageURL != "" {
+	oldImageURL := user.ProfileImageURL
+	if input.ProfileImageURL != "" && input.ProfileImageURL != user.ProfileImageURL {
+		// Foto profil cuma boleh dari upload sendiri
+		if _, err := h.Media.OwnedUpload(input.ProfileImageURL, user.ID, user.Role == "admin"); err != nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
 		user.ProfileImageURL = input.ProfileImageURL
 	}
 
 	if err := h.DB.Save(&user).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengupdate profil"})
 	}
+	if oldImageURL != user.ProfileImageURL {
+		h.Media.Release(oldImageURL)
+	}
 
 	response := UserResponse{
 		ID:              user.ID,
 		Username:        user.Username,
 		Email:           user.Email,
 		Role:            user.Role,
+		EmailVerified:   user.EmailVerifiedAt != nil,
 		ProfileImageURL: user.ProfileImageURL,
 	}
 
@@ -89,6 +101,10 @@ func (h *UserHandler) GetUserPublicProfile(c *fiber.Ctx) error {
 		Role:            user.Role,
 		JoinedAt:        user.CreatedAt,
 	}
+	var store models.Store
+	if err := h.DB.Select("slug").Where("seller_id = ?", user.ID).First(&store).Error; err == nil {
+		response.StoreSlug = store.Slug
+	}
 
 	return c.Status(fiber.StatusOK).JSON(response)
 }
@@ -108,7 +124,7 @@ func (h *UserHandler) GetUserProducts(c *fiber.Ctx) error {
 	}
 
 	var products []models.Product
-	if err := h.DB.Preload("Seller").Where("seller_id = ?", user.ID).Find(&products).Error; err != nil {
+	if err := h.DB.Preload("Seller").Preload("Images", models.OrderedImages).Where("seller_id = ?", user.ID).Find(&products).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil produk user"})
 	}
 	var response []ProductResponse
@@ -120,12 +136,18 @@ func (h *UserHandler) GetUserProducts(c *fiber.Ctx) error {
 			Description: p.Description,
 			Price:       p.Price,
 			Stock:       p.Stock,
+			AvailableStock: p.Available(),
+			ReservedStock:  p.Reserved,
 			ImageURL:    p.ImageURL,
+			ThumbnailURL: imageVariantURL(p.ImageURL, "thumbnail"),
+			RatingAverage: p.RatingAverage,
+			RatingCount:   p.RatingCount,
 			Seller: UserResponse{
 				ID:       p.Seller.ID,
 				Username: p.Seller.Username,
 				Email:    p.Seller.Email,
 			},
+			Images: toProductImageResponses(p.Images),
 		})
 	}
 
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gosimple/slug"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VariantInput struct {
+	SKU     string                `json:"sku"`
+	Name    string                `json:"name"`
+	Options models.VariantOptions `json:"options"`
+	Price   float64               `json:"price"`
+	Stock   int                   `json:"stock"`
+}
+
+type VariantResponse struct {
+	ID             uint                  `json:"id"`
+	SKU            string                `json:"sku"`
+	Name           string                `json:"name"`
+	Options        models.VariantOptions `json:"options"`
+	Price          float64               `json:"price"`
+	Stock          int                   `json:"stock"`
+	AvailableStock int                   `json:"available_stock"`
+	ReservedStock  int                   `json:"reserved_stock"`
+}
+
+func toVariantResponses(variants []models.ProductVariant) []VariantResponse {
+	responses := make([]VariantResponse, 0, len(variants))
+	for _, v := range variants {
+		responses = append(responses, VariantResponse{
+			ID:             v.ID,
+			SKU:            v.SKU,
+			Name:           v.Name,
+			Options:        v.Options,
+			Price:          v.Price,
+			Stock:          v.Stock,
+			AvailableStock: v.Available(),
+			ReservedStock:  v.Reserved,
+		})
+	}
+	return responses
+}
+
+func findVariant(variants []models.ProductVariant, id uint) *models.ProductVariant {
+	for i := range variants {
+		if variants[i].ID == id {
+			return &variants[i]
+		}
+	}
+	return nil
+}
+
+// buildVariant ngecek input & ngisi nama/SKU yang kosong (nama dari options, SKU dari slug produk + nama).
+func buildVariant(product *models.Product, input *VariantInput) (*models.ProductVariant, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		name = input.Options.Label()
+	}
+	if name == "" {
+		return nil, errors.New("nama varian atau options wajib diisi")
+	}
+	if input.Price <= 0 {
+		return nil, fmt.Errorf("harga varian %s harus lebih dari 0", name)
+	}
+	if input.Stock < 0 {
+		return nil, fmt.Errorf("stok varian %s tidak boleh minus", name)
+	}
+
+	sku := strings.ToUpper(strings.TrimSpace(input.SKU))
+	if sku == "" {
+		sku = strings.ToUpper(slug.Make(product.Slug + "-" + name))
+	}
+	options := input.Options
+	if options == nil {
+		options = models.VariantOptions{}
+	}
+
+	return &models.ProductVariant{
+		ProductID: product.ID,
+		SKU:       sku,
+		Name:      name,
+		Options:   options,
+		Price:     input.Price,
+		Stock:     input.Stock,
+	}, nil
+}
+
+var (
+	errNotProductOwner    = errors.New("bukan pemilik produk")
+	errStockBelowReserved = errors.New("stok di bawah jumlah yang lagi di-hold")
+)
+
+// findOwnedProduct ngambil produk dari :id, cuma kalau yang akses pemiliknya (atau admin).
+func (h *ProductHandler) findOwnedProduct(c *fiber.Ctx) (*models.Product, error) {
+	userID, _ := c.Locals("user_id").(uint)
+	role, _ := c.Locals("user_role").(string)
+
+	var product models.Product
+	if err := h.DB.First(&product, c.Params("id")).Error; err != nil {
+		return nil, err
+	}
+	if product.SellerID != userID && role != "admin" {
+		return nil, errNotProductOwner
+	}
+	return &product, nil
+}
+
+func productAccessError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, errNotProductOwner) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Forbidden", "message": "Anda tidak punya izin untuk mengubah produk ini"})
+	}
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produk tidak ditemukan"})
+}
+
+// GET /products/:id/variants
+func (h *ProductHandler) GetVariants(c *fiber.Ctx) error {
+	var variants []models.ProductVariant
+	if err := h.DB.Where("product_id = ?", c.Params("id")).Order("id asc").Find(&variants).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil varian produk"})
+	}
+	return c.JSON(toVariantResponses(variants))
+}
+
+// POST /products/:id/variants
+func (h *ProductHandler) CreateVariant(c *fiber.Ctx) error {
+	product, err := h.findOwnedProduct(c)
+	if err != nil {
+		return productAccessError(c, err)
+	}
+
+	input := new(VariantInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	variant, err := buildVariant(product, input)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(variant).Error; err != nil {
+			return err
+		}
+		return models.SyncVariantTotals(tx, product.ID)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "duplicate key") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "SKU " + variant.SKU + " sudah dipakai"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menyimpan varian"})
+	}
+	h.StockAlerts.Notify([]uint{product.ID})
+
+	return c.Status(fiber.StatusCreated).JSON(toVariantResponses([]models.ProductVariant{*variant})[0])
+}
+
+// PUT /products/:id/variants/:variantId
+func (h *ProductHandler) UpdateVariant(c *fiber.Ctx) error {
+	product, err := h.findOwnedProduct(c)
+	if err != nil {
+		return productAccessError(c, err)
+	}
+
+	var variant models.ProductVariant
+	if err := h.DB.Where("id = ? AND product_id = ?", c.Params("variantId"), product.ID).First(&variant).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Varian produk tidak ditemukan"})
+	}
+
+	input := new(VariantInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	if input.SKU == "" {
+		input.SKU = variant.SKU
+	}
+	if input.Options == nil {
+		input.Options = variant.Options
+	}
+	updated, err := buildVariant(product, input)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	var reserved int
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		// Kunci barisnya dulu: stok dari seller diterapin sebagai selisih dari stok yang dibaca di atas,
+		// jadi penjualan yang ke-commit di sela-selanya gak ketimpa
+		var locked models.ProductVariant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, variant.ID).Error; err != nil {
+			return err
+		}
+		stock := locked.Stock + (updated.Stock - variant.Stock)
+		if stock < locked.Reserved {
+			reserved = locked.Reserved
+			return errStockBelowReserved
+		}
+
+		err := tx.Model(&locked).Updates(map[string]interface{}{
+			"sku":     updated.SKU,
+			"name":    updated.Name,
+			"options": updated.Options,
+			"price":   updated.Price,
+			"stock":   stock,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return models.SyncVariantTotals(tx, product.ID)
+	})
+	if err != nil {
+		if errors.Is(err, errStockBelowReserved) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("Stok tidak boleh kurang dari %d unit yang lagi di-hold order pending", reserved),
+			})
+		}
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "duplicate key") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "SKU " + updated.SKU + " sudah dipakai"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengupdate varian"})
+	}
+	h.StockAlerts.Notify([]uint{product.ID})
+
+	h.DB.First(&variant, variant.ID)
+	return c.JSON(toVariantResponses([]models.ProductVariant{variant})[0])
+}
+
+// DELETE /products/:id/variants/:variantId
+func (h *ProductHandler) DeleteVariant(c *fiber.Ctx) error {
+	product, err := h.findOwnedProduct(c)
+	if err != nil {
+		return productAccessError(c, err)
+	}
+
+	var variant models.ProductVariant
+	if err := h.DB.Where("id = ? AND product_id = ?", c.Params("variantId"), product.ID).First(&variant).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Varian produk tidak ditemukan"})
+	}
+	if variant.Reserved > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": fmt.Sprintf("Varian ini lagi di-hold %d unit oleh order pending, tunggu sampai selesai", variant.Reserved),
+		})
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&variant).Error; err != nil {
+			return err
+		}
+		// Varian yang udah dihapus gak bisa dibeli lagi dari keranjang
+		if err := tx.Where("variant_id = ?", variant.ID).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+		return models.SyncVariantTotals(tx, product.ID)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menghapus varian"})
+	}
+	return c.JSON(fiber.Map{"message": "Varian berhasil dihapus"})
+}
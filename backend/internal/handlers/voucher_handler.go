@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/voucher"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type VoucherHandler struct {
+	DB       *gorm.DB
+	Vouchers *voucher.Service
+}
+
+func NewVoucherHandler(db *gorm.DB, vouchers *voucher.Service) *VoucherHandler {
+	return &VoucherHandler{DB: db, Vouchers: vouchers}
+}
+
+// VoucherInput dipake buat bikin & ngubah voucher. Waktu ngubah, field yang gak dikirim gak diubah.
+type VoucherInput struct {
+	Code         *string             `json:"code"`
+	Description  *string             `json:"description"`
+	Type         *models.VoucherType `json:"type"`
+	Value        *float64            `json:"value"`
+	MinSpend     *float64            `json:"min_spend"`
+	MaxDiscount  *float64            `json:"max_discount"`
+	UsageLimit   *int                `json:"usage_limit"`
+	PerUserLimit *int                `json:"per_user_limit"`
+	StartsAt     *time.Time          `json:"starts_at"`
+	EndsAt       *time.Time          `json:"ends_at"`
+	IsActive     *bool               `json:"is_active"`
+	SellerID     *uint               `json:"seller_id"` // Cuma dibaca dari admin, 0 = voucher platform
+}
+
+type VoucherCheckResponse struct {
+	Code             string  `json:"code"`
+	Description      string  `json:"description"`
+	Subtotal         float64 `json:"subtotal"`
+	EligibleSubtotal float64 `json:"eligible_subtotal"`
+	DiscountAmount   float64 `json:"discount_amount"`
+	Total            float64 `json:"total"`
+}
+
+// voucherErrorStatus nerjemahin error voucher ke status HTTP (500 = bukan salah input).
+func voucherErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, voucher.ErrNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, voucher.ErrInactive), errors.Is(err, voucher.ErrNotStarted), errors.Is(err, voucher.ErrExpired),
+		errors.Is(err, voucher.ErrUsageLimit), errors.Is(err, voucher.ErrUserLimit), errors.Is(err, voucher.ErrNotApplicable),
+		errors.Is(err, voucher.ErrMinSpend), errors.Is(err, voucher.ErrInvalid):
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
+
+func (in *VoucherInput) apply(v *models.Voucher) {
+	if in.Code != nil {
+		v.Code = voucher.NormalizeCode(*in.Code)
+	}
+	if in.Description != nil {
+		v.Description = strings.TrimSpace(*in.Description)
+	}
+	if in.Type != nil {
+		v.Type = *in.Type
+	}
+	if in.Value != nil {
+		v.Value = *in.Value
+	}
+	if in.MinSpend != nil {
+		v.MinSpend = *in.MinSpend
+	}
+	if in.MaxDiscount != nil {
+		v.MaxDiscount = *in.MaxDiscount
+	}
+	if in.UsageLimit != nil {
+		v.UsageLimit = *in.UsageLimit
+	}
+	if in.PerUserLimit != nil {
+		v.PerUserLimit = *in.PerUserLimit
+	}
+	if in.StartsAt != nil {
+		v.StartsAt = in.StartsAt
+	}
+	if in.EndsAt != nil {
+		v.EndsAt = in.EndsAt
+	}
+	if in.IsActive != nil {
+		v.IsActive = *in.IsActive
+	}
+}
+
+func (h *VoucherHandler) save(c *fiber.Ctx, v *models.Voucher, status int) error {
+	if err := voucher.Validate(v); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.DB.Save(v).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "duplicate key") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Kode voucher " + v.Code + " udah dipakai"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menyimpan voucher"})
+	}
+	return c.Status(status).JSON(v)
+}
+
+// GET /me/vouchers
+func (h *VoucherHandler) GetMyVouchers(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var vouchers []models.Voucher
+	if err := h.DB.Where("seller_id = ?", userID).Order("created_at desc").Find(&vouchers).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil voucher"})
+	}
+	return c.JSON(vouchers)
+}
+
+// POST /me/vouchers — voucher toko, cuma motong barang seller itu sendiri
+func (h *VoucherHandler) CreateMyVoucher(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	input := new(VoucherInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	v := models.Voucher{SellerID: &userID, CreatedByID: userID, IsActive: true}
+	input.apply(&v)
+	return h.save(c, &v, fiber.StatusCreated)
+}
+
+// PUT /me/vouchers/:id
+func (h *VoucherHandler) UpdateMyVoucher(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var v models.Voucher
+	if err := h.DB.Where("id = ? AND seller_id = ?", c.Params("id"), userID).First(&v).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Voucher tidak ditemukan"})
+	}
+	input := new(VoucherInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	input.apply(&v)
+	return h.save(c, &v, fiber.StatusOK)
+}
+
+// GET /admin/vouchers?scope=platform|seller
+func (h *VoucherHandler) AdminListVouchers(c *fiber.Ctx) error {
+	query := h.DB.Order("created_at desc")
+	switch c.Query("scope") {
+	case "platform":
+		query = query.Where("seller_id IS NULL")
+	case "seller":
+		query = query.Where("seller_id IS NOT NULL")
+	}
+
+	var vouchers []models.Voucher
+	if err := query.Find(&vouchers).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil voucher"})
+	}
+	return c.JSON(vouchers)
+}
+
+// POST /admin/vouchers — tanpa seller_id = voucher platform
+func (h *VoucherHandler) AdminCreateVoucher(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	input := new(VoucherInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	v := models.Voucher{CreatedByID: adminID, IsActive: true}
+	if input.SellerID != nil && *input.SellerID != 0 {
+		var seller models.User
+		if err := h.DB.Where("id = ? AND role IN ?", *input.SellerID, []string{"seller", "admin"}).First(&seller).Error; err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Seller tidak ditemukan"})
+		}
+		v.SellerID = &seller.ID
+	}
+	input.apply(&v)
+	return h.save(c, &v, fiber.StatusCreated)
+}
+
+// PUT /admin/vouchers/:id
+func (h *VoucherHandler) AdminUpdateVoucher(c *fiber.Ctx) error {
+	var v models.Voucher
+	if err := h.DB.First(&v, c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Voucher tidak ditemukan"})
+	}
+	input := new(VoucherInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+	}
+	input.apply(&v)
+	return h.save(c, &v, fiber.StatusOK)
+}
+
+// POST /vouchers/check — hitung potongan voucher ke isi keranjang sekarang
+func (h *VoucherHandler) CheckVoucher(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var input struct {
+		Code string `json:"code"`
+	}
+	if err := c.BodyParser(&input); err != nil || strings.TrimSpace(input.Code) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Kode voucher wajib diisi"})
+	}
+
+	var cart models.Cart
+	if err := h.DB.Preload("CartItems.Product").Preload("CartItems.Variant").Where("user_id = ?", userID).First(&cart).Error; err != nil || len(cart.CartItems) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Keranjang kosong"})
+	}
+
+	var subtotal float64
+	lines := make([]voucher.Line, 0, len(cart.CartItems))
+	for _, item := range cart.CartItems {
+		if item.Product == nil {
+			continue
+		}
+		price := item.Product.Price
+		if item.Variant != nil {
+			price = item.Variant.Price
+		}
+		amount := float64(item.Quantity) * price
+		subtotal += amount
+		lines = append(lines, voucher.Line{SellerID: item.Product.SellerID, Amount: amount})
+	}
+
+	quote, err := h.Vouchers.Preview(input.Code, userID, lines)
+	if err != nil {
+		status := voucherErrorStatus(err)
+		if status == fiber.StatusInternalServerError {
+			return c.Status(status).JSON(fiber.Map{"error": "Gagal ngecek voucher"})
+		}
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(VoucherCheckResponse{
+		Code:             quote.Voucher.Code,
+		Description:      quote.Voucher.Description,
+		Subtotal:         subtotal,
+		EligibleSubtotal: quote.Eligible,
+		DiscountAmount:   quote.Discount,
+		Total:            subtotal - quote.Discount,
+	})
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/stockalert"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WishlistHandler struct {
+	DB          *gorm.DB
+	StockAlerts *stockalert.Service
+}
+
+func NewWishlistHandler(db *gorm.DB, stockAlerts *stockalert.Service) *WishlistHandler {
+	return &WishlistHandler{DB: db, StockAlerts: stockAlerts}
+}
+
+type WishlistItemResponse struct {
+	ID          uint            `json:"id"`
+	ProductID   uint            `json:"product_id"`
+	AddedAt     time.Time       `json:"added_at"`
+	AlertActive bool            `json:"alert_active"` // Lagi langganan "kabari saya" buat produk ini
+	Product     ProductResponse `json:"product"`
+}
+
+type StockAlertResponse struct {
+	ID          uint      `json:"id"`
+	ProductID   uint      `json:"product_id"`
+	VariantID   uint      `json:"variant_id"`
+	ProductName string    `json:"product_name"`
+	ProductSlug string    `json:"product_slug"`
+	VariantName string    `json:"variant_name,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// activeAlertProducts = produk yang lagi user tungguin stoknya.
+func (h *WishlistHandler) activeAlertProducts(userID uint) (map[uint]bool, error) {
+	var ids []uint
+	err := h.DB.Model(&models.StockAlert{}).
+		Where("user_id = ? AND notified_at IS NULL", userID).
+		Distinct().Pluck("product_id", &ids).Error
+	active := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		active[id] = true
+	}
+	return active, err
+}
+
+// GET /me/wishlist
+func (h *WishlistHandler) GetWishlist(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var items []models.WishlistItem
+	err := h.DB.Preload("Product").Preload("Product.Seller").Preload("Product.Category").
+		Preload("Product.Images", models.OrderedImages).
+		Where("user_id = ?", userID).Order("created_at desc").Find(&items).Error
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil wishlist"})
+	}
+	alerts, err := h.activeAlertProducts(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil wishlist"})
+	}
+
+	response := make([]WishlistItemResponse, 0, len(items))
+	for _, item := range items {
+		if item.Product == nil {
+			continue // Produknya udah dihapus seller
+		}
+		response = append(response, WishlistItemResponse{
+			ID:          item.ID,
+			ProductID:   item.ProductID,
+			AddedAt:     item.CreatedAt,
+			AlertActive: alerts[item.ProductID],
+			Product:     toProductResponse(*item.Product),
+		})
+	}
+	return c.JSON(response)
+}
+
+// POST /me/wishlist
+func (h *WishlistHandler) AddToWishlist(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var input struct {
+		ProductID uint `json:"product_id"`
+	}
+	if err := c.BodyParser(&input); err != nil || input.ProductID == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "product_id wajib diisi"})
+	}
+	if err := h.DB.Select("id").First(&models.Product{}, input.ProductID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produk tidak ditemukan"})
+	}
+
+	// Udah ada di wishlist = gak apa-apa, anggep sukses aja
+	item := models.WishlistItem{UserID: userID, ProductID: input.ProductID}
+	if err := h.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&item).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menyimpan ke wishlist"})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Produk disimpan ke wishlist", "product_id": input.ProductID})
+}
+
+// DELETE /me/wishlist/:productId
+func (h *WishlistHandler) RemoveFromWishlist(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	err := h.DB.Where("user_id = ? AND product_id = ?", userID, c.Params("productId")).Delete(&models.WishlistItem{}).Error
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal menghapus dari wishlist"})
+	}
+	return c.JSON(fiber.Map{"message": "Produk dihapus dari wishlist"})
+}
+
+// GET /me/stock-alerts
+func (h *WishlistHandler) GetStockAlerts(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var alerts []models.StockAlert
+	err := h.DB.Preload("Product").
+		Where("user_id = ? AND notified_at IS NULL", userID).Order("created_at desc").Find(&alerts).Error
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil langganan stok"})
+	}
+
+	variantIDs := make([]uint, 0, len(alerts))
+	for _, a := range alerts {
+		if a.VariantID != 0 {
+			variantIDs = append(variantIDs, a.VariantID)
+		}
+	}
+	variantNames := make(map[uint]string, len(variantIDs))
+	if len(variantIDs) > 0 {
+		var variants []models.ProductVariant
+		h.DB.Select("id", "name").Where("id IN ?", variantIDs).Find(&variants)
+		for _, v := range variants {
+			variantNames[v.ID] = v.Name
+		}
+	}
+
+	response := make([]StockAlertResponse, 0, len(alerts))
+	for _, a := range alerts {
+		if a.Product == nil {
+			continue
+		}
+		response = append(response, StockAlertResponse{
+			ID:          a.ID,
+			ProductID:   a.ProductID,
+			VariantID:   a.VariantID,
+			ProductName: a.Product.Name,
+			ProductSlug: a.Product.Slug,
+			VariantName: variantNames[a.VariantID],
+			CreatedAt:   a.CreatedAt,
+		})
+	}
+	return c.JSON(response)
+}
+
+// POST /products/:id/stock-alert
+func (h *WishlistHandler) SubscribeStockAlert(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID produk tidak valid"})
+	}
+
+	var input struct {
+		VariantID uint `json:"variant_id"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Data request tidak valid"})
+		}
+	}
+
+	alert, err := h.StockAlerts.Subscribe(userID, uint(productID), input.VariantID)
+	if err != nil {
+		switch {
+		case errors.Is(err, stockalert.ErrProductNotFound), errors.Is(err, stockalert.ErrVariantNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, stockalert.ErrInStock):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mendaftarkan pengingat stok"})
+	}
+	return c.Status(fiber.StatusCreated).JSON(alert)
+}
+
+// DELETE /products/:id/stock-alert?variant_id=
+func (h *WishlistHandler) UnsubscribeStockAlert(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID produk tidak valid"})
+	}
+
+	if err := h.StockAlerts.Unsubscribe(userID, uint(productID), uint(c.QueryInt("variant_id"))); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal membatalkan pengingat stok"})
+	}
+	return c.JSON(fiber.Map{"message": "Pengingat stok dibatalin"})
+}
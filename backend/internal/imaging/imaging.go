@@ -0,0 +1,222 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// maxPixels = batas total piksel (lebar x tinggi) sebelum gambar di-decode, biar file kecil
+// yang ngaku 50000x50000 gak ngabisin RAM server.
+const maxPixels = 40_000_000
+
+// jpegQuality = kualitas JPEG hasil encode ulang.
+const jpegQuality = 85
+
+var (
+	ErrUnsupportedFormat = errors.New("format gambar tidak didukung, hanya JPEG, PNG, GIF, atau WebP")
+	ErrTooLarge          = errors.New("ukuran file gambar kebesaran")
+	ErrTooManyPixels     = errors.New("dimensi gambar kebesaran")
+	ErrCorrupt           = errors.New("file gambar rusak atau tidak bisa dibaca")
+)
+
+// Limits = batas upload gambar.
+type Limits struct {
+	MaxBytes     int64 // ukuran file maksimal
+	MaxDimension int   // lebar/tinggi maksimal (piksel)
+}
+
+// Spec = satu ukuran hasil olahan.
+type Spec struct {
+	Name    string // nama di response API
+	Suffix  string // akhiran key file
+	MaxSide int    // sisi terpanjang maksimal (gak pernah di-upscale)
+}
+
+// Variants = ukuran yang dibikin dari tiap upload, dari yang paling kecil.
+var Variants = []Spec{
+	{Name: "thumbnail", Suffix: "thumb", MaxSide: 200},
+	{Name: "medium", Suffix: "medium", MaxSide: 800},
+	{Name: "full", Suffix: "full", MaxSide: 2000},
+}
+
+// Output = satu file hasil olahan yang siap disimpen.
+type Output struct {
+	Spec
+	Data   []byte
+	Width  int
+	Height int
+}
+
+// Result = hasil Process: format asli + semua ukuran yang udah di-encode ulang.
+type Result struct {
+	SourceFormat string // format asli hasil sniff (jpeg/png/gif/webp)
+	Ext          string // ekstensi file hasil (.jpg atau .png)
+	ContentType  string
+	Width        int // dimensi asli (setelah rotasi EXIF)
+	Height       int
+	Outputs      []Output
+}
+
+// Sniff nebak format dari magic bytes, bukan dari Content-Type kiriman client.
+func Sniff(data []byte) (string, error) {
+	switch {
+	case len(data) >= 3 && data[0] == 0xFF && data[1] == 0xD8 && data[2] == 0xFF:
+		return "jpeg", nil
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return "png", nil
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return "gif", nil
+	case len(data) >= 12 && bytes.Equal(data[0:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WEBP")):
+		return "webp", nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+func decodeConfig(format string, data []byte) (image.Config, error) {
+	r := bytes.NewReader(data)
+	switch format {
+	case "jpeg":
+		return jpeg.DecodeConfig(r)
+	case "png":
+		return png.DecodeConfig(r)
+	case "gif":
+		return gif.DecodeConfig(r)
+	default:
+		return webp.DecodeConfig(r)
+	}
+}
+
+func decode(format string, data []byte) (image.Image, error) {
+	r := bytes.NewReader(data)
+	switch format {
+	case "jpeg":
+		return jpeg.Decode(r)
+	case "png":
+		return png.Decode(r)
+	case "gif":
+		// GIF animasi cuma diambil frame pertamanya
+		return gif.Decode(r)
+	default:
+		return webp.Decode(r)
+	}
+}
+
+// Process ngecek & ngolah ulang gambar upload: sniff format, cek batas ukuran & dimensi,
+// benerin rotasi dari EXIF, lalu encode ulang ke tiap ukuran di Variants.
+// Hasil encode ulang gak bawa metadata apapun, jadi EXIF/GPS dari kamera HP otomatis kebuang.
+// Gambar tanpa transparansi jadi JPEG, yang transparan jadi PNG.
+func Process(data []byte, limits Limits) (*Result, error) {
+	if limits.MaxBytes > 0 && int64(len(data)) > limits.MaxBytes {
+		return nil, ErrTooLarge
+	}
+	format, err := Sniff(data)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := decodeConfig(format, data)
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, ErrCorrupt
+	}
+	if (limits.MaxDimension > 0 && (cfg.Width > limits.MaxDimension || cfg.Height > limits.MaxDimension)) ||
+		cfg.Width*cfg.Height > maxPixels {
+		return nil, fmt.Errorf("%w: %dx%d piksel (maks %d piksel per sisi)", ErrTooManyPixels, cfg.Width, cfg.Height, limits.MaxDimension)
+	}
+
+	img, err := decode(format, data)
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+
+	// Kecilin dulu ke ukuran terbesar baru diputer, biar muter gambar 12MP gak lama
+	width, height := cfg.Width, cfg.Height
+	img = fit(img, Variants[len(Variants)-1].MaxSide)
+	if format == "jpeg" {
+		orientation := jpegOrientation(data)
+		img = applyOrientation(img, orientation)
+		if orientation >= 5 {
+			width, height = height, width
+		}
+	}
+
+	result := &Result{
+		SourceFormat: format,
+		Ext:          ".jpg",
+		ContentType:  "image/jpeg",
+		Width:        width,
+		Height:       height,
+	}
+	transparent := !isOpaque(img)
+	if transparent {
+		result.Ext, result.ContentType = ".png", "image/png"
+	}
+
+	for _, spec := range Variants {
+		resized := fit(img, spec.MaxSide)
+		var buf bytes.Buffer
+		if transparent {
+			err = png.Encode(&buf, resized)
+		} else {
+			err = jpeg.Encode(&buf, flatten(resized), &jpeg.Options{Quality: jpegQuality})
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Outputs = append(result.Outputs, Output{
+			Spec:   spec,
+			Data:   buf.Bytes(),
+			Width:  resized.Bounds().Dx(),
+			Height: resized.Bounds().Dy(),
+		})
+	}
+	return result, nil
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// fit ngecilin gambar biar sisi terpanjangnya <= maxSide (rasio tetep, gak pernah diperbesar).
+func fit(img image.Image, maxSide int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSide && h <= maxSide {
+		return img
+	}
+	if w >= h {
+		h = max(1, h*maxSide/w)
+		w = maxSide
+	} else {
+		w = max(1, w*maxSide/h)
+		h = maxSide
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// flatten naro gambar di atas latar putih buat JPEG (yang gak punya alpha).
+func flatten(img image.Image) image.Image {
+	if isOpaque(img) {
+		return img
+	}
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Over)
+	return dst
+}
@@ -0,0 +1,57 @@
+package imaging
+
+import "strings"
+
+// VariantKey = key file satu ukuran: "<base>-<suffix><ext>", mis. "1700-kaos-thumb.jpg".
+func VariantKey(base, suffix, ext string) string {
+	return base + "-" + suffix + ext
+}
+
+// splitVariant mecah key/URL hasil olahan jadi base, suffix & ekstensi.
+// ok = false kalau bukan file hasil pipeline (mis. upload lama sebelum ada varian).
+func splitVariant(s string) (base, suffix, ext string, ok bool) {
+	dot := strings.LastIndex(s, ".")
+	if dot < 0 || strings.Contains(s[dot:], "/") {
+		return "", "", "", false
+	}
+	ext = s[dot:]
+	if ext != ".jpg" && ext != ".png" {
+		return "", "", "", false
+	}
+	name := s[:dot]
+	for _, spec := range Variants {
+		if strings.HasSuffix(name, "-"+spec.Suffix) {
+			return strings.TrimSuffix(name, "-"+spec.Suffix), spec.Suffix, ext, true
+		}
+	}
+	return "", "", "", false
+}
+
+// Siblings = semua key ukuran lain dari upload yang sama (termasuk key itu sendiri).
+// Dipake biar thumbnail & medium ikut dianggep kepake / ikut kehapus bareng file full-nya.
+func Siblings(key string) []string {
+	base, _, ext, ok := splitVariant(key)
+	if !ok {
+		return []string{key}
+	}
+	keys := make([]string, 0, len(Variants))
+	for _, spec := range Variants {
+		keys = append(keys, VariantKey(base, spec.Suffix, ext))
+	}
+	return keys
+}
+
+// VariantURL ngubah URL satu ukuran jadi URL ukuran lain (name = "thumbnail"/"medium"/"full").
+// Balikin "" kalau URL-nya bukan hasil pipeline, biar yang manggil fallback ke URL asli.
+func VariantURL(url, name string) string {
+	base, _, ext, ok := splitVariant(url)
+	if !ok {
+		return ""
+	}
+	for _, spec := range Variants {
+		if spec.Name == name {
+			return VariantKey(base, spec.Suffix, ext)
+		}
+	}
+	return ""
+}
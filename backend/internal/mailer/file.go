@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9@._-]+`)
+
+// FileMailer gak beneran ngirim email: isinya di-log dan (kalau Dir diisi) disimpen jadi
+// file .eml, buat dev/CI biar link verifikasi & reset bisa diambil tanpa SMTP.
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return &FileMailer{Dir: dir, From: from}, nil
+}
+
+func (m *FileMailer) Name() string {
+	return "file"
+}
+
+func (m *FileMailer) Send(msg Message) error {
+	msg.To = sanitizeHeader(msg.To)
+	msg.Subject = sanitizeHeader(msg.Subject)
+
+	log.Printf("[MAIL] Ke: %s | Subjek: %s\n%s", msg.To, msg.Subject, msg.Body)
+	if m.Dir == "" {
+		return nil
+	}
+
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+	if err := os.WriteFile(filepath.Join(m.Dir, name), render(m.From, msg), 0o644); err != nil {
+		return fmt.Errorf("gagal nyimpen email ke %s: %w", m.Dir, err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Message = satu email teks biasa.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer = tempat ngirim email transaksional (verifikasi, reset password, dst).
+type Mailer interface {
+	Name() string
+	Send(msg Message) error
+}
+
+// render nyusun email format RFC 5322 (header + body), dipake SMTP & file mailer.
+func render(from string, msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// sanitizeHeader buang CR/LF biar isi header gak bisa nyisipin header lain.
+func sanitizeHeader(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"fmt"
+	"net/mail"
+	"net/smtp"
+)
+
+// SMTPMailer ngirim email lewat server SMTP (STARTTLS otomatis kalau server-nya dukung).
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{Host: host, Port: port, Username: username, Password: password, From: from}
+}
+
+func (m *SMTPMailer) Name() string {
+	return "smtp"
+}
+
+func (m *SMTPMailer) Send(msg Message) error {
+	msg.To = sanitizeHeader(msg.To)
+	msg.Subject = sanitizeHeader(msg.Subject)
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+	// MAIL FROM cuma boleh alamatnya doang, From boleh "Nama <alamat>"
+	envelopeFrom := m.From
+	if parsed, err := mail.ParseAddress(m.From); err == nil {
+		envelopeFrom = parsed.Address
+	}
+	addr := fmt.Sprintf("%s:%d", m.Host, m.Port)
+	if err := smtp.SendMail(addr, auth, envelopeFrom, []string{msg.To}, render(m.From, msg)); err != nil {
+		return fmt.Errorf("gagal kirim email ke %s lewat SMTP: %w", msg.To, err)
+	}
+	return nil
+}
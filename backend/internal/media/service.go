@@ -0,0 +1,273 @@
+package media
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/akhdanrgya/telu-hub/internal/imaging"
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/storage"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUploadNotOwned   = errors.New("gambar harus hasil upload kamu sendiri (upload dulu lewat /upload/image)")
+	ErrDocumentNotOwned = errors.New("dokumen harus hasil upload kamu sendiri (upload dulu lewat /me/seller-application/documents)")
+)
+
+// PrivateURLExpiry = umur signed URL file private yang dibalikin ke client.
+const PrivateURLExpiry = 15 * time.Minute
+
+// Quotas = kuota default total upload per role (byte), 0 = tanpa batas. Admin selalu tanpa batas.
+type Quotas struct {
+	User   int64
+	Seller int64
+}
+
+// Service ngurus umur file upload: kepemilikan & kuota (tabel uploads), plus file yang udah
+// gak dipake produk (galeri/image_url) atau foto profil manapun dihapus dari storage.
+type Service struct {
+	DB      *gorm.DB
+	Storage storage.Storage
+	Quotas  Quotas
+	// OrphanTTL = umur minimal file yang gak pernah dipake sebelum disapu. Ngasih waktu
+	// buat seller yang baru upload tapi belum nyimpen produknya.
+	OrphanTTL time.Duration
+	// KeyPrefix = awalan key semua file upload baru ("uploads/"), sweeper gak nyentuh file di luarnya.
+	KeyPrefix string
+	// SweepUntracked = file di bawah KeyPrefix yang gak punya catatan upload ikut dihapus.
+	// Default mati: bucket bisa aja berisi file yang ditaruh di luar app ini.
+	SweepUntracked bool
+}
+
+func NewService(db *gorm.DB, store storage.Storage, quotas Quotas, orphanTTL time.Duration, keyPrefix string, sweepUntracked bool) *Service {
+	return &Service{DB: db, Storage: store, Quotas: quotas, OrphanTTL: orphanTTL, KeyPrefix: keyPrefix, SweepUntracked: sweepUntracked}
+}
+
+// QuotaFor = kuota upload user dalam byte (0 = tanpa batas). Kuota khusus user
+// (users.upload_quota_mb) ngalahin default role-nya.
+func (s *Service) QuotaFor(user *models.User) int64 {
+	if user.UploadQuotaMB != nil {
+		return int64(*user.UploadQuotaMB) << 20
+	}
+	switch user.Role {
+	case "admin":
+		return 0
+	case "seller":
+		return s.Quotas.Seller
+	default:
+		return s.Quotas.User
+	}
+}
+
+// UsedBytes = total ukuran upload milik user.
+func (s *Service) UsedBytes(userID uint) (int64, error) {
+	var used int64
+	err := s.DB.Model(&models.Upload{}).Where("user_id = ?", userID).
+		Select("COALESCE(SUM(size), 0)").Scan(&used).Error
+	return used, err
+}
+
+// OwnedUpload nyari upload dari URL-nya (versi full), cuma kalau yang upload si user
+// (admin boleh pake upload siapa aja). File private gak bisa dipasang di tempat publik.
+func (s *Service) OwnedUpload(url string, userID uint, isAdmin bool) (*models.Upload, error) {
+	key := s.Storage.Key(url)
+	if key == "" || storage.IsPrivate(key) {
+		return nil, ErrUploadNotOwned
+	}
+	query := s.DB.Where("key = ?", key)
+	if !isAdmin {
+		query = query.Where("user_id = ?", userID)
+	}
+	var upload models.Upload
+	if err := query.First(&upload).Error; err != nil {
+		return nil, ErrUploadNotOwned
+	}
+	return &upload, nil
+}
+
+// OwnedDocument = OwnedUpload versi file private: cuma nerima upload private milik si user.
+func (s *Service) OwnedDocument(url string, userID uint) (*models.Upload, error) {
+	key := s.Storage.Key(url)
+	if key == "" || !storage.IsPrivate(key) {
+		return nil, ErrDocumentNotOwned
+	}
+	var upload models.Upload
+	if err := s.DB.Where("key = ? AND user_id = ?", key, userID).First(&upload).Error; err != nil {
+		return nil, ErrDocumentNotOwned
+	}
+	return &upload, nil
+}
+
+// ViewURL = URL buat nampilin file ke client: file private dapet signed URL yang cepet
+// kedaluwarsa, file publik tetep URL aslinya.
+func (s *Service) ViewURL(url string) string {
+	key := s.Storage.Key(url)
+	if key == "" || !storage.IsPrivate(key) {
+		return url
+	}
+	signed, err := s.Storage.SignedURL(key, PrivateURLExpiry)
+	if err != nil {
+		log.Printf("[MEDIA] WARNING: Gagal bikin signed URL %s: %v", key, err)
+		return url
+	}
+	return signed
+}
+
+// InUseKeys = key file upload yang masih dipake produk, foto profil, dokumen pengajuan
+// seller, banner toko, atau foto ulasan. URL luar (bukan file storage kita) dilewatin.
+func (s *Service) InUseKeys() (map[string]bool, error) {
+	sources := []struct {
+		model  interface{}
+		column string
+	}{
+		{&models.ProductImage{}, "url"},
+		{&models.Product{}, "image_url"},
+		{&models.User{}, "profile_image_url"},
+		{&models.SellerApplicationDocument{}, "url"},
+		{&models.Store{}, "banner_url"},
+		{&models.ReviewPhoto{}, "url"},
+	}
+	var urls []string
+	for _, src := range sources {
+		var found []string
+		if err := s.DB.Model(src.model).Where(src.column + " <> ''").Pluck(src.column, &found).Error; err != nil {
+			return nil, err
+		}
+		urls = append(urls, found...)
+	}
+
+	keys := make(map[string]bool, len(urls))
+	for _, u := range urls {
+		if key := s.Storage.Key(u); key != "" {
+			// Thumbnail & medium dari upload yang sama ikut dianggep kepake
+			for _, k := range imaging.Siblings(key) {
+				keys[k] = true
+			}
+		}
+	}
+	return keys, nil
+}
+
+// deleteKeys ngehapus semua ukuran dari satu key, balikin error pertama (sisanya tetep dicoba).
+func (s *Service) deleteKeys(key string) error {
+	var firstErr error
+	for _, k := range imaging.Siblings(key) {
+		if err := s.Storage.Delete(k); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// DeleteUpload ngehapus file (semua ukuran) lalu catatan upload-nya. Yang manggil wajib
+// udah mastiin upload-nya gak lagi dipake.
+func (s *Service) DeleteUpload(upload *models.Upload) error {
+	if err := s.deleteKeys(upload.Key); err != nil {
+		return err
+	}
+	return s.DB.Delete(upload).Error
+}
+
+// Release ngehapus file upload dari URL yang dikasih (plus catatan upload-nya) kalau udah
+// gak dipake di mana-mana. Panggil SETELAH transaksi yang ngelepas gambarnya commit.
+// Gagal hapus cuma di-log, nanti kesapu lagi sama SweepOrphans.
+func (s *Service) Release(urls ...string) {
+	var candidates []string
+	for _, u := range urls {
+		if key := s.Storage.Key(u); key != "" {
+			candidates = append(candidates, key)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	used, err := s.InUseKeys()
+	if err != nil {
+		log.Printf("[MEDIA] WARNING: Gagal ngecek pemakaian file upload: %v", err)
+		return
+	}
+	for _, key := range candidates {
+		if used[key] {
+			continue
+		}
+		if err := s.deleteKeys(key); err != nil {
+			log.Printf("[MEDIA] WARNING: Gagal hapus file %s: %v", key, err)
+			continue
+		}
+		s.DB.Where("key IN ?", imaging.Siblings(key)).Delete(&models.Upload{})
+		log.Printf("[MEDIA] File %s udah gak dipake, dihapus", key)
+	}
+}
+
+// SweepOrphans ngehapus upload yang lebih tua dari OrphanTTL dan gak dipake produk/user
+// manapun (upload yang gak jadi dipake, atau lolos dari Release). Kalau SweepUntracked nyala,
+// file di bawah KeyPrefix yang gak punya catatan upload sama sekali (sisa upload lama) ikut dihapus.
+func (s *Service) SweepOrphans() (int, error) {
+	used, err := s.InUseKeys()
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-s.OrphanTTL)
+	removed := 0
+
+	var uploads []models.Upload
+	if err := s.DB.Find(&uploads).Error; err != nil {
+		return 0, err
+	}
+	owned := make(map[string]bool, len(uploads)*len(imaging.Variants))
+	for i := range uploads {
+		u := &uploads[i]
+		if !used[u.Key] && u.CreatedAt.Before(cutoff) {
+			if err := s.DeleteUpload(u); err != nil {
+				log.Printf("[MEDIA] WARNING: Gagal hapus upload yatim %s: %v", u.Key, err)
+			} else {
+				removed++
+				continue
+			}
+		}
+		for _, k := range imaging.Siblings(u.Key) {
+			owned[k] = true
+		}
+	}
+
+	if !s.SweepUntracked {
+		return removed, nil
+	}
+	objects, err := s.Storage.List(s.KeyPrefix)
+	if err != nil {
+		return removed, err
+	}
+	for _, obj := range objects {
+		if used[obj.Key] || owned[obj.Key] || obj.ModTime.After(cutoff) {
+			continue
+		}
+		if err := s.Storage.Delete(obj.Key); err != nil {
+			log.Printf("[MEDIA] WARNING: Gagal hapus file yatim %s: %v", obj.Key, err)
+			continue
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// RunSweeper jalan terus di background, nyapu file upload yatim tiap interval.
+func (s *Service) RunSweeper(interval time.Duration) {
+	log.Printf("[MEDIA] Pembersih file upload jalan (umur minimal %s, cek tiap %s)", s.OrphanTTL, interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		count, err := s.SweepOrphans()
+		if err != nil {
+			log.Printf("[MEDIA] WARNING: Gagal nyapu file upload: %v", err)
+			continue
+		}
+		if count > 0 {
+			log.Printf("[MEDIA] %d file upload yang gak dipake dihapus", count)
+		}
+	}
+}
@@ -1,18 +1,31 @@
 package middleware
 
 import (
+	"errors"
 	"log"
 	"strings"
 
-	"github.com/akhdanrgya/telu-hub/config"
+	"github.com/akhdanrgya/telu-hub/internal/session"
 	"github.com/akhdanrgya/telu-hub/internal/utils"
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/gofiber/websocket/v2"
 )
 
+// sessions dipake Protected buat ngecek session token masih aktif, di-set sekali dari main.
+var sessions *session.Service
+
+func UseSessions(s *session.Service) {
+	sessions = s
+}
+
 func Protected() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
+
+		// Browser gak bisa nyetel header waktu buka WebSocket, jadi token boleh lewat ?token=
+		if authHeader == "" && websocket.IsWebSocketUpgrade(c) && c.Query("token") != "" {
+			authHeader = "Bearer " + c.Query("token")
+		}
 		
 		if authHeader == "" {
 			log.Println("Middleware Error: Header Authorization kosong")
@@ -33,14 +46,7 @@ func Protected() fiber.Handler {
 		
 		tokenString := tokenParts[1]
 
-		claims := &utils.JWTClaims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fiber.NewError(fiber.StatusUnauthorized, "Signing method token salah")
-			}
-			return []byte(config.GetJWTSecret()), nil
-		})
-
+		claims, err := utils.ParseToken(tokenString)
 		if err != nil {
 			log.Printf("Middleware Error: Token parse gagal: %v", err)
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -49,16 +55,25 @@ func Protected() fiber.Handler {
 			})
 		}
 
-		if !token.Valid {
-			log.Println("Middleware Error: Token tidak valid")
+		if sessions == nil {
+			log.Println("Middleware Error: Session service belom di-set (middleware.UseSessions)")
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Auth belum siap"})
+		}
+		if err := sessions.Authenticate(claims); err != nil {
+			if !errors.Is(err, session.ErrSessionRevoked) && !errors.Is(err, session.ErrTokenStale) {
+				log.Printf("Middleware Error: Gagal ngecek session: %v", err)
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal ngecek session login"})
+			}
+			log.Printf("Middleware Error: Token user %d ditolak: %v", claims.UserID, err)
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error":   "Unauthorized",
-				"message": "Token tidak valid",
+				"message": err.Error(),
 			})
 		}
 
 		c.Locals("user_id", claims.UserID)
 		c.Locals("user_role", claims.Role)
+		c.Locals("session_id", claims.SessionID)
 
 		return c.Next()
 	}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kondisi baris keranjang dibanding waktu dimasukin (lihat CartItem.Warnings).
+const (
+	CartWarningPriceUp           = "price_up"
+	CartWarningPriceDown         = "price_down"
+	CartWarningInsufficientStock = "insufficient_stock"
+	CartWarningUnavailable       = "unavailable"
+)
+
+// CartWarning = peringatan buat satu baris keranjang.
+type CartWarning struct {
+	Type      string  `json:"type"`
+	Message   string  `json:"message"`
+	OldPrice  float64 `json:"old_price,omitempty"`
+	NewPrice  float64 `json:"new_price,omitempty"`
+	Available int     `json:"available,omitempty"`
+}
+
+// UnscopedCartItems = preload isi keranjang termasuk produk/varian yang udah dihapus seller,
+// biar barisnya tetep muncul (dengan peringatan) dan gak jadi nil.
+func UnscopedCartItems(db *gorm.DB) *gorm.DB {
+	unscoped := func(db *gorm.DB) *gorm.DB { return db.Unscoped() }
+	return db.Preload("CartItems.Product", unscoped).Preload("CartItems.Variant", unscoped)
+}
+
+// Unavailable = produk atau varian di baris ini udah dihapus seller.
+func (i *CartItem) Unavailable() bool {
+	if i.Product == nil || i.Product.DeletedAt.Valid {
+		return true
+	}
+	return i.VariantID != nil && (i.Variant == nil || i.Variant.DeletedAt.Valid)
+}
+
+// CurrentPrice = harga sekarang (harga varian kalau pake varian).
+func (i *CartItem) CurrentPrice() float64 {
+	if i.Variant != nil {
+		return i.Variant.Price
+	}
+	if i.Product != nil {
+		return i.Product.Price
+	}
+	return 0
+}
+
+// Available = stok yang bisa dibeli sekarang (stok - hold).
+func (i *CartItem) Available() int {
+	if i.Variant != nil {
+		return i.Variant.Available()
+	}
+	if i.Product != nil {
+		return i.Product.Available()
+	}
+	return 0
+}
+
+// PriceChanged = harga berubah sejak terakhir dilihat buyer. Item lama tanpa snapshot gak dihitung.
+func (i *CartItem) PriceChanged() bool {
+	return i.PriceAtAdd > 0 && i.PriceAtAdd != i.CurrentPrice()
+}
+
+// Warnings ngumpulin semua peringatan baris ini. Produk yang udah gak dijual cuma dapet unavailable.
+func (i *CartItem) Warnings() []CartWarning {
+	if i.Unavailable() {
+		return []CartWarning{{Type: CartWarningUnavailable, Message: "Barang ini udah gak dijual"}}
+	}
+	var warnings []CartWarning
+	if i.PriceChanged() {
+		w := CartWarning{Type: CartWarningPriceDown, OldPrice: i.PriceAtAdd, NewPrice: i.CurrentPrice()}
+		w.Message = fmt.Sprintf("Harga turun dari Rp %.0f jadi Rp %.0f", w.OldPrice, w.NewPrice)
+		if w.NewPrice > w.OldPrice {
+			w.Type = CartWarningPriceUp
+			w.Message = fmt.Sprintf("Harga naik dari Rp %.0f jadi Rp %.0f", w.OldPrice, w.NewPrice)
+		}
+		warnings = append(warnings, w)
+	}
+	if available := i.Available(); i.Quantity > available {
+		available = max(available, 0)
+		warnings = append(warnings, CartWarning{
+			Type:      CartWarningInsufficientStock,
+			Message:   fmt.Sprintf("Stok tinggal %d, kurang dari jumlah di keranjang", available),
+			Available: available,
+		})
+	}
+	return warnings
+}
+
+// GuestCart = keranjang pengunjung yang belum login. Dipegang client lewat cart token
+// (utils.GenerateCartToken), digabung ke Cart user waktu login/daftar, dan disapu kalau
+// udah lama gak disentuh.
+type GuestCart struct {
+	gorm.Model
+	LastActiveAt time.Time `gorm:"not null;index"`
+	Items        []GuestCartItem
+}
+
+type GuestCartItem struct {
+	gorm.Model
+	GuestCartID uint    `gorm:"not null;index"`
+	ProductID   uint    `gorm:"not null"`
+	VariantID   *uint   `gorm:"index"` // nil = produk tanpa varian
+	Quantity    int     `gorm:"not null;default:1"`
+	PriceAtAdd  float64 `gorm:"not null;default:0"`
+
+	Product *Product        `gorm:"foreignKey:ProductID"`
+	Variant *ProductVariant `gorm:"foreignKey:VariantID"`
+}
+
+// UnscopedGuestCartItems = UnscopedCartItems versi keranjang tamu.
+func UnscopedGuestCartItems(db *gorm.DB) *gorm.DB {
+	unscoped := func(db *gorm.DB) *gorm.DB { return db.Unscoped() }
+	return db.Preload("Items.Product", unscoped).Preload("Items.Variant", unscoped)
+}
+
+// AsCartItem nyalin item tamu ke bentuk CartItem biar bisa pake Warnings/Available dkk.
+func (i *GuestCartItem) AsCartItem() CartItem {
+	return CartItem{
+		Model:      i.Model,
+		ProductID:  i.ProductID,
+		VariantID:  i.VariantID,
+		Quantity:   i.Quantity,
+		PriceAtAdd: i.PriceAtAdd,
+		Product:    i.Product,
+		Variant:    i.Variant,
+	}
+}
+
+// AsCart = keranjang tamu dalam bentuk Cart (UserID 0), buat dibalikin dengan format yang sama.
+func (g *GuestCart) AsCart() *Cart {
+	cart := &Cart{Model: g.Model, CartItems: make([]CartItem, 0, len(g.Items))}
+	for i := range g.Items {
+		cart.CartItems = append(cart.CartItems, g.Items[i].AsCartItem())
+	}
+	return cart
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+type ChatRoom struct {
+	ID        uint `gorm:"primaryKey" json:"id"`
+	BuyerID   uint `gorm:"not null;uniqueIndex:idx_chat_room_pair" json:"buyer_id"`
+	SellerID  uint `gorm:"not null;uniqueIndex:idx_chat_room_pair;index" json:"seller_id"`
+	ProductID uint `gorm:"not null;default:0;uniqueIndex:idx_chat_room_pair" json:"product_id"` // 0 = chat umum (gak terikat produk)
+	OrderID   uint `gorm:"not null;default:0;uniqueIndex:idx_chat_room_pair" json:"order_id"`   // 0 = gak terikat order
+
+	LastMessageAt *time.Time `json:"last_message_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	Buyer  *User `gorm:"foreignKey:BuyerID" json:"-"`
+	Seller *User `gorm:"foreignKey:SellerID" json:"-"`
+}
+
+// IsParticipant ngecek apakah user ini buyer atau seller di room.
+func (r *ChatRoom) IsParticipant(userID uint) bool {
+	return r.BuyerID == userID || r.SellerID == userID
+}
+
+// OtherParticipant balikin ID lawan bicara dari userID.
+func (r *ChatRoom) OtherParticipant(userID uint) uint {
+	if r.BuyerID == userID {
+		return r.SellerID
+	}
+	return r.BuyerID
+}
+
+type ChatMessage struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	RoomID    uint      `gorm:"not null;index" json:"room_id"`
+	SenderID  uint      `gorm:"not null" json:"sender_id"`
+	Content   string    `gorm:"type:text;not null" json:"content"`
+	IsRead    bool      `gorm:"default:false" json:"is_read"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Room   *ChatRoom `gorm:"foreignKey:RoomID" json:"-"`
+	Sender *User     `gorm:"foreignKey:SenderID" json:"-"`
+}
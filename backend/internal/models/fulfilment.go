@@ -0,0 +1,206 @@
+package models
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+)
+
+type FulfilmentType string
+
+const (
+	FulfilmentMeetup  FulfilmentType = "meetup"  // Ketemuan di titik kampus
+	FulfilmentPickup  FulfilmentType = "pickup"  // Buyer ambil sendiri ke lokasi seller
+	FulfilmentCourier FulfilmentType = "courier" // Dikirim kurir ke alamat buyer
+)
+
+func (t FulfilmentType) IsValid() bool {
+	return t == FulfilmentMeetup || t == FulfilmentPickup || t == FulfilmentCourier
+}
+
+// NeedsAddress = cuma kurir yang butuh alamat pengiriman buyer.
+func (t FulfilmentType) NeedsAddress() bool {
+	return t == FulfilmentCourier
+}
+
+// UsesHandoverCode = serah terima langsung dikonfirmasi pake kode yang dipegang buyer.
+func (t FulfilmentType) UsesHandoverCode() bool {
+	return t == FulfilmentMeetup || t == FulfilmentPickup
+}
+
+type FeeType string
+
+const (
+	FeeFlat   FeeType = "flat"   // Ongkir tetap per sub-order
+	FeeWeight FeeType = "weight" // Ongkir dari tabel berat
+)
+
+var ErrTooHeavy = errors.New("paket terlalu berat buat metode pengiriman ini")
+
+// WeightRate = satu baris tabel ongkir: paket sampai MaxGrams kena Fee.
+type WeightRate struct {
+	MaxGrams int     `json:"max_grams"`
+	Fee      float64 `json:"fee"`
+}
+
+// WeightRates disimpen sebagai JSON di satu kolom, urut dari yang paling ringan.
+type WeightRates []WeightRate
+
+func (r WeightRates) Value() (driver.Value, error) {
+	if r == nil {
+		return "[]", nil
+	}
+	raw, err := json.Marshal(r)
+	return string(raw), err
+}
+
+func (r *WeightRates) Scan(src interface{}) error {
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		*r = WeightRates{}
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("tipe tabel ongkir gak dikenal: %T", src)
+	}
+	return json.Unmarshal(raw, r)
+}
+
+// Validate ngecek tabel ongkir gak kosong, beratnya naik terus, dan ongkirnya gak negatif.
+func (r WeightRates) Validate() error {
+	if len(r) == 0 {
+		return errors.New("tabel ongkir berat minimal satu baris")
+	}
+	prev := 0
+	for _, rate := range r {
+		if rate.MaxGrams <= prev {
+			return errors.New("batas berat di tabel ongkir harus naik dan lebih dari 0")
+		}
+		if rate.Fee < 0 || rate.Fee != math.Trunc(rate.Fee) {
+			return errors.New("ongkir harus rupiah bulat dan gak boleh negatif")
+		}
+		prev = rate.MaxGrams
+	}
+	return nil
+}
+
+// FulfilmentMethod = cara seller nyerahin barang ke buyer. Seller boleh punya beberapa,
+// buyer milih satu per seller waktu checkout.
+type FulfilmentMethod struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	SellerID    uint           `gorm:"not null;index" json:"seller_id"`
+	Type        FulfilmentType `gorm:"type:varchar(20);not null" json:"type"`
+	Name        string         `gorm:"size:100;not null" json:"name"` // Mis. "COD depan TULT" atau "JNE REG"
+	Description string         `gorm:"type:text" json:"description"`
+	Location    string         `gorm:"size:255" json:"location"` // Titik ketemu / lokasi ambil, kosong buat kurir
+	FeeType     FeeType        `gorm:"type:varchar(10);not null;default:'flat'" json:"fee_type"`
+	FlatFee     float64        `gorm:"not null;default:0" json:"flat_fee"`
+	WeightRates WeightRates    `gorm:"type:text" json:"weight_rates"`
+	IsActive    bool           `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// Validate ngecek isian metode sebelum disimpen.
+func (m *FulfilmentMethod) Validate() error {
+	if !m.Type.IsValid() {
+		return errors.New("tipe pengiriman harus meetup, pickup, atau courier")
+	}
+	if strings.TrimSpace(m.Name) == "" {
+		return errors.New("nama metode pengiriman wajib diisi")
+	}
+	if m.Type.UsesHandoverCode() && strings.TrimSpace(m.Location) == "" {
+		return errors.New("lokasi ketemuan / ambil barang wajib diisi")
+	}
+	switch m.FeeType {
+	case FeeFlat:
+		if m.FlatFee < 0 || m.FlatFee != math.Trunc(m.FlatFee) {
+			return errors.New("ongkir harus rupiah bulat dan gak boleh negatif")
+		}
+	case FeeWeight:
+		if m.Type != FulfilmentCourier {
+			return errors.New("ongkir berdasarkan berat cuma buat kurir")
+		}
+		return m.WeightRates.Validate()
+	default:
+		return errors.New("tipe ongkir harus flat atau weight")
+	}
+	return nil
+}
+
+// Fee = ongkir buat paket seberat weightGrams.
+func (m *FulfilmentMethod) Fee(weightGrams int) (float64, error) {
+	if m.FeeType != FeeWeight {
+		return m.FlatFee, nil
+	}
+	for _, rate := range m.WeightRates {
+		if weightGrams <= rate.MaxGrams {
+			return rate.Fee, nil
+		}
+	}
+	return 0, ErrTooHeavy
+}
+
+// DefaultFulfilmentMethod = dipake kalau seller belum ngatur metode pengiriman sama sekali:
+// ketemuan gratis di titik ambil tokonya. ID-nya 0 karena gak disimpen.
+func DefaultFulfilmentMethod(store *Store) FulfilmentMethod {
+	method := FulfilmentMethod{
+		Type:     FulfilmentMeetup,
+		Name:     "Ketemuan di kampus",
+		Location: "Diatur lewat chat dengan seller",
+		FeeType:  FeeFlat,
+		IsActive: true,
+	}
+	if store != nil {
+		method.SellerID = store.SellerID
+		if strings.TrimSpace(store.PickupLocation) != "" {
+			method.Location = store.PickupLocation
+		}
+	}
+	return method
+}
+
+// NewHandoverCode = kode 6 digit yang ditunjukin buyer waktu serah terima barang.
+func NewHandoverCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// Address = alamat pengiriman yang disimpen buyer buat checkout pake kurir.
+type Address struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        uint      `gorm:"not null;index" json:"user_id"`
+	Label         string    `gorm:"size:50" json:"label"` // Mis. "Kos" atau "Rumah"
+	RecipientName string    `gorm:"size:100;not null" json:"recipient_name"`
+	Phone         string    `gorm:"size:20;not null" json:"phone"`
+	Street        string    `gorm:"type:text;not null" json:"street"`
+	City          string    `gorm:"size:100;not null" json:"city"`
+	PostalCode    string    `gorm:"size:10" json:"postal_code"`
+	Notes         string    `gorm:"type:text" json:"notes"`
+	IsDefault     bool      `gorm:"not null;default:false" json:"is_default"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Format = alamat dalam satu teks, disalin ke sub-order biar gak ikut berubah kalau alamatnya diedit.
+func (a *Address) Format() string {
+	text := fmt.Sprintf("%s (%s)\n%s, %s %s", a.RecipientName, a.Phone, a.Street, a.City, a.PostalCode)
+	text = strings.TrimSpace(text)
+	if a.Notes != "" {
+		text += "\nCatatan: " + a.Notes
+	}
+	return text
+}
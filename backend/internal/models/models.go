@@ -1,6 +1,9 @@
 package models
 
 import (
+	"math"
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -11,6 +14,10 @@ type User struct {
 	Password string `gorm:"size:255;not null"`
 	Role     string `gorm:"size:50;not null;default:'user'"`
 	ProfileImageURL string `gorm:"size:255"`
+	UploadQuotaMB   *int   // Kuota upload khusus user ini (MB), nil = ikut default role-nya
+	// Access token yang diterbitin sebelum waktu ini ditolak (role diganti / password di-reset)
+	TokensValidAfter *time.Time
+	EmailVerifiedAt  *time.Time // nil = email belum diverifikasi
 
 	Products []Product `gorm:"foreignKey:SellerID"`
 	Cart     Cart      `gorm:"foreignKey:UserID"`
@@ -24,15 +31,31 @@ type Product struct {
 	Description string  `gorm:"type:text"`
 	Price       float64 `gorm:"not null"`
 	Stock       int     `gorm:"not null;default:0"`
+	Reserved    int     `gorm:"not null;default:0"` // Stok yang lagi di-hold order pending
+	WeightGrams int     `gorm:"not null;default:0"` // Berat per item, dipake buat ongkir kurir
 	ImageURL    string  `gorm:"size:255"`
+	// Ringkasan ulasan yang tampil, didenormalisasi biar bisa di-sort (lihat SyncProductRating)
+	RatingAverage float64 `gorm:"not null;default:0"`
+	RatingCount   int     `gorm:"not null;default:0"`
 
 	SellerID uint `gorm:"not null"`
 	Seller   *User `gorm:"foreignKey:SellerID"`
 
 	CategoryID uint     `json:"category_id"`
 	Category   Category `json:"category" gorm:"foreignKey:CategoryID"`
+
+	Variants []ProductVariant `gorm:"foreignKey:ProductID"`
+	Images   []ProductImage   `gorm:"foreignKey:ProductID"`
 }
 
+// ProductSearchExpr = dokumen full-text produk (nama + deskripsi). Dipake buat index GIN
+// idx_products_search sekaligus query pencarian, jadi dua-duanya harus pake expression ini.
+const ProductSearchExpr = "to_tsvector('simple', coalesce(name, '') || ' ' || coalesce(description, ''))"
+
+// Available = stok yang masih bisa dibeli (stok fisik dikurangi yang lagi di-hold).
+func (p *Product) Available() int {
+	return p.Stock - p.Reserved
+}
 
 type Cart struct {
 	gorm.Model
@@ -47,19 +70,28 @@ type CartItem struct {
 	gorm.Model
 	CartID    uint `gorm:"not null"`
 	ProductID uint `gorm:"not null"`
+	VariantID *uint `gorm:"index"` // nil = produk tanpa varian
 	Quantity  int  `gorm:"not null;default:1"`
+	PriceAtAdd float64 `gorm:"not null;default:0"` // Harga yang terakhir dilihat buyer, 0 = item lama sebelum ada snapshot
 
 	Cart    *Cart    `gorm:"foreignKey:CartID"`
 	Product *Product `gorm:"foreignKey:ProductID"`
+	Variant *ProductVariant `gorm:"foreignKey:VariantID"`
 }
 
 
 type Order struct {
 	gorm.Model
 	UserID      uint    `gorm:"not null"`
-	TotalAmount float64 `gorm:"not null"`
-	Status      string  `gorm:"size:50;not null;default:'pending'"`
+	TotalAmount float64 `gorm:"not null"` // Yang dibayar buyer: barang - voucher + ongkir
+	DiscountAmount float64 `gorm:"not null;default:0"`
+	ShippingTotal  float64 `gorm:"not null;default:0"` // Total ongkir semua sub-order
+	VoucherCode    string  `gorm:"size:50"`
+	Status      OrderStatus `gorm:"size:50;not null;default:'pending'"`
+	PaymentRef    string `gorm:"size:100;index"` // order_id yang dikirim ke payment gateway (TELUHUB-<id>-<ts>)
+	PaymentStatus string `gorm:"size:50"`        // transaction_status terakhir yang udah diproses dari gateway
 	OrderItems  []OrderItem
+	SellerOrders []SellerOrder
 
 	User *User `gorm:"foreignKey:UserID"`
 }
@@ -68,12 +100,44 @@ type Order struct {
 type OrderItem struct {
 	gorm.Model
 	OrderID     uint    `gorm:"not null"`
+	SellerOrderID *uint `gorm:"index"` // nil buat order lama sebelum ada sub-order (diisi backfill)
 	ProductID   uint    `gorm:"not null"`
+	VariantID   *uint   `gorm:"index"`      // nil = produk tanpa varian
+	VariantName string  `gorm:"size:255"` // Nama varian waktu checkout, biar gak ikut berubah
 	Quantity    int     `gorm:"not null"`
 	PriceAtTime float64 `gorm:"not null"`
+	RefundedQuantity int `gorm:"not null;default:0"` // Jumlah yang udah di-refund / dibatalin setelah bayar
+	DiscountAmount   float64 `gorm:"not null;default:0"` // Bagian potongan voucher yang jatuh ke baris ini
 
 	Order   *Order   `gorm:"foreignKey:OrderID"`
 	Product *Product `gorm:"foreignKey:ProductID"`
+	Variant *ProductVariant `gorm:"foreignKey:VariantID"`
+}
+
+// ProductName = nama produk buat ditampilin/di notifikasi, plus nama variannya kalau ada.
+func (i *OrderItem) ProductName() string {
+	if i.Product == nil {
+		return ""
+	}
+	if i.VariantName != "" {
+		return i.Product.Name + " - " + i.VariantName
+	}
+	return i.Product.Name
+}
+
+// paidFor = yang dibayar buyer buat n unit pertama baris ini (udah dipotong voucher), dibulatin ke bawah.
+func (i *OrderItem) paidFor(n int) float64 {
+	if i.Quantity == 0 {
+		return 0
+	}
+	net := i.PriceAtTime*float64(i.Quantity) - i.DiscountAmount
+	return math.Floor(net * float64(n) / float64(i.Quantity))
+}
+
+// RefundAmount = dana yang dibalikin buat qty unit berikutnya yang di-refund. Diitung dari
+// selisih kumulatif, jadi kalau semua unit udah di-refund totalnya pas sama yang dibayar.
+func (i *OrderItem) RefundAmount(qty int) float64 {
+	return i.paidFor(i.RefundedQuantity+qty) - i.paidFor(i.RefundedQuantity)
 }
 
 type Category struct {
@@ -8,6 +8,9 @@ const (
 	NotificationTypeOrder NotificationType = "order"
 	NotificationTypeChat  NotificationType = "chat"
 	NotificationTypeInfo  NotificationType = "info"
+	NotificationTypeSeller NotificationType = "seller" // Status pengajuan seller
+	NotificationTypeReview NotificationType = "review" // Ulasan baru / balasan seller
+	NotificationTypeStock  NotificationType = "stock"  // Produk incaran udah ada stok lagi
 )
 
 type Notification struct {
@@ -0,0 +1,99 @@
+package models
+
+import "time"
+
+type OrderStatus string
+
+const (
+	OrderStatusPending    OrderStatus = "pending"    // Nunggu pembayaran
+	OrderStatusPaid       OrderStatus = "paid"       // Lunas, nunggu diproses seller
+	OrderStatusProcessing OrderStatus = "processing" // Lagi disiapin seller
+	OrderStatusShipped    OrderStatus = "shipped"    // Udah dikirim / dibawa ke titik temu
+	OrderStatusDelivered  OrderStatus = "delivered"  // Udah sampe di buyer
+	OrderStatusCompleted  OrderStatus = "completed"  // Buyer konfirmasi selesai
+	OrderStatusCancelled  OrderStatus = "cancelled"
+	OrderStatusRefunded   OrderStatus = "refunded"
+	OrderStatusFailed     OrderStatus = "failed" // Pembayaran gagal / expired
+)
+
+// orderTransitions = daftar status tujuan yang sah dari tiap status.
+// Status yang gak ada di map ini dianggap final.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:    {OrderStatusPaid, OrderStatusCancelled, OrderStatusFailed},
+	OrderStatusPaid:       {OrderStatusProcessing, OrderStatusCancelled, OrderStatusRefunded},
+	OrderStatusProcessing: {OrderStatusShipped, OrderStatusCancelled, OrderStatusRefunded},
+	OrderStatusShipped:    {OrderStatusDelivered, OrderStatusRefunded},
+	OrderStatusDelivered:  {OrderStatusCompleted, OrderStatusRefunded},
+	// Pembayaran yang telat masuk setelah order keburu expired masih boleh dianggap lunas
+	OrderStatusFailed: {OrderStatusPaid},
+}
+
+// orderFlow = urutan status normal dari checkout sampai selesai.
+var orderFlow = []OrderStatus{
+	OrderStatusPending, OrderStatusPaid, OrderStatusProcessing,
+	OrderStatusShipped, OrderStatusDelivered, OrderStatusCompleted,
+}
+
+// FlowRank = posisi status di alur normal, -1 kalau status cabang (cancelled/refunded/failed).
+func (s OrderStatus) FlowRank() int {
+	for i, status := range orderFlow {
+		if status == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// NextInFlow = status berikutnya di alur normal (kosong kalau udah di ujung / di cabang).
+func (s OrderStatus) NextInFlow() OrderStatus {
+	rank := s.FlowRank()
+	if rank < 0 || rank+1 >= len(orderFlow) {
+		return ""
+	}
+	return orderFlow[rank+1]
+}
+
+// IsClosed = order/sub-order udah keluar dari alur (batal, refund, gagal bayar).
+func (s OrderStatus) IsClosed() bool {
+	return s == OrderStatusCancelled || s == OrderStatusRefunded || s == OrderStatusFailed
+}
+
+func (s OrderStatus) IsValid() bool {
+	switch s {
+	case OrderStatusPending, OrderStatusPaid, OrderStatusProcessing, OrderStatusShipped,
+		OrderStatusDelivered, OrderStatusCompleted, OrderStatusCancelled, OrderStatusRefunded, OrderStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (s OrderStatus) CanTransitionTo(to OrderStatus) bool {
+	for _, next := range orderTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (s OrderStatus) NextStatuses() []OrderStatus {
+	return orderTransitions[s]
+}
+
+type OrderStatusHistory struct {
+	ID            uint        `gorm:"primaryKey" json:"id"`
+	OrderID       uint        `gorm:"not null;index" json:"order_id"`
+	SellerOrderID uint        `gorm:"not null;default:0;index" json:"seller_order_id"` // 0 = status order induk
+	FromStatus    OrderStatus `gorm:"type:varchar(50);not null" json:"from_status"`
+	ToStatus      OrderStatus `gorm:"type:varchar(50);not null" json:"to_status"`
+	ActorID       *uint       `json:"actor_id"`                                    // nil kalau yang ngubah sistem (webhook, worker)
+	ActorRole     string      `gorm:"type:varchar(20);not null" json:"actor_role"` // buyer, seller, admin, system
+	Reason        string      `gorm:"type:text" json:"reason"`
+	CreatedAt     time.Time   `json:"created_at"`
+
+	Order *Order `gorm:"foreignKey:OrderID" json:"-"`
+}
+
+func (OrderStatusHistory) TableName() string {
+	return "order_status_history"
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+type PaymentEventState string
+
+const (
+	PaymentEventReceived  PaymentEventState = "received"  // Baru dicatat, belum diproses
+	PaymentEventProcessed PaymentEventState = "processed" // Udah ngubah order
+	PaymentEventSkipped   PaymentEventState = "skipped"   // Valid tapi gak ngubah apa-apa (telat, duplikat, kalah prioritas)
+	PaymentEventFailed    PaymentEventState = "failed"    // Gagal diproses, bakal dicoba lagi kalau gateway ngirim ulang
+)
+
+// Asal notifikasi yang dicatat di payment_events.
+const (
+	PaymentSourceWebhook   = "webhook"   // Dikirim gateway lewat HTTP notification
+	PaymentSourceReconcile = "reconcile" // Hasil polling status ke gateway sama reconciler
+)
+
+// PaymentEvent = log semua notifikasi dari payment gateway. Satu baris per
+// (transaction_id, transaction_status), jadi notifikasi yang dikirim ulang gak diproses dua kali.
+type PaymentEvent struct {
+	ID                uint              `gorm:"primaryKey" json:"id"`
+	OrderID           uint              `gorm:"not null;default:0;index" json:"order_id"` // 0 kalau order-nya gak ketemu
+	OrderRef          string            `gorm:"size:100;not null;index" json:"order_ref"`
+	Gateway           string            `gorm:"size:20;not null" json:"gateway"`
+	Source            string            `gorm:"size:20;not null;default:'webhook'" json:"source"`
+	TransactionID     string            `gorm:"size:100;not null;uniqueIndex:idx_payment_event_tx_status" json:"transaction_id"`
+	TransactionStatus string            `gorm:"size:50;not null;uniqueIndex:idx_payment_event_tx_status" json:"transaction_status"`
+	StatusCode        string            `gorm:"size:10" json:"status_code"`
+	GrossAmount       string            `gorm:"size:50" json:"gross_amount"`
+	PaymentType       string            `gorm:"size:50" json:"payment_type"`
+	FraudStatus       string            `gorm:"size:20" json:"fraud_status"`
+	Payload           string            `gorm:"type:text" json:"payload"`
+	State             PaymentEventState `gorm:"type:varchar(20);not null;default:'received';index" json:"state"`
+	Note              string            `gorm:"type:text" json:"note"`
+	Attempts          int               `gorm:"not null;default:0" json:"attempts"`
+	ProcessedAt       *time.Time        `json:"processed_at"`
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+}
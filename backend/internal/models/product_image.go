@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProductImage = satu foto di galeri produk. Urutan tampil ikut Position, dan tepat satu
+// foto per produk jadi foto utama (IsPrimary) yang URL-nya dicerminin ke Product.ImageURL
+// biar kartu produk, keranjang & order yang cuma baca image_url tetep jalan.
+// Dihapus permanen (bukan soft delete) biar gampang ngecek file upload masih dipake atau nggak.
+type ProductImage struct {
+	ID        uint   `gorm:"primarykey"`
+	ProductID uint   `gorm:"not null;index"`
+	URL       string `gorm:"size:255;not null"`
+	AltText   string `gorm:"size:255"`
+	Position  int    `gorm:"not null;default:0"`
+	IsPrimary bool   `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// OrderedImages = preload galeri sesuai urutan tampil, pake: Preload("Images", OrderedImages).
+func OrderedImages(db *gorm.DB) *gorm.DB {
+	return db.Order("position asc, id asc")
+}
+
+// SyncPrimaryImage mastiin produk punya tepat satu foto utama (kalau galerinya gak kosong)
+// dan nyamain Product.ImageURL dengan foto itu. Kalau belum ada yang primary, foto pertama
+// yang dipilih; kalau galerinya kosong, image_url dikosongin.
+// Panggil tiap kali foto ditambah/dihapus/diubah primary-nya.
+func SyncPrimaryImage(tx *gorm.DB, productID uint) error {
+	var images []ProductImage
+	if err := OrderedImages(tx.Where("product_id = ?", productID)).Find(&images).Error; err != nil {
+		return err
+	}
+
+	primary := ""
+	primaryID := uint(0)
+	for _, img := range images {
+		if img.IsPrimary {
+			primary, primaryID = img.URL, img.ID
+			break
+		}
+	}
+	if primaryID == 0 && len(images) > 0 {
+		primary, primaryID = images[0].URL, images[0].ID
+	}
+
+	if primaryID != 0 {
+		err := tx.Model(&ProductImage{}).Where("product_id = ?", productID).
+			Update("is_primary", gorm.Expr("id = ?", primaryID)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Model(&Product{}).Where("id = ?", productID).Update("image_url", primary).Error
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+type RefundStatus string
+
+const (
+	RefundPending   RefundStatus = "pending"   // Lagi diminta ke payment gateway
+	RefundSucceeded RefundStatus = "succeeded" // Dana udah dibalikin, stok udah disesuaiin
+	RefundFailed    RefundStatus = "failed"    // Ditolak gateway, jumlah item dibalikin lagi
+)
+
+// Refund = satu pengembalian dana (full/parsial) buat sebagian item order.
+type Refund struct {
+	ID            uint         `gorm:"primaryKey" json:"id"`
+	OrderID       uint         `gorm:"not null;index" json:"order_id"`
+	RefundKey     string       `gorm:"size:100;not null;uniqueIndex" json:"refund_key"`
+	Amount        float64      `gorm:"not null" json:"amount"`
+	Reason        string       `gorm:"type:text" json:"reason"`
+	Status        RefundStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	GatewayStatus string       `gorm:"size:50" json:"gateway_status"`
+	FailureReason string       `gorm:"type:text" json:"failure_reason,omitempty"`
+	Restock       bool         `gorm:"not null" json:"restock"`
+	RequestedByID *uint        `json:"requested_by_id"`
+	RequestedRole string       `gorm:"size:20;not null" json:"requested_role"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+
+	Items []RefundItem `gorm:"foreignKey:RefundID" json:"items"`
+	Order *Order       `gorm:"foreignKey:OrderID" json:"-"`
+}
+
+// RefundItem = satu baris refund: barang dari order, atau ongkir sub-order yang dibatalin
+// (OrderItemID nil, ProductID 0, Quantity 0).
+type RefundItem struct {
+	ID            uint    `gorm:"primaryKey" json:"id"`
+	RefundID      uint    `gorm:"not null;index" json:"refund_id"`
+	OrderItemID   *uint   `gorm:"index" json:"order_item_id"`
+	SellerOrderID uint    `gorm:"not null;index" json:"seller_order_id"`
+	ProductID     uint    `gorm:"not null" json:"product_id"`
+	VariantID     *uint   `json:"variant_id,omitempty"`
+	Quantity      int     `gorm:"not null" json:"quantity"`
+	Amount        float64 `gorm:"not null" json:"amount"`
+
+	OrderItem *OrderItem `gorm:"foreignKey:OrderItemID" json:"-"`
+}
+
+// IsShipping = baris ongkir, bukan barang.
+func (i RefundItem) IsShipping() bool {
+	return i.OrderItemID == nil
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type ReservationStatus string
+
+const (
+	ReservationHeld      ReservationStatus = "held"      // Stok lagi di-hold, nunggu pembayaran
+	ReservationCommitted ReservationStatus = "committed" // Udah dibayar, stok fisik beneran dikurangin
+	ReservationReleased  ReservationStatus = "released"  // Batal/expired, stok dibalikin
+)
+
+type StockReservation struct {
+	ID            uint              `gorm:"primaryKey" json:"id"`
+	OrderID       uint              `gorm:"not null;index" json:"order_id"`
+	ProductID     uint              `gorm:"not null;index" json:"product_id"`
+	VariantID     *uint             `gorm:"index" json:"variant_id,omitempty"`
+	Quantity      int               `gorm:"not null" json:"quantity"`
+	Status        ReservationStatus `gorm:"type:varchar(20);not null;default:'held';index" json:"status"`
+	ExpiresAt     time.Time         `gorm:"not null;index" json:"expires_at"`
+	ReleaseReason string            `gorm:"size:50" json:"release_reason,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+
+	Order   *Order   `gorm:"foreignKey:OrderID" json:"-"`
+	Product *Product `gorm:"foreignKey:ProductID" json:"-"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Review = ulasan pembeli buat produk yang beneran dia beli. Satu baris order cuma
+// bisa diulas sekali; seller boleh bales sekali, admin bisa nyembunyiin ulasan kasar.
+type Review struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	ProductID   uint   `gorm:"not null;index" json:"product_id"`
+	UserID      uint   `gorm:"not null;index" json:"user_id"`
+	OrderItemID uint   `gorm:"not null;uniqueIndex" json:"order_item_id"`
+	Rating      int    `gorm:"not null" json:"rating"` // 1-5
+	Comment     string `gorm:"type:text" json:"comment"`
+
+	SellerReply     string     `gorm:"type:text" json:"seller_reply,omitempty"`
+	SellerRepliedAt *time.Time `json:"seller_replied_at,omitempty"`
+
+	IsHidden     bool       `gorm:"not null;index" json:"is_hidden"` // Disembunyiin admin, gak ikut dihitung rating
+	HiddenReason string     `gorm:"type:text" json:"hidden_reason,omitempty"`
+	HiddenByID   *uint      `json:"hidden_by_id,omitempty"`
+	HiddenAt     *time.Time `json:"hidden_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Photos    []ReviewPhoto `gorm:"foreignKey:ReviewID" json:"photos"`
+	User      *User         `gorm:"foreignKey:UserID" json:"-"`
+	Product   *Product      `gorm:"foreignKey:ProductID" json:"-"`
+	OrderItem *OrderItem    `gorm:"foreignKey:OrderItemID" json:"-"`
+}
+
+// ReviewPhoto = foto lampiran ulasan, hasil upload si pengulas.
+type ReviewPhoto struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	ReviewID uint   `gorm:"not null;index" json:"review_id"`
+	URL      string `gorm:"size:255;not null" json:"url"`
+	Position int    `gorm:"not null;default:0" json:"position"`
+}
+
+// OrderedReviewPhotos dipake di Preload("Photos", OrderedReviewPhotos) biar urutannya konsisten.
+func OrderedReviewPhotos(db *gorm.DB) *gorm.DB {
+	return db.Order("position asc, id asc")
+}
+
+// SyncProductRating ngitung ulang rata-rata & jumlah rating produk dari ulasan yang gak
+// disembunyiin. Panggil di transaksi yang sama tiap ulasan dibikin/diubah/disembunyiin.
+func SyncProductRating(tx *gorm.DB, productID uint) error {
+	var totals struct {
+		Average float64
+		Count   int
+	}
+	err := tx.Model(&Review{}).
+		Select("COALESCE(AVG(rating), 0) AS average, COUNT(*) AS count").
+		Where("product_id = ? AND is_hidden = ?", productID, false).
+		Scan(&totals).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&Product{}).Where("id = ?", productID).Updates(map[string]interface{}{
+		"rating_average": totals.Average,
+		"rating_count":   totals.Count,
+	}).Error
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+type SellerApplicationStatus string
+
+const (
+	ApplicationPending  SellerApplicationStatus = "pending"  // Nunggu direview admin
+	ApplicationApproved SellerApplicationStatus = "approved" // Disetujui, role user udah jadi seller
+	ApplicationRejected SellerApplicationStatus = "rejected" // Ditolak, user boleh ngajuin lagi
+)
+
+// SellerApplication = pengajuan user biasa buat jadi seller. Satu user cuma boleh punya
+// satu pengajuan pending; role baru berubah waktu admin nyetujuin.
+type SellerApplication struct {
+	ID              uint                    `gorm:"primaryKey" json:"id"`
+	UserID          uint                    `gorm:"not null;index" json:"user_id"`
+	StoreName       string                  `gorm:"size:100;not null" json:"store_name"`
+	Description     string                  `gorm:"type:text" json:"description"`
+	StudentID       string                  `gorm:"size:30;not null" json:"student_id"` // NIM
+	Status          SellerApplicationStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	RejectionReason string                  `gorm:"type:text" json:"rejection_reason,omitempty"`
+	ReviewedByID    *uint                   `json:"reviewed_by_id,omitempty"`
+	ReviewedAt      *time.Time              `json:"reviewed_at,omitempty"`
+	CreatedAt       time.Time               `json:"created_at"`
+	UpdatedAt       time.Time               `json:"updated_at"`
+
+	Documents []SellerApplicationDocument `gorm:"foreignKey:ApplicationID" json:"documents"`
+	User      *User                       `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// SellerApplicationDocument = dokumen pendukung (KTM, foto lapak, dst), berupa hasil upload si pengaju.
+type SellerApplicationDocument struct {
+	ID            uint   `gorm:"primaryKey" json:"id"`
+	ApplicationID uint   `gorm:"not null;index" json:"application_id"`
+	URL           string `gorm:"size:255;not null" json:"url"`
+	Label         string `gorm:"size:100" json:"label"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SellerOrder = bagian order milik satu seller. Buyer tetep bayar sekali per Order,
+// tapi tiap seller ngurus pengiriman & status sub-order-nya sendiri.
+type SellerOrder struct {
+	gorm.Model
+	OrderID  uint        `gorm:"not null;index"`
+	SellerID uint        `gorm:"not null;index"`
+	Status   OrderStatus `gorm:"size:50;not null;default:'pending'"`
+	Subtotal float64     `gorm:"not null"`
+	DiscountAmount float64 `gorm:"not null;default:0"` // Potongan voucher yang jatuh ke barang seller ini
+
+	// Pengiriman yang dipilih buyer waktu checkout. Nama, alamat, dan titik ketemu disalin
+	// biar gak ikut berubah kalau seller ngedit metodenya / buyer ngedit alamatnya.
+	FulfilmentMethodID *uint          `gorm:"index"` // nil = metode default (ketemuan di titik ambil toko)
+	FulfilmentType     FulfilmentType `gorm:"type:varchar(20);not null;default:'meetup'"`
+	FulfilmentName     string         `gorm:"size:100"`
+	ShippingFee        float64        `gorm:"not null;default:0"`
+	ShippingAddress    string         `gorm:"type:text"` // Cuma buat kurir
+	MeetupPoint        string         `gorm:"size:255"`  // Cuma buat meetup / pickup
+	TrackingNumber     string         `gorm:"size:100"`  // Diisi seller waktu paket dikirim kurir
+	HandoverCode       string         `gorm:"size:10"`   // Kode serah terima yang dipegang buyer (meetup / pickup)
+	HandoverAttempts   int            `gorm:"not null;default:0"` // Berapa kali kode serah terima salah dimasukin
+	HandedOverAt       *time.Time
+
+	OrderItems []OrderItem `gorm:"foreignKey:SellerOrderID"`
+
+	Order  *Order `gorm:"foreignKey:OrderID"`
+	Seller *User  `gorm:"foreignKey:SellerID"`
+}
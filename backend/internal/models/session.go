@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Session = satu login di satu device. Yang disimpen cuma hash refresh token-nya; tiap refresh
+// token-nya diganti (rotasi) dan hash lama disimpen di PreviousHash buat ketahuan kalau
+// token lama dipake lagi (tanda token-nya dicuri).
+type Session struct {
+	ID           uint   `gorm:"primarykey"`
+	UserID       uint   `gorm:"not null;index"`
+	TokenHash    string `gorm:"size:64;not null;uniqueIndex"`
+	PreviousHash string `gorm:"size:64;index"`
+	UserAgent    string `gorm:"size:255"`
+	IPAddress    string `gorm:"size:64"`
+	LastUsedAt   time.Time
+	ExpiresAt    time.Time  `gorm:"not null;index"`
+	RevokedAt    *time.Time `gorm:"index"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	User *User `gorm:"foreignKey:UserID"`
+}
+
+// Active = session belum di-revoke dan refresh token-nya belum expired.
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,150 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gosimple/slug"
+	"gorm.io/gorm"
+)
+
+// Store = etalase toko milik satu seller. Dibikin otomatis waktu user jadi seller,
+// habis itu seller bebas ngatur nama, banner, lokasi, dan jam bukanya.
+type Store struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	SellerID       uint           `gorm:"not null;uniqueIndex" json:"seller_id"`
+	Name           string         `gorm:"size:100;not null" json:"name"`
+	Slug           string         `gorm:"size:120;not null;uniqueIndex" json:"slug"`
+	Description    string         `gorm:"type:text" json:"description"`
+	BannerURL      string         `gorm:"size:255" json:"banner_url"`
+	PickupLocation string         `gorm:"size:255" json:"pickup_location"` // Titik ambil di kampus, mis. "Gedung TULT lobby"
+	ShippingOrigin string         `gorm:"size:100" json:"shipping_origin"` // Kota asal pengiriman
+	OperatingHours OperatingHours `gorm:"type:text" json:"operating_hours"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+
+	Seller *User `gorm:"foreignKey:SellerID" json:"-"`
+}
+
+// OperatingHour = jam buka toko di satu hari. Day ngikutin time.Weekday (0 = Minggu).
+type OperatingHour struct {
+	Day   int    `json:"day"`
+	Open  string `json:"open"`  // "08:00"
+	Close string `json:"close"` // "17:00"
+}
+
+// OperatingHours disimpen sebagai JSON di satu kolom. Hari yang gak ada di daftar = tutup.
+type OperatingHours []OperatingHour
+
+func (h OperatingHours) Value() (driver.Value, error) {
+	if h == nil {
+		return "[]", nil
+	}
+	raw, err := json.Marshal(h)
+	return string(raw), err
+}
+
+func (h *OperatingHours) Scan(src interface{}) error {
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		*h = OperatingHours{}
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("tipe jam buka toko gak dikenal: %T", src)
+	}
+	return json.Unmarshal(raw, h)
+}
+
+// Validate ngecek format jam (HH:MM), jam tutup setelah jam buka, dan satu hari gak dobel.
+func (h OperatingHours) Validate() error {
+	seen := make(map[int]bool, len(h))
+	for _, oh := range h {
+		if oh.Day < 0 || oh.Day > 6 {
+			return errors.New("hari jam buka harus 0 (Minggu) sampai 6 (Sabtu)")
+		}
+		if seen[oh.Day] {
+			return errors.New("jam buka satu hari gak boleh dobel")
+		}
+		seen[oh.Day] = true
+
+		open, err1 := time.Parse("15:04", oh.Open)
+		closing, err2 := time.Parse("15:04", oh.Close)
+		if err1 != nil || err2 != nil {
+			return errors.New("format jam buka harus HH:MM")
+		}
+		if !closing.After(open) {
+			return errors.New("jam tutup harus setelah jam buka")
+		}
+	}
+	return nil
+}
+
+// IsOpen ngecek toko lagi buka di waktu t (pake zona waktu t). Toko tanpa jadwal
+// dianggep selalu buka.
+func (h OperatingHours) IsOpen(t time.Time) bool {
+	if len(h) == 0 {
+		return true
+	}
+	now := t.Format("15:04")
+	for _, oh := range h {
+		if oh.Day == int(t.Weekday()) && now >= oh.Open && now < oh.Close {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueStoreSlug nambahin akhiran -2, -3, dst sampai slug-nya belum dipake toko lain.
+func uniqueStoreSlug(tx *gorm.DB, name string) (string, error) {
+	base := slug.Make(name)
+	if base == "" {
+		base = "toko"
+	}
+	candidate := base
+	for i := 2; ; i++ {
+		var count int64
+		if err := tx.Model(&Store{}).Where("slug = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// EnsureStore balikin toko milik seller, atau bikin baru kalau belum ada.
+// Nama kosong diganti username seller.
+func EnsureStore(tx *gorm.DB, seller *User, name, description string) (*Store, error) {
+	var store Store
+	err := tx.Where("seller_id = ?", seller.ID).First(&store).Error
+	if err == nil {
+		return &store, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = seller.Username
+	}
+	storeSlug, err := uniqueStoreSlug(tx, name)
+	if err != nil {
+		return nil, err
+	}
+	store = Store{SellerID: seller.ID, Name: name, Slug: storeSlug, Description: description, OperatingHours: OperatingHours{}}
+	if err := tx.Create(&store).Error; err != nil {
+		return nil, err
+	}
+	return &store, nil
+}
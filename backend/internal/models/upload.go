@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Upload = satu gambar yang diupload user (semua ukurannya: thumbnail/medium/full).
+// Dipake buat ngitung kuota, dedup (hash file asli), dan ngecek kepemilikan waktu URL-nya
+// dipasang di produk/foto profil. Dihapus permanen bareng file-nya.
+type Upload struct {
+	ID          uint   `gorm:"primarykey"`
+	UserID      uint   `gorm:"not null;index:idx_uploads_user_hash"`
+	Key         string `gorm:"size:255;uniqueIndex;not null"`                // key storage versi full
+	URL         string `gorm:"size:255;not null"`                            // URL publik versi full
+	Hash        string `gorm:"size:64;not null;index:idx_uploads_user_hash"` // sha256 file asli (sebelum diolah)
+	Size        int64  `gorm:"not null"`                                     // total byte semua ukuran yang disimpen
+	ContentType string `gorm:"size:50"`
+	Width       int
+	Height      int
+	CreatedAt   time.Time
+
+	User *User `gorm:"foreignKey:UserID"`
+}
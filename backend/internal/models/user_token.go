@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+type UserTokenPurpose string
+
+const (
+	TokenVerifyEmail   UserTokenPurpose = "verify_email"
+	TokenResetPassword UserTokenPurpose = "reset_password"
+)
+
+// UserToken = token sekali pakai yang dikirim lewat email (verifikasi email, reset password).
+// Yang disimpen cuma hash-nya; token dianggep kepake begitu UsedAt keisi.
+type UserToken struct {
+	ID        uint             `gorm:"primarykey"`
+	UserID    uint             `gorm:"not null;index"`
+	Purpose   UserTokenPurpose `gorm:"size:30;not null"`
+	TokenHash string           `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time        `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+
+	User *User `gorm:"foreignKey:UserID"`
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// VariantOptions = atribut pilihan varian, mis. {"ukuran": "L", "warna": "Hitam"}.
+type VariantOptions map[string]string
+
+func (o VariantOptions) Value() (driver.Value, error) {
+	if o == nil {
+		return "{}", nil
+	}
+	raw, err := json.Marshal(o)
+	return string(raw), err
+}
+
+func (o *VariantOptions) Scan(src interface{}) error {
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		*o = VariantOptions{}
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("tipe options varian gak dikenal: %T", src)
+	}
+	return json.Unmarshal(raw, o)
+}
+
+// Label nyusun nama varian dari nilai atributnya, urut nama atribut ("L / Hitam").
+func (o VariantOptions) Label() string {
+	keys := make([]string, 0, len(o))
+	for k := range o {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, o[k])
+	}
+	return strings.Join(values, " / ")
+}
+
+// ProductVariant = satu pilihan produk (ukuran/warna) dengan SKU, harga & stok sendiri.
+// Kalau produk punya varian, Stock/Reserved/Price di Product cuma ringkasan dari variannya.
+type ProductVariant struct {
+	gorm.Model
+	ProductID uint           `gorm:"not null;index"`
+	SKU       string         `gorm:"size:100;not null"` // Unik di antara varian yang belum dihapus (partial index, lihat database.createSearchIndexes)
+	Name      string         `gorm:"size:255;not null"`
+	Options   VariantOptions `gorm:"type:jsonb"`
+	Price     float64        `gorm:"not null"`
+	Stock     int            `gorm:"not null;default:0"`
+	Reserved  int            `gorm:"not null;default:0"` // Stok varian yang lagi di-hold order pending
+
+	Product *Product `gorm:"foreignKey:ProductID"`
+}
+
+// Available = stok varian yang masih bisa dibeli.
+func (v *ProductVariant) Available() int {
+	return v.Stock - v.Reserved
+}
+
+// SyncVariantTotals nyamain stok & harga produk dengan varian-variannya (stok = total,
+// harga = harga varian termurah). Reserved gak ikut dihitung ulang karena udah diupdate
+// bareng-bareng sama hold varian. Kalau varian terakhir baru dihapus, stok produk di-nol-in
+// biar gak bisa dibeli sebagai produk biasa pake stok sisa varian; seller tinggal isi stok lagi.
+// Panggil tiap kali varian ditambah/diubah/dihapus.
+func SyncVariantTotals(tx *gorm.DB, productID uint) error {
+	var count int64
+	if err := tx.Model(&ProductVariant{}).Where("product_id = ?", productID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return tx.Model(&Product{}).Where("id = ?", productID).Update("stock", 0).Error
+	}
+
+	var totals struct {
+		Stock int
+		Price float64
+	}
+	err := tx.Model(&ProductVariant{}).
+		Select("COALESCE(SUM(stock), 0) AS stock, MIN(price) AS price").
+		Where("product_id = ?", productID).
+		Scan(&totals).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&Product{}).Where("id = ?", productID).Updates(map[string]interface{}{
+		"stock": totals.Stock,
+		"price": totals.Price,
+	}).Error
+}
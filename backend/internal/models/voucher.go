@@ -0,0 +1,108 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type VoucherType string
+
+const (
+	VoucherTypePercentage VoucherType = "percentage" // Value = persen potongan (1-100)
+	VoucherTypeFixed      VoucherType = "fixed"      // Value = potongan rupiah
+)
+
+// Voucher = kode promo yang dipake waktu checkout. SellerID nil = voucher platform (berlaku
+// buat semua barang), selain itu cuma motong barang dari seller itu.
+type Voucher struct {
+	ID           uint        `gorm:"primaryKey" json:"id"`
+	Code         string      `gorm:"size:50;not null;uniqueIndex" json:"code"` // Selalu huruf besar
+	Description  string      `gorm:"type:text" json:"description"`
+	Type         VoucherType `gorm:"type:varchar(20);not null" json:"type"`
+	Value        float64     `gorm:"not null" json:"value"`
+	MinSpend     float64     `gorm:"not null;default:0" json:"min_spend"`    // Minimal belanja barang yang kena voucher
+	MaxDiscount  float64     `gorm:"not null;default:0" json:"max_discount"` // 0 = gak dibatasi
+	UsageLimit   int         `gorm:"not null;default:0" json:"usage_limit"`  // Total pemakaian, 0 = gak dibatasi
+	PerUserLimit int         `gorm:"not null;default:0" json:"per_user_limit"`
+	UsedCount    int         `gorm:"not null;default:0" json:"used_count"` // Redemption yang masih aktif
+	SellerID     *uint       `gorm:"index" json:"seller_id,omitempty"`
+	StartsAt     *time.Time  `json:"starts_at,omitempty"`
+	EndsAt       *time.Time  `json:"ends_at,omitempty"`
+	IsActive     bool        `gorm:"not null;default:true" json:"is_active"`
+	CreatedByID  uint        `gorm:"not null" json:"created_by_id"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+
+	Seller *User `gorm:"foreignKey:SellerID" json:"-"`
+}
+
+type VoucherRedemptionStatus string
+
+const (
+	RedemptionActive   VoucherRedemptionStatus = "active"
+	RedemptionReleased VoucherRedemptionStatus = "released" // Order-nya gagal/expired/batal sebelum dibayar
+)
+
+// VoucherRedemption = pemakaian voucher di satu order. Yang statusnya active dihitung ke kuota.
+type VoucherRedemption struct {
+	ID            uint                    `gorm:"primaryKey" json:"id"`
+	VoucherID     uint                    `gorm:"not null;index" json:"voucher_id"`
+	UserID        uint                    `gorm:"not null;index" json:"user_id"`
+	OrderID       uint                    `gorm:"not null;uniqueIndex" json:"order_id"` // Satu voucher per order
+	Amount        float64                 `gorm:"not null" json:"amount"`
+	Status        VoucherRedemptionStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	ReleaseReason string                  `gorm:"size:50" json:"release_reason,omitempty"`
+	ReleasedAt    *time.Time              `json:"released_at,omitempty"`
+	CreatedAt     time.Time               `json:"created_at"`
+	UpdatedAt     time.Time               `json:"updated_at"`
+
+	Voucher *Voucher `gorm:"foreignKey:VoucherID" json:"-"`
+}
+
+// ReleaseVoucherRedemption ngebalikin kuota voucher order yang gak jadi dibayar.
+// Panggil di transaksi yang sama dengan perubahan status order-nya.
+func ReleaseVoucherRedemption(tx *gorm.DB, orderID uint, reason string) error {
+	var redemption VoucherRedemption
+	err := tx.Where("order_id = ? AND status = ?", orderID, RedemptionActive).First(&redemption).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err = tx.Model(&redemption).Updates(map[string]interface{}{
+		"status":         RedemptionReleased,
+		"release_reason": reason,
+		"released_at":    now,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&Voucher{}).Where("id = ? AND used_count > 0", redemption.VoucherID).
+		Update("used_count", gorm.Expr("used_count - 1")).Error
+}
+
+// RestoreVoucherRedemption ngaktifin lagi voucher order yang pembayarannya telat masuk
+// setelah keburu expired. Kuota gak dicek ulang karena buyer udah bayar harga diskonnya.
+func RestoreVoucherRedemption(tx *gorm.DB, orderID uint) error {
+	result := tx.Model(&VoucherRedemption{}).
+		Where("order_id = ? AND status = ?", orderID, RedemptionReleased).
+		Updates(map[string]interface{}{
+			"status":         RedemptionActive,
+			"release_reason": "",
+			"released_at":    nil,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	var redemption VoucherRedemption
+	if err := tx.Where("order_id = ?", orderID).First(&redemption).Error; err != nil {
+		return err
+	}
+	return tx.Model(&Voucher{}).Where("id = ?", redemption.VoucherID).
+		Update("used_count", gorm.Expr("used_count + 1")).Error
+}
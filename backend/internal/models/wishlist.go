@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// WishlistItem = produk yang disimpen user buat dibeli nanti, satu produk sekali per user.
+type WishlistItem struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_wishlist_user_product" json:"user_id"`
+	ProductID uint      `gorm:"not null;uniqueIndex:idx_wishlist_user_product;index" json:"product_id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Product *Product `gorm:"foreignKey:ProductID" json:"-"`
+}
+
+// StockAlert = langganan "kabari saya kalau stok ada lagi". Aktif selama NotifiedAt
+// masih nil; begitu notifikasinya kekirim langganannya selesai, user bisa daftar ulang.
+type StockAlert struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;uniqueIndex:idx_stock_alert_sub" json:"user_id"`
+	ProductID  uint       `gorm:"not null;uniqueIndex:idx_stock_alert_sub;index" json:"product_id"`
+	VariantID  uint       `gorm:"not null;default:0;uniqueIndex:idx_stock_alert_sub" json:"variant_id"` // 0 = produknya (varian apa aja)
+	NotifiedAt *time.Time `gorm:"index" json:"notified_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	Product *Product `gorm:"foreignKey:ProductID" json:"-"`
+}
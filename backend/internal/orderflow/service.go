@@ -0,0 +1,418 @@
+package orderflow
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/notification"
+	"gorm.io/gorm"
+)
+
+const (
+	ActorBuyer  = "buyer"
+	ActorSeller = "seller"
+	ActorAdmin  = "admin"
+	ActorSystem = "system"
+)
+
+var (
+	ErrIllegalTransition = errors.New("perubahan status order tidak valid")
+	ErrActorNotAllowed   = errors.New("kamu tidak boleh mengubah order ke status ini")
+	ErrStaleOrder        = errors.New("status order sudah berubah, coba muat ulang")
+)
+
+// Actor = siapa yang ngubah status. UserID 0 buat sistem (webhook, worker).
+type Actor struct {
+	UserID uint
+	Role   string
+}
+
+var SystemActor = Actor{Role: ActorSystem}
+
+// actorRules: status tujuan -> role yang boleh mindahin order ke status itu.
+// Sistem & admin boleh semua transisi yang sah di state machine.
+var actorRules = map[models.OrderStatus][]string{
+	models.OrderStatusPaid:       {ActorSystem, ActorAdmin},
+	models.OrderStatusFailed:     {ActorSystem, ActorAdmin},
+	models.OrderStatusProcessing: {ActorSeller, ActorAdmin},
+	models.OrderStatusShipped:    {ActorSeller, ActorAdmin},
+	models.OrderStatusDelivered:  {ActorSeller, ActorAdmin, ActorSystem},
+	models.OrderStatusCompleted:  {ActorBuyer, ActorAdmin, ActorSystem},
+	models.OrderStatusCancelled:  {ActorBuyer, ActorAdmin, ActorSystem},
+	models.OrderStatusRefunded:   {ActorSeller, ActorAdmin, ActorSystem},
+}
+
+type Service struct {
+	DB           *gorm.DB
+	NotifService *notification.Service
+}
+
+func NewService(db *gorm.DB, notifService *notification.Service) *Service {
+	return &Service{DB: db, NotifService: notifService}
+}
+
+func actorAllowed(actor Actor, from, to models.OrderStatus) bool {
+	// Buyer cuma boleh batalin order yang belum dikirim (yang udah dibayar di-refund lewat refund.Service)
+	if actor.Role == ActorBuyer && to == models.OrderStatusCancelled &&
+		from != models.OrderStatusPending && from != models.OrderStatusPaid && from != models.OrderStatusProcessing {
+		return false
+	}
+	for _, role := range actorRules[to] {
+		if role == actor.Role {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition mindahin status order induk sesuai state machine dan nyatet riwayatnya.
+// Sub-order yang bisa ikut pindah ke status yang sama bakal ikut (misal semua jadi paid).
+// Jalanin di dalam transaksi pemanggil; panggil Notify setelah transaksinya commit.
+func (s *Service) Transition(tx *gorm.DB, order *models.Order, to models.OrderStatus, actor Actor, reason string) (*models.OrderStatusHistory, error) {
+	from := order.Status
+	if !from.CanTransitionTo(to) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, from, to)
+	}
+	if !actorAllowed(actor, from, to) {
+		return nil, fmt.Errorf("%w (%s -> %s)", ErrActorNotAllowed, from, to)
+	}
+
+	history, err := s.moveOrder(tx, order, to, actor, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	var subs []models.SellerOrder
+	if err := tx.Where("order_id = ?", order.ID).Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	for i := range subs {
+		if !subs[i].Status.CanTransitionTo(to) {
+			continue
+		}
+		if _, err := s.moveSellerOrder(tx, &subs[i], to, actor, reason); err != nil {
+			return nil, err
+		}
+	}
+
+	return history, nil
+}
+
+// TransitionSellerOrder mindahin status satu sub-order, terus nyesuaiin status order induknya.
+func (s *Service) TransitionSellerOrder(tx *gorm.DB, sub *models.SellerOrder, to models.OrderStatus, actor Actor, reason string) (*models.OrderStatusHistory, error) {
+	from := sub.Status
+	if !from.CanTransitionTo(to) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, from, to)
+	}
+	if !actorAllowed(actor, from, to) {
+		return nil, fmt.Errorf("%w (%s -> %s)", ErrActorNotAllowed, from, to)
+	}
+
+	history, err := s.moveSellerOrder(tx, sub, to, actor, reason)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.syncParent(tx, sub.OrderID); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// syncParent nurunin status order induk dari sub-order-nya: induk ikut sub-order
+// yang paling ketinggalan, dan jadi cancelled/refunded kalau semua sub-order ditutup.
+func (s *Service) syncParent(tx *gorm.DB, orderID uint) error {
+	var order models.Order
+	if err := tx.First(&order, orderID).Error; err != nil {
+		return err
+	}
+	var subs []models.SellerOrder
+	if err := tx.Where("order_id = ?", orderID).Find(&subs).Error; err != nil {
+		return err
+	}
+
+	target := models.OrderStatus("")
+	anyRefunded := false
+	for _, sub := range subs {
+		if sub.Status.IsClosed() {
+			anyRefunded = anyRefunded || sub.Status == models.OrderStatusRefunded
+			continue
+		}
+		if target == "" || sub.Status.FlowRank() < target.FlowRank() {
+			target = sub.Status
+		}
+	}
+
+	const reason = "Menyesuaikan status sub-order"
+	if target == "" {
+		target = models.OrderStatusCancelled
+		if anyRefunded {
+			target = models.OrderStatusRefunded
+		}
+		if order.Status.CanTransitionTo(target) {
+			_, err := s.moveOrder(tx, &order, target, SystemActor, reason)
+			return err
+		}
+		return nil
+	}
+
+	// Alurnya linear, jadi induk dimajuin satu-satu sampe nyamain target
+	for order.Status.FlowRank() >= 0 && order.Status.FlowRank() < target.FlowRank() {
+		next := order.Status.NextInFlow()
+		if !order.Status.CanTransitionTo(next) {
+			break
+		}
+		if _, err := s.moveOrder(tx, &order, next, SystemActor, reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) moveOrder(tx *gorm.DB, order *models.Order, to models.OrderStatus, actor Actor, reason string) (*models.OrderStatusHistory, error) {
+	// Update bersyarat biar dua proses yang barengan gak bisa sama-sama mindahin dari status yang sama
+	result := tx.Model(&models.Order{}).
+		Where("id = ? AND status = ?", order.ID, order.Status).
+		Update("status", to)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrStaleOrder
+	}
+
+	// Voucher order yang gak jadi dibayar balik ke kuota, kalau pembayarannya telat masuk dipake lagi
+	switch {
+	case order.Status == models.OrderStatusPending && (to == models.OrderStatusFailed || to == models.OrderStatusCancelled):
+		if err := models.ReleaseVoucherRedemption(tx, order.ID, string(to)); err != nil {
+			return nil, err
+		}
+	case order.Status == models.OrderStatusFailed && to == models.OrderStatusPaid:
+		if err := models.RestoreVoucherRedemption(tx, order.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	history, err := s.record(tx, order.ID, 0, order.Status, to, actor, reason)
+	if err != nil {
+		return nil, err
+	}
+	order.Status = to
+	return history, nil
+}
+
+func (s *Service) moveSellerOrder(tx *gorm.DB, sub *models.SellerOrder, to models.OrderStatus, actor Actor, reason string) (*models.OrderStatusHistory, error) {
+	result := tx.Model(&models.SellerOrder{}).
+		Where("id = ? AND status = ?", sub.ID, sub.Status).
+		Update("status", to)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrStaleOrder
+	}
+
+	history, err := s.record(tx, sub.OrderID, sub.ID, sub.Status, to, actor, reason)
+	if err != nil {
+		return nil, err
+	}
+	sub.Status = to
+	return history, nil
+}
+
+func (s *Service) record(tx *gorm.DB, orderID, sellerOrderID uint, from, to models.OrderStatus, actor Actor, reason string) (*models.OrderStatusHistory, error) {
+	history := models.OrderStatusHistory{
+		OrderID:       orderID,
+		SellerOrderID: sellerOrderID,
+		FromStatus:    from,
+		ToStatus:      to,
+		ActorRole:     actor.Role,
+		Reason:        reason,
+	}
+	if actor.UserID != 0 {
+		actorID := actor.UserID
+		history.ActorID = &actorID
+	}
+	if err := tx.Create(&history).Error; err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+// RecordCreated nyatet entri pertama riwayat (order baru dibuat, status pending).
+func (s *Service) RecordCreated(tx *gorm.DB, order *models.Order, actor Actor) error {
+	_, err := s.record(tx, order.ID, 0, "", order.Status, actor, "Order dibuat")
+	return err
+}
+
+// TransitionAndNotify = Transition dalam transaksi sendiri + kirim notifikasi.
+func (s *Service) TransitionAndNotify(order *models.Order, to models.OrderStatus, actor Actor, reason string) error {
+	var history *models.OrderStatusHistory
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		h, err := s.Transition(tx, order, to, actor, reason)
+		history = h
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.Notify(order, history)
+	return nil
+}
+
+// TransitionSellerOrderAndNotify = TransitionSellerOrder dalam transaksi sendiri + kirim notifikasi.
+func (s *Service) TransitionSellerOrderAndNotify(sub *models.SellerOrder, to models.OrderStatus, actor Actor, reason string) error {
+	var history *models.OrderStatusHistory
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		h, err := s.TransitionSellerOrder(tx, sub, to, actor, reason)
+		history = h
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.NotifySellerOrder(sub, history)
+	return nil
+}
+
+func (s *Service) GetHistory(orderID uint) ([]models.OrderStatusHistory, error) {
+	var history []models.OrderStatusHistory
+	err := s.DB.Where("order_id = ?", orderID).Order("created_at asc, id asc").Find(&history).Error
+	return history, err
+}
+
+// Notify ngirim notifikasi perubahan status ke buyer & semua seller yang
+// produknya ada di order (kecuali ke orang yang ngubah statusnya sendiri).
+func (s *Service) Notify(order *models.Order, history *models.OrderStatusHistory) {
+	if history == nil {
+		return
+	}
+
+	var items []models.OrderItem
+	if err := s.DB.Preload("Product").Where("order_id = ?", order.ID).Find(&items).Error; err != nil {
+		log.Printf("[ORDER-FLOW] WARNING: Gagal ambil item Order %d buat notifikasi: %v", order.ID, err)
+	}
+
+	sellerProducts := make(map[uint][]string)
+	for _, item := range items {
+		if item.Product == nil {
+			continue
+		}
+		sellerProducts[item.Product.SellerID] = append(sellerProducts[item.Product.SellerID],
+			fmt.Sprintf("%s (x%d)", item.ProductName(), item.Quantity))
+	}
+
+	actorID := uint(0)
+	if history.ActorID != nil {
+		actorID = *history.ActorID
+	}
+
+	if order.UserID != actorID {
+		title, message := buyerMessage(order.ID, history)
+		go s.send(order.UserID, title, message, order.ID)
+	}
+
+	// Seller baru peduli sama order yang udah dibayar
+	if history.FromStatus == models.OrderStatusPending && history.ToStatus != models.OrderStatusPaid {
+		return
+	}
+
+	for sellerID, products := range sellerProducts {
+		if sellerID == actorID {
+			continue
+		}
+		title, message := sellerMessage(order.ID, history, strings.Join(products, ", "))
+		go s.send(sellerID, title, message, order.ID)
+	}
+}
+
+// NotifySellerOrder ngabarin buyer & seller sub-order soal perubahan status sub-order.
+func (s *Service) NotifySellerOrder(sub *models.SellerOrder, history *models.OrderStatusHistory) {
+	if history == nil {
+		return
+	}
+
+	var order models.Order
+	if err := s.DB.First(&order, sub.OrderID).Error; err != nil {
+		log.Printf("[ORDER-FLOW] WARNING: Gagal ambil Order %d buat notifikasi: %v", sub.OrderID, err)
+		return
+	}
+
+	var items []models.OrderItem
+	s.DB.Preload("Product").Where("seller_order_id = ?", sub.ID).Find(&items)
+	var names []string
+	for _, item := range items {
+		if item.Product != nil {
+			names = append(names, fmt.Sprintf("%s (x%d)", item.ProductName(), item.Quantity))
+		}
+	}
+	products := strings.Join(names, ", ")
+
+	actorID := uint(0)
+	if history.ActorID != nil {
+		actorID = *history.ActorID
+	}
+
+	if order.UserID != actorID {
+		title, message := buyerMessage(order.ID, history)
+		if products != "" {
+			message += " Produk: " + products + "."
+		}
+		go s.send(order.UserID, title, message, order.ID)
+	}
+	if sub.SellerID != actorID {
+		title, message := sellerMessage(order.ID, history, products)
+		go s.send(sub.SellerID, title, message, order.ID)
+	}
+}
+
+func (s *Service) send(userID uint, title, message string, orderID uint) {
+	if err := s.NotifService.CreateAndSend(userID, models.NotificationTypeOrder, title, message, orderID); err != nil {
+		log.Printf("[ORDER-FLOW] WARNING: Gagal kirim notif ke user %d: %v", userID, err)
+	}
+}
+
+func withReason(message, reason string) string {
+	if reason == "" {
+		return message
+	}
+	return message + " Alasan: " + reason
+}
+
+func buyerMessage(orderID uint, h *models.OrderStatusHistory) (string, string) {
+	switch h.ToStatus {
+	case models.OrderStatusPaid:
+		return "Pembayaran Berhasil!", fmt.Sprintf("Order #%d kamu sudah lunas dan akan segera diproses.", orderID)
+	case models.OrderStatusProcessing:
+		return "Pesanan Diproses", fmt.Sprintf("Order #%d kamu lagi disiapin sama seller.", orderID)
+	case models.OrderStatusShipped:
+		return "Pesanan Dikirim 🚚", fmt.Sprintf("Order #%d kamu udah dikirim.", orderID)
+	case models.OrderStatusDelivered:
+		return "Pesanan Sampai", fmt.Sprintf("Order #%d udah sampai. Jangan lupa konfirmasi selesai ya!", orderID)
+	case models.OrderStatusCompleted:
+		return "Pesanan Selesai", fmt.Sprintf("Order #%d sudah selesai. Makasih udah belanja!", orderID)
+	case models.OrderStatusCancelled:
+		return "Pesanan Dibatalkan", withReason(fmt.Sprintf("Order #%d dibatalkan.", orderID), h.Reason)
+	case models.OrderStatusRefunded:
+		return "Dana Dikembalikan", withReason(fmt.Sprintf("Order #%d sudah di-refund.", orderID), h.Reason)
+	case models.OrderStatusFailed:
+		return "Pembayaran Gagal", withReason(fmt.Sprintf("Pembayaran order #%d gagal atau kedaluwarsa.", orderID), h.Reason)
+	}
+	return "Status Pesanan Berubah", fmt.Sprintf("Order #%d sekarang berstatus %s.", orderID, h.ToStatus)
+}
+
+func sellerMessage(orderID uint, h *models.OrderStatusHistory, products string) (string, string) {
+	switch h.ToStatus {
+	case models.OrderStatusPaid:
+		return "Produk Anda Terjual! 🎉", fmt.Sprintf("Ada pesanan baru untuk produk: %s. Segera proses ya!", products)
+	case models.OrderStatusCompleted:
+		return "Pesanan Selesai", fmt.Sprintf("Buyer sudah konfirmasi order #%d (%s) selesai.", orderID, products)
+	case models.OrderStatusCancelled:
+		return "Pesanan Dibatalkan", withReason(fmt.Sprintf("Order #%d (%s) dibatalkan.", orderID, products), h.Reason)
+	case models.OrderStatusRefunded:
+		return "Pesanan Di-refund", withReason(fmt.Sprintf("Order #%d (%s) di-refund ke buyer.", orderID, products), h.Reason)
+	}
+	return "Status Pesanan Berubah", fmt.Sprintf("Order #%d (%s) sekarang berstatus %s.", orderID, products, h.ToStatus)
+}
@@ -0,0 +1,233 @@
+package payment
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// statusCodes = status_code yang dikirim Midtrans buat tiap status transaksi.
+var statusCodes = map[string]string{
+	StatusPending:       "201",
+	StatusSettlement:    "200",
+	StatusCapture:       "200",
+	StatusExpire:        "407",
+	StatusDeny:          "202",
+	StatusFailure:       "202",
+	StatusCancel:        "200",
+	StatusRefund:        "200",
+	StatusPartialRefund: "200",
+}
+
+type FakeTransaction struct {
+	Token             string    `json:"token"`
+	OrderRef          string    `json:"order_id"`
+	TransactionID     string    `json:"transaction_id"`
+	Amount            int64     `json:"amount"`
+	Refunded          int64     `json:"refunded"`
+	TransactionStatus string    `json:"transaction_status"`
+	CreatedAt         time.Time `json:"created_at"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// FakeGateway = payment gateway bohongan yang jalan di dalam proses, buat CI & dev offline.
+// Token & transaksinya cuma disimpen di memori; webhook-nya ditembak manual lewat Fire.
+type FakeGateway struct {
+	ServerKey  string
+	WebhookURL string
+	BaseURL    string // Dipake buat redirect_url, misal http://localhost:8080/api/v1/payments/fake
+
+	mu           sync.Mutex
+	transactions map[string]*FakeTransaction
+	client       *http.Client
+}
+
+func NewFakeGateway(serverKey, webhookURL, baseURL string) *FakeGateway {
+	return &FakeGateway{
+		ServerKey:    serverKey,
+		WebhookURL:   webhookURL,
+		BaseURL:      baseURL,
+		transactions: make(map[string]*FakeTransaction),
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (g *FakeGateway) CreateTransaction(req ChargeRequest) (*ChargeResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, exists := g.transactions[req.OrderRef]; exists {
+		return nil, fmt.Errorf("order_id %s udah pernah dipake", req.OrderRef)
+	}
+	// Sama kayak Midtrans: total item_details (termasuk item minus diskon) harus pas sama gross_amount
+	if len(req.Items) > 0 {
+		var sum int64
+		for _, item := range req.Items {
+			sum += item.Price * int64(item.Qty)
+		}
+		if sum != req.Amount {
+			return nil, fmt.Errorf("total item (Rp %d) gak sama dengan gross amount (Rp %d)", sum, req.Amount)
+		}
+	}
+
+	now := time.Now()
+	tx := &FakeTransaction{
+		Token:             "fake-" + randomHex(16),
+		OrderRef:          req.OrderRef,
+		TransactionID:     randomHex(8),
+		Amount:            req.Amount,
+		TransactionStatus: StatusPending,
+		CreatedAt:         now,
+	}
+	if req.Expiry > 0 {
+		tx.ExpiresAt = now.Add(req.Expiry)
+	}
+	g.transactions[req.OrderRef] = tx
+
+	log.Printf("[FAKE-PAYMENT] Transaksi %s dibuat (Rp %d), token %s", req.OrderRef, req.Amount, tx.Token)
+	return &ChargeResult{Token: tx.Token, RedirectURL: g.BaseURL + "/transactions/" + tx.Token}, nil
+}
+
+func (g *FakeGateway) VerifyWebhook(body []byte) (*Notification, error) {
+	var notif Notification
+	if err := json.Unmarshal(body, &notif); err != nil {
+		return nil, ErrInvalidPayload
+	}
+	if Signature(notif.OrderRef, notif.StatusCode, notif.GrossAmount, g.ServerKey) != notif.SignatureKey {
+		return nil, ErrInvalidSignature
+	}
+	return &notif, nil
+}
+
+func (g *FakeGateway) GetStatus(orderRef string) (*Notification, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tx, ok := g.transactions[orderRef]
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+	return g.notificationFor(tx), nil
+}
+
+func (g *FakeGateway) Refund(req RefundRequest) (*RefundResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tx, ok := g.transactions[req.OrderRef]
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+	if tx.TransactionStatus != StatusSettlement && tx.TransactionStatus != StatusCapture && tx.TransactionStatus != StatusPartialRefund {
+		return nil, fmt.Errorf("transaksi %s statusnya '%s', gak bisa di-refund", req.OrderRef, tx.TransactionStatus)
+	}
+	if req.Amount <= 0 || tx.Refunded+req.Amount > tx.Amount {
+		return nil, fmt.Errorf("nominal refund tidak valid (sisa yang bisa di-refund Rp %d)", tx.Amount-tx.Refunded)
+	}
+
+	tx.Refunded += req.Amount
+	tx.TransactionStatus = StatusPartialRefund
+	if tx.Refunded == tx.Amount {
+		tx.TransactionStatus = StatusRefund
+	}
+
+	log.Printf("[FAKE-PAYMENT] Refund Rp %d buat %s (%s)", req.Amount, req.OrderRef, req.Reason)
+	return &RefundResult{RefundKey: req.RefundKey, Amount: req.Amount, TransactionStatus: tx.TransactionStatus}, nil
+}
+
+// Find nyari transaksi pake order_id atau token Snap-nya.
+func (g *FakeGateway) Find(refOrToken string) (*FakeTransaction, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if tx, ok := g.transactions[refOrToken]; ok {
+		snapshot := *tx
+		return &snapshot, true
+	}
+	for _, tx := range g.transactions {
+		if tx.Token == refOrToken {
+			snapshot := *tx
+			return &snapshot, true
+		}
+	}
+	return nil, false
+}
+
+func (g *FakeGateway) List() []FakeTransaction {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	list := make([]FakeTransaction, 0, len(g.transactions))
+	for _, tx := range g.transactions {
+		list = append(list, *tx)
+	}
+	return list
+}
+
+// Fire ngubah status transaksi lalu nembak webhook bertanda tangan ke WebhookURL,
+// persis kayak notifikasi HTTP dari Midtrans.
+func (g *FakeGateway) Fire(refOrToken, status string) (*Notification, error) {
+	if _, ok := statusCodes[status]; !ok || status == StatusRefund || status == StatusPartialRefund {
+		return nil, fmt.Errorf("status '%s' gak bisa ditembak manual", status)
+	}
+
+	found, ok := g.Find(refOrToken)
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+
+	g.mu.Lock()
+	tx := g.transactions[found.OrderRef]
+	tx.TransactionStatus = status
+	notif := g.notificationFor(tx)
+	g.mu.Unlock()
+
+	body, err := json.Marshal(notif)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := g.client.Post(g.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("gagal nembak webhook: %v", err)
+	}
+	defer resp.Body.Close()
+
+	log.Printf("[FAKE-PAYMENT] Webhook '%s' buat %s dikirim, dibales %d", status, tx.OrderRef, resp.StatusCode)
+	if resp.StatusCode >= 300 {
+		return notif, fmt.Errorf("webhook dibales status %d", resp.StatusCode)
+	}
+	return notif, nil
+}
+
+func (g *FakeGateway) notificationFor(tx *FakeTransaction) *Notification {
+	notif := &Notification{
+		OrderRef:          tx.OrderRef,
+		TransactionID:     tx.TransactionID,
+		TransactionStatus: tx.TransactionStatus,
+		StatusCode:        statusCodes[tx.TransactionStatus],
+		GrossAmount:       strconv.FormatInt(tx.Amount, 10) + ".00",
+		PaymentType:       "fake",
+		FraudStatus:       "accept",
+	}
+	notif.SignatureKey = Signature(notif.OrderRef, notif.StatusCode, notif.GrossAmount, g.ServerKey)
+	return notif
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,119 @@
+package payment
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// Status transaksi ngikutin istilah Midtrans, jadi gateway lain tinggal nerjemahin ke sini.
+const (
+	StatusPending       = "pending"
+	StatusSettlement    = "settlement"
+	StatusCapture       = "capture"
+	StatusExpire        = "expire"
+	StatusDeny          = "deny"
+	StatusFailure       = "failure"
+	StatusCancel        = "cancel"
+	StatusRefund        = "refund"
+	StatusPartialRefund = "partial_refund"
+
+	// StatusChallenge bukan status Midtrans: capture kartu yang fraud_status-nya "challenge",
+	// dicatat terpisah biar capture "accept" / deny setelah review tetep diproses.
+	StatusChallenge = "challenge"
+
+	FraudAccept    = "accept"
+	FraudChallenge = "challenge"
+)
+
+var (
+	ErrInvalidSignature    = errors.New("signature webhook tidak valid")
+	ErrInvalidPayload      = errors.New("payload webhook tidak bisa dibaca")
+	ErrTransactionNotFound = errors.New("transaksi tidak ditemukan di payment gateway")
+)
+
+// Gateway = semua yang dibutuhin checkout & webhook dari penyedia pembayaran.
+type Gateway interface {
+	Name() string
+	CreateTransaction(req ChargeRequest) (*ChargeResult, error)
+	// VerifyWebhook ngecek signature body webhook mentah dan nerjemahin isinya.
+	VerifyWebhook(body []byte) (*Notification, error)
+	GetStatus(orderRef string) (*Notification, error)
+	Refund(req RefundRequest) (*RefundResult, error)
+}
+
+type Item struct {
+	ID    string
+	Name  string
+	Price int64
+	Qty   int32
+}
+
+type ChargeRequest struct {
+	OrderRef      string // ID transaksi di gateway, misal TELUHUB-12-1700000000
+	Amount        int64
+	CustomerName  string
+	CustomerEmail string
+	Items         []Item
+	Expiry        time.Duration // Batas waktu bayar, 0 = default gateway
+}
+
+type ChargeResult struct {
+	Token       string
+	RedirectURL string
+}
+
+// Notification = status transaksi dari gateway, baik dari webhook maupun dari GetStatus.
+type Notification struct {
+	OrderRef          string `json:"order_id"`
+	TransactionID     string `json:"transaction_id"`
+	TransactionStatus string `json:"transaction_status"`
+	StatusCode        string `json:"status_code"`
+	GrossAmount       string `json:"gross_amount"`
+	PaymentType       string `json:"payment_type"`
+	FraudStatus       string `json:"fraud_status"`
+	SignatureKey      string `json:"signature_key"`
+}
+
+// EffectiveStatus = status yang dipake buat prioritas & dedup. Capture yang masih di-challenge
+// fraud detection belum dianggap bayar.
+func (n *Notification) EffectiveStatus() string {
+	if n.TransactionStatus == StatusCapture && n.FraudStatus == FraudChallenge {
+		return StatusChallenge
+	}
+	return n.TransactionStatus
+}
+
+// IsPaid: settlement, atau capture kartu yang lolos fraud detection.
+func (n *Notification) IsPaid() bool {
+	return n.TransactionStatus == StatusSettlement || (n.TransactionStatus == StatusCapture && n.FraudStatus == FraudAccept)
+}
+
+func (n *Notification) IsFailed() bool {
+	switch n.TransactionStatus {
+	case StatusExpire, StatusFailure, StatusDeny, StatusCancel:
+		return true
+	}
+	return false
+}
+
+type RefundRequest struct {
+	OrderRef  string
+	RefundKey string // Biar refund yang sama gak kedobel kalau di-retry
+	Amount    int64
+	Reason    string
+}
+
+type RefundResult struct {
+	RefundKey         string
+	Amount            int64
+	TransactionStatus string
+}
+
+// Signature = SHA-512(order_id + status_code + gross_amount + server_key), skema signature webhook Midtrans.
+func Signature(orderRef, statusCode, grossAmount, serverKey string) string {
+	hasher := sha512.New()
+	hasher.Write([]byte(orderRef + statusCode + grossAmount + serverKey))
+	return hex.EncodeToString(hasher.Sum(nil))
+}
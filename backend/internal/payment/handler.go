@@ -0,0 +1,155 @@
+package payment
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type Handler struct {
+	Processor  *Processor
+	Reconciler *Reconciler
+}
+
+func NewHandler(p *Processor, r *Reconciler) *Handler {
+	return &Handler{Processor: p, Reconciler: r}
+}
+
+// POST /payments/webhook — notifikasi HTTP dari payment gateway
+func (h *Handler) HandleWebhook(c *fiber.Ctx) error {
+	notif, err := h.Processor.Gateway.VerifyWebhook(c.Body())
+	if err != nil {
+		if errors.Is(err, ErrInvalidSignature) {
+			log.Printf("[WEBHOOK] 401 Unauthorized: Signature key salah!")
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid signature"})
+		}
+		log.Printf("[WEBHOOK] 400 Bad Request: Gagal parse body: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse request"})
+	}
+
+	log.Printf("[WEBHOOK] Notifikasi diterima untuk Order ID: %s, Status: %s", notif.OrderRef, notif.TransactionStatus)
+
+	event, err := h.Processor.HandleNotification(notif, c.Body(), models.PaymentSourceWebhook)
+	if err != nil {
+		if errors.Is(err, ErrOrderNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order not found"})
+		}
+		// Balikin 500 biar gateway ngirim ulang notifikasinya
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Webhook received and processed", "state": event.State})
+}
+
+// GET /admin/payment-events?order_id=&status=&state=&limit=
+func (h *Handler) ListEvents(c *fiber.Ctx) error {
+	query := h.Processor.DB.Model(&models.PaymentEvent{}).Order("created_at desc, id desc")
+
+	if orderID := c.Query("order_id"); orderID != "" {
+		id, err := strconv.ParseUint(orderID, 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "order_id tidak valid"})
+		}
+		query = query.Where("order_id = ?", id)
+	}
+	if ref := c.Query("order_ref"); ref != "" {
+		query = query.Where("order_ref = ?", ref)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("transaction_status = ?", status)
+	}
+	if state := c.Query("state"); state != "" {
+		query = query.Where("state = ?", state)
+	}
+
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	var events []models.PaymentEvent
+	if err := query.Limit(limit).Find(&events).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengambil payment events"})
+	}
+	return c.JSON(events)
+}
+
+// GET /admin/payment-events/:id
+func (h *Handler) GetEvent(c *fiber.Ctx) error {
+	var event models.PaymentEvent
+	if err := h.Processor.DB.First(&event, c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Payment event tidak ditemukan"})
+	}
+	return c.JSON(event)
+}
+
+// POST /admin/payment-events/:id/replay — proses ulang satu event
+func (h *Handler) ReplayEvent(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	event, err := h.Processor.Replay(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Payment event tidak ditemukan"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error(), "event": event})
+	}
+	return c.JSON(fiber.Map{"message": "Event diproses ulang", "event": event})
+}
+
+// POST /admin/payments/reconcile — jalanin rekonsiliasi sekarang juga
+func (h *Handler) Reconcile(c *fiber.Ctx) error {
+	report, err := h.Reconciler.RunOnce()
+	if err != nil {
+		if errors.Is(err, ErrReconcileRunning) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal rekonsiliasi pembayaran"})
+	}
+	return c.JSON(report)
+}
+
+// FakeHandler = endpoint buat ngendaliin FakeGateway (cuma didaftarin kalau PAYMENT_GATEWAY=fake).
+type FakeHandler struct {
+	Gateway *FakeGateway
+}
+
+func NewFakeHandler(g *FakeGateway) *FakeHandler {
+	return &FakeHandler{Gateway: g}
+}
+
+// GET /payments/fake/transactions
+func (h *FakeHandler) ListTransactions(c *fiber.Ctx) error {
+	return c.JSON(h.Gateway.List())
+}
+
+// GET /payments/fake/transactions/:ref — :ref boleh order_id atau token
+func (h *FakeHandler) GetTransaction(c *fiber.Ctx) error {
+	tx, ok := h.Gateway.Find(c.Params("ref"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Transaksi tidak ditemukan"})
+	}
+	return c.JSON(tx)
+}
+
+// POST /payments/fake/transactions/:ref/:status — tembak webhook settlement/expire/deny/dll
+func (h *FakeHandler) FireWebhook(c *fiber.Ctx) error {
+	notif, err := h.Gateway.Fire(c.Params("ref"), c.Params("status"))
+	if err != nil {
+		if errors.Is(err, ErrTransactionNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Transaksi tidak ditemukan"})
+		}
+		if notif == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": err.Error(), "notification": notif})
+	}
+	return c.JSON(fiber.Map{"message": "Webhook terkirim", "notification": notif})
+}
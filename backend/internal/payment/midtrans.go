@@ -0,0 +1,115 @@
+package payment
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
+	"github.com/midtrans/midtrans-go/snap"
+)
+
+// MidtransGateway = Gateway pake Midtrans Snap buat bayar & Core API buat cek status / refund.
+type MidtransGateway struct {
+	ServerKey string
+	Snap      snap.Client
+	Core      coreapi.Client
+}
+
+func NewMidtransGateway(serverKey string, env midtrans.EnvironmentType) *MidtransGateway {
+	g := &MidtransGateway{ServerKey: serverKey}
+	g.Snap.New(serverKey, env)
+	g.Core.New(serverKey, env)
+	return g
+}
+
+func (g *MidtransGateway) Name() string {
+	return "midtrans"
+}
+
+func (g *MidtransGateway) CreateTransaction(req ChargeRequest) (*ChargeResult, error) {
+	items := make([]midtrans.ItemDetails, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, midtrans.ItemDetails{
+			ID:    item.ID,
+			Name:  item.Name,
+			Price: item.Price,
+			Qty:   item.Qty,
+		})
+	}
+
+	snapReq := &snap.Request{
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  req.OrderRef,
+			GrossAmt: req.Amount,
+		},
+		CustomerDetail: &midtrans.CustomerDetails{
+			FName: req.CustomerName,
+			Email: req.CustomerEmail,
+		},
+		Items: &items,
+	}
+	if req.Expiry > 0 {
+		snapReq.Expiry = &snap.ExpiryDetails{
+			Unit:     "minute",
+			Duration: int64(req.Expiry.Minutes()),
+		}
+	}
+
+	resp, err := g.Snap.CreateTransaction(snapReq)
+	if err != nil {
+		return nil, fmt.Errorf("gagal bikin transaksi Midtrans: %s", err.GetMessage())
+	}
+	return &ChargeResult{Token: resp.Token, RedirectURL: resp.RedirectURL}, nil
+}
+
+func (g *MidtransGateway) VerifyWebhook(body []byte) (*Notification, error) {
+	var notif Notification
+	if err := json.Unmarshal(body, &notif); err != nil {
+		return nil, ErrInvalidPayload
+	}
+	if Signature(notif.OrderRef, notif.StatusCode, notif.GrossAmount, g.ServerKey) != notif.SignatureKey {
+		return nil, ErrInvalidSignature
+	}
+	return &notif, nil
+}
+
+func (g *MidtransGateway) GetStatus(orderRef string) (*Notification, error) {
+	resp, err := g.Core.CheckTransaction(orderRef)
+	if err != nil {
+		if err.GetStatusCode() == http.StatusNotFound {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, fmt.Errorf("gagal cek status Midtrans: %s", err.GetMessage())
+	}
+	if resp.StatusCode == "404" {
+		return nil, ErrTransactionNotFound
+	}
+	return &Notification{
+		OrderRef:          resp.OrderID,
+		TransactionID:     resp.TransactionID,
+		TransactionStatus: resp.TransactionStatus,
+		StatusCode:        resp.StatusCode,
+		GrossAmount:       resp.GrossAmount,
+		PaymentType:       resp.PaymentType,
+		FraudStatus:       resp.FraudStatus,
+		SignatureKey:      resp.SignatureKey,
+	}, nil
+}
+
+func (g *MidtransGateway) Refund(req RefundRequest) (*RefundResult, error) {
+	resp, err := g.Core.RefundTransaction(req.OrderRef, &coreapi.RefundReq{
+		RefundKey: req.RefundKey,
+		Amount:    req.Amount,
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gagal refund Midtrans: %s", err.GetMessage())
+	}
+	return &RefundResult{
+		RefundKey:         req.RefundKey,
+		Amount:            req.Amount,
+		TransactionStatus: resp.TransactionStatus,
+	}, nil
+}
@@ -0,0 +1,299 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/orderflow"
+	"github.com/akhdanrgya/telu-hub/internal/reservation"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrOrderNotFound = errors.New("order untuk notifikasi ini tidak ditemukan")
+
+// statusRank = prioritas status pembayaran. Notifikasi cuma diproses kalau prioritasnya
+// lebih tinggi dari status terakhir order, jadi "expire" yang telat nyampe gak bisa
+// nimpa order yang udah "settlement", dan notifikasi duplikat otomatis ke-skip.
+var statusRank = map[string]int{
+	StatusPending:       1,
+	StatusChallenge:     1,
+	StatusExpire:        2,
+	StatusDeny:          2,
+	StatusFailure:       2,
+	StatusCancel:        2,
+	StatusCapture:       3,
+	StatusSettlement:    3,
+	StatusPartialRefund: 4,
+	StatusRefund:        5,
+}
+
+// StatusRank balikin prioritas status pembayaran (0 = status gak dikenal / belum ada).
+func StatusRank(status string) int {
+	return statusRank[status]
+}
+
+// Processor nyatet tiap notifikasi gateway ke payment_events lalu nerapin ke order.
+type Processor struct {
+	DB           *gorm.DB
+	Gateway      Gateway
+	Reservations *reservation.Service
+	Orders       *orderflow.Service
+}
+
+func NewProcessor(db *gorm.DB, gateway Gateway, reservations *reservation.Service, orders *orderflow.Service) *Processor {
+	return &Processor{DB: db, Gateway: gateway, Reservations: reservations, Orders: orders}
+}
+
+// HandleNotification nyimpen notifikasi (sekali per transaction_id + status) terus
+// ngeprosesnya. Notifikasi yang udah pernah beres diproses gak diproses ulang.
+func (p *Processor) HandleNotification(notif *Notification, raw []byte, source string) (*models.PaymentEvent, error) {
+	event, err := p.record(notif, raw, source)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.State == models.PaymentEventProcessed || event.State == models.PaymentEventSkipped {
+		log.Printf("[PAYMENT] INFO: Notifikasi %s '%s' udah pernah diproses (event #%d), skip", event.OrderRef, event.TransactionStatus, event.ID)
+		return event, nil
+	}
+	return event, p.Process(event)
+}
+
+// Replay ngeproses ulang event yang udah kesimpen (buat debugging pembayaran yang disengketain).
+// Aman dipanggil berkali-kali karena tetep lewat pengecekan prioritas status.
+func (p *Processor) Replay(eventID uint) (*models.PaymentEvent, error) {
+	var event models.PaymentEvent
+	if err := p.DB.First(&event, eventID).Error; err != nil {
+		return nil, err
+	}
+	log.Printf("[PAYMENT] Replay event #%d (%s '%s')", event.ID, event.OrderRef, event.TransactionStatus)
+	return &event, p.Process(&event)
+}
+
+func (p *Processor) record(notif *Notification, raw []byte, source string) (*models.PaymentEvent, error) {
+	txID := notif.TransactionID
+	if txID == "" {
+		txID = notif.OrderRef
+	}
+
+	event := models.PaymentEvent{
+		OrderRef:          notif.OrderRef,
+		Gateway:           p.Gateway.Name(),
+		Source:            source,
+		TransactionID:     txID,
+		TransactionStatus: notif.EffectiveStatus(),
+		StatusCode:        notif.StatusCode,
+		GrossAmount:       notif.GrossAmount,
+		PaymentType:       notif.PaymentType,
+		FraudStatus:       notif.FraudStatus,
+		Payload:           string(raw),
+		State:             models.PaymentEventReceived,
+	}
+	if order, err := p.findOrder(p.DB, notif.OrderRef); err == nil {
+		event.OrderID = order.ID
+	}
+
+	result := p.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&event)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		// Notifikasi yang sama dikirim ulang gateway, pake baris yang udah ada
+		var existing models.PaymentEvent
+		err := p.DB.Where("transaction_id = ? AND transaction_status = ?", txID, notif.EffectiveStatus()).First(&existing).Error
+		if err != nil {
+			return nil, err
+		}
+		return &existing, nil
+	}
+	return &event, nil
+}
+
+// findOrder nyari order dari order_id gateway. Order lama (sebelum payment_ref disimpen)
+// masih dicari dari format TELUHUB-<id>-<ts>.
+func (p *Processor) findOrder(tx *gorm.DB, orderRef string) (*models.Order, error) {
+	var order models.Order
+	err := tx.Where("payment_ref = ?", orderRef).First(&order).Error
+	if err == nil {
+		return &order, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	parts := strings.Split(orderRef, "-")
+	if len(parts) < 3 || parts[0] != "TELUHUB" {
+		return nil, ErrOrderNotFound
+	}
+	id, parseErr := strconv.ParseUint(parts[1], 10, 64)
+	if parseErr != nil {
+		return nil, ErrOrderNotFound
+	}
+	if err := tx.Where("id = ? AND (payment_ref = '' OR payment_ref IS NULL)", id).First(&order).Error; err != nil {
+		return nil, ErrOrderNotFound
+	}
+	return &order, nil
+}
+
+// Process nerapin satu event ke order-nya, terus nyatet hasilnya di event itu.
+func (p *Processor) Process(event *models.PaymentEvent) error {
+	if event.OrderID == 0 {
+		order, err := p.findOrder(p.DB, event.OrderRef)
+		if err != nil {
+			if !strings.HasPrefix(event.OrderRef, "TELUHUB-") {
+				// Notifikasi tes dari dashboard Midtrans
+				return p.finish(event, models.PaymentEventSkipped, "Bukan transaksi TeluHub (notifikasi tes?)")
+			}
+			p.finish(event, models.PaymentEventFailed, "Order tidak ditemukan")
+			return ErrOrderNotFound
+		}
+		event.OrderID = order.ID
+	}
+
+	notif := &Notification{
+		OrderRef:          event.OrderRef,
+		TransactionID:     event.TransactionID,
+		TransactionStatus: event.TransactionStatus,
+		PaymentType:       event.PaymentType,
+		FraudStatus:       event.FraudStatus,
+	}
+
+	var order models.Order
+	var changedProductIDs []uint
+	var history *models.OrderStatusHistory
+	state := models.PaymentEventProcessed
+	note := ""
+
+	err := p.DB.Transaction(func(tx *gorm.DB) error {
+		// Kunci order biar notifikasi buat order yang sama diproses satu-satu
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, event.OrderID).Error; err != nil {
+			return err
+		}
+
+		if StatusRank(notif.EffectiveStatus()) <= StatusRank(order.PaymentStatus) {
+			state = models.PaymentEventSkipped
+			note = fmt.Sprintf("Status pembayaran order udah '%s', notifikasi '%s' diabaikan", order.PaymentStatus, notif.TransactionStatus)
+			return nil
+		}
+
+		switch {
+		case notif.IsPaid():
+			ids, h, skipNote, err := p.applyPaid(tx, &order, notif)
+			if err != nil {
+				return err
+			}
+			changedProductIDs, history = ids, h
+			if skipNote != "" {
+				state, note = models.PaymentEventSkipped, skipNote
+			}
+		case notif.IsFailed():
+			ids, h, skipNote, err := p.applyFailed(tx, &order, notif)
+			if err != nil {
+				return err
+			}
+			changedProductIDs, history = ids, h
+			if skipNote != "" {
+				state, note = models.PaymentEventSkipped, skipNote
+			}
+		default:
+			note = "Status pembayaran dicatat"
+		}
+
+		// Dana yang masuk tetep dicatat walau status order-nya gak bisa diubah, biar bisa di-refund
+		return tx.Model(&order).Update("payment_status", notif.EffectiveStatus()).Error
+	})
+
+	if err != nil {
+		log.Printf("[PAYMENT] ERROR: Gagal proses notifikasi %s '%s': %v", event.OrderRef, event.TransactionStatus, err)
+		p.finish(event, models.PaymentEventFailed, err.Error())
+
+		if notif.IsPaid() {
+			// Duit udah masuk tapi stok gak bisa dipenuhin: gagalin order yang masih pending
+			p.DB.First(&order, event.OrderID)
+			if order.Status == models.OrderStatusPending {
+				if failErr := p.Orders.TransitionAndNotify(&order, models.OrderStatusFailed, orderflow.SystemActor, err.Error()); failErr != nil {
+					log.Printf("[PAYMENT] WARNING: Gagal nge-fail-in Order %d: %v", order.ID, failErr)
+				}
+			}
+		}
+		return err
+	}
+
+	p.Reservations.Broadcast(changedProductIDs)
+	p.Orders.Notify(&order, history)
+
+	log.Printf("[PAYMENT] Notifikasi %s '%s' -> %s %s", event.OrderRef, event.TransactionStatus, state, note)
+	return p.finish(event, state, note)
+}
+
+func (p *Processor) applyPaid(tx *gorm.DB, order *models.Order, notif *Notification) ([]uint, *models.OrderStatusHistory, string, error) {
+	if !order.Status.CanTransitionTo(models.OrderStatusPaid) {
+		return nil, nil, fmt.Sprintf("Dana masuk tapi order udah '%s', perlu dicek buat refund", order.Status), nil
+	}
+
+	// Stok udah di-hold waktu checkout, tinggal di-commit
+	productIDs, err := p.Reservations.Commit(tx, order.ID)
+	if errors.Is(err, reservation.ErrNoActiveHold) {
+		// Hold-nya udah kelepas (TTL lewat) tapi pembayaran tetep masuk: coba hold ulang lalu commit
+		log.Printf("[PAYMENT] WARNING: Hold Order %d udah gak aktif, coba hold ulang", order.ID)
+		var items []models.OrderItem
+		if err := tx.Where("order_id = ?", order.ID).Preload("Product").Find(&items).Error; err != nil {
+			return nil, nil, "", fmt.Errorf("gagal ambil order items: %v", err)
+		}
+		if len(items) == 0 {
+			return nil, nil, "", fmt.Errorf("order %d gak punya item", order.ID)
+		}
+		if _, err := p.Reservations.Hold(tx, order.ID, items); err != nil {
+			return nil, nil, "", err
+		}
+		productIDs, err = p.Reservations.Commit(tx, order.ID)
+	}
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("gagal commit stok: %v", err)
+	}
+
+	history, err := p.Orders.Transition(tx, order, models.OrderStatusPaid, orderflow.SystemActor,
+		"Pembayaran "+notif.TransactionStatus+" via "+notif.PaymentType)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("gagal update status order: %v", err)
+	}
+	return productIDs, history, "", nil
+}
+
+func (p *Processor) applyFailed(tx *gorm.DB, order *models.Order, notif *Notification) ([]uint, *models.OrderStatusHistory, string, error) {
+	if order.Status != models.OrderStatusPending {
+		return nil, nil, fmt.Sprintf("Order udah '%s', notifikasi '%s' gak ngubah order", order.Status, notif.TransactionStatus), nil
+	}
+
+	productIDs, err := p.Reservations.Release(tx, order.ID, notif.TransactionStatus)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	history, err := p.Orders.Transition(tx, order, models.OrderStatusFailed, orderflow.SystemActor,
+		"Pembayaran "+notif.TransactionStatus)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return productIDs, history, "", nil
+}
+
+func (p *Processor) finish(event *models.PaymentEvent, state models.PaymentEventState, note string) error {
+	now := time.Now()
+	event.State = state
+	event.Note = note
+	event.Attempts++
+	event.ProcessedAt = &now
+	return p.DB.Model(event).Updates(map[string]interface{}{
+		"order_id":     event.OrderID,
+		"state":        state,
+		"note":         note,
+		"attempts":     event.Attempts,
+		"processed_at": now,
+	}).Error
+}
@@ -0,0 +1,145 @@
+package payment
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/akhdanrgya/telu-hub/internal/models"
+)
+
+var ErrReconcileRunning = errors.New("rekonsiliasi lagi jalan, coba lagi nanti")
+
+// Reconciler nge-poll status pembayaran order yang nyangkut ke gateway, buat jaga-jaga
+// kalau webhook-nya hilang. Hasilnya diproses lewat Processor yang sama dengan webhook.
+type Reconciler struct {
+	Processor  *Processor
+	StaleAfter time.Duration // Order pending baru dicek setelah umurnya segini
+	Lookback   time.Duration // Order failed yang masih dicek (siapa tau ternyata lunas)
+
+	mu sync.Mutex
+}
+
+type ReconcileResult struct {
+	OrderID       uint                     `json:"order_id"`
+	OrderRef      string                   `json:"order_ref"`
+	OrderStatus   models.OrderStatus       `json:"order_status"`
+	GatewayStatus string                   `json:"gateway_status"`
+	EventID       uint                     `json:"event_id,omitempty"`
+	State         models.PaymentEventState `json:"state,omitempty"`
+	Note          string                   `json:"note,omitempty"`
+	Error         string                   `json:"error,omitempty"`
+}
+
+type ReconcileReport struct {
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt time.Time         `json:"finished_at"`
+	Checked    int               `json:"checked"`
+	Changed    int               `json:"changed"`
+	Results    []ReconcileResult `json:"results"`
+}
+
+func NewReconciler(p *Processor, staleAfter time.Duration) *Reconciler {
+	return &Reconciler{Processor: p, StaleAfter: staleAfter, Lookback: 24 * time.Hour}
+}
+
+// RunOnce ngecek semua order pending yang udah basi (plus order failed yang baru
+// di-fail-in) ke gateway, dan nerapin status yang beda lewat Processor.
+func (r *Reconciler) RunOnce() (*ReconcileReport, error) {
+	if !r.mu.TryLock() {
+		return nil, ErrReconcileRunning
+	}
+	defer r.mu.Unlock()
+
+	report := &ReconcileReport{StartedAt: time.Now(), Results: make([]ReconcileResult, 0)}
+	db := r.Processor.DB
+
+	var orders []models.Order
+	err := db.Where("payment_ref <> '' AND ((status = ? AND created_at < ?) OR (status = ? AND updated_at > ?))",
+		models.OrderStatusPending, report.StartedAt.Add(-r.StaleAfter),
+		models.OrderStatusFailed, report.StartedAt.Add(-r.Lookback)).
+		Order("id asc").
+		Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, order := range orders {
+		result := r.reconcileOrder(&order, report.StartedAt)
+		if result == nil {
+			continue
+		}
+		report.Checked++
+		if result.State == models.PaymentEventProcessed {
+			report.Changed++
+		}
+		report.Results = append(report.Results, *result)
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+func (r *Reconciler) reconcileOrder(order *models.Order, now time.Time) *ReconcileResult {
+	result := &ReconcileResult{OrderID: order.ID, OrderRef: order.PaymentRef, OrderStatus: order.Status}
+	windowElapsed := now.After(order.CreatedAt.Add(r.Processor.Reservations.TTL))
+
+	notif, err := r.Processor.Gateway.GetStatus(order.PaymentRef)
+	switch {
+	case errors.Is(err, ErrTransactionNotFound):
+		// Buyer gak pernah buka halaman bayar, transaksinya belum ada di gateway
+		if order.Status != models.OrderStatusPending || !windowElapsed {
+			return nil
+		}
+		notif = &Notification{OrderRef: order.PaymentRef, TransactionStatus: StatusExpire}
+	case err != nil:
+		result.Error = err.Error()
+		return result
+	}
+	result.GatewayStatus = notif.TransactionStatus
+
+	// Gateway masih bilang pending padahal batas waktunya udah lewat: anggap expired
+	if notif.TransactionStatus == StatusPending && order.Status == models.OrderStatusPending && windowElapsed {
+		notif.TransactionStatus = StatusExpire
+	}
+
+	if StatusRank(notif.EffectiveStatus()) <= StatusRank(order.PaymentStatus) {
+		return nil
+	}
+	if order.Status == models.OrderStatusFailed && !notif.IsPaid() {
+		// Order failed cuma perlu diurus kalau ternyata dananya masuk
+		return nil
+	}
+
+	raw, _ := json.Marshal(notif)
+	event, err := r.Processor.HandleNotification(notif, raw, models.PaymentSourceReconcile)
+	if event != nil {
+		result.EventID = event.ID
+		result.State = event.State
+		result.Note = event.Note
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Run jalan terus di background, rekonsiliasi tiap interval.
+func (r *Reconciler) Run(interval time.Duration) {
+	log.Printf("[RECONCILER] Rekonsiliasi pembayaran jalan (cek tiap %s, order pending > %s)", interval, r.StaleAfter)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		report, err := r.RunOnce()
+		if err != nil {
+			log.Printf("[RECONCILER] WARNING: Gagal rekonsiliasi: %v", err)
+			continue
+		}
+		if report.Changed > 0 {
+			log.Printf("[RECONCILER] %d dari %d order disesuaiin sama status gateway", report.Changed, report.Checked)
+		}
+	}
+}
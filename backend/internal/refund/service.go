@@ -0,0 +1,505 @@
+package refund
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/notification"
+	"github.com/akhdanrgya/telu-hub/internal/orderflow"
+	"github.com/akhdanrgya/telu-hub/internal/payment"
+	"github.com/akhdanrgya/telu-hub/internal/reservation"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotRefundable   = errors.New("order ini belum dibayar atau sudah ditutup, tidak bisa di-refund")
+	ErrNoPaymentRef    = errors.New("order ini gak punya referensi pembayaran, refund harus manual")
+	ErrInvalidItem     = errors.New("item refund tidak valid")
+	ErrNothingToRefund = errors.New("tidak ada item yang bisa di-refund")
+	ErrAlreadyShipped  = errors.New("pesanan sudah dikirim, tidak bisa dibatalkan")
+	ErrGatewayRefund   = errors.New("payment gateway menolak refund")
+	ErrUseOrderCancel  = errors.New("order belum dibayar, batalkan order-nya langsung")
+)
+
+// ItemRequest = item yang mau di-refund. Quantity 0 = semua sisa quantity item itu.
+type ItemRequest struct {
+	OrderItemID uint `json:"order_item_id"`
+	Quantity    int  `json:"quantity"`
+}
+
+type Request struct {
+	OrderID       uint
+	SellerOrderID uint          // 0 = item dari sub-order mana aja (admin / buyer batalin order)
+	Items         []ItemRequest // Kosong = semua sisa item
+	Reason        string
+	Restock       bool
+	Actor         orderflow.Actor
+	// CloseAs = status sub-order kalau semua item-nya udah balik: refunded (default) atau cancelled
+	CloseAs models.OrderStatus
+}
+
+// Service ngatur pengembalian dana lewat payment gateway + balikin stok yang batal kejual.
+type Service struct {
+	DB           *gorm.DB
+	Gateway      payment.Gateway
+	Reservations *reservation.Service
+	Orders       *orderflow.Service
+	NotifService *notification.Service
+}
+
+func NewService(db *gorm.DB, gateway payment.Gateway, reservations *reservation.Service, orders *orderflow.Service, notifService *notification.Service) *Service {
+	return &Service{DB: db, Gateway: gateway, Reservations: reservations, Orders: orders, NotifService: notifService}
+}
+
+// CancelOrder dipake buyer buat batalin order yang udah dibayar tapi belum dikirim:
+// semua sisa item di-refund dan stoknya dibalikin.
+func (s *Service) CancelOrder(order *models.Order, actor orderflow.Actor, reason string) (*models.Refund, error) {
+	if order.Status == models.OrderStatusPending {
+		return nil, ErrUseOrderCancel
+	}
+	var subs []models.SellerOrder
+	if err := s.DB.Where("order_id = ?", order.ID).Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	for _, sub := range subs {
+		if !sub.Status.IsClosed() && sub.Status != models.OrderStatusPaid && sub.Status != models.OrderStatusProcessing {
+			return nil, ErrAlreadyShipped
+		}
+	}
+
+	return s.Refund(Request{
+		OrderID: order.ID,
+		Reason:  reason,
+		Restock: true,
+		Actor:   actor,
+		CloseAs: models.OrderStatusCancelled,
+	})
+}
+
+// CancelSellerOrder = CancelOrder tapi cuma buat item dari satu seller.
+func (s *Service) CancelSellerOrder(sub *models.SellerOrder, actor orderflow.Actor, reason string) (*models.Refund, error) {
+	switch sub.Status {
+	case models.OrderStatusPending:
+		return nil, ErrUseOrderCancel
+	case models.OrderStatusPaid, models.OrderStatusProcessing:
+	default:
+		if sub.Status.IsClosed() {
+			return nil, ErrNotRefundable
+		}
+		return nil, ErrAlreadyShipped
+	}
+
+	return s.Refund(Request{
+		OrderID:       sub.OrderID,
+		SellerOrderID: sub.ID,
+		Reason:        reason,
+		Restock:       true,
+		Actor:         actor,
+		CloseAs:       models.OrderStatusCancelled,
+	})
+}
+
+// Refund ngembaliin dana item yang diminta lewat gateway. Jumlah item dikunci dulu di DB
+// (biar dua refund barengan gak ngelebihin quantity), baru gateway dipanggil, terus
+// stok & status sub-order disesuaiin setelah gateway bilang sukses.
+func (s *Service) Refund(req Request) (*models.Refund, error) {
+	if req.CloseAs == "" {
+		req.CloseAs = models.OrderStatusRefunded
+	}
+
+	refund, order, err := s.reserve(req)
+	if err != nil {
+		return nil, err
+	}
+
+	result, gwErr := s.Gateway.Refund(payment.RefundRequest{
+		OrderRef:  order.PaymentRef,
+		RefundKey: refund.RefundKey,
+		Amount:    int64(refund.Amount),
+		Reason:    refund.Reason,
+	})
+	if gwErr != nil {
+		log.Printf("[REFUND] ERROR: Refund %s Order %d ditolak gateway: %v", refund.RefundKey, order.ID, gwErr)
+		if err := s.rollback(refund, gwErr.Error()); err != nil {
+			log.Printf("[REFUND] WARNING: Gagal balikin quantity refund %s: %v", refund.RefundKey, err)
+		}
+		return refund, fmt.Errorf("%w: %v", ErrGatewayRefund, gwErr)
+	}
+
+	var restockedIDs []uint
+	var closed []closedSub
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		refund.Status = models.RefundSucceeded
+		refund.GatewayStatus = result.TransactionStatus
+		if err := tx.Model(refund).Updates(map[string]interface{}{
+			"status":         refund.Status,
+			"gateway_status": refund.GatewayStatus,
+		}).Error; err != nil {
+			return err
+		}
+
+		if refund.Restock {
+			for _, item := range refund.Items {
+				if item.IsShipping() {
+					continue
+				}
+				if item.VariantID != nil {
+					err := tx.Model(&models.ProductVariant{}).Where("id = ?", *item.VariantID).
+						Update("stock", gorm.Expr("stock + ?", item.Quantity)).Error
+					if err != nil {
+						return err
+					}
+				}
+				err := tx.Model(&models.Product{}).Where("id = ?", item.ProductID).
+					Update("stock", gorm.Expr("stock + ?", item.Quantity)).Error
+				if err != nil {
+					return err
+				}
+				restockedIDs = append(restockedIDs, item.ProductID)
+			}
+		}
+
+		paymentStatus := payment.StatusPartialRefund
+		var remaining int64
+		tx.Model(&models.OrderItem{}).Where("order_id = ? AND refunded_quantity < quantity", order.ID).Count(&remaining)
+		if remaining == 0 {
+			paymentStatus = payment.StatusRefund
+		}
+		if err := tx.Model(&models.Order{}).Where("id = ?", order.ID).Update("payment_status", paymentStatus).Error; err != nil {
+			return err
+		}
+
+		closed, err = s.closeFinishedSubOrders(tx, refund, req)
+		return err
+	})
+	if err != nil {
+		// Dana udah balik di gateway, jadi jangan di-rollback; cukup dicatat buat dicek admin
+		log.Printf("[REFUND] ERROR: Refund %s sukses di gateway tapi gagal dicatat: %v", refund.RefundKey, err)
+		return refund, err
+	}
+
+	s.Reservations.Broadcast(restockedIDs)
+	for _, c := range closed {
+		s.Orders.NotifySellerOrder(c.sub, c.history)
+	}
+	s.notifyRefund(order, refund)
+
+	log.Printf("[REFUND] Refund %s Order %d sukses: Rp %.0f (%d item)", refund.RefundKey, order.ID, refund.Amount, len(refund.Items))
+	return refund, nil
+}
+
+// reserve ngecek & ngunci quantity yang mau di-refund, lalu nyimpen Refund berstatus pending.
+func (s *Service) reserve(req Request) (*models.Refund, *models.Order, error) {
+	var order models.Order
+	var refund *models.Refund
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, req.OrderID).Error; err != nil {
+			return err
+		}
+		switch order.Status {
+		case models.OrderStatusPending, models.OrderStatusFailed, models.OrderStatusCancelled, models.OrderStatusRefunded:
+			return ErrNotRefundable
+		}
+		if order.PaymentRef == "" {
+			return ErrNoPaymentRef
+		}
+
+		query := tx.Where("order_id = ?", order.ID)
+		if req.SellerOrderID != 0 {
+			query = query.Where("seller_order_id = ?", req.SellerOrderID)
+		}
+		var items []models.OrderItem
+		if err := query.Order("id asc").Find(&items).Error; err != nil {
+			return err
+		}
+
+		refundItems, err := pickItems(items, req.Items)
+		if err != nil {
+			return err
+		}
+		// Sub-order yang dibatalin gak jadi dikirim, jadi ongkirnya ikut dibalikin
+		if req.CloseAs == models.OrderStatusCancelled {
+			shipping, err := shippingLines(tx, items, refundItems)
+			if err != nil {
+				return err
+			}
+			refundItems = append(refundItems, shipping...)
+		}
+
+		refund = &models.Refund{
+			OrderID:       order.ID,
+			RefundKey:     fmt.Sprintf("RF-%d-%d", order.ID, time.Now().UnixNano()),
+			Reason:        req.Reason,
+			Status:        models.RefundPending,
+			Restock:       req.Restock,
+			RequestedRole: req.Actor.Role,
+			Items:         refundItems,
+		}
+		if req.Actor.UserID != 0 {
+			actorID := req.Actor.UserID
+			refund.RequestedByID = &actorID
+		}
+		for _, item := range refundItems {
+			refund.Amount += item.Amount
+		}
+		if err := tx.Create(refund).Error; err != nil {
+			return err
+		}
+
+		for _, item := range refundItems {
+			if item.IsShipping() {
+				continue
+			}
+			// Syarat di WHERE jaga-jaga kalau ada refund lain yang nyelip
+			result := tx.Model(&models.OrderItem{}).
+				Where("id = ? AND refunded_quantity + ? <= quantity", item.OrderItemID, item.Quantity).
+				Update("refunded_quantity", gorm.Expr("refunded_quantity + ?", item.Quantity))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("%w: item #%d udah di-refund", ErrInvalidItem, item.OrderItemID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return refund, &order, nil
+}
+
+// pickItems nerjemahin permintaan jadi RefundItem. Tanpa permintaan = semua sisa item.
+func pickItems(items []models.OrderItem, requested []ItemRequest) ([]models.RefundItem, error) {
+	byID := make(map[uint]models.OrderItem, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	if len(requested) == 0 {
+		for _, item := range items {
+			requested = append(requested, ItemRequest{OrderItemID: item.ID})
+		}
+	}
+
+	seen := make(map[uint]bool)
+	var result []models.RefundItem
+	for _, r := range requested {
+		item, ok := byID[r.OrderItemID]
+		if !ok || seen[r.OrderItemID] {
+			return nil, fmt.Errorf("%w: item #%d", ErrInvalidItem, r.OrderItemID)
+		}
+		seen[r.OrderItemID] = true
+
+		left := item.Quantity - item.RefundedQuantity
+		qty := r.Quantity
+		if qty == 0 {
+			qty = left
+		}
+		if qty < 0 || qty > left {
+			return nil, fmt.Errorf("%w: item #%d cuma bisa di-refund maksimal %d", ErrInvalidItem, item.ID, left)
+		}
+		if qty == 0 {
+			continue
+		}
+
+		sellerOrderID := uint(0)
+		if item.SellerOrderID != nil {
+			sellerOrderID = *item.SellerOrderID
+		}
+		orderItemID := item.ID
+		result = append(result, models.RefundItem{
+			OrderItemID:   &orderItemID,
+			SellerOrderID: sellerOrderID,
+			ProductID:     item.ProductID,
+			VariantID:     item.VariantID,
+			Quantity:      qty,
+			Amount:        item.RefundAmount(qty),
+		})
+	}
+
+	if len(result) == 0 {
+		return nil, ErrNothingToRefund
+	}
+	return result, nil
+}
+
+// shippingLines = baris refund ongkir buat sub-order yang semua sisa item-nya ikut di refund ini
+// (jadi bakal ditutup) dan masih bisa dibatalin. items = semua item order / sub-order yang di-refund.
+func shippingLines(tx *gorm.DB, items []models.OrderItem, refundItems []models.RefundItem) ([]models.RefundItem, error) {
+	refundQty := make(map[uint]int, len(refundItems))
+	for _, ri := range refundItems {
+		refundQty[*ri.OrderItemID] += ri.Quantity
+	}
+	finished := make(map[uint]bool)
+	for _, item := range items {
+		if item.SellerOrderID == nil {
+			continue
+		}
+		subID := *item.SellerOrderID
+		done := item.RefundedQuantity+refundQty[item.ID] >= item.Quantity
+		if _, seen := finished[subID]; !seen {
+			finished[subID] = done
+		} else {
+			finished[subID] = finished[subID] && done
+		}
+	}
+
+	var subIDs []uint
+	for subID, done := range finished {
+		if done {
+			subIDs = append(subIDs, subID)
+		}
+	}
+	if len(subIDs) == 0 {
+		return nil, nil
+	}
+	var subs []models.SellerOrder
+	if err := tx.Where("id IN ? AND shipping_fee > 0", subIDs).Order("id asc").Find(&subs).Error; err != nil {
+		return nil, err
+	}
+
+	var lines []models.RefundItem
+	for _, sub := range subs {
+		if !sub.Status.CanTransitionTo(models.OrderStatusCancelled) {
+			continue
+		}
+		lines = append(lines, models.RefundItem{SellerOrderID: sub.ID, Amount: sub.ShippingFee})
+	}
+	return lines, nil
+}
+
+func (s *Service) rollback(refund *models.Refund, reason string) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		for _, item := range refund.Items {
+			if item.IsShipping() {
+				continue
+			}
+			err := tx.Model(&models.OrderItem{}).Where("id = ?", item.OrderItemID).
+				Update("refunded_quantity", gorm.Expr("refunded_quantity - ?", item.Quantity)).Error
+			if err != nil {
+				return err
+			}
+		}
+		refund.Status = models.RefundFailed
+		refund.FailureReason = reason
+		return tx.Model(refund).Updates(map[string]interface{}{
+			"status":         refund.Status,
+			"failure_reason": reason,
+		}).Error
+	})
+}
+
+type closedSub struct {
+	sub     *models.SellerOrder
+	history *models.OrderStatusHistory
+}
+
+// closeFinishedSubOrders nutup sub-order yang semua item-nya udah di-refund.
+func (s *Service) closeFinishedSubOrders(tx *gorm.DB, refund *models.Refund, req Request) ([]closedSub, error) {
+	subIDs := make(map[uint]bool)
+	for _, item := range refund.Items {
+		if item.SellerOrderID != 0 {
+			subIDs[item.SellerOrderID] = true
+		}
+	}
+
+	var closed []closedSub
+	for subID := range subIDs {
+		var left int64
+		err := tx.Model(&models.OrderItem{}).
+			Where("seller_order_id = ? AND refunded_quantity < quantity", subID).
+			Count(&left).Error
+		if err != nil {
+			return nil, err
+		}
+		if left > 0 {
+			continue
+		}
+
+		var sub models.SellerOrder
+		if err := tx.First(&sub, subID).Error; err != nil {
+			return nil, err
+		}
+		if !sub.Status.CanTransitionTo(req.CloseAs) {
+			continue
+		}
+		reason := req.Reason
+		if reason == "" {
+			reason = "Semua item di-refund"
+		}
+		history, err := s.Orders.TransitionSellerOrder(tx, &sub, req.CloseAs, req.Actor, reason)
+		if err != nil {
+			return nil, err
+		}
+		closed = append(closed, closedSub{sub: &sub, history: history})
+	}
+	return closed, nil
+}
+
+func (s *Service) notifyRefund(order *models.Order, refund *models.Refund) {
+	var items []models.OrderItem
+	ids := make([]uint, 0, len(refund.Items))
+	qty := make(map[uint]int, len(refund.Items))
+	amount := make(map[uint]float64, len(refund.Items))
+	var shippingFee float64
+	for _, item := range refund.Items {
+		if item.IsShipping() {
+			shippingFee += item.Amount
+			continue
+		}
+		ids = append(ids, *item.OrderItemID)
+		qty[*item.OrderItemID] = item.Quantity
+		amount[*item.OrderItemID] = item.Amount
+	}
+	s.DB.Preload("Product").Where("id IN ?", ids).Find(&items)
+
+	sellerProducts := make(map[uint][]string)
+	sellerAmount := make(map[uint]float64)
+	var all []string
+	for _, item := range items {
+		if item.Product == nil {
+			continue
+		}
+		line := fmt.Sprintf("%s (x%d)", item.ProductName(), qty[item.ID])
+		sellerProducts[item.Product.SellerID] = append(sellerProducts[item.Product.SellerID], line)
+		sellerAmount[item.Product.SellerID] += amount[item.ID]
+		all = append(all, line)
+	}
+
+	message := fmt.Sprintf("Dana Rp %.0f untuk order #%d (%s) sudah dikembalikan.", refund.Amount, order.ID, strings.Join(all, ", "))
+	if shippingFee > 0 {
+		message = fmt.Sprintf("Dana Rp %.0f untuk order #%d (%s, plus ongkir Rp %.0f) sudah dikembalikan.", refund.Amount, order.ID, strings.Join(all, ", "), shippingFee)
+	}
+	if refund.Reason != "" {
+		message += " Alasan: " + refund.Reason
+	}
+	go s.send(order.UserID, "Refund Diproses 💸", message, order.ID)
+
+	for sellerID, products := range sellerProducts {
+		msg := fmt.Sprintf("Refund Rp %.0f untuk order #%d (%s) sudah diproses.", sellerAmount[sellerID], order.ID, strings.Join(products, ", "))
+		if refund.Restock {
+			msg += " Stok sudah dikembalikan."
+		}
+		go s.send(sellerID, "Pesanan Di-refund", msg, order.ID)
+	}
+}
+
+func (s *Service) send(userID uint, title, message string, orderID uint) {
+	if err := s.NotifService.CreateAndSend(userID, models.NotificationTypeOrder, title, message, orderID); err != nil {
+		log.Printf("[REFUND] WARNING: Gagal kirim notif ke user %d: %v", userID, err)
+	}
+}
+
+// GetOrderRefunds balikin semua refund order (terbaru duluan).
+func (s *Service) GetOrderRefunds(orderID uint) ([]models.Refund, error) {
+	var refunds []models.Refund
+	err := s.DB.Preload("Items").Where("order_id = ?", orderID).Order("created_at desc").Find(&refunds).Error
+	return refunds, err
+}
@@ -0,0 +1,251 @@
+package reservation
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/orderflow"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	grpc_service "github.com/akhdanrgya/telu-hub/internal/grpc_service"
+	"github.com/akhdanrgya/telu-hub/internal/stockalert"
+)
+
+var (
+	ErrInsufficientStock = errors.New("stok tidak cukup")
+	ErrNoActiveHold      = errors.New("tidak ada stok yang di-hold untuk order ini")
+)
+
+// Service ngatur hold stok: di-hold waktu order dibuat, di-commit waktu lunas,
+// dan dilepas lagi kalau pembayaran gagal/expired atau TTL-nya lewat.
+type Service struct {
+	DB           *gorm.DB
+	StockService *grpc_service.StockService
+	Orders       *orderflow.Service
+	StockAlerts  *stockalert.Service
+	TTL          time.Duration
+}
+
+func NewService(db *gorm.DB, stockService *grpc_service.StockService, orders *orderflow.Service, stockAlerts *stockalert.Service, ttl time.Duration) *Service {
+	return &Service{DB: db, StockService: stockService, Orders: orders, StockAlerts: stockAlerts, TTL: ttl}
+}
+
+// Hold nge-reserve stok semua item order secara atomik. Harus dipanggil di dalam
+// transaksi yang sama dengan pembuatan order, biar kalau gagal semuanya ke-rollback.
+func (s *Service) Hold(tx *gorm.DB, orderID uint, items []models.OrderItem) (time.Time, error) {
+	expiresAt := time.Now().Add(s.TTL)
+
+	for _, item := range items {
+		name := fmt.Sprintf("produk #%d", item.ProductID)
+		if item.Product != nil {
+			name = item.Product.Name
+		}
+
+		if item.VariantID != nil {
+			// Produk bervarian: stok yang dicek stok variannya, stok produk cuma ikut ringkasan
+			result := tx.Model(&models.ProductVariant{}).
+				Where("id = ? AND product_id = ? AND stock - reserved >= ?", *item.VariantID, item.ProductID, item.Quantity).
+				Update("reserved", gorm.Expr("reserved + ?", item.Quantity))
+			if result.Error != nil {
+				return time.Time{}, result.Error
+			}
+			if result.RowsAffected == 0 {
+				return time.Time{}, fmt.Errorf("%w untuk %s (%s)", ErrInsufficientStock, name, item.VariantName)
+			}
+		}
+
+		// Cek & tambah reserved dalam satu UPDATE, jadi dua buyer gak bisa rebutan unit terakhir
+		result := tx.Model(&models.Product{}).
+			Where("id = ? AND stock - reserved >= ?", item.ProductID, item.Quantity).
+			Update("reserved", gorm.Expr("reserved + ?", item.Quantity))
+		if result.Error != nil {
+			return time.Time{}, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return time.Time{}, fmt.Errorf("%w untuk %s", ErrInsufficientStock, name)
+		}
+
+		hold := models.StockReservation{
+			OrderID:   orderID,
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+			Status:    models.ReservationHeld,
+			ExpiresAt: expiresAt,
+		}
+		if err := tx.Create(&hold).Error; err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	return expiresAt, nil
+}
+
+// Commit ngubah hold jadi pengurangan stok beneran (dipanggil waktu settlement).
+// Balikin ID produk yang berubah biar bisa disiarin setelah transaksi commit.
+func (s *Service) Commit(tx *gorm.DB, orderID uint) ([]uint, error) {
+	holds, err := s.lockHolds(tx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(holds) == 0 {
+		return nil, ErrNoActiveHold
+	}
+
+	var productIDs []uint
+	for _, hold := range holds {
+		changes := func() map[string]interface{} {
+			return map[string]interface{}{
+				"stock":    gorm.Expr("stock - ?", hold.Quantity),
+				"reserved": gorm.Expr("reserved - ?", hold.Quantity),
+			}
+		}
+		if hold.VariantID != nil {
+			if err := tx.Model(&models.ProductVariant{}).Where("id = ?", *hold.VariantID).Updates(changes()).Error; err != nil {
+				return nil, err
+			}
+		}
+		if err := tx.Model(&models.Product{}).Where("id = ?", hold.ProductID).Updates(changes()).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Model(&hold).Update("status", models.ReservationCommitted).Error; err != nil {
+			return nil, err
+		}
+		productIDs = append(productIDs, hold.ProductID)
+	}
+	return productIDs, nil
+}
+
+// Release ngelepas semua hold order yang masih aktif dan balikin stoknya.
+func (s *Service) Release(tx *gorm.DB, orderID uint, reason string) ([]uint, error) {
+	holds, err := s.lockHolds(tx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var productIDs []uint
+	for _, hold := range holds {
+		if hold.VariantID != nil {
+			err := tx.Model(&models.ProductVariant{}).Where("id = ?", *hold.VariantID).
+				Update("reserved", gorm.Expr("reserved - ?", hold.Quantity)).Error
+			if err != nil {
+				return nil, err
+			}
+		}
+		err := tx.Model(&models.Product{}).Where("id = ?", hold.ProductID).
+			Update("reserved", gorm.Expr("reserved - ?", hold.Quantity)).Error
+		if err != nil {
+			return nil, err
+		}
+		err = tx.Model(&hold).Updates(map[string]interface{}{
+			"status":         models.ReservationReleased,
+			"release_reason": reason,
+		}).Error
+		if err != nil {
+			return nil, err
+		}
+		productIDs = append(productIDs, hold.ProductID)
+	}
+	return productIDs, nil
+}
+
+func (s *Service) lockHolds(tx *gorm.DB, orderID uint) ([]models.StockReservation, error) {
+	var holds []models.StockReservation
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND status = ?", orderID, models.ReservationHeld).
+		Find(&holds).Error
+	return holds, err
+}
+
+// Broadcast ngambil stok terbaru produk (plus semua variannya), nyiarin ke penonton gRPC,
+// terus ngabarin yang langganan "kabari saya" kalau stoknya udah ada lagi.
+// Panggil SETELAH transaksi commit biar yang disiarin data final.
+func (s *Service) Broadcast(productIDs []uint) {
+	if len(productIDs) == 0 {
+		return
+	}
+	var products []models.Product
+	if err := s.DB.Select("id", "stock", "reserved").Where("id IN ?", productIDs).Find(&products).Error; err != nil {
+		log.Printf("[RESERVATION] WARNING: Gagal ambil stok buat disiarin: %v", err)
+		return
+	}
+	for _, p := range products {
+		s.StockService.BroadcastStockUpdate(p.ID, 0, p.Stock, p.Reserved)
+	}
+
+	var variants []models.ProductVariant
+	if err := s.DB.Select("id", "product_id", "stock", "reserved").Where("product_id IN ?", productIDs).Find(&variants).Error; err != nil {
+		log.Printf("[RESERVATION] WARNING: Gagal ambil stok varian buat disiarin: %v", err)
+		return
+	}
+	for _, v := range variants {
+		s.StockService.BroadcastStockUpdate(v.ProductID, v.ID, v.Stock, v.Reserved)
+	}
+
+	// Stok yang balik (order batal, hold expired, refund) bisa bikin produk habis ada lagi
+	s.StockAlerts.Notify(productIDs)
+}
+
+// ReleaseExpired ngelepas hold yang TTL-nya udah lewat dan nge-fail-in order pending-nya.
+func (s *Service) ReleaseExpired() (int, error) {
+	var orderIDs []uint
+	err := s.DB.Model(&models.StockReservation{}).
+		Where("status = ? AND expires_at < ?", models.ReservationHeld, time.Now()).
+		Distinct().Pluck("order_id", &orderIDs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, orderID := range orderIDs {
+		var order models.Order
+		var productIDs []uint
+		var history *models.OrderStatusHistory
+		err := s.DB.Transaction(func(tx *gorm.DB) error {
+			ids, err := s.Release(tx, orderID, "expired")
+			if err != nil {
+				return err
+			}
+			productIDs = ids
+
+			if err := tx.First(&order, orderID).Error; err != nil {
+				return err
+			}
+			if order.Status != models.OrderStatusPending {
+				return nil
+			}
+			history, err = s.Orders.Transition(tx, &order, models.OrderStatusFailed, orderflow.SystemActor, "Batas waktu pembayaran habis")
+			return err
+		})
+		if err != nil {
+			log.Printf("[RESERVATION] WARNING: Gagal lepas hold Order %d: %v", orderID, err)
+			continue
+		}
+		s.Broadcast(productIDs)
+		s.Orders.Notify(&order, history)
+		released++
+	}
+	return released, nil
+}
+
+// RunExpiryWorker jalan terus di background, ngecek hold yang expired tiap interval.
+func (s *Service) RunExpiryWorker(interval time.Duration) {
+	log.Printf("[RESERVATION] Worker hold stok jalan (TTL %s, cek tiap %s)", s.TTL, interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		count, err := s.ReleaseExpired()
+		if err != nil {
+			log.Printf("[RESERVATION] WARNING: Gagal cek hold expired: %v", err)
+			continue
+		}
+		if count > 0 {
+			log.Printf("[RESERVATION] %d order expired, stok di-hold dibalikin", count)
+		}
+	}
+}
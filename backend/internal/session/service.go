@@ -0,0 +1,264 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRefresh  = errors.New("refresh token tidak valid atau sudah expired")
+	ErrRefreshReused   = errors.New("refresh token lama dipake lagi, session di-revoke demi keamanan")
+	ErrSessionNotFound = errors.New("session tidak ditemukan")
+	ErrSessionRevoked  = errors.New("session sudah logout atau expired, silakan login ulang")
+	ErrTokenStale      = errors.New("token diterbitin sebelum role/password berubah, silakan refresh token")
+)
+
+// reuseGrace = jeda setelah rotasi di mana refresh token lama yang dipake lagi cuma ditolak,
+// belum dianggep dicuri. Dua tab yang refresh barengan gak langsung nge-logout-in user.
+const reuseGrace = 30 * time.Second
+
+// Tokens = pasangan token yang dibalikin ke client waktu login / refresh.
+type Tokens struct {
+	SessionID        uint      `json:"session_id"`
+	AccessToken      string    `json:"access_token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// Service ngatur session login: access token JWT umur pendek + refresh token acak yang
+// disimpen (hash-nya) di server dan diganti tiap dipake.
+type Service struct {
+	DB         *gorm.DB
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+func NewService(db *gorm.DB, accessTTL, refreshTTL time.Duration) *Service {
+	return &Service{DB: db, AccessTTL: accessTTL, RefreshTTL: refreshTTL}
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func newRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func (s *Service) issue(sess *models.Session, user *models.User, refreshToken string) (*Tokens, error) {
+	access, expiresAt, err := utils.GenerateToken(user.ID, user.Role, sess.ID, s.AccessTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &Tokens{
+		SessionID:        sess.ID,
+		AccessToken:      access,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: sess.ExpiresAt,
+	}, nil
+}
+
+// Start bikin session baru buat user yang barusan login.
+func (s *Service) Start(user *models.User, userAgent, ip string) (*Tokens, error) {
+	raw, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	sess := models.Session{
+		UserID:     user.ID,
+		TokenHash:  hashToken(raw),
+		UserAgent:  truncate(userAgent, 255),
+		IPAddress:  truncate(ip, 64),
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(s.RefreshTTL),
+	}
+	if err := s.DB.Create(&sess).Error; err != nil {
+		return nil, err
+	}
+
+	// Sekalian beresin session lama user ini yang udah expired
+	s.DB.Where("user_id = ? AND expires_at < ?", user.ID, now).Delete(&models.Session{})
+
+	return s.issue(&sess, user, raw)
+}
+
+// Rotate nuker refresh token dengan pasangan token baru. Refresh token lama langsung mati;
+// kalau token yang udah dirotasi dipake lagi (lewat dari reuseGrace), session-nya di-revoke.
+func (s *Service) Rotate(refreshToken, userAgent, ip string) (*Tokens, *models.User, error) {
+	if refreshToken == "" {
+		return nil, nil, ErrInvalidRefresh
+	}
+	hash := hashToken(refreshToken)
+	now := time.Now()
+
+	var sess models.Session
+	if err := s.DB.Where("token_hash = ?", hash).First(&sess).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, err
+		}
+		return nil, nil, s.checkReuse(hash, now)
+	}
+	if !sess.Active(now) {
+		return nil, nil, ErrInvalidRefresh
+	}
+
+	var user models.User
+	if err := s.DB.First(&user, sess.UserID).Error; err != nil {
+		return nil, nil, ErrInvalidRefresh
+	}
+
+	raw, err := newRefreshToken()
+	if err != nil {
+		return nil, nil, err
+	}
+	updates := map[string]interface{}{
+		"token_hash":    hashToken(raw),
+		"previous_hash": hash,
+		"last_used_at":  now,
+		"expires_at":    now.Add(s.RefreshTTL),
+	}
+	if userAgent != "" {
+		updates["user_agent"] = truncate(userAgent, 255)
+	}
+	if ip != "" {
+		updates["ip_address"] = truncate(ip, 64)
+	}
+	// Cuma berhasil kalau token-nya belum keburu dirotasi request lain
+	result := s.DB.Model(&models.Session{}).Where("id = ? AND token_hash = ?", sess.ID, hash).Updates(updates)
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil, ErrInvalidRefresh
+	}
+	sess.ExpiresAt = updates["expires_at"].(time.Time)
+
+	tokens, err := s.issue(&sess, &user, raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, &user, nil
+}
+
+func (s *Service) checkReuse(hash string, now time.Time) error {
+	var sess models.Session
+	if err := s.DB.Where("previous_hash = ?", hash).First(&sess).Error; err != nil {
+		return ErrInvalidRefresh
+	}
+	if sess.RevokedAt != nil || now.Sub(sess.LastUsedAt) < reuseGrace {
+		return ErrInvalidRefresh
+	}
+	s.DB.Model(&sess).Update("revoked_at", now)
+	log.Printf("[AUTH] WARNING: Refresh token lama session #%d (user #%d) dipake lagi, session di-revoke", sess.ID, sess.UserID)
+	return ErrRefreshReused
+}
+
+// Authenticate ngecek access token masih boleh dipake: session-nya belum logout/expired,
+// role di token masih sama, dan token-nya gak lebih tua dari users.tokens_valid_after.
+func (s *Service) Authenticate(claims *utils.JWTClaims) error {
+	if claims.SessionID == 0 {
+		// Token format lama (sebelum ada session)
+		return ErrSessionRevoked
+	}
+
+	var row struct {
+		RevokedAt        *time.Time
+		ExpiresAt        time.Time
+		Role             string
+		TokensValidAfter *time.Time
+	}
+	result := s.DB.Table("sessions").
+		Select("sessions.revoked_at, sessions.expires_at, users.role, users.tokens_valid_after").
+		Joins("JOIN users ON users.id = sessions.user_id AND users.deleted_at IS NULL").
+		Where("sessions.id = ? AND sessions.user_id = ?", claims.SessionID, claims.UserID).
+		Scan(&row)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 || row.RevokedAt != nil || time.Now().After(row.ExpiresAt) {
+		return ErrSessionRevoked
+	}
+	if row.Role != claims.Role {
+		return ErrTokenStale
+	}
+	// iat di JWT cuma presisi detik
+	if row.TokensValidAfter != nil && claims.IssuedAt != nil &&
+		claims.IssuedAt.Time.Before(row.TokensValidAfter.Truncate(time.Second)) {
+		return ErrTokenStale
+	}
+	return nil
+}
+
+// List = session user yang masih aktif, paling baru dipake duluan.
+func (s *Service) List(userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	err := s.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at desc").Find(&sessions).Error
+	return sessions, err
+}
+
+// Revoke nge-logout satu session milik user.
+func (s *Service) Revoke(userID, sessionID uint) error {
+	result := s.DB.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeToken nge-logout session pemilik refresh token ini (buat logout tanpa access token).
+func (s *Service) RevokeToken(refreshToken string) error {
+	result := s.DB.Model(&models.Session{}).
+		Where("token_hash = ? AND revoked_at IS NULL", hashToken(refreshToken)).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeAll nge-logout semua session user kecuali exceptID (0 = semuanya).
+func (s *Service) RevokeAll(db *gorm.DB, userID, exceptID uint) (int64, error) {
+	result := db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL AND id <> ?", userID, exceptID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// InvalidateAccessTokens bikin semua access token user yang udah terbit ditolak. Session-nya
+// tetep hidup, jadi client cukup refresh buat dapet token dengan data (role) terbaru.
+func (s *Service) InvalidateAccessTokens(db *gorm.DB, userID uint) error {
+	return db.Model(&models.User{}).Where("id = ?", userID).Update("tokens_valid_after", time.Now()).Error
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
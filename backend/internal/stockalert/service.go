@@ -0,0 +1,148 @@
+package stockalert
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"github.com/akhdanrgya/telu-hub/internal/notification"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProductNotFound = errors.New("produk tidak ditemukan")
+	ErrVariantNotFound = errors.New("varian produk tidak ditemukan")
+	ErrInStock         = errors.New("produk ini masih ada stoknya, langsung beli aja")
+)
+
+// Service ngurus langganan "kabari saya kalau stok ada lagi". Notify dipanggil tiap
+// stok produk bisa nambah (restock seller, order batal, hold expired, refund).
+type Service struct {
+	DB           *gorm.DB
+	NotifService *notification.Service
+}
+
+func NewService(db *gorm.DB, notifService *notification.Service) *Service {
+	return &Service{DB: db, NotifService: notifService}
+}
+
+// available = stok yang beneran bisa dibeli (stok dikurangi yang lagi di-hold order pending).
+// variantID 0 berarti stok produknya (total semua varian).
+func (s *Service) available(productID, variantID uint) (int, error) {
+	if variantID != 0 {
+		var variant models.ProductVariant
+		if err := s.DB.Select("id", "stock", "reserved").Where("id = ? AND product_id = ?", variantID, productID).First(&variant).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return 0, ErrVariantNotFound
+			}
+			return 0, err
+		}
+		return variant.Stock - variant.Reserved, nil
+	}
+
+	var product models.Product
+	if err := s.DB.Select("id", "stock", "reserved").First(&product, productID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrProductNotFound
+		}
+		return 0, err
+	}
+	return product.Stock - product.Reserved, nil
+}
+
+// Subscribe daftarin user buat dikabarin begitu stoknya ada lagi. Cuma bisa buat produk/varian
+// yang lagi habis; langganan lama yang udah pernah kekirim diaktifin ulang.
+func (s *Service) Subscribe(userID, productID, variantID uint) (*models.StockAlert, error) {
+	if _, err := s.available(productID, 0); err != nil {
+		return nil, err
+	}
+	stock, err := s.available(productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+	if stock > 0 {
+		return nil, ErrInStock
+	}
+
+	alert := models.StockAlert{UserID: userID, ProductID: productID, VariantID: variantID}
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(&alert, "UserID", "ProductID", "VariantID").FirstOrCreate(&alert).Error; err != nil {
+			return err
+		}
+		if alert.NotifiedAt == nil {
+			return nil
+		}
+		alert.NotifiedAt = nil
+		return tx.Model(&alert).Update("notified_at", nil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// Unsubscribe ngehapus langganan user buat produk/varian itu (kalau ada).
+func (s *Service) Unsubscribe(userID, productID, variantID uint) error {
+	return s.DB.Where("user_id = ? AND product_id = ? AND variant_id = ?", userID, productID, variantID).
+		Delete(&models.StockAlert{}).Error
+}
+
+// Notify ngecek langganan aktif buat produk-produk ini dan ngirim notifikasi ke yang
+// stoknya udah ada lagi. Langganan ditandain dulu baru notif dikirim, jadi dua pemanggil
+// barengan gak bakal ngirim dobel. Panggil setelah transaksi perubahan stok ke-commit.
+func (s *Service) Notify(productIDs []uint) {
+	if len(productIDs) == 0 {
+		return
+	}
+	var alerts []models.StockAlert
+	err := s.DB.Preload("Product").
+		Where("product_id IN ? AND notified_at IS NULL", productIDs).
+		Order("id asc").Find(&alerts).Error
+	if err != nil {
+		log.Printf("[STOCK-ALERT] WARNING: Gagal ambil langganan stok: %v", err)
+		return
+	}
+
+	for _, alert := range alerts {
+		if alert.Product == nil {
+			continue // Produknya udah dihapus
+		}
+		name := alert.Product.Name
+		stock, err := s.available(alert.ProductID, alert.VariantID)
+		if errors.Is(err, ErrVariantNotFound) {
+			// Variannya udah dihapus seller, kabarin aja kalau produknya ada stok
+			stock, err = s.available(alert.ProductID, 0)
+		} else if err == nil && alert.VariantID != 0 {
+			var variant models.ProductVariant
+			if s.DB.Select("name").First(&variant, alert.VariantID).Error == nil && variant.Name != "" {
+				name = fmt.Sprintf("%s (%s)", name, variant.Name)
+			}
+		}
+		if err != nil {
+			log.Printf("[STOCK-ALERT] WARNING: Gagal cek stok produk #%d: %v", alert.ProductID, err)
+			continue
+		}
+		if stock <= 0 {
+			continue
+		}
+
+		result := s.DB.Model(&models.StockAlert{}).
+			Where("id = ? AND notified_at IS NULL", alert.ID).
+			Update("notified_at", time.Now())
+		if result.Error != nil {
+			log.Printf("[STOCK-ALERT] WARNING: Gagal nandain langganan #%d: %v", alert.ID, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue // Udah dikirim sama pemanggil lain
+		}
+
+		err = s.NotifService.CreateAndSend(alert.UserID, models.NotificationTypeStock, "Stok Ada Lagi!",
+			fmt.Sprintf("%s yang kamu tunggu udah ada stok lagi, buruan sebelum kehabisan.", name), alert.ProductID)
+		if err != nil {
+			log.Printf("[STOCK-ALERT] WARNING: Gagal kirim notif stok ke user #%d: %v", alert.UserID, err)
+		}
+	}
+}
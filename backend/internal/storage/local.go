@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Local = Storage di disk lokal, file-nya disajiin Fiber lewat app.Static. File di bawah
+// PrivatePrefix disimpen di PrivateDir (di luar folder yang disajiin app.Static) dan cuma
+// bisa diambil lewat route yang ngecek Verify.
+type Local struct {
+	Dir        string
+	PrivateDir string
+	PublicURL  string // mis. http://localhost:8910/uploads
+	SigningKey []byte // kunci HMAC buat SignedURL
+}
+
+func NewLocal(dir, privateDir, publicURL string, signingKey []byte) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	// File private lama yang sempet ditaruh di dalem folder publik dipindahin keluar
+	legacy := filepath.Join(dir, strings.TrimSuffix(PrivatePrefix, "/"))
+	if _, err := os.Stat(legacy); err == nil {
+		if _, err := os.Stat(privateDir); os.IsNotExist(err) {
+			if err := os.MkdirAll(filepath.Dir(privateDir), 0o755); err != nil {
+				return nil, err
+			}
+			if err := os.Rename(legacy, privateDir); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := os.MkdirAll(privateDir, 0o700); err != nil {
+		return nil, err
+	}
+	return &Local{Dir: dir, PrivateDir: privateDir, PublicURL: strings.TrimSuffix(publicURL, "/"), SigningKey: signingKey}, nil
+}
+
+func (l *Local) Name() string {
+	return "local"
+}
+
+func (l *Local) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	if IsPrivate(key) {
+		return filepath.Join(l.PrivateDir, filepath.FromSlash(strings.TrimPrefix(key, PrivatePrefix))), nil
+	}
+	return filepath.Join(l.Dir, filepath.FromSlash(key)), nil
+}
+
+func (l *Local) Put(key string, body io.Reader, size int64, contentType string) error {
+	dst, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+
+	// Tulis ke file sementara dulu biar gak ada file setengah jadi yang kesajiin
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+func (l *Local) Get(key string) (io.ReadCloser, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (l *Local) Delete(key string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (l *Local) signature(key string, expires int64) string {
+	mac := hmac.New(sha256.New, l.SigningKey)
+	mac.Write([]byte(key + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignedURL = URL publik + expires & signature HMAC, dicek Verify sebelum file private disajiin.
+func (l *Local) SignedURL(key string, expiry time.Duration) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	expires := time.Now().Add(expiry).Unix()
+	return l.URL(key) + "?expires=" + strconv.FormatInt(expires, 10) + "&signature=" + l.signature(key, expires), nil
+}
+
+// Verify ngecek expires & signature dari SignedURL buat key ini.
+func (l *Local) Verify(key, expires, signature string) error {
+	if validateKey(key) != nil {
+		return ErrInvalidKey
+	}
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(l.signature(key, exp))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (l *Local) URL(key string) string {
+	return l.PublicURL + "/" + key
+}
+
+// Key juga ngenalin URL lama (sebelum ada base URL publik) yang host-nya diambil dari
+// request, cukup dari bagian ".../uploads/<key>".
+func (l *Local) Key(url string) string {
+	if key := keyFromBase(l.PublicURL, url); key != "" {
+		return key
+	}
+	i := strings.LastIndex(url, "/uploads/")
+	if i < 0 {
+		return ""
+	}
+	key := url[i+len("/uploads/"):]
+	if validateKey(key) != nil {
+		return ""
+	}
+	return key
+}
+
+func (l *Local) List(prefix string) ([]Object, error) {
+	var objects []Object
+	if !IsPrivate(prefix) {
+		found, err := walk(l.Dir, "", prefix)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, found...)
+	}
+	if IsPrivate(prefix) || strings.HasPrefix(PrivatePrefix, prefix) {
+		found, err := walk(l.PrivateDir, PrivatePrefix, prefix)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, found...)
+	}
+	return objects, nil
+}
+
+// walk ngambil file di dir yang key-nya (keyPrefix + path relatif) diawali prefix.
+func walk(dir, keyPrefix, prefix string) ([]Object, error) {
+	// Cukup jalan dari folder terdalam yang pasti kena prefix, sisanya difilter per key
+	root := dir
+	rel := strings.TrimPrefix(prefix, keyPrefix)
+	if strings.HasPrefix(prefix, keyPrefix) {
+		if i := strings.LastIndex(rel, "/"); i >= 0 {
+			if err := validateKey(rel[:i]); err != nil {
+				return nil, err
+			}
+			root = filepath.Join(dir, filepath.FromSlash(rel[:i]))
+		}
+	}
+
+	var objects []Object
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && p != root {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return nil
+		}
+		key := keyPrefix + filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		objects = append(objects, Object{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	return objects, err
+}
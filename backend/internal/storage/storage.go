@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound         = errors.New("file tidak ditemukan di storage")
+	ErrInvalidKey       = errors.New("key file tidak valid")
+	ErrInvalidSignature = errors.New("link file udah kedaluwarsa atau tidak valid")
+)
+
+// PrivatePrefix = awalan key file private (mis. dokumen pengajuan seller). File di sini gak
+// boleh dibuka lewat URL publik, cuma lewat SignedURL.
+const PrivatePrefix = "private/"
+
+// IsPrivate = key-nya file private atau bukan.
+func IsPrivate(key string) bool {
+	return strings.HasPrefix(key, PrivatePrefix)
+}
+
+// Object = info satu file di storage.
+type Object struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// Storage = tempat nyimpen file upload. Key = path relatif file ("1700000000-foto.jpg"),
+// URL publiknya selalu dibangun dari base URL yang dikonfigurasi (bukan host request).
+type Storage interface {
+	Name() string
+	Put(key string, body io.Reader, size int64, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+	// SignedURL = URL sementara buat ngakses file walau bucket-nya private.
+	SignedURL(key string, expiry time.Duration) (string, error)
+	// URL = URL publik file.
+	URL(key string) string
+	// Key kebalikan URL: ngambil key dari URL publik, "" kalau URL-nya bukan file storage ini.
+	Key(url string) string
+	// List ngambil semua file yang key-nya diawali prefix ("" = semua), dipake buat nyapu
+	// file yang udah gak kepake.
+	List(prefix string) ([]Object, error)
+}
+
+// validateKey nolak key kosong, absolut, atau yang nyoba keluar folder ("..").
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
+
+// keyFromBase ngambil key dari URL yang diawali base URL publik.
+func keyFromBase(base, url string) string {
+	prefix := strings.TrimSuffix(base, "/") + "/"
+	if !strings.HasPrefix(url, prefix) {
+		return ""
+	}
+	key := strings.TrimPrefix(url, prefix)
+	if i := strings.IndexAny(key, "?#"); i >= 0 {
+		key = key[:i]
+	}
+	if validateKey(key) != nil {
+		return ""
+	}
+	return key
+}
@@ -9,22 +9,26 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 type JWTClaims struct {
-	UserID uint   `json:"user_id"`
-	Role   string `json:"role"`
+	UserID    uint   `json:"user_id"`
+	Role      string `json:"role"`
+	SessionID uint   `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uint, role string) (string, error) {
+// GenerateToken bikin access token (umurnya pendek) buat satu session login.
+func GenerateToken(userID uint, role string, sessionID uint, ttl time.Duration) (string, time.Time, error) {
 	jwtSecret := config.GetJWTSecret()
-	
-	expirationTime := time.Now().Add(72 * time.Hour)
+
+	now := time.Now()
+	expirationTime := now.Add(ttl)
 
 	claims := &JWTClaims{
-		UserID: userID,
-		Role:   role,
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
@@ -32,8 +36,65 @@ func GenerateToken(userID uint, role string) (string, error) {
 
 	t, err := token.SignedString([]byte(jwtSecret))
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
-	return t, nil
-}
\ No newline at end of file
+	return t, expirationTime, nil
+}
+
+// ParseToken ngecek tanda tangan & umur access token, balikin claims-nya.
+func ParseToken(tokenString string) (*JWTClaims, error) {
+	claims := &JWTClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(config.GetJWTSecret()), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
+
+// cartTokenSubject bedain cart token dari access token (sama-sama ditandatanganin JWT_SECRET).
+const cartTokenSubject = "guest_cart"
+
+type CartTokenClaims struct {
+	GuestCartID uint `json:"gcid"`
+	jwt.RegisteredClaims
+}
+
+// GenerateCartToken bikin token buat keranjang tamu. Gak ada expiry, keranjangnya sendiri
+// yang disapu kalau udah lama gak dipake.
+func GenerateCartToken(guestCartID uint) (string, error) {
+	claims := &CartTokenClaims{
+		GuestCartID: guestCartID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  cartTokenSubject,
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(config.GetJWTSecret()))
+}
+
+// ParseCartToken ngecek tanda tangan cart token, balikin ID keranjang tamunya.
+func ParseCartToken(tokenString string) (uint, error) {
+	claims := &CartTokenClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(config.GetJWTSecret()), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if !token.Valid || claims.Subject != cartTokenSubject || claims.GuestCartID == 0 {
+		return 0, jwt.ErrTokenInvalidClaims
+	}
+	return claims.GuestCartID, nil
+}
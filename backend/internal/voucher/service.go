@@ -0,0 +1,203 @@
+package voucher
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/akhdanrgya/telu-hub/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotFound      = errors.New("kode voucher tidak ditemukan")
+	ErrInactive      = errors.New("voucher ini udah gak aktif")
+	ErrNotStarted    = errors.New("voucher ini belum bisa dipake")
+	ErrExpired       = errors.New("voucher ini udah kedaluwarsa")
+	ErrUsageLimit    = errors.New("kuota voucher ini udah habis")
+	ErrUserLimit     = errors.New("kamu udah mencapai batas pemakaian voucher ini")
+	ErrNotApplicable = errors.New("gak ada barang di keranjang yang bisa pake voucher ini")
+	ErrMinSpend      = errors.New("belanjaan belum memenuhi minimal pembelian voucher")
+	ErrInvalid       = errors.New("data voucher tidak valid")
+)
+
+var codePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,50}$`)
+
+// Line = satu baris checkout yang mau dicek ke voucher (harga × jumlah).
+type Line struct {
+	SellerID uint
+	Amount   float64
+}
+
+// Quote = hasil ngitung voucher ke keranjang. Allocations urutannya sama dengan lines
+// yang dikirim, isinya bagian potongan tiap baris (baris yang gak kena voucher = 0).
+type Quote struct {
+	Voucher     *models.Voucher
+	Eligible    float64
+	Discount    float64
+	Allocations []float64
+}
+
+type Service struct {
+	DB *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{DB: db}
+}
+
+// NormalizeCode = kode voucher gak case-sensitive, disimpen huruf besar.
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate ngecek isian voucher sebelum disimpen (kode udah dinormalisasi).
+func Validate(v *models.Voucher) error {
+	if !codePattern.MatchString(v.Code) {
+		return fmt.Errorf("%w: kode 3-50 karakter, cuma huruf, angka, - dan _", ErrInvalid)
+	}
+	switch v.Type {
+	case models.VoucherTypePercentage:
+		if v.Value <= 0 || v.Value > 100 {
+			return fmt.Errorf("%w: persen potongan harus 1-100", ErrInvalid)
+		}
+	case models.VoucherTypeFixed:
+		if v.Value <= 0 {
+			return fmt.Errorf("%w: nominal potongan harus lebih dari 0", ErrInvalid)
+		}
+	default:
+		return fmt.Errorf("%w: tipe harus percentage atau fixed", ErrInvalid)
+	}
+	if v.MinSpend < 0 || v.MaxDiscount < 0 || v.UsageLimit < 0 || v.PerUserLimit < 0 {
+		return fmt.Errorf("%w: minimal belanja, maksimal potongan & batas pemakaian gak boleh negatif", ErrInvalid)
+	}
+	if v.StartsAt != nil && v.EndsAt != nil && !v.EndsAt.After(*v.StartsAt) {
+		return fmt.Errorf("%w: waktu selesai harus setelah waktu mulai", ErrInvalid)
+	}
+	return nil
+}
+
+func (s *Service) find(db *gorm.DB, code string) (*models.Voucher, error) {
+	var v models.Voucher
+	if err := db.Where("code = ?", NormalizeCode(code)).First(&v).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &v, nil
+}
+
+// Preview ngitung potongan voucher buat keranjang tanpa nyimpen apa-apa.
+func (s *Service) Preview(code string, userID uint, lines []Line) (*Quote, error) {
+	v, err := s.find(s.DB, code)
+	if err != nil {
+		return nil, err
+	}
+	return s.quote(s.DB, v, userID, lines)
+}
+
+// Apply = Preview versi checkout: baris voucher dikunci sampai transaksi selesai, jadi dua
+// checkout barengan gak bisa sama-sama make sisa kuota terakhir. Lanjutin pake Redeem.
+func (s *Service) Apply(tx *gorm.DB, code string, userID uint, lines []Line) (*Quote, error) {
+	v, err := s.find(tx.Clauses(clause.Locking{Strength: "UPDATE"}), code)
+	if err != nil {
+		return nil, err
+	}
+	return s.quote(tx, v, userID, lines)
+}
+
+// Redeem nyatet pemakaian voucher di order dan motong kuotanya. Jalanin di transaksi checkout.
+func (s *Service) Redeem(tx *gorm.DB, q *Quote, userID, orderID uint) error {
+	redemption := models.VoucherRedemption{
+		VoucherID: q.Voucher.ID,
+		UserID:    userID,
+		OrderID:   orderID,
+		Amount:    q.Discount,
+		Status:    models.RedemptionActive,
+	}
+	if err := tx.Create(&redemption).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Voucher{}).Where("id = ?", q.Voucher.ID).
+		Update("used_count", gorm.Expr("used_count + 1")).Error
+}
+
+func (s *Service) quote(db *gorm.DB, v *models.Voucher, userID uint, lines []Line) (*Quote, error) {
+	now := time.Now()
+	switch {
+	case !v.IsActive:
+		return nil, ErrInactive
+	case v.StartsAt != nil && now.Before(*v.StartsAt):
+		return nil, ErrNotStarted
+	case v.EndsAt != nil && now.After(*v.EndsAt):
+		return nil, ErrExpired
+	case v.UsageLimit > 0 && v.UsedCount >= v.UsageLimit:
+		return nil, ErrUsageLimit
+	}
+	if v.PerUserLimit > 0 {
+		var used int64
+		err := db.Model(&models.VoucherRedemption{}).
+			Where("voucher_id = ? AND user_id = ? AND status = ?", v.ID, userID, models.RedemptionActive).
+			Count(&used).Error
+		if err != nil {
+			return nil, err
+		}
+		if int(used) >= v.PerUserLimit {
+			return nil, ErrUserLimit
+		}
+	}
+
+	q := &Quote{Voucher: v, Allocations: make([]float64, len(lines))}
+	for _, line := range lines {
+		if v.SellerID == nil || *v.SellerID == line.SellerID {
+			q.Eligible += line.Amount
+		}
+	}
+	if q.Eligible <= 0 {
+		return nil, ErrNotApplicable
+	}
+	if q.Eligible < v.MinSpend {
+		return nil, fmt.Errorf("%w (min Rp %.0f)", ErrMinSpend, v.MinSpend)
+	}
+
+	discount := v.Value
+	if v.Type == models.VoucherTypePercentage {
+		discount = q.Eligible * v.Value / 100
+		if v.MaxDiscount > 0 && discount > v.MaxDiscount {
+			discount = v.MaxDiscount
+		}
+	}
+	q.Discount = math.Floor(math.Min(discount, q.Eligible))
+	q.allocate(lines)
+	return q, nil
+}
+
+// allocate bagi potongan ke baris yang kena voucher sebanding harganya (dibulatin ke bawah),
+// sisa pembulatannya ditaruh ke baris yang masih muat. Dipake buat ngitung refund per barang.
+func (q *Quote) allocate(lines []Line) {
+	remaining := q.Discount
+	for i, line := range lines {
+		if q.Voucher.SellerID != nil && *q.Voucher.SellerID != line.SellerID {
+			continue
+		}
+		share := math.Floor(q.Discount * line.Amount / q.Eligible)
+		q.Allocations[i] = share
+		remaining -= share
+	}
+	for i, line := range lines {
+		if remaining <= 0 {
+			break
+		}
+		if q.Voucher.SellerID != nil && *q.Voucher.SellerID != line.SellerID {
+			continue
+		}
+		extra := math.Min(remaining, math.Floor(line.Amount-q.Allocations[i]))
+		q.Allocations[i] += extra
+		remaining -= extra
+	}
+}
@@ -22,8 +22,10 @@ const (
 )
 
 type TrackStockRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ProductId     uint32                 `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId uint32                 `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// 0 = pantau stok total produk, selain itu cuma stok varian ini
+	VariantId     uint32 `protobuf:"varint,2,opt,name=variant_id,json=variantId,proto3" json:"variant_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,10 +67,24 @@ func (x *TrackStockRequest) GetProductId() uint32 {
 	return 0
 }
 
+func (x *TrackStockRequest) GetVariantId() uint32 {
+	if x != nil {
+		return x.VariantId
+	}
+	return 0
+}
+
 type StockUpdateResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ProductId     uint32                 `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
-	NewStock      int32                  `protobuf:"varint,2,opt,name=new_stock,json=newStock,proto3" json:"new_stock,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId uint32                 `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// Stok fisik di gudang (termasuk yang lagi di-hold order pending)
+	NewStock int32 `protobuf:"varint,2,opt,name=new_stock,json=newStock,proto3" json:"new_stock,omitempty"`
+	// Stok yang masih bisa dibeli (new_stock - reserved)
+	Available int32 `protobuf:"varint,3,opt,name=available,proto3" json:"available,omitempty"`
+	// Stok yang lagi di-hold order yang belum dibayar
+	Reserved int32 `protobuf:"varint,4,opt,name=reserved,proto3" json:"reserved,omitempty"`
+	// 0 = stok total produk (gabungan semua varian)
+	VariantId     uint32 `protobuf:"varint,5,opt,name=variant_id,json=variantId,proto3" json:"variant_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -117,18 +133,45 @@ func (x *StockUpdateResponse) GetNewStock() int32 {
 	return 0
 }
 
+func (x *StockUpdateResponse) GetAvailable() int32 {
+	if x != nil {
+		return x.Available
+	}
+	return 0
+}
+
+func (x *StockUpdateResponse) GetReserved() int32 {
+	if x != nil {
+		return x.Reserved
+	}
+	return 0
+}
+
+func (x *StockUpdateResponse) GetVariantId() uint32 {
+	if x != nil {
+		return x.VariantId
+	}
+	return 0
+}
+
 var File_stock_proto protoreflect.FileDescriptor
 
 const file_stock_proto_rawDesc = "" +
 	"\n" +
-	"\vstock.proto\x12\rteluhub.stock\"2\n" +
+	"\vstock.proto\x12\rteluhub.stock\"Q\n" +
 	"\x11TrackStockRequest\x12\x1d\n" +
 	"\n" +
-	"product_id\x18\x01 \x01(\rR\tproductId\"Q\n" +
+	"product_id\x18\x01 \x01(\rR\tproductId\x12\x1d\n" +
+	"\n" +
+	"variant_id\x18\x02 \x01(\rR\tvariantId\"\xaa\x01\n" +
 	"\x13StockUpdateResponse\x12\x1d\n" +
 	"\n" +
 	"product_id\x18\x01 \x01(\rR\tproductId\x12\x1b\n" +
-	"\tnew_stock\x18\x02 \x01(\x05R\bnewStock2d\n" +
+	"\tnew_stock\x18\x02 \x01(\x05R\bnewStock\x12\x1c\n" +
+	"\tavailable\x18\x03 \x01(\x05R\tavailable\x12\x1a\n" +
+	"\breserved\x18\x04 \x01(\x05R\breserved\x12\x1d\n" +
+	"\n" +
+	"variant_id\x18\x05 \x01(\rR\tvariantId2d\n" +
 	"\fStockService\x12T\n" +
 	"\n" +
 	"TrackStock\x12 .teluhub.stock.TrackStockRequest\x1a\".teluhub.stock.StockUpdateResponse0\x01B4Z2github.com/akhdanrgya/TelU-Hub/backend/proto/stockb\x06proto3"
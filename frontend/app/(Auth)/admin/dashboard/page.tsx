@@ -26,6 +26,9 @@ import {
 import { Select, SelectItem } from "@heroui/select";
 import { Link } from "@heroui/link";
 import NextLink from "next/link";
+import { SellerApplicationQueue } from "@/components/sellerapplicationqueue";
+import { ReviewModeration } from "@/components/reviewmoderation";
+import { VoucherManager } from "@/components/vouchermanager";
 
 const AdminDashboardPage = () => {
   const { user: adminUser, loading: authLoading } = useAuth();
@@ -100,6 +103,12 @@ const AdminDashboardPage = () => {
 
       {error && <p className="text-danger mb-4 text-center">{error}</p>}
 
+      <SellerApplicationQueue onReviewed={fetchAllUsers} />
+
+      <ReviewModeration />
+
+      <VoucherManager admin />
+
       <Table aria-label="Tabel User">
         <TableHeader>
           <TableColumn>ID</TableColumn>
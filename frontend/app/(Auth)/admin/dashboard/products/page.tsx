@@ -3,7 +3,7 @@
 import React, { useState, useEffect } from "react";
 import { useAuth } from "@/contexts/AuthContext";
 import api from "@/libs/api";
-import { Product } from "@/types";
+import { Product, ProductListResponse } from "@/types";
 import { Button } from "@heroui/button";
 import { Link } from "@heroui/link";
 import { Table, TableHeader, TableBody, TableColumn, TableRow, TableCell } from "@heroui/table"; 
@@ -28,8 +28,8 @@ const AdminProductManagementPage = () => {
   const fetchAllProducts = async () => {
     setLoading(true);
     try {
-      const response = await api.get("/products"); 
-      setProducts(response.data);
+      const response = await api.get<ProductListResponse>("/products", { params: { limit: 100 } });
+      setProducts(response.data.data);
     } catch (err) {
       setError("Gagal mengambil semua produk");
     } finally {
@@ -0,0 +1,52 @@
+"use client";
+
+import React, { Suspense } from "react";
+import { useSearchParams } from "next/navigation";
+import { Button } from "@heroui/button";
+import { Spinner } from "@heroui/spinner";
+import NextLink from "next/link";
+import { CheckoutPanel } from "@/components/checkoutpanel";
+
+// Beli langsung satu produk tanpa nyentuh keranjang: /buy-now?product=1&variant=2&qty=1
+const BuyNow = () => {
+  const params = useSearchParams();
+  const productId = Number(params.get("product") || 0);
+  const variantId = Number(params.get("variant") || 0);
+  const quantity = Math.max(1, Number(params.get("qty") || 1));
+
+  if (!productId) {
+    return (
+      <div className="text-center py-20">
+        <h1 className="text-3xl font-bold text-danger">Produk belum dipilih</h1>
+        <Button as={NextLink} href="/" color="primary" className="mt-4">
+          Kembali ke Home
+        </Button>
+      </div>
+    );
+  }
+
+  return (
+    <div className="py-8 max-w-xl mx-auto">
+      <h1 className="text-3xl font-bold mb-6">Beli Sekarang</h1>
+      <div className="p-6 border rounded-lg bg-content1">
+        <CheckoutPanel
+          request={{
+            buy_now: {
+              product_id: productId,
+              variant_id: variantId || undefined,
+              quantity,
+            },
+          }}
+        />
+      </div>
+    </div>
+  );
+};
+
+const BuyNowPage = () => (
+  <Suspense fallback={<div className="text-center p-10"><Spinner size="lg" /></div>}>
+    <BuyNow />
+  </Suspense>
+);
+
+export default BuyNowPage;
@@ -7,19 +7,15 @@ import { Button } from "@heroui/button";
 import { Link } from "@heroui/link";
 import { Avatar } from "@heroui/avatar";
 import NextLink from "next/link";
-import { FiTrash2, FiPlus, FiMinus } from "react-icons/fi";
-import { useRouter } from "next/navigation";
+import { FiTrash2, FiPlus, FiMinus, FiRefreshCw } from "react-icons/fi";
 import api from "@/libs/api";
-
-declare global {
-  interface Window {
-    snap: any;
-  }
-}
+import { Cart, CartChange } from "@/types";
+import { CheckoutPanel } from "@/components/checkoutpanel";
 
 const CartPage = () => {
   const {
     cart,
+    isAuthenticated,
     loading: authLoading,
     loadingCart,
     updateCartQuantity,
@@ -27,54 +23,30 @@ const CartPage = () => {
     fetchCart,
   } = useAuth();
 
-  // Hitung total harga keseluruhan
-  const grandTotal = cart?.CartItems
-    ? cart.CartItems.reduce(
-        (total, item) => total + item.quantity * item.Product.price,
-        0
-      )
-    : 0;
-
-  const [checkoutLoading, setCheckoutLoading] = useState(false);
-  const router = useRouter();
+  // Baris yang gak dicentang tetep di keranjang buat dibayar nanti
+  const [excluded, setExcluded] = useState<number[]>([]);
+  const selectedItems = cart?.CartItems.filter((item) => !item.unavailable && !excluded.includes(item.id)) ?? [];
 
-  const handleCheckout = async () => {
-    setCheckoutLoading(true);
+  const [refreshing, setRefreshing] = useState(false);
+  const [changes, setChanges] = useState<CartChange[]>([]);
 
+  // Hapus barang yang udah gak dijual, turunin jumlah ke stok, dan terima harga baru
+  const refreshCart = async () => {
+    setRefreshing(true);
     try {
-      const response = await api.post("/checkout", {});
-      const { snap_token, order_id } = response.data;
-
-      window.snap.pay(snap_token, {
-        onSuccess: (result: any) => {
-          console.log("Pembayaran Sukses!", result);
-          router.push(`/orders/${order_id}`);
-          setCheckoutLoading(false);
-          fetchCart();
-        },
-        onPending: (result: any) => {
-          console.log("Pembayaran Pending", result);
-          router.push(`/orders/${order_id}`);
-          setCheckoutLoading(false);
-          fetchCart();
-        },
-        onError: (error: any) => {
-          console.error("Pembayaran Error", error);
-          alert("Pembayaran Gagal");
-          setCheckoutLoading(false);
-        },
-        onClose: () => {
-          console.log("Popup Midtrans ditutup");
-          setCheckoutLoading(false);
-        },
-      });
-    } catch (err) {
-      console.error(err);
-      alert("Gagal membuat order (API Error)");
-      setCheckoutLoading(false);
+      const res = await api.post<Cart>("/cart/refresh");
+      setChanges(res.data.changes ?? []);
+      await fetchCart();
+    } catch (err: any) {
+      alert(err.response?.data?.error || "Gagal memperbarui keranjang");
+    } finally {
+      setRefreshing(false);
     }
   };
 
+  const toggleItem = (id: number) =>
+    setExcluded((prev) => (prev.includes(id) ? prev.filter((x) => x !== id) : [...prev, id]));
+
   if (authLoading) {
     return (
       <div className="text-center p-10">
@@ -101,6 +73,27 @@ const CartPage = () => {
     <div className="py-8">
       <h1 className="text-3xl font-bold mb-6">Keranjang Belanja</h1>
 
+      {cart.has_warnings && isAuthenticated && (
+        <div className="flex items-center justify-between gap-4 p-4 mb-4 border border-warning rounded-lg bg-warning-50">
+          <p className="text-sm">
+            Ada harga, stok, atau barang yang berubah sejak kamu masukin ke keranjang. Cek dulu, terus perbarui keranjang sebelum checkout.
+          </p>
+          <Button color="warning" variant="flat" startContent={<FiRefreshCw />} isLoading={refreshing} onPress={refreshCart}>
+            Perbarui keranjang
+          </Button>
+        </div>
+      )}
+
+      {changes.length > 0 && (
+        <div className="p-4 mb-4 border rounded-lg bg-content1 text-sm space-y-1">
+          {changes.map((change) => (
+            <p key={`${change.cart_item_id}-${change.action}`}>
+              <span className="font-semibold">{change.name}</span>: {change.message}
+            </p>
+          ))}
+        </div>
+      )}
+
       <div className="grid grid-cols-1 lg:grid-cols-3 gap-8">
         {/* Kolom Kiri: Daftar Item */}
         <div className="lg:col-span-2 space-y-4">
@@ -109,6 +102,14 @@ const CartPage = () => {
               key={item.id}
               className="flex items-center gap-4 p-4 border rounded-lg bg-content1"
             >
+              <input
+                type="checkbox"
+                className="w-5 h-5 accent-primary"
+                aria-label={`Checkout ${item.Product.name}`}
+                checked={!item.unavailable && !excluded.includes(item.id)}
+                disabled={item.unavailable}
+                onChange={() => toggleItem(item.id)}
+              />
               <Avatar
                 src={item.Product.image_url}
                 name={item.Product.name.charAt(0)}
@@ -124,9 +125,20 @@ const CartPage = () => {
                 >
                   {item.Product.name}
                 </Link>
+                {item.Variant && (
+                  <p className="text-sm text-default-500">Varian: {item.Variant.name}</p>
+                )}
                 <p className="text-primary font-bold">
                   Rp {item.Product.price.toLocaleString("id-ID")}
                 </p>
+                {item.warnings.map((warning) => (
+                  <p
+                    key={warning.type}
+                    className={`text-sm ${warning.type === "price_down" ? "text-success" : "text-danger"}`}
+                  >
+                    {warning.message}
+                  </p>
+                ))}
               </div>
 
               <div className="flex items-center gap-2">
@@ -181,49 +193,26 @@ const CartPage = () => {
           <div className="p-6 border rounded-lg bg-content1 sticky top-24">
             <h2 className="text-2xl font-bold mb-4">Ringkasan</h2>
             
-            <div className="space-y-3">
-              {/* 🔥 LOOPING BARANG DI SINI 🔥 */}
-              {cart.CartItems.map((item) => (
-                <div key={item.id} className="flex justify-between text-sm">
-                  <p className="text-default-600 truncate pr-2">
-                    {item.Product.name} <span className="font-semibold">x{item.quantity}</span>
-                  </p>
-                  <p className="font-semibold whitespace-nowrap">
-                    Rp {(item.quantity * item.Product.price).toLocaleString("id-ID")}
-                  </p>
-                </div>
-              ))}
-
-              <div className="border-t my-2 opacity-50"></div>
-              
-              <div className="flex justify-between">
-                <p className="text-default-600">Biaya Admin</p>
-                <p className="font-semibold">Rp 0</p>
-              </div>
-              
-              <div className="border-t my-2"></div>
-              
-              <div className="flex justify-between text-xl font-bold text-primary">
-                <p>Total</p>
-                <p>Rp {grandTotal.toLocaleString("id-ID")}</p>
+            {!isAuthenticated ? (
+              <div className="space-y-3">
+                <p className="text-default-500">
+                  Login atau daftar dulu buat checkout. Isi keranjang ini otomatis ikut pindah ke akun kamu.
+                </p>
+                <Button as={NextLink} href="/login" color="primary" className="w-full" size="lg">
+                  Login buat Checkout
+                </Button>
               </div>
-            </div>
-
-            <Button
-              color="primary"
-              className="w-full mt-6"
-              size="lg"
-              onPress={handleCheckout}
-              isLoading={checkoutLoading}
-              disabled={
-                checkoutLoading ||
-                loadingCart ||
-                !cart ||
-                cart.CartItems.length === 0
-              }
-            >
-              {checkoutLoading ? "Memproses..." : "Lanjut ke Checkout"}
-            </Button>
+            ) : selectedItems.length > 0 ? (
+              <CheckoutPanel
+                request={{
+                  items: selectedItems.map((item) => ({ cart_item_id: item.id, quantity: item.quantity })),
+                }}
+                revision={cart.CartItems.map((item) => `${item.id}:${item.price_at_add}`).join(",")}
+                onPaid={fetchCart}
+              />
+            ) : (
+              <p className="text-default-500">Centang barang yang mau dibayar sekarang.</p>
+            )}
           </div>
         </div>
       </div>
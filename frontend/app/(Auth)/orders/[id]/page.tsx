@@ -106,6 +106,18 @@ const OrderDetailPage = () => {
               <span className="text-default-600">Status Pembayaran:</span>
               <span className="font-medium">{order.status.charAt(0).toUpperCase() + order.status.slice(1)}</span>
             </div>
+            {!!order.discount_amount && (
+              <div className="flex justify-between text-success">
+                <span>Voucher {order.voucher_code}:</span>
+                <span className="font-medium">- Rp {order.discount_amount.toLocaleString("id-ID")}</span>
+              </div>
+            )}
+            {!!order.shipping_total && (
+              <div className="flex justify-between">
+                <span className="text-default-600">Ongkir:</span>
+                <span className="font-medium">Rp {order.shipping_total.toLocaleString("id-ID")}</span>
+              </div>
+            )}
             <div className="border-t my-2"></div>
             <div className="flex justify-between text-xl font-bold">
               <span>Total Pembayaran:</span>
@@ -115,12 +127,26 @@ const OrderDetailPage = () => {
         </div>
         
         <div className="bg-content1 p-6 rounded-lg shadow-sm border">
-          <h2 className="text-2xl font-bold mb-4">Alamat Pengiriman</h2>
-          <div className="space-y-1">
-            <p className="font-semibold">(Data ini belum ada di DB, cuma contoh)</p>
-            <p>King Akhdan</p>
-            <p>Jl. Telekomunikasi No. 1</p>
-            <p>Bandung, Jawa Barat, 40257</p>
+          <h2 className="text-2xl font-bold mb-4">Pengiriman</h2>
+          <div className="space-y-4">
+            {(order.seller_orders ?? []).map((sub) => (
+              <div key={sub.id} className="space-y-1 text-sm">
+                <p className="font-semibold">
+                  {sub.seller_name} · {sub.fulfilment.name}
+                  {sub.fulfilment.shipping_fee > 0 && ` (Rp ${sub.fulfilment.shipping_fee.toLocaleString("id-ID")})`}
+                </p>
+                {sub.fulfilment.meetup_point && <p>Lokasi: {sub.fulfilment.meetup_point}</p>}
+                {sub.fulfilment.shipping_address && <p className="whitespace-pre-wrap">{sub.fulfilment.shipping_address}</p>}
+                {sub.fulfilment.tracking_number && <p>No. resi: <span className="font-mono">{sub.fulfilment.tracking_number}</span></p>}
+                {sub.fulfilment.handover_code && (
+                  <p>
+                    Kode serah terima: <span className="font-mono font-bold text-lg">{sub.fulfilment.handover_code}</span>
+                    <span className="block text-default-500">Tunjukin ke seller waktu barang diserahin, jangan dikasih sebelum barangnya di tangan.</span>
+                  </p>
+                )}
+                {sub.fulfilment.handed_over_at && <p className="text-success">Diserahkan {formatDate(sub.fulfilment.handed_over_at)}</p>}
+              </div>
+            ))}
           </div>
         </div>
       </div>
@@ -140,6 +166,9 @@ const OrderDetailPage = () => {
                 <Link as={NextLink} href={`/products/${item.Product.id}`} className="font-semibold text-lg" color="foreground">
                   {item.Product.name}
                 </Link>
+                {item.variant_name && (
+                  <p className="text-sm text-default-500">Varian: {item.variant_name}</p>
+                )}
                 <p className="text-sm text-default-500">
                   {item.quantity} x Rp {item.price_at_time.toLocaleString("id-ID")}
                 </p>
@@ -10,6 +10,7 @@ import NextLink from "next/link";
 import { Card, CardHeader, CardBody, CardFooter } from "@heroui/card";
 import { Avatar } from "@heroui/avatar";
 import { Badge } from "@heroui/badge";
+import { PendingReviews } from "@/components/pendingreviews";
 
 
 const formatDate = (dateString: string) => {
@@ -84,6 +85,7 @@ const OrdersPage = () => {
   return (
     <div className="py-8">
       <h1 className="text-3xl font-bold mb-6">Riwayat Pesanan Saya</h1>
+      <PendingReviews />
       <div className="flex flex-col gap-6">
         {orders.map((order) => (
           <NextLink
@@ -7,6 +7,9 @@ import { Input } from "@heroui/input";
 import { Button } from "@heroui/button";
 import { Spinner } from "@heroui/spinner";
 import { Avatar } from "@heroui/avatar";
+import { UploadLibrary } from "@/components/uploadlibrary";
+import { SessionList } from "@/components/sessionlist";
+import { ChangePasswordForm } from "@/components/changepassword";
 
 const ProfilePage = () => {
   const { user, loading: authLoading, refreshUser } = useAuth();
@@ -19,6 +22,7 @@ const ProfilePage = () => {
   const [loading, setLoading] = useState(false);
   const [success, setSuccess] = useState("");
   const [error, setError] = useState("");
+  const [uploadsVersion, setUploadsVersion] = useState(0);
 
   useEffect(() => {
     if (user) {
@@ -62,9 +66,10 @@ const ProfilePage = () => {
       setSuccess("Profil berhasil di-update!");
       setImageFile(null);
       setNewImagePreview(null);
+      setUploadsVersion((v) => v + 1);
       
     } catch (err: any) {
-      setError(err.response?.data?.message || "Gagal mengupdate profil");
+      setError(err.response?.data?.error || err.response?.data?.message || "Gagal mengupdate profil");
     } finally {
       setLoading(false);
     }
@@ -117,6 +122,18 @@ const ProfilePage = () => {
           {loading ? "Menyimpan..." : "Simpan Perubahan"}
         </Button>
       </form>
+
+      <div className="mt-6">
+        <UploadLibrary refreshKey={uploadsVersion} />
+      </div>
+
+      <div className="mt-6">
+        <ChangePasswordForm />
+      </div>
+
+      <div className="mt-6">
+        <SessionList />
+      </div>
     </div>
   );
 };
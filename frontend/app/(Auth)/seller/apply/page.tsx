@@ -0,0 +1,172 @@
+"use client";
+
+import React, { useEffect, useState } from "react";
+import { Input, Textarea } from "@heroui/input";
+import { Button } from "@heroui/button";
+import { Chip } from "@heroui/chip";
+import { Spinner } from "@heroui/spinner";
+import NextImage from "next/image";
+import NextLink from "next/link";
+import api from "@/libs/api";
+import { useAuth } from "@/contexts/AuthContext";
+import { SellerApplication } from "@/types";
+
+type DocumentDraft = { url: string; thumbnail_url?: string; label: string };
+
+const statusColor = { pending: "warning", approved: "success", rejected: "danger" } as const;
+const statusLabel = { pending: "Lagi direview", approved: "Disetujui", rejected: "Ditolak" };
+
+const SellerApplyPage = () => {
+  const { user, loading: authLoading, refreshUser } = useAuth();
+
+  const [application, setApplication] = useState<SellerApplication | null>(null);
+  const [pageLoading, setPageLoading] = useState(true);
+
+  const [storeName, setStoreName] = useState("");
+  const [description, setDescription] = useState("");
+  const [studentId, setStudentId] = useState("");
+  const [documents, setDocuments] = useState<DocumentDraft[]>([]);
+  const [docLabel, setDocLabel] = useState("");
+  const [uploading, setUploading] = useState(false);
+
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState("");
+
+  useEffect(() => {
+    api.get<SellerApplication>("/me/seller-application")
+      .then((res) => setApplication(res.data))
+      .catch(() => setApplication(null))
+      .finally(() => setPageLoading(false));
+  }, []);
+
+  // Role baru kebaca setelah pengajuan disetujui
+  useEffect(() => {
+    if (application?.status === "approved" && user?.role === "user") {
+      refreshUser();
+    }
+  }, [application, user]);
+
+  const handleUpload = async (e: React.ChangeEvent<HTMLInputElement>) => {
+    const file = e.target.files?.[0];
+    if (!file) return;
+    setUploading(true);
+    setError("");
+    try {
+      const formData = new FormData();
+      formData.append("image", file);
+      // Dokumen disimpen private, preview-nya pake signed URL dari variants
+      const res = await api.post("/me/seller-application/documents", formData, {
+        headers: { "Content-Type": "multipart/form-data" },
+      });
+      setDocuments((docs) => [
+        ...docs,
+        { url: res.data.imageUrl, thumbnail_url: res.data.variants?.thumbnail?.url, label: docLabel || file.name },
+      ]);
+      setDocLabel("");
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal upload dokumen");
+    } finally {
+      setUploading(false);
+      e.target.value = "";
+    }
+  };
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setLoading(true);
+    setError("");
+    try {
+      const res = await api.post<SellerApplication>("/me/seller-application", {
+        store_name: storeName,
+        description,
+        student_id: studentId,
+        documents: documents.map(({ url, label }) => ({ url, label })),
+      });
+      setApplication(res.data);
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal ngirim pengajuan");
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  if (authLoading || pageLoading) {
+    return <div className="text-center p-10"><Spinner size="lg" /></div>;
+  }
+
+  if (user && user.role !== "user") {
+    return (
+      <div className="py-8 max-w-2xl mx-auto text-center space-y-4">
+        <h1 className="text-3xl font-bold">Akun kamu udah bisa jualan</h1>
+        <Button as={NextLink} href="/seller/dashboard" color="primary">Ke Seller Dashboard</Button>
+      </div>
+    );
+  }
+
+  const canApply = !application || application.status === "rejected";
+
+  return (
+    <div className="py-8 max-w-2xl mx-auto space-y-6">
+      <h1 className="text-3xl font-bold">Daftar Jadi Seller</h1>
+
+      {application && (
+        <div className="p-6 border rounded-lg bg-content1 space-y-2">
+          <div className="flex items-center justify-between">
+            <h2 className="text-xl font-semibold">{application.store_name}</h2>
+            <Chip color={statusColor[application.status]} variant="flat">{statusLabel[application.status]}</Chip>
+          </div>
+          <p className="text-sm text-default-500">
+            Diajukan {new Date(application.created_at).toLocaleString("id-ID")}
+          </p>
+          {application.status === "rejected" && (
+            <p className="text-danger text-sm">Alasan: {application.rejection_reason}</p>
+          )}
+          {application.status === "approved" && (
+            <Button as={NextLink} href="/seller/dashboard" color="primary" size="sm">Mulai Jualan</Button>
+          )}
+        </div>
+      )}
+
+      {canApply && (
+        <form onSubmit={handleSubmit} className="space-y-4 p-6 border rounded-lg bg-content1">
+          {application?.status === "rejected" && <p className="text-sm">Perbaiki data kamu lalu ajukan lagi.</p>}
+          <Input label="Nama Toko" value={storeName} onChange={(e) => setStoreName(e.target.value)} isRequired />
+          <Textarea label="Deskripsi Toko" value={description} onChange={(e) => setDescription(e.target.value)} />
+          <Input label="NIM" value={studentId} onChange={(e) => setStudentId(e.target.value)} isRequired />
+
+          <div className="space-y-2">
+            <p className="text-sm font-semibold">Dokumen Pendukung (misal foto KTM)</p>
+            <div className="flex flex-wrap gap-3">
+              {documents.map((doc, i) => (
+                <div key={doc.url} className="flex flex-col items-center gap-1 w-24">
+                  <div className="relative w-24 h-24 rounded-md overflow-hidden border">
+                    <NextImage src={doc.thumbnail_url || doc.url} alt={doc.label} fill style={{ objectFit: "cover" }} unoptimized={true} />
+                  </div>
+                  <span className="text-xs truncate w-full text-center">{doc.label}</span>
+                  <Button size="sm" variant="light" color="danger" onPress={() => setDocuments((docs) => docs.filter((_, j) => j !== i))}>
+                    Hapus
+                  </Button>
+                </div>
+              ))}
+            </div>
+            {documents.length < 5 && (
+              <div className="flex gap-2 items-end">
+                <Input label="Label dokumen" size="sm" value={docLabel} onChange={(e) => setDocLabel(e.target.value)} className="flex-1" />
+                <Input type="file" accept="image/*" size="sm" onChange={handleUpload} disabled={uploading} className="flex-1" />
+                {uploading && <Spinner size="sm" />}
+              </div>
+            )}
+          </div>
+
+          {error && <p className="text-danger text-center">{error}</p>}
+
+          <Button type="submit" color="primary" className="w-full" isLoading={loading} disabled={loading || documents.length === 0}>
+            Kirim Pengajuan
+          </Button>
+        </form>
+      )}
+    </div>
+  );
+};
+
+export default SellerApplyPage;
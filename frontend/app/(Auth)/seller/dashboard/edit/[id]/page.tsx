@@ -9,8 +9,9 @@ import { useRouter, useParams } from "next/navigation";
 import { Spinner } from "@heroui/spinner";
 import { Link } from "@heroui/link";
 import NextLink from "next/link";
-import { Product, Category } from "@/types";
-import { Image } from "@heroui/image";
+import { Product, Category, ProductVariant, ProductImage } from "@/types";
+import { VariantManager } from "@/components/variantmanager";
+import { GalleryManager } from "@/components/gallerymanager";
 
 const EditProductPage = () => {
   const params = useParams();
@@ -21,13 +22,13 @@ const EditProductPage = () => {
   const [description, setDescription] = useState("");
   const [price, setPrice] = useState(0);
   const [stock, setStock] = useState(0);
+  const [weight, setWeight] = useState(0);
+  const [variants, setVariants] = useState<ProductVariant[]>([]);
   
   const [categoryId, setCategoryId] = useState(""); 
   const [categories, setCategories] = useState<Category[]>([]);
 
-  const [imageFile, setImageFile] = useState<File | null>(null);
-  const [newImagePreview, setNewImagePreview] = useState<string | null>(null);
-  const [existingImageUrl, setExistingImageUrl] = useState<string | null>(null);
+  const [images, setImages] = useState<ProductImage[]>([]);
 
   const [loading, setLoading] = useState(false);
   const [pageLoading, setPageLoading] = useState(true);
@@ -50,7 +51,9 @@ const EditProductPage = () => {
         setDescription(product.description);
         setPrice(product.price);
         setStock(product.stock);
-        setExistingImageUrl(product.image_url);
+        setWeight(product.weight_grams ?? 0);
+        setVariants(product.variants ?? []);
+        setImages(product.images ?? []);
 
         if (product.category?.ID) {
             setCategoryId(String(product.category.ID));
@@ -72,39 +75,20 @@ const EditProductPage = () => {
     fetchData();
   }, [id]);
 
-  const handleImageChange = (e: React.ChangeEvent<HTMLInputElement>) => {
-    if (e.target.files && e.target.files[0]) {
-      const file = e.target.files[0];
-      setImageFile(file);
-      setNewImagePreview(URL.createObjectURL(file));
-    }
-  };
-
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault();
     setLoading(true);
     setError("");
 
-    let finalImageUrl = existingImageUrl;
-
     try {
-      if (imageFile) {
-        const formData = new FormData();
-        formData.append("image", imageFile);
-
-        const uploadResponse = await api.post("/upload/image", formData, {
-          headers: { "Content-Type": "multipart/form-data" },
-        });
-        finalImageUrl = uploadResponse.data.imageUrl;
-      }
-
+      // Gambar diurus GalleryManager (langsung ke server), di sini cuma data produk
       await api.put(`/products/${id}`, {
         name,
         description,
         price: Number(price),
         stock: Number(stock),
+        weight_grams: Number(weight),
         category_id: Number(categoryId),
-        image_url: finalImageUrl,
       });
 
       router.push("/seller/dashboard");
@@ -156,6 +140,7 @@ const EditProductPage = () => {
           onChange={(e) => setDescription(e.target.value)}
         />
         
+        {variants.length === 0 && (
         <div className="flex gap-4">
             <Input
             label="Harga (Rp)"
@@ -174,33 +159,19 @@ const EditProductPage = () => {
             className="flex-1"
             />
         </div>
+        )}
+
+        <Input
+        label="Berat per item (gram)"
+        description="Dipakai buat ngitung ongkir kurir"
+        type="number"
+        value={String(weight)}
+        onChange={(e) => setWeight(Number(e.target.value))}
+        />
+
+        <VariantManager productId={id} variants={variants} onChange={setVariants} />
       
-        <div className="border p-4 rounded-lg bg-default-50">
-          <label className="block text-sm font-medium text-default-700 mb-2">
-            Gambar Produk
-          </label>
-          <Input
-            type="file"
-            accept="image/*"
-            onChange={handleImageChange}
-          />
-          
-          <div className="mt-4 flex justify-center">
-            {newImagePreview ? (
-              <div className="text-center">
-                  <p className="text-xs text-default-400 mb-1">Preview Baru</p>
-                  <Image src={newImagePreview} alt="Preview" width={200} height={200} className="rounded-lg object-cover" />
-              </div>
-            ) : existingImageUrl ? (
-               <div className="text-center">
-                  <p className="text-xs text-default-400 mb-1">Gambar Saat Ini</p>
-                  <Image src={existingImageUrl} alt="Gambar Lama" width={200} height={200} className="rounded-lg object-cover" />
-              </div>
-            ) : (
-              <p className="text-default-400">Belom ada gambar</p>
-            )}
-          </div>
-        </div>
+        <GalleryManager productId={id} images={images} onChange={setImages} />
 
         {error && <p className="text-danger text-center font-semibold">{error}</p>}
         
@@ -28,6 +28,7 @@ const AddProductPage = () => {
   const [description, setDescription] = useState("");
   const [price, setPrice] = useState("");
   const [stock, setStock] = useState("");
+  const [weight, setWeight] = useState("");
   
   // 🔥 State Kategori
   const [categories, setCategories] = useState<Category[]>([]);
@@ -118,6 +119,7 @@ const AddProductPage = () => {
         description,
         price: parseFloat(price),
         stock: parseInt(stock),
+        weight_grams: parseInt(weight || "0"),
         image_url: imageUrl, 
         category_id: parseInt(categoryId),
       });
@@ -205,6 +207,15 @@ const AddProductPage = () => {
                 />
             </div>
 
+            <Input
+              label="Berat per item (gram)"
+              description="Dipakai buat ngitung ongkir kurir"
+              placeholder="0"
+              type="number"
+              value={weight}
+              onValueChange={setWeight}
+            />
+
             <div>
               <label className="block text-sm font-medium text-default-700">
                 Gambar Produk
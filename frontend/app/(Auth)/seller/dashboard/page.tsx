@@ -17,6 +17,9 @@ import {
 import { Spinner } from "@heroui/spinner";
 import NextLink from "next/link";
 import { useRouter } from "next/navigation";
+import { VoucherManager } from "@/components/vouchermanager";
+import { FulfilmentManager } from "@/components/fulfilmentmanager";
+import { SalesFulfilment } from "@/components/salesfulfilment";
 
 import {
   Modal,
@@ -94,13 +97,24 @@ const MyProductsPage = () => {
     <div className="py-8">
       <div className="flex justify-between items-center mb-6">
         <h1 className="text-3xl font-bold">Produk Saya</h1>
-        <Button as={NextLink} href="/seller/dashboard/new" color="primary">
-          Tambah Produk Baru
-        </Button>
+        <div className="flex gap-2">
+          <Button as={NextLink} href="/seller/store" variant="flat">
+            Pengaturan Toko
+          </Button>
+          <Button as={NextLink} href="/seller/dashboard/new" color="primary">
+            Tambah Produk Baru
+          </Button>
+        </div>
       </div>
 
       {error && <p className="text-danger mb-4 text-center">{error}</p>}
 
+      <SalesFulfilment />
+
+      <FulfilmentManager />
+
+      <VoucherManager />
+
       <Table aria-label="Tabel Produk Saya">
         <TableHeader>
           <TableColumn>Nama Produk</TableColumn>
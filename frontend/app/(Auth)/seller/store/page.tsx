@@ -0,0 +1,174 @@
+"use client";
+
+import React, { useEffect, useState } from "react";
+import NextImage from "next/image";
+import NextLink from "next/link";
+import { Input, Textarea } from "@heroui/input";
+import { Button } from "@heroui/button";
+import { Spinner } from "@heroui/spinner";
+import api from "@/libs/api";
+import { OperatingHour, Store } from "@/types";
+import { OperatingHoursEditor } from "@/components/operatinghours";
+
+const StoreSettingsPage = () => {
+  const [store, setStore] = useState<Store | null>(null);
+  const [name, setName] = useState("");
+  const [slug, setSlug] = useState("");
+  const [description, setDescription] = useState("");
+  const [pickupLocation, setPickupLocation] = useState("");
+  const [shippingOrigin, setShippingOrigin] = useState("");
+  const [operatingHours, setOperatingHours] = useState<OperatingHour[]>([]);
+  const [bannerURL, setBannerURL] = useState("");
+  const [bannerFile, setBannerFile] = useState<File | null>(null);
+  const [bannerPreview, setBannerPreview] = useState<string | null>(null);
+
+  const [loading, setLoading] = useState(true);
+  const [saving, setSaving] = useState(false);
+  const [error, setError] = useState("");
+  const [success, setSuccess] = useState("");
+
+  const fillForm = (s: Store) => {
+    setStore(s);
+    setName(s.name);
+    setSlug(s.slug);
+    setDescription(s.description);
+    setPickupLocation(s.pickup_location);
+    setShippingOrigin(s.shipping_origin);
+    setOperatingHours(s.operating_hours);
+    setBannerURL(s.banner_url);
+  };
+
+  useEffect(() => {
+    api
+      .get<Store>("/me/store")
+      .then((res) => fillForm(res.data))
+      .catch((err) => setError(err.response?.data?.error || "Gagal mengambil data toko"))
+      .finally(() => setLoading(false));
+  }, []);
+
+  const handleBannerChange = (e: React.ChangeEvent<HTMLInputElement>) => {
+    if (e.target.files && e.target.files[0]) {
+      const file = e.target.files[0];
+      setBannerFile(file);
+      setBannerPreview(URL.createObjectURL(file));
+    }
+  };
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setSaving(true);
+    setError("");
+    setSuccess("");
+
+    try {
+      let finalBannerURL = bannerURL;
+      if (bannerFile) {
+        const formData = new FormData();
+        formData.append("image", bannerFile);
+        const uploadResponse = await api.post("/upload/image", formData, {
+          headers: { "Content-Type": "multipart/form-data" },
+        });
+        finalBannerURL = uploadResponse.data.imageUrl;
+      }
+
+      const res = await api.put<Store>("/me/store", {
+        name,
+        slug,
+        description,
+        banner_url: finalBannerURL,
+        pickup_location: pickupLocation,
+        shipping_origin: shippingOrigin,
+        operating_hours: operatingHours,
+      });
+      fillForm(res.data);
+      setBannerFile(null);
+      setBannerPreview(null);
+      setSuccess("Toko berhasil di-update!");
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal menyimpan data toko");
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  if (loading) {
+    return <div className="text-center p-10"><Spinner size="lg" /></div>;
+  }
+
+  return (
+    <div className="py-8 max-w-2xl mx-auto">
+      <div className="flex justify-between items-center mb-6">
+        <h1 className="text-3xl font-bold">Pengaturan Toko</h1>
+        {store && (
+          <Button as={NextLink} href={`/stores/${store.slug}`} variant="flat">
+            Lihat Toko
+          </Button>
+        )}
+      </div>
+
+      <form onSubmit={handleSubmit} className="space-y-6 p-6 border rounded-lg bg-content1">
+        <div className="space-y-2">
+          <div className="relative w-full h-40 rounded-lg overflow-hidden bg-default-100">
+            {(bannerPreview || bannerURL) && (
+              <NextImage src={bannerPreview || bannerURL} alt="Banner toko" fill style={{ objectFit: "cover" }} unoptimized={true} />
+            )}
+          </div>
+          <div className="flex gap-2 items-center">
+            <Input type="file" accept="image/*" onChange={handleBannerChange} className="max-w-xs" />
+            {(bannerPreview || bannerURL) && (
+              <Button
+                size="sm"
+                variant="light"
+                color="danger"
+                onPress={() => {
+                  setBannerURL("");
+                  setBannerFile(null);
+                  setBannerPreview(null);
+                }}
+              >
+                Hapus Banner
+              </Button>
+            )}
+          </div>
+        </div>
+
+        <Input label="Nama Toko" value={name} onChange={(e) => setName(e.target.value)} isRequired />
+        <Input
+          label="Slug"
+          description={`Alamat toko: /stores/${slug}`}
+          value={slug}
+          onChange={(e) => setSlug(e.target.value)}
+          isRequired
+        />
+        <Textarea label="Deskripsi Toko" value={description} onChange={(e) => setDescription(e.target.value)} />
+        <Input
+          label="Lokasi Ambil di Kampus"
+          placeholder="Misal: Lobby Gedung TULT"
+          value={pickupLocation}
+          onChange={(e) => setPickupLocation(e.target.value)}
+        />
+        <Input
+          label="Asal Pengiriman"
+          placeholder="Misal: Bandung"
+          value={shippingOrigin}
+          onChange={(e) => setShippingOrigin(e.target.value)}
+        />
+
+        <div>
+          <h2 className="font-semibold mb-2">Jam Buka</h2>
+          <p className="text-sm text-default-500 mb-3">Kalau gak ada hari yang diatur, toko dianggep selalu buka.</p>
+          <OperatingHoursEditor value={operatingHours} onChange={setOperatingHours} />
+        </div>
+
+        {error && <p className="text-danger text-center">{error}</p>}
+        {success && <p className="text-success text-center">{success}</p>}
+
+        <Button type="submit" color="primary" className="w-full" isLoading={saving} disabled={saving}>
+          {saving ? "Menyimpan..." : "Simpan Toko"}
+        </Button>
+      </form>
+    </div>
+  );
+};
+
+export default StoreSettingsPage;
@@ -0,0 +1,114 @@
+"use client";
+
+import React, { useEffect, useState } from "react";
+import NextLink from "next/link";
+import { Button } from "@heroui/button";
+import { Chip } from "@heroui/chip";
+import { Spinner } from "@heroui/spinner";
+import api from "@/libs/api";
+import { useAuth } from "@/contexts/AuthContext";
+import { ProductCard } from "@/components/productcard";
+import { StockAlert, WishlistItem } from "@/types";
+
+const WishlistPage = () => {
+  const { loading: authLoading } = useAuth();
+  const [items, setItems] = useState<WishlistItem[]>([]);
+  const [alerts, setAlerts] = useState<StockAlert[]>([]);
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState("");
+
+  useEffect(() => {
+    if (authLoading) return;
+    setLoading(true);
+    Promise.all([api.get<WishlistItem[]>("/me/wishlist"), api.get<StockAlert[]>("/me/stock-alerts")])
+      .then(([wishlistRes, alertsRes]) => {
+        setItems(wishlistRes.data);
+        setAlerts(alertsRes.data);
+      })
+      .catch(() => setError("Gagal mengambil wishlist"))
+      .finally(() => setLoading(false));
+  }, [authLoading]);
+
+  const removeItem = async (productId: number) => {
+    try {
+      await api.delete(`/me/wishlist/${productId}`);
+      setItems((prev) => prev.filter((item) => item.product_id !== productId));
+    } catch (err) {
+      console.error("Gagal hapus dari wishlist:", err);
+    }
+  };
+
+  const cancelAlert = async (alert: StockAlert) => {
+    try {
+      await api.delete(`/products/${alert.product_id}/stock-alert`, { params: { variant_id: alert.variant_id } });
+      setAlerts((prev) => prev.filter((a) => a.id !== alert.id));
+    } catch (err) {
+      console.error("Gagal batalin pengingat stok:", err);
+    }
+  };
+
+  if (loading || authLoading) {
+    return (
+      <div className="flex justify-center items-center py-20">
+        <Spinner size="lg" label="Lagi ngambil wishlist..." />
+      </div>
+    );
+  }
+
+  if (error) {
+    return <p className="text-center text-danger py-20">{error}</p>;
+  }
+
+  return (
+    <div className="container mx-auto p-4 space-y-8">
+      <h1 className="text-3xl font-bold">Wishlist Saya</h1>
+
+      {alerts.length > 0 && (
+        <div className="p-4 border rounded-lg bg-content1 space-y-2">
+          <h2 className="text-xl font-bold">Nungguin Stok ({alerts.length})</h2>
+          <p className="text-sm text-default-500">Kamu bakal dapet notifikasi begitu barang ini ada stok lagi.</p>
+          {alerts.map((alert) => (
+            <div key={alert.id} className="flex items-center justify-between gap-4">
+              <NextLink href={`/products/${alert.product_slug}`} className="hover:underline">
+                {alert.product_name}
+                {alert.variant_name && <span className="text-default-500"> · {alert.variant_name}</span>}
+              </NextLink>
+              <Button size="sm" variant="light" color="danger" onPress={() => cancelAlert(alert)}>
+                Batalin
+              </Button>
+            </div>
+          ))}
+        </div>
+      )}
+
+      {items.length === 0 ? (
+        <div className="text-center py-20">
+          <p className="text-default-500 mb-4">Wishlist kamu masih kosong.</p>
+          <Button as={NextLink} href="/shop" color="primary">
+            Cari Barang
+          </Button>
+        </div>
+      ) : (
+        <div className="grid grid-cols-1 sm:grid-cols-2 md:grid-cols-3 lg:grid-cols-4 gap-6">
+          {items.map((item) => (
+            <div key={item.id} className="space-y-2">
+              <ProductCard product={item.product} />
+              <div className="flex items-center justify-between">
+                {item.alert_active ? (
+                  <Chip size="sm" color="warning" variant="flat">Nungguin stok</Chip>
+                ) : (
+                  <span />
+                )}
+                <Button size="sm" variant="light" color="danger" onPress={() => removeItem(item.product_id)}>
+                  Hapus
+                </Button>
+              </div>
+            </div>
+          ))}
+        </div>
+      )}
+    </div>
+  );
+};
+
+export default WishlistPage;
@@ -0,0 +1,68 @@
+"use client";
+
+import React, { useState } from "react";
+import { Input } from "@heroui/input";
+import { Button } from "@heroui/button";
+import { Link } from "@heroui/link";
+import NextLink from "next/link";
+import api from "@/libs/api";
+
+const ForgotPasswordPage = () => {
+  const [email, setEmail] = useState("");
+  const [loading, setLoading] = useState(false);
+  const [message, setMessage] = useState("");
+  const [error, setError] = useState("");
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setLoading(true);
+    setError("");
+    setMessage("");
+    try {
+      const res = await api.post("/auth/password/forgot", { email });
+      setMessage(res.data.message);
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal ngirim link reset password");
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div className="flex justify-center items-center py-12">
+      <div className="w-full max-w-md p-8 space-y-6 rounded-lg shadow-lg bg-content1">
+        <h2 className="text-3xl font-bold text-center">Lupa Password</h2>
+        <p className="text-sm text-default-500 text-center">
+          Masukin email akun kamu, nanti kami kirim link buat bikin password baru.
+        </p>
+
+        <form onSubmit={handleSubmit} className="space-y-4">
+          <Input
+            label="Email"
+            type="email"
+            placeholder="email@example.com"
+            value={email}
+            onChange={(e) => setEmail(e.target.value)}
+            isRequired
+            fullWidth
+          />
+
+          {error && <p className="text-danger text-sm text-center">{error}</p>}
+          {message && <p className="text-success text-sm text-center">{message}</p>}
+
+          <Button type="submit" color="primary" className="w-full" isLoading={loading} disabled={loading}>
+            Kirim Link Reset
+          </Button>
+        </form>
+
+        <p className="text-center text-sm">
+          <Link as={NextLink} href="/login" color="primary">
+            Balik ke Login
+          </Link>
+        </p>
+      </div>
+    </div>
+  );
+};
+
+export default ForgotPasswordPage;
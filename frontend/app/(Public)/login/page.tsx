@@ -6,29 +6,44 @@ import { Button } from "@heroui/button";
 import { Link } from "@heroui/link";
 import NextLink from "next/link";
 import { useAuth } from "@/contexts/AuthContext";
+import api from "@/libs/api";
 
 const LoginPage = () => {
   const [email, setEmail] = useState("");
   const [password, setPassword] = useState("");
   const [error, setError] = useState("");
+  const [notVerified, setNotVerified] = useState(false);
+  const [resendMessage, setResendMessage] = useState("");
 
   const { login, loading } = useAuth();
 
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault();
     setError("");
+    setNotVerified(false);
+    setResendMessage("");
 
     try {
-      const success = await login(email, password);
+      const result = await login(email, password);
 
-      if (!success) {
-        setError("Email atau Password salah. Coba lagi.");
+      if (!result.ok) {
+        setNotVerified(result.code === "email_not_verified");
+        setError(result.error || "Email atau Password salah. Coba lagi.");
       }
     } catch (err) {
       setError("Gagal terhubung ke server. Coba beberapa saat lagi.");
     }
   };
 
+  const handleResend = async () => {
+    try {
+      const res = await api.post("/auth/verify-email/resend", { email });
+      setResendMessage(res.data.message);
+    } catch (err: any) {
+      setResendMessage(err.response?.data?.error || "Gagal kirim ulang email verifikasi");
+    }
+  };
+
   return (
     <div className="flex justify-center items-center py-12">
       <div className="w-full max-w-md p-8 space-y-6 rounded-lg shadow-lg bg-content1">
@@ -64,6 +79,14 @@ const LoginPage = () => {
           {error && (
             <p className="text-danger text-sm text-center">{error}</p>
           )}
+          {notVerified && (
+            <div className="text-center text-sm">
+              <Button size="sm" variant="light" color="primary" onPress={handleResend}>
+                Kirim ulang email verifikasi
+              </Button>
+              {resendMessage && <p className="text-default-500">{resendMessage}</p>}
+            </div>
+          )}
 
           <Button
             type="submit"
@@ -76,6 +99,12 @@ const LoginPage = () => {
           </Button>
         </form>
 
+        <p className="text-center text-sm">
+          <Link as={NextLink} href="/forgot-password" color="primary" size="sm">
+            Lupa password?
+          </Link>
+        </p>
+
         <p className="text-center text-sm">
           Belum punya akun?{" "}
           <Link as={NextLink} href="/register" color="primary">
@@ -27,7 +27,7 @@ const HomePage = () => {
         setError(null);
 
         const [productsRes, categoriesRes] = await Promise.all([
-          api.get("/products"),
+          api.get("/products", { params: { limit: 100 } }),
           api.get("/categories"),
         ]);
 
@@ -1,7 +1,7 @@
 "use client";
 
 import React, { useState, useEffect } from "react";
-import { useParams } from "next/navigation";
+import { useParams, useRouter } from "next/navigation";
 import api from "@/libs/api";
 import { Product } from "@/types";
 import { Spinner } from "@heroui/spinner";
@@ -11,21 +11,45 @@ import { Link } from "@heroui/link";
 import NextLink from "next/link";
 import { useStockStream } from "@/hooks/useStockStream";
 import { useAuth } from "@/contexts/AuthContext";
+import { ProductReviews, Stars } from "@/components/productreviews";
+import { WishlistActions } from "@/components/wishlistactions";
 
 const ProductDetailPage = () => {
 
   const params = useParams();
+  const router = useRouter();
   const slug = params.slug as string;
 
   const [product, setProduct] = useState<Product | null>(null);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState("");
-  const liveStock = useStockStream(product?.id || null);
-  const { addToCart, loadingCart } = useAuth()
+  const [variantId, setVariantId] = useState<number | null>(null);
+  const [imageIndex, setImageIndex] = useState(0);
+  const liveStock = useStockStream(product?.id || null, variantId);
+  const { user, addToCart, loadingCart } = useAuth()
+
+  const variants = product?.variants ?? [];
+  const selectedVariant = variants.find((v) => v.id === variantId) ?? null;
+  const needsVariant = variants.length > 0 && !selectedVariant;
+
+  // Galeri: foto utama tampil duluan, produk lama tanpa galeri pake image_url
+  const gallery = product?.images?.length
+    ? [...product.images].sort((a, b) => Number(b.is_primary) - Number(a.is_primary) || a.position - b.position)
+    : product?.image_url
+      ? [{ id: 0, url: product.image_url, alt_text: product.name, position: 0, is_primary: true }]
+      : [];
+  const activeImage = gallery[imageIndex] ?? gallery[0];
+
+  // Harga & stok ikut varian yang dipilih, kalau belum milih pake ringkasan produk
+  const price = selectedVariant ? selectedVariant.price : product?.price ?? 0;
+  const baseStock = selectedVariant
+    ? selectedVariant.available_stock
+    : (product?.available_stock ?? product?.stock ?? 0);
+  const currentStock = liveStock !== null ? liveStock : baseStock;
 
   const handleAddToCart = () => {
-    if (!product) return; 
-    addToCart(product.id, 1);
+    if (!product || needsVariant) return; 
+    addToCart(product.id, 1, selectedVariant?.id);
   };
 
   useEffect(() => {
@@ -75,28 +99,74 @@ const ProductDetailPage = () => {
       <div className="grid grid-cols-1 md:grid-cols-2 gap-8 md:gap-12">
         
         {/* Kolom Kiri: Gambar */}
-        <div className="relative aspect-square w-full rounded-lg overflow-hidden border">
-          <NextImage
-            src={product.image_url}
-            alt={product.name}
-            fill
-            style={{ objectFit: "cover" }}
-            priority
-            unoptimized={true}
-          />
+        <div className="flex flex-col gap-3">
+          <div className="relative aspect-square w-full rounded-lg overflow-hidden border">
+            {activeImage && (
+              <NextImage
+                src={activeImage.medium_url || activeImage.url}
+                alt={activeImage.alt_text || product.name}
+                fill
+                style={{ objectFit: "cover" }}
+                priority
+                unoptimized={true}
+              />
+            )}
+          </div>
+          {gallery.length > 1 && (
+            <div className="flex gap-2 overflow-x-auto">
+              {gallery.map((img, i) => (
+                <button
+                  key={img.id}
+                  type="button"
+                  onClick={() => setImageIndex(i)}
+                  className={`relative w-16 h-16 shrink-0 rounded-md overflow-hidden border-2 ${
+                    img === activeImage ? "border-primary" : "border-transparent"
+                  }`}
+                >
+                  <NextImage src={img.thumbnail_url || img.url} alt={img.alt_text || product.name} fill style={{ objectFit: "cover" }} unoptimized={true} />
+                </button>
+              ))}
+            </div>
+          )}
         </div>
         
 
         <div className="flex flex-col gap-4 py-4">
           <h1 className="text-4xl font-bold">{product.name}</h1>
+          {!!product.rating_count && (
+            <p className="text-sm text-default-600">
+              <Stars rating={product.rating_average || 0} /> {product.rating_average?.toFixed(1)} ({product.rating_count} ulasan)
+            </p>
+          )}
           
           <p className="text-3xl font-bold text-primary">
-            Rp {product.price.toLocaleString("id-ID")}
+            {variants.length > 0 && !selectedVariant && "Mulai "}
+            Rp {price.toLocaleString("id-ID")}
           </p>
+
+          {variants.length > 0 && (
+            <div>
+              <h2 className="text-sm font-semibold mb-2">Pilih Varian</h2>
+              <div className="flex flex-wrap gap-2">
+                {variants.map((v) => (
+                  <Button
+                    key={v.id}
+                    size="sm"
+                    color={v.id === variantId ? "primary" : "default"}
+                    variant={v.id === variantId ? "solid" : "bordered"}
+                    className={v.available_stock <= 0 ? "line-through" : ""}
+                    onPress={() => setVariantId(v.id)}
+                  >
+                    {v.name}
+                  </Button>
+                ))}
+              </div>
+            </div>
+          )}
           
           <div className="text-sm text-default-600">
             <span className="font-bold text-lg">
-              Stok: {liveStock !== null ? liveStock : product.stock}
+              Stok: {currentStock}
             </span>
             {liveStock !== null && (
               <span className="ml-2 text-success-600 font-bold">(Live Update!)</span>
@@ -121,17 +191,41 @@ const ProductDetailPage = () => {
             color="primary"
             size="lg"
             className="mt-6 w-full md:w-auto"
-            disabled={(liveStock !== null ? liveStock : product.stock) === 0}
+            disabled={currentStock <= 0 || needsVariant}
             isLoading={loadingCart}
             onPress={handleAddToCart}
           >
-            {(liveStock !== null ? liveStock : product.stock) === 0 
+            {currentStock <= 0 
               ? "Stok Habis" 
-              : "Tambah ke Keranjang"}
+              : needsVariant
+                ? "Pilih Varian Dulu"
+                : "Tambah ke Keranjang"}
+          </Button>
+
+          <Button
+            color="primary"
+            variant="bordered"
+            size="lg"
+            className="w-full md:w-auto"
+            disabled={currentStock <= 0 || needsVariant}
+            onPress={() => router.push(
+              user
+                ? `/buy-now?product=${product.id}${selectedVariant ? `&variant=${selectedVariant.id}` : ""}`
+                : "/login"
+            )}
+          >
+            Beli Sekarang
           </Button>
+
+          <WishlistActions productId={product.id} variantId={selectedVariant?.id ?? null} outOfStock={currentStock <= 0} />
         </div>
 
       </div>
+
+      <ProductReviews
+        productId={product.id}
+        canReply={!!user && (user.id === product.seller.id || user.role === "admin")}
+      />
     </div>
   );
 };
@@ -92,6 +92,11 @@ const UserProfilePage = () => {
       <p className="text-default-500 mt-4">
         Bergabung sejak {formatDate(profile.joined_at)}
       </p>
+      {profile.store_slug && (
+        <Button as={NextLink} href={`/stores/${profile.store_slug}`} color="primary" variant="flat" className="mt-4">
+          Kunjungi Toko
+        </Button>
+      )}
 
 
       <div className="mt-10 w-full">
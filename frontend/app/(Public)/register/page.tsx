@@ -6,7 +6,7 @@ import { Button } from "@heroui/button";
 import { Link } from "@heroui/link";
 import NextLink from "next/link";
 import { useRouter } from "next/navigation";
-import api from "@/libs/api";
+import api, { saveCartToken } from "@/libs/api";
 
 const RegisterPage = () => {
   const [username, setUsername] = useState("");
@@ -38,13 +38,15 @@ const RegisterPage = () => {
         email,
         password,
       });
+      // Keranjang tamu (header X-Cart-Token) udah dipindah ke akun baru
+      saveCartToken(undefined);
 
-      setSuccess("Pendaftaran berhasil! Silakan login.");
+      setSuccess("Pendaftaran berhasil! Cek email kamu buat link verifikasi, habis itu baru login.");
       setLoading(false);
       
       setTimeout(() => {
         router.push("/login");
-      }, 2000);
+      }, 4000);
 
     } catch (err: any) {
       setLoading(false);
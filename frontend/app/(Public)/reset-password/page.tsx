@@ -0,0 +1,90 @@
+"use client";
+
+import React, { Suspense, useState } from "react";
+import { useRouter, useSearchParams } from "next/navigation";
+import { Input } from "@heroui/input";
+import { Button } from "@heroui/button";
+import { Spinner } from "@heroui/spinner";
+import { Link } from "@heroui/link";
+import NextLink from "next/link";
+import api from "@/libs/api";
+
+const ResetPasswordForm = () => {
+  const token = useSearchParams().get("token") || "";
+  const router = useRouter();
+
+  const [password, setPassword] = useState("");
+  const [confirmPassword, setConfirmPassword] = useState("");
+  const [loading, setLoading] = useState(false);
+  const [message, setMessage] = useState("");
+  const [error, setError] = useState("");
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setError("");
+    if (password !== confirmPassword) {
+      setError("Password dan Konfirmasi Password tidak sama!");
+      return;
+    }
+
+    setLoading(true);
+    try {
+      const res = await api.post("/auth/password/reset", { token, password });
+      setMessage(res.data.message);
+      setTimeout(() => router.push("/login"), 2000);
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal reset password");
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div className="flex justify-center items-center py-12">
+      <div className="w-full max-w-md p-8 space-y-6 rounded-lg shadow-lg bg-content1">
+        <h2 className="text-3xl font-bold text-center">Bikin Password Baru</h2>
+
+        {!token ? (
+          <p className="text-danger text-center">
+            Link reset tidak lengkap.{" "}
+            <Link as={NextLink} href="/forgot-password" color="primary">Minta link baru</Link>
+          </p>
+        ) : (
+          <form onSubmit={handleSubmit} className="space-y-4">
+            <Input
+              label="Password Baru"
+              type="password"
+              value={password}
+              onChange={(e) => setPassword(e.target.value)}
+              isRequired
+              fullWidth
+            />
+            <Input
+              label="Konfirmasi Password Baru"
+              type="password"
+              value={confirmPassword}
+              onChange={(e) => setConfirmPassword(e.target.value)}
+              isRequired
+              fullWidth
+            />
+
+            {error && <p className="text-danger text-sm text-center">{error}</p>}
+            {message && <p className="text-success text-sm text-center">{message}</p>}
+
+            <Button type="submit" color="primary" className="w-full" isLoading={loading} disabled={loading || !!message}>
+              Simpan Password
+            </Button>
+          </form>
+        )}
+      </div>
+    </div>
+  );
+};
+
+const ResetPasswordPage = () => (
+  <Suspense fallback={<div className="text-center p-10"><Spinner size="lg" /></div>}>
+    <ResetPasswordForm />
+  </Suspense>
+);
+
+export default ResetPasswordPage;
@@ -7,7 +7,8 @@ import { Select, SelectItem } from "@heroui/select";
 import { Button } from "@heroui/button";
 import { Skeleton } from "@heroui/skeleton";
 import api from "@/libs/api";
-import { Product, Category } from "@/types";
+import { Switch } from "@heroui/switch";
+import { Product, Category, ProductListResponse } from "@/types";
 
 // --- ICON COMPONENT (Biar Search Bar Ganteng) ---
 const SearchIcon = (props: React.SVGProps<SVGSVGElement>) => (
@@ -38,82 +39,93 @@ const SearchIcon = (props: React.SVGProps<SVGSVGElement>) => (
   </svg>
 );
 
+const PAGE_SIZE = 20;
+
 export default function ShopPage() {
   // --- STATE MANAGEMENT ---
   const [products, setProducts] = useState<Product[]>([]);
   const [categories, setCategories] = useState<Category[]>([]);
+  const [total, setTotal] = useState(0);
+  const [nextCursor, setNextCursor] = useState<string | undefined>();
   const [loading, setLoading] = useState(true);
+  const [loadingMore, setLoadingMore] = useState(false);
 
   // Filter States
   const [searchQuery, setSearchQuery] = useState("");
+  const [debouncedQuery, setDebouncedQuery] = useState("");
   const [selectedCategory, setSelectedCategory] = useState("all");
   const [sortOption, setSortOption] = useState("newest");
+  const [inStockOnly, setInStockOnly] = useState(false);
+
+  useEffect(() => {
+    api
+      .get("/categories")
+      .then((res) => {
+        const categoriesData = Array.isArray(res.data)
+          ? res.data
+          : res.data?.data || [];
+        setCategories(categoriesData);
+      })
+      .catch((error) => console.error("Gagal ambil kategori:", error));
+  }, []);
 
+  // Search baru dikirim ke server kalau user udah berhenti ngetik
   useEffect(() => {
-    const fetchData = async () => {
+    const timer = setTimeout(() => setDebouncedQuery(searchQuery.trim()), 300);
+    return () => clearTimeout(timer);
+  }, [searchQuery]);
+
+  // --- 2. FILTERING LOGIC (sekarang dikerjain backend) ---
+  const params = useMemo(
+    () => ({
+      q: debouncedQuery || undefined,
+      category: selectedCategory !== "all" ? selectedCategory : undefined,
+      in_stock: inStockOnly || undefined,
+      sort: sortOption,
+      limit: PAGE_SIZE,
+    }),
+    [debouncedQuery, selectedCategory, inStockOnly, sortOption]
+  );
+
+  useEffect(() => {
+    let cancelled = false;
+    const fetchProducts = async () => {
       try {
         setLoading(true);
-        const [productsRes, categoriesRes] = await Promise.all([
-          api.get("/products"),
-          api.get("/categories"),
-        ]);
-
-        const productsData = Array.isArray(productsRes.data)
-          ? productsRes.data
-          : productsRes.data?.data || [];
-        setProducts(productsData);
-
-        const categoriesData = Array.isArray(categoriesRes.data)
-          ? categoriesRes.data
-          : categoriesRes.data?.data || [];
-        setCategories(categoriesData);
+        const res = await api.get<ProductListResponse>("/products", { params });
+        if (cancelled) return;
+        setProducts(res.data.data);
+        setTotal(res.data.total);
+        setNextCursor(res.data.next_cursor);
       } catch (error) {
         console.error("Gagal ambil data shop:", error);
       } finally {
-        setLoading(false);
+        if (!cancelled) setLoading(false);
       }
     };
 
-    fetchData();
-  }, []);
-
-  // --- 2. FILTERING LOGIC (MESIN PEMROSES) ---
-  const processedProducts = useMemo(() => {
-    let result = [...products];
-
-    // A. Logic Search (Case Insensitive)
-    if (searchQuery) {
-      const lowerQuery = searchQuery.toLowerCase();
-      result = result.filter((p) =>
-        p.name.toLowerCase().includes(lowerQuery) || 
-        p.description.toLowerCase().includes(lowerQuery)
-      );
-    }
-
-    // B. Logic Category
-    if (selectedCategory !== "all") {
-      result = result.filter((p) => p.category?.slug === selectedCategory);
-    }
+    fetchProducts();
+    return () => {
+      cancelled = true;
+    };
+  }, [params]);
 
-    // C. Logic Sorting
-    switch (sortOption) {
-      case "price_low":
-        result.sort((a, b) => a.price - b.price); // Murah ke Mahal
-        break;
-      case "price_high":
-        result.sort((a, b) => b.price - a.price); // Mahal ke Murah
-        break;
-      case "name_az":
-        result.sort((a, b) => a.name.localeCompare(b.name)); // Abjad A-Z
-        break;
-      case "newest":
-      default:
-        result.sort((a, b) => b.id - a.id); // ID Gede = Baru
-        break;
+  const loadMore = async () => {
+    if (!nextCursor) return;
+    try {
+      setLoadingMore(true);
+      const res = await api.get<ProductListResponse>("/products", {
+        params: { ...params, cursor: nextCursor },
+      });
+      setProducts((prev) => [...prev, ...res.data.data]);
+      setTotal(res.data.total);
+      setNextCursor(res.data.next_cursor);
+    } catch (error) {
+      console.error("Gagal ambil produk berikutnya:", error);
+    } finally {
+      setLoadingMore(false);
     }
-
-    return result;
-  }, [products, searchQuery, selectedCategory, sortOption]);
+  };
 
   // --- HELPER BUAT SKELETON ---
   const renderSkeletons = () => (
@@ -141,7 +153,7 @@ export default function ShopPage() {
         </div>
         
         <div className="text-default-400 text-sm">
-            Total: <span className="text-primary font-bold">{processedProducts.length}</span> Produk ditemukan
+            Total: <span className="text-primary font-bold">{total}</span> Produk ditemukan
         </div>
       </div>
 
@@ -150,7 +162,7 @@ export default function ShopPage() {
         <div className="grid grid-cols-1 gap-4 md:grid-cols-12">
           
           {/* 1. SEARCH BAR */}
-          <div className="md:col-span-6">
+          <div className="md:col-span-4">
             <Input
               isClearable
               placeholder="Cari laptop, baju, ..."
@@ -188,19 +200,28 @@ export default function ShopPage() {
               onChange={(e) => setSortOption(e.target.value)}
             >
               <SelectItem key="newest">Terbaru</SelectItem>
-              <SelectItem key="price_low">Termurah</SelectItem>
-              <SelectItem key="price_high">Termahal</SelectItem>
-              <SelectItem key="name_az">Nama (A-Z)</SelectItem>
+              <SelectItem key="best_selling">Terlaris</SelectItem>
+              <SelectItem key="top_rated">Rating Tertinggi</SelectItem>
+              <SelectItem key="price_asc">Termurah</SelectItem>
+              <SelectItem key="price_desc">Termahal</SelectItem>
+              <SelectItem key="name_asc">Nama (A-Z)</SelectItem>
             </Select>
           </div>
 
+          {/* 4. STOK TERSEDIA */}
+          <div className="flex items-center md:col-span-2">
+            <Switch isSelected={inStockOnly} onValueChange={setInStockOnly} size="sm">
+              Stok ada
+            </Switch>
+          </div>
+
         </div>
       </div>
 
       {/* PRODUCT GRID SECTION */}
       {loading ? (
         renderSkeletons()
-      ) : processedProducts.length === 0 ? (
+      ) : products.length === 0 ? (
         <div className="flex flex-col items-center justify-center py-20 text-center">
             <h3 className="text-xl font-bold">Produk tidak ditemukan</h3>
             <p className="text-default-500 mb-6">Coba ganti kata kunci atau reset filter lo, King.</p>
@@ -211,6 +232,7 @@ export default function ShopPage() {
                     setSearchQuery("");
                     setSelectedCategory("all");
                     setSortOption("newest");
+                    setInStockOnly(false);
                 }}
             >
                 Reset Filter
@@ -218,11 +240,19 @@ export default function ShopPage() {
         </div>
       ) : (
         <div className="grid grid-cols-1 gap-6 sm:grid-cols-2 lg:grid-cols-4">
-          {processedProducts.map((product) => (
+          {products.map((product) => (
             <ProductCard key={product.id} product={product} />
           ))}
         </div>
       )}
+
+      {!loading && nextCursor && (
+        <div className="mt-8 flex justify-center">
+          <Button color="primary" variant="flat" isLoading={loadingMore} onPress={loadMore}>
+            Muat lebih banyak
+          </Button>
+        </div>
+      )}
     </div>
   );
 }
\ No newline at end of file
@@ -0,0 +1,229 @@
+"use client";
+
+import React, { useState, useEffect } from "react";
+import { useParams } from "next/navigation";
+import NextImage from "next/image";
+import NextLink from "next/link";
+import api from "@/libs/api";
+import { Store, Product, ProductListResponse } from "@/types";
+import { Spinner } from "@heroui/spinner";
+import { Avatar } from "@heroui/avatar";
+import { Button } from "@heroui/button";
+import { Select, SelectItem } from "@heroui/select";
+import { ProductCard } from "@/components/productcard";
+import { DAY_NAMES } from "@/components/operatinghours";
+
+const PAGE_SIZE = 20;
+
+const formatResponseTime = (minutes: number | null) => {
+  if (minutes === null) return "-";
+  if (minutes < 60) return `± ${minutes} menit`;
+  if (minutes < 60 * 24) return `± ${Math.round(minutes / 60)} jam`;
+  return `± ${Math.round(minutes / (60 * 24))} hari`;
+};
+
+const StorePage = () => {
+  const params = useParams();
+  const slug = params.slug as string;
+
+  const [store, setStore] = useState<Store | null>(null);
+  const [products, setProducts] = useState<Product[]>([]);
+  const [total, setTotal] = useState(0);
+  const [nextCursor, setNextCursor] = useState<string | undefined>();
+  const [sortOption, setSortOption] = useState("newest");
+  const [loading, setLoading] = useState(true);
+  const [loadingProducts, setLoadingProducts] = useState(true);
+  const [loadingMore, setLoadingMore] = useState(false);
+  const [error, setError] = useState("");
+
+  useEffect(() => {
+    if (!slug) return;
+    api
+      .get<Store>(`/stores/${slug}`)
+      .then((res) => setStore(res.data))
+      .catch((err) => setError(err.response?.data?.error || "Toko tidak ditemukan"))
+      .finally(() => setLoading(false));
+  }, [slug]);
+
+  useEffect(() => {
+    if (!slug) return;
+    let cancelled = false;
+    setLoadingProducts(true);
+    api
+      .get<ProductListResponse>(`/stores/${slug}/products`, { params: { sort: sortOption, limit: PAGE_SIZE } })
+      .then((res) => {
+        if (cancelled) return;
+        setProducts(res.data.data);
+        setTotal(res.data.total);
+        setNextCursor(res.data.next_cursor);
+      })
+      .catch((err) => console.error("Gagal ambil produk toko:", err))
+      .finally(() => {
+        if (!cancelled) setLoadingProducts(false);
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [slug, sortOption]);
+
+  const loadMore = async () => {
+    if (!nextCursor) return;
+    try {
+      setLoadingMore(true);
+      const res = await api.get<ProductListResponse>(`/stores/${slug}/products`, {
+        params: { sort: sortOption, limit: PAGE_SIZE, cursor: nextCursor },
+      });
+      setProducts((prev) => [...prev, ...res.data.data]);
+      setNextCursor(res.data.next_cursor);
+    } catch (err) {
+      console.error("Gagal ambil produk berikutnya:", err);
+    } finally {
+      setLoadingMore(false);
+    }
+  };
+
+  if (loading) {
+    return <div className="text-center p-10"><Spinner size="lg" /></div>;
+  }
+
+  if (error || !store) {
+    return (
+      <div className="text-center py-20">
+        <h1 className="text-3xl font-bold text-danger">404 - Toko Tidak Ditemukan</h1>
+        <p>{error}</p>
+        <Button as={NextLink} href="/shop" color="primary" className="mt-4">
+          Kembali ke Shop
+        </Button>
+      </div>
+    );
+  }
+
+  const stats = store.stats;
+
+  return (
+    <div className="py-8 max-w-7xl mx-auto">
+      <div className="relative w-full h-48 md:h-64 rounded-xl overflow-hidden bg-gradient-to-r from-primary-200 to-secondary-200">
+        {store.banner_url && (
+          <NextImage src={store.banner_url} alt={store.name} fill style={{ objectFit: "cover" }} unoptimized={true} />
+        )}
+      </div>
+
+      <div className="flex flex-col md:flex-row gap-6 -mt-12 px-6">
+        <Avatar
+          src={store.seller.profile_image_url}
+          name={store.name.charAt(0).toUpperCase()}
+          className="w-28 h-28 text-4xl shrink-0"
+          isBordered
+          color="primary"
+        />
+        <div className="flex-1 pt-14 md:pt-12">
+          <div className="flex flex-wrap items-center gap-3">
+            <h1 className="text-3xl font-bold">{store.name}</h1>
+            <span className={`px-3 py-1 rounded-full text-sm font-bold ${store.is_open ? "bg-success text-success-foreground" : "bg-default-200"}`}>
+              {store.is_open ? "Buka" : "Tutup"}
+            </span>
+          </div>
+          <p className="text-default-500 text-sm mt-1">
+            oleh{" "}
+            <NextLink href={`/profile/${store.seller.username}`} className="text-primary">
+              {store.seller.username}
+            </NextLink>
+          </p>
+          {store.description && <p className="mt-3 whitespace-pre-wrap">{store.description}</p>}
+        </div>
+      </div>
+
+      {stats && (
+        <div className="grid grid-cols-2 md:grid-cols-4 gap-4 mt-8">
+          <div className="p-4 border rounded-lg bg-content1 text-center">
+            <p className="text-2xl font-bold">{stats.product_count}</p>
+            <p className="text-sm text-default-500">Produk</p>
+          </div>
+          <div className="p-4 border rounded-lg bg-content1 text-center">
+            <p className="text-2xl font-bold">{stats.sold_count}</p>
+            <p className="text-sm text-default-500">Terjual</p>
+          </div>
+          <div className="p-4 border rounded-lg bg-content1 text-center">
+            <p className="text-2xl font-bold">
+              {stats.average_rating !== null ? `⭐ ${stats.average_rating.toFixed(1)}` : "-"}
+            </p>
+            <p className="text-sm text-default-500">Rating ({stats.rating_count} ulasan)</p>
+          </div>
+          <div className="p-4 border rounded-lg bg-content1 text-center">
+            <p className="text-2xl font-bold">{formatResponseTime(stats.response_time_minutes)}</p>
+            <p className="text-sm text-default-500">Waktu Balas Chat</p>
+          </div>
+        </div>
+      )}
+
+      <div className="grid grid-cols-1 md:grid-cols-2 gap-4 mt-4">
+        <div className="p-4 border rounded-lg bg-content1">
+          <h2 className="font-semibold mb-2">Lokasi</h2>
+          <p className="text-sm">📍 Ambil di kampus: {store.pickup_location || "-"}</p>
+          <p className="text-sm">🚚 Dikirim dari: {store.shipping_origin || "-"}</p>
+        </div>
+        <div className="p-4 border rounded-lg bg-content1">
+          <h2 className="font-semibold mb-2">Jam Buka</h2>
+          {store.operating_hours.length === 0 ? (
+            <p className="text-sm text-default-500">Jadwal belum diatur</p>
+          ) : (
+            <ul className="text-sm space-y-1">
+              {[...store.operating_hours]
+                .sort((a, b) => a.day - b.day)
+                .map((oh) => (
+                  <li key={oh.day} className="flex justify-between">
+                    <span>{DAY_NAMES[oh.day]}</span>
+                    <span>{oh.open} - {oh.close}</span>
+                  </li>
+                ))}
+            </ul>
+          )}
+        </div>
+      </div>
+
+      <div className="mt-10">
+        <div className="flex flex-col gap-4 md:flex-row md:items-end md:justify-between mb-4">
+          <h2 className="text-2xl font-bold">Katalog ({total})</h2>
+          <Select
+            aria-label="Urutkan"
+            className="md:max-w-xs"
+            selectedKeys={[sortOption]}
+            onChange={(e) => e.target.value && setSortOption(e.target.value)}
+          >
+            <SelectItem key="newest">Terbaru</SelectItem>
+            <SelectItem key="best_selling">Terlaris</SelectItem>
+            <SelectItem key="top_rated">Rating Tertinggi</SelectItem>
+            <SelectItem key="price_asc">Harga Terendah</SelectItem>
+            <SelectItem key="price_desc">Harga Tertinggi</SelectItem>
+            <SelectItem key="name_asc">Nama (A-Z)</SelectItem>
+          </Select>
+        </div>
+
+        {loadingProducts ? (
+          <div className="text-center p-10"><Spinner /></div>
+        ) : products.length > 0 ? (
+          <>
+            <div className="grid grid-cols-1 gap-6 sm:grid-cols-2 lg:grid-cols-4">
+              {products.map((product) => (
+                <ProductCard key={product.id} product={product} />
+              ))}
+            </div>
+            {nextCursor && (
+              <div className="mt-8 flex justify-center">
+                <Button variant="flat" isLoading={loadingMore} onPress={loadMore}>
+                  Muat Lebih Banyak
+                </Button>
+              </div>
+            )}
+          </>
+        ) : (
+          <div className="p-8 border rounded-lg text-center bg-content1">
+            <p className="text-default-500">Toko ini belom jualan apa-apa... 😅</p>
+          </div>
+        )}
+      </div>
+    </div>
+  );
+};
+
+export default StorePage;
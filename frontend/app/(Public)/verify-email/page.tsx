@@ -0,0 +1,55 @@
+"use client";
+
+import React, { Suspense, useEffect, useState } from "react";
+import { useSearchParams } from "next/navigation";
+import { Button } from "@heroui/button";
+import { Spinner } from "@heroui/spinner";
+import NextLink from "next/link";
+import api from "@/libs/api";
+
+const VerifyEmail = () => {
+  const token = useSearchParams().get("token") || "";
+  const [status, setStatus] = useState<"loading" | "success" | "error">("loading");
+  const [message, setMessage] = useState("");
+
+  useEffect(() => {
+    if (!token) {
+      setStatus("error");
+      setMessage("Link verifikasi tidak lengkap");
+      return;
+    }
+    api.post("/auth/verify-email", { token })
+      .then((res) => {
+        setStatus("success");
+        setMessage(res.data.message);
+      })
+      .catch((err) => {
+        setStatus("error");
+        setMessage(err.response?.data?.error || "Gagal verifikasi email");
+      });
+  }, [token]);
+
+  if (status === "loading") {
+    return <div className="text-center p-10"><Spinner size="lg" label="Lagi verifikasi email..." /></div>;
+  }
+
+  return (
+    <div className="flex justify-center items-center py-12">
+      <div className="w-full max-w-md p-8 space-y-6 rounded-lg shadow-lg bg-content1 text-center">
+        <h2 className="text-3xl font-bold">{status === "success" ? "Email Terverifikasi" : "Verifikasi Gagal"}</h2>
+        <p className={status === "success" ? "text-success" : "text-danger"}>{message}</p>
+        <Button as={NextLink} href="/login" color="primary" className="w-full">
+          Ke Halaman Login
+        </Button>
+      </div>
+    </div>
+  );
+};
+
+const VerifyEmailPage = () => (
+  <Suspense fallback={<div className="text-center p-10"><Spinner size="lg" /></div>}>
+    <VerifyEmail />
+  </Suspense>
+);
+
+export default VerifyEmailPage;
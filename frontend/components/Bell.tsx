@@ -10,7 +10,7 @@ import {
 import { Badge } from "@heroui/badge";
 import { Button } from "@heroui/button";
 import { Spinner } from "@heroui/spinner";
-import { FiBell, FiShoppingBag, FiMessageSquare, FiInfo } from "react-icons/fi";
+import { FiBell, FiShoppingBag, FiMessageSquare, FiInfo, FiPackage } from "react-icons/fi";
 import clsx from "clsx";
 import NextLink from "next/link";
 import { formatDistanceToNow } from "date-fns";
@@ -25,6 +25,8 @@ const getNotificationIcon = (type: NotificationType) => {
       return <FiShoppingBag className="text-primary" size={20} />;
     case "chat":
       return <FiMessageSquare className="text-success" size={20} />;
+    case "stock":
+      return <FiPackage className="text-warning" size={20} />;
     case "info":
     default:
       return <FiInfo className="text-default-500" size={20} />;
@@ -37,6 +39,8 @@ const getNotificationLink = (notif: Notification) => {
       return `/orders/${notif.reference_id}`;
     case "chat":
       return `/chat/${notif.reference_id}`;
+    case "stock":
+      return `/products/${notif.reference_id}`;
     case "info":
     default:
       return "#";
@@ -0,0 +1,58 @@
+"use client";
+
+import React, { useState } from "react";
+import { Input } from "@heroui/input";
+import { Button } from "@heroui/button";
+import api from "@/libs/api";
+
+// Form ganti password di halaman profil. Device lain otomatis di-logout sama server.
+export const ChangePasswordForm = () => {
+  const [currentPassword, setCurrentPassword] = useState("");
+  const [newPassword, setNewPassword] = useState("");
+  const [confirmPassword, setConfirmPassword] = useState("");
+  const [loading, setLoading] = useState(false);
+  const [message, setMessage] = useState("");
+  const [error, setError] = useState("");
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setError("");
+    setMessage("");
+    if (newPassword !== confirmPassword) {
+      setError("Password baru dan konfirmasinya tidak sama!");
+      return;
+    }
+
+    setLoading(true);
+    try {
+      const res = await api.put("/me/password", {
+        current_password: currentPassword,
+        new_password: newPassword,
+      });
+      setMessage(res.data.message);
+      setCurrentPassword("");
+      setNewPassword("");
+      setConfirmPassword("");
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal ganti password");
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <form onSubmit={handleSubmit} className="space-y-4 p-6 border rounded-lg bg-content1">
+      <h2 className="text-xl font-semibold">Ganti Password</h2>
+      <Input label="Password Lama" type="password" value={currentPassword} onChange={(e) => setCurrentPassword(e.target.value)} isRequired />
+      <Input label="Password Baru" type="password" value={newPassword} onChange={(e) => setNewPassword(e.target.value)} isRequired />
+      <Input label="Konfirmasi Password Baru" type="password" value={confirmPassword} onChange={(e) => setConfirmPassword(e.target.value)} isRequired />
+
+      {error && <p className="text-danger text-sm text-center">{error}</p>}
+      {message && <p className="text-success text-sm text-center">{message}</p>}
+
+      <Button type="submit" color="primary" className="w-full" isLoading={loading} disabled={loading}>
+        Ganti Password
+      </Button>
+    </form>
+  );
+};
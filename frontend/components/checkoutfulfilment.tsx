@@ -0,0 +1,130 @@
+"use client";
+
+import React, { useState } from "react";
+import { Button } from "@heroui/button";
+import { Input } from "@heroui/input";
+import { Select, SelectItem } from "@heroui/select";
+import api from "@/libs/api";
+import { Address, CheckoutPreview } from "@/types";
+
+const emptyAddress = {
+  label: "",
+  recipient_name: "",
+  phone: "",
+  street: "",
+  city: "",
+  postal_code: "",
+};
+
+const typeLabel = { meetup: "Ketemuan", pickup: "Ambil sendiri", courier: "Kurir" };
+
+const formatAddress = (a: Address) => `${a.label ? a.label + " · " : ""}${a.recipient_name}, ${a.street}, ${a.city}`;
+
+// Pilih pengiriman per seller. Metode yang kepilih & ongkirnya diambil dari preview checkout,
+// jadi seller yang belum dipilih buyer nampilin default dari server.
+export const CheckoutFulfilment = ({
+  preview,
+  addressId,
+  onMethodChange,
+  onAddressChange,
+}: {
+  preview: CheckoutPreview;
+  addressId: number | null;
+  onMethodChange: (sellerId: number, methodId: number) => void;
+  onAddressChange: (addressId: number) => void;
+}) => {
+  const [addresses, setAddresses] = useState<Address[]>(preview.addresses);
+  const [addressForm, setAddressForm] = useState(emptyAddress);
+  const [showAddressForm, setShowAddressForm] = useState(false);
+  const [saving, setSaving] = useState(false);
+  const [error, setError] = useState("");
+
+  const allAddresses = addresses.length >= preview.addresses.length ? addresses : preview.addresses;
+  const needsAddress = preview.sellers.some(
+    (seller) => seller.options.find((o) => o.method.id === seller.method_id)?.method.type === "courier"
+  );
+
+  const saveAddress = async () => {
+    setSaving(true);
+    setError("");
+    try {
+      const res = await api.post<Address>("/me/addresses", addressForm);
+      setAddresses([...allAddresses, res.data]);
+      onAddressChange(res.data.id);
+      setAddressForm(emptyAddress);
+      setShowAddressForm(false);
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal menyimpan alamat");
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  const set = (field: keyof typeof emptyAddress) => (e: React.ChangeEvent<HTMLInputElement>) =>
+    setAddressForm((f) => ({ ...f, [field]: e.target.value }));
+
+  return (
+    <div className="space-y-3">
+      {preview.sellers.map((seller) => (
+        <Select
+          key={seller.seller_id}
+          size="sm"
+          label={`Pengiriman dari ${seller.seller_name}`}
+          selectedKeys={[String(seller.method_id)]}
+          disabledKeys={seller.options.filter((o) => !o.available).map((o) => String(o.method.id))}
+          onChange={(e) => e.target.value && onMethodChange(seller.seller_id, Number(e.target.value))}
+        >
+          {seller.options.map((o) => (
+            <SelectItem
+              key={String(o.method.id)}
+              description={o.available ? o.method.location || o.method.description : "Paket terlalu berat buat metode ini"}
+            >
+              {`${typeLabel[o.method.type]} · ${o.method.name} · ${o.fee > 0 ? `Rp ${o.fee.toLocaleString("id-ID")}` : "Gratis"}`}
+            </SelectItem>
+          ))}
+        </Select>
+      ))}
+
+      {needsAddress && (
+        <div className="space-y-2">
+          {allAddresses.length > 0 && (
+            <Select
+              size="sm"
+              label="Alamat pengiriman"
+              selectedKeys={addressId ? [String(addressId)] : []}
+              onChange={(e) => e.target.value && onAddressChange(Number(e.target.value))}
+            >
+              {allAddresses.map((a) => (
+                <SelectItem key={String(a.id)}>{formatAddress(a)}</SelectItem>
+              ))}
+            </Select>
+          )}
+          {showAddressForm ? (
+            <div className="grid grid-cols-2 gap-2">
+              <Input size="sm" label="Label (Kos, Rumah)" value={addressForm.label} onChange={set("label")} />
+              <Input size="sm" label="Nama penerima" value={addressForm.recipient_name} onChange={set("recipient_name")} isRequired />
+              <Input size="sm" label="No. HP" value={addressForm.phone} onChange={set("phone")} isRequired />
+              <Input size="sm" label="Kota" value={addressForm.city} onChange={set("city")} isRequired />
+              <Input size="sm" className="col-span-2" label="Alamat lengkap" value={addressForm.street} onChange={set("street")} isRequired />
+              <Input size="sm" label="Kode pos" value={addressForm.postal_code} onChange={set("postal_code")} />
+              <div className="flex gap-2 items-center">
+                <Button size="sm" color="primary" isLoading={saving} onPress={saveAddress}>
+                  Simpan
+                </Button>
+                <Button size="sm" variant="flat" onPress={() => setShowAddressForm(false)}>
+                  Batal
+                </Button>
+              </div>
+            </div>
+          ) : (
+            <Button size="sm" variant="flat" onPress={() => setShowAddressForm(true)}>
+              Tambah alamat
+            </Button>
+          )}
+        </div>
+      )}
+
+      {error && <p className="text-danger text-sm">{error}</p>}
+    </div>
+  );
+};
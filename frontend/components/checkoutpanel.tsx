@@ -0,0 +1,204 @@
+"use client";
+
+import React, { useEffect, useState } from "react";
+import { Button } from "@heroui/button";
+import { Input } from "@heroui/input";
+import { Spinner } from "@heroui/spinner";
+import { useRouter } from "next/navigation";
+import api from "@/libs/api";
+import { CheckoutPreview, CheckoutRequest } from "@/types";
+import { CheckoutFulfilment } from "@/components/checkoutfulfilment";
+
+declare global {
+  interface Window {
+    snap: any;
+  }
+}
+
+const rupiah = (n: number) => `Rp ${n.toLocaleString("id-ID")}`;
+
+// Ringkasan + tombol bayar, dipake keranjang (items) dan beli langsung (buy_now).
+// Total, ongkir, potongan voucher, dan masalah stok semuanya dari POST /checkout/preview.
+// revision = penanda isi keranjang di server (mis. habis diperbarui), biar preview dihitung ulang.
+export const CheckoutPanel = ({
+  request,
+  revision,
+  onPaid,
+}: {
+  request: Pick<CheckoutRequest, "items" | "buy_now">;
+  revision?: string;
+  onPaid?: () => void;
+}) => {
+  const router = useRouter();
+  const [preview, setPreview] = useState<CheckoutPreview | null>(null);
+  const [previewError, setPreviewError] = useState("");
+  const [voucherCode, setVoucherCode] = useState("");
+  const [appliedVoucher, setAppliedVoucher] = useState("");
+  const [methodBySeller, setMethodBySeller] = useState<Record<number, number>>({});
+  const [addressId, setAddressId] = useState<number | null>(null);
+  const [checkoutLoading, setCheckoutLoading] = useState(false);
+
+  const body: CheckoutRequest = {
+    ...request,
+    voucher_code: appliedVoucher || undefined,
+    fulfilment: Object.entries(methodBySeller).map(([sellerId, methodId]) => ({
+      seller_id: Number(sellerId),
+      method_id: methodId,
+      address_id: addressId ?? undefined,
+    })),
+  };
+  const bodyKey = JSON.stringify(body);
+
+  useEffect(() => {
+    let cancelled = false;
+    api
+      .post<CheckoutPreview>("/checkout/preview", body)
+      .then((res) => {
+        if (cancelled) return;
+        setPreview(res.data);
+        setPreviewError("");
+        if (addressId === null && res.data.addresses.length > 0) {
+          setAddressId(res.data.addresses.find((a) => a.is_default)?.id ?? res.data.addresses[0].id);
+        }
+      })
+      .catch((err: any) => {
+        if (cancelled) return;
+        setPreview(null);
+        setPreviewError(err.response?.data?.error || "Gagal ngitung total checkout");
+      });
+    return () => {
+      cancelled = true;
+    };
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [bodyKey, revision]);
+
+  const voucherProblem = preview?.problems.find((p) => p.type === "voucher");
+
+  const handleCheckout = async () => {
+    setCheckoutLoading(true);
+
+    try {
+      const response = await api.post("/checkout", body);
+      const { snap_token, order_id, gateway } = response.data;
+      const done = () => {
+        router.push(`/orders/${order_id}`);
+        setCheckoutLoading(false);
+        onPaid?.();
+      };
+
+      // Gateway fake (dev/CI) gak punya popup Snap, pembayaran disimulasiin lewat webhook manual
+      if (gateway === "fake") {
+        done();
+        return;
+      }
+
+      window.snap.pay(snap_token, {
+        onSuccess: (result: any) => {
+          console.log("Pembayaran Sukses!", result);
+          done();
+        },
+        onPending: (result: any) => {
+          console.log("Pembayaran Pending", result);
+          done();
+        },
+        onError: (error: any) => {
+          console.error("Pembayaran Error", error);
+          alert("Pembayaran Gagal");
+          setCheckoutLoading(false);
+        },
+        onClose: () => {
+          console.log("Popup Midtrans ditutup");
+          setCheckoutLoading(false);
+        },
+      });
+    } catch (err: any) {
+      console.error(err);
+      alert(err.response?.data?.error || "Gagal membuat order (API Error)");
+      setCheckoutLoading(false);
+    }
+  };
+
+  if (!preview) {
+    return previewError ? <p className="text-danger text-sm">{previewError}</p> : <Spinner size="sm" />;
+  }
+
+  return (
+    <div className="space-y-3">
+      {preview.items.map((item) => (
+        <div key={`${item.cart_item_id ?? 0}-${item.product_id}-${item.variant_id ?? 0}`} className="flex justify-between text-sm">
+          <p className="text-default-600 truncate pr-2">
+            {item.name} <span className="font-semibold">x{item.quantity}</span>
+          </p>
+          <p className="font-semibold whitespace-nowrap">{rupiah(item.subtotal)}</p>
+        </div>
+      ))}
+
+      <div className="border-t my-2 opacity-50"></div>
+
+      <div className="flex gap-2 items-start">
+        <Input
+          size="sm"
+          label="Kode voucher"
+          value={voucherCode}
+          onChange={(e) => setVoucherCode(e.target.value.toUpperCase())}
+          errorMessage={voucherProblem?.message}
+          isInvalid={!!voucherProblem}
+        />
+        {appliedVoucher ? (
+          <Button size="lg" variant="flat" color="danger" onPress={() => { setAppliedVoucher(""); setVoucherCode(""); }}>
+            Hapus
+          </Button>
+        ) : (
+          <Button size="lg" variant="flat" color="primary" isDisabled={!voucherCode.trim()} onPress={() => setAppliedVoucher(voucherCode.trim())}>
+            Pakai
+          </Button>
+        )}
+      </div>
+
+      {preview.discount_amount > 0 && (
+        <div className="flex justify-between text-success">
+          <p>Voucher {preview.voucher_code}</p>
+          <p className="font-semibold">- {rupiah(preview.discount_amount)}</p>
+        </div>
+      )}
+
+      <CheckoutFulfilment
+        preview={preview}
+        addressId={addressId}
+        onMethodChange={(sellerId, methodId) => setMethodBySeller((prev) => ({ ...prev, [sellerId]: methodId }))}
+        onAddressChange={setAddressId}
+      />
+
+      <div className="flex justify-between">
+        <p className="text-default-600">Ongkir</p>
+        <p className="font-semibold">{rupiah(preview.shipping_total)}</p>
+      </div>
+
+      <div className="border-t my-2"></div>
+
+      <div className="flex justify-between text-xl font-bold text-primary">
+        <p>Total</p>
+        <p>{rupiah(preview.total)}</p>
+      </div>
+
+      {preview.problems
+        .filter((p) => p.type !== "voucher")
+        .map((p, i) => (
+          <p key={i} className="text-danger text-sm">
+            {p.message}
+          </p>
+        ))}
+
+      <Button
+        color="primary"
+        className="w-full mt-6"
+        size="lg"
+        onPress={handleCheckout}
+        isLoading={checkoutLoading}
+        disabled={checkoutLoading || !preview.can_checkout}
+      >
+        {checkoutLoading ? "Memproses..." : "Lanjut ke Checkout"}
+      </Button>
+    </div>
+  );
+};
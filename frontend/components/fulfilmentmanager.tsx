@@ -0,0 +1,193 @@
+"use client";
+
+import React, { useEffect, useState } from "react";
+import { Button } from "@heroui/button";
+import { Input } from "@heroui/input";
+import { Select, SelectItem } from "@heroui/select";
+import { Switch } from "@heroui/switch";
+import { Spinner } from "@heroui/spinner";
+import api from "@/libs/api";
+import { FeeType, FulfilmentMethod, FulfilmentType, WeightRate } from "@/types";
+
+const emptyForm = {
+  type: "meetup" as FulfilmentType,
+  name: "",
+  description: "",
+  location: "",
+  fee_type: "flat" as FeeType,
+  flat_fee: "",
+  weight_rates: "",
+};
+
+const typeLabel = { meetup: "Ketemuan di kampus", pickup: "Ambil sendiri", courier: "Kurir" };
+
+// "1000:10000, 3000:18000" -> [{max_grams: 1000, fee: 10000}, ...]
+const parseRates = (text: string): WeightRate[] =>
+  text
+    .split(",")
+    .map((part) => part.trim())
+    .filter(Boolean)
+    .map((part) => {
+      const [grams, fee] = part.split(":");
+      return { max_grams: Number(grams), fee: Number(fee) };
+    });
+
+const describe = (m: FulfilmentMethod) => {
+  if (m.fee_type === "weight") {
+    return (m.weight_rates ?? [])
+      .map((r) => `≤${r.max_grams} g: Rp ${r.fee.toLocaleString("id-ID")}`)
+      .join(" · ");
+  }
+  return m.flat_fee > 0 ? `Rp ${m.flat_fee.toLocaleString("id-ID")}` : "Gratis";
+};
+
+// Kelola metode pengiriman toko (/me/fulfilment-methods). Belum ada metode = buyer
+// otomatis ketemuan gratis di titik ambil toko.
+export const FulfilmentManager = () => {
+  const [methods, setMethods] = useState<FulfilmentMethod[]>([]);
+  const [form, setForm] = useState(emptyForm);
+  const [loading, setLoading] = useState(true);
+  const [saving, setSaving] = useState(false);
+  const [error, setError] = useState("");
+
+  useEffect(() => {
+    api
+      .get<FulfilmentMethod[]>("/me/fulfilment-methods")
+      .then((res) => setMethods(res.data))
+      .catch(() => setError("Gagal mengambil metode pengiriman"))
+      .finally(() => setLoading(false));
+  }, []);
+
+  const set = (field: keyof typeof emptyForm) => (e: React.ChangeEvent<HTMLInputElement>) =>
+    setForm((f) => ({ ...f, [field]: e.target.value }));
+
+  const handleCreate = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setSaving(true);
+    setError("");
+    try {
+      const res = await api.post<FulfilmentMethod>("/me/fulfilment-methods", {
+        type: form.type,
+        name: form.name,
+        description: form.description,
+        location: form.location,
+        fee_type: form.fee_type,
+        flat_fee: Number(form.flat_fee || 0),
+        weight_rates: form.fee_type === "weight" ? parseRates(form.weight_rates) : undefined,
+      });
+      setMethods((prev) => [...prev, res.data]);
+      setForm(emptyForm);
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal menyimpan metode pengiriman");
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  const toggleActive = async (m: FulfilmentMethod, isActive: boolean) => {
+    try {
+      const res = await api.put<FulfilmentMethod>(`/me/fulfilment-methods/${m.id}`, { is_active: isActive });
+      setMethods((prev) => prev.map((x) => (x.id === m.id ? res.data : x)));
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal mengubah metode pengiriman");
+    }
+  };
+
+  const remove = async (m: FulfilmentMethod) => {
+    try {
+      await api.delete(`/me/fulfilment-methods/${m.id}`);
+      setMethods((prev) => prev.filter((x) => x.id !== m.id));
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal menghapus metode pengiriman");
+    }
+  };
+
+  return (
+    <div className="mb-8 p-4 border rounded-lg bg-content1">
+      <h2 className="text-xl font-bold mb-4">Metode Pengiriman</h2>
+
+      <form onSubmit={handleCreate} className="grid grid-cols-1 md:grid-cols-3 gap-3 mb-4">
+        <Select
+          size="sm"
+          label="Tipe"
+          selectedKeys={[form.type]}
+          onChange={(e) =>
+            setForm((f) => {
+              const type = (e.target.value || "meetup") as FulfilmentType;
+              return { ...f, type, fee_type: type === "courier" ? f.fee_type : "flat" };
+            })
+          }
+        >
+          <SelectItem key="meetup">{typeLabel.meetup}</SelectItem>
+          <SelectItem key="pickup">{typeLabel.pickup}</SelectItem>
+          <SelectItem key="courier">{typeLabel.courier}</SelectItem>
+        </Select>
+        <Input size="sm" label="Nama (mis. COD depan TULT)" value={form.name} onChange={set("name")} isRequired />
+        {form.type === "courier" ? (
+          <Select
+            size="sm"
+            label="Ongkir"
+            selectedKeys={[form.fee_type]}
+            onChange={(e) => setForm((f) => ({ ...f, fee_type: (e.target.value || "flat") as FeeType }))}
+          >
+            <SelectItem key="flat">Tarif tetap</SelectItem>
+            <SelectItem key="weight">Berdasarkan berat</SelectItem>
+          </Select>
+        ) : (
+          <Input size="sm" label="Lokasi ketemu / ambil" value={form.location} onChange={set("location")} isRequired />
+        )}
+        {form.fee_type === "weight" ? (
+          <Input
+            size="sm"
+            className="md:col-span-2"
+            label="Tabel ongkir (gram:ongkir, dipisah koma)"
+            placeholder="1000:10000, 3000:18000"
+            value={form.weight_rates}
+            onChange={set("weight_rates")}
+            isRequired
+          />
+        ) : (
+          <Input size="sm" type="number" label="Ongkir (Rp, 0 = gratis)" value={form.flat_fee} onChange={set("flat_fee")} />
+        )}
+        <Input size="sm" label="Keterangan" value={form.description} onChange={set("description")} />
+        <Button type="submit" color="primary" isLoading={saving}>
+          Tambah Metode
+        </Button>
+      </form>
+
+      {error && <p className="text-danger text-sm mb-2">{error}</p>}
+
+      {loading ? (
+        <Spinner size="sm" />
+      ) : methods.length === 0 ? (
+        <p className="text-default-500 text-sm">
+          Belum ada metode, buyer otomatis ketemuan gratis di titik ambil toko lo.
+        </p>
+      ) : (
+        <div className="space-y-2">
+          {methods.map((m) => (
+            <div key={m.id} className="flex items-center justify-between gap-4 p-3 border rounded-md">
+              <div>
+                <p className="font-semibold">
+                  {m.name} <span className="text-default-500 font-normal">({typeLabel[m.type]})</span>
+                </p>
+                <p className="text-sm text-default-500">
+                  {m.location ? `${m.location} · ` : ""}
+                  {describe(m)}
+                </p>
+              </div>
+              <div className="flex items-center gap-2">
+                <Switch size="sm" isSelected={m.is_active} onValueChange={(isActive) => toggleActive(m, isActive)}>
+                  Aktif
+                </Switch>
+                <Button size="sm" variant="flat" color="danger" onPress={() => remove(m)}>
+                  Hapus
+                </Button>
+              </div>
+            </div>
+          ))}
+        </div>
+      )}
+    </div>
+  );
+};
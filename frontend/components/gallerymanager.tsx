@@ -0,0 +1,151 @@
+"use client";
+
+import React, { useState } from "react";
+import { Input } from "@heroui/input";
+import { Button } from "@heroui/button";
+import { Image } from "@heroui/image";
+import api from "@/libs/api";
+import { ProductImage } from "@/types";
+
+interface GalleryManagerProps {
+  productId: number | string;
+  images: ProductImage[];
+  onChange: (images: ProductImage[]) => void;
+}
+
+// Kelola galeri foto produk: upload & tambah, pilih foto utama, geser urutan, hapus.
+// Sama kayak varian, tiap perubahan langsung dikirim ke backend.
+export const GalleryManager = ({ productId, images, onChange }: GalleryManagerProps) => {
+  const [file, setFile] = useState<File | null>(null);
+  const [altText, setAltText] = useState("");
+  const [busyId, setBusyId] = useState<number | "new" | "order" | null>(null);
+  const [error, setError] = useState("");
+
+  const handleAdd = async () => {
+    if (!file) return;
+    setBusyId("new");
+    setError("");
+    try {
+      const formData = new FormData();
+      formData.append("image", file);
+      const upload = await api.post("/upload/image", formData, {
+        headers: { "Content-Type": "multipart/form-data" },
+      });
+      await api.post<ProductImage>(`/products/${productId}/images`, {
+        url: upload.data.imageUrl,
+        alt_text: altText,
+      });
+      // Ambil ulang biar flag primary & urutannya sama persis kayak di server
+      const res = await api.get<ProductImage[]>(`/products/${productId}/images`);
+      onChange(res.data);
+      setFile(null);
+      setAltText("");
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal menambah gambar");
+    } finally {
+      setBusyId(null);
+    }
+  };
+
+  const handlePrimary = async (image: ProductImage) => {
+    setBusyId(image.id);
+    setError("");
+    try {
+      await api.patch(`/products/${productId}/images/${image.id}`, { is_primary: true });
+      onChange(images.map((img) => ({ ...img, is_primary: img.id === image.id })));
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal mengganti gambar utama");
+    } finally {
+      setBusyId(null);
+    }
+  };
+
+  const handleMove = async (index: number, delta: number) => {
+    const target = index + delta;
+    if (target < 0 || target >= images.length) return;
+    const reordered = [...images];
+    [reordered[index], reordered[target]] = [reordered[target], reordered[index]];
+
+    setBusyId("order");
+    setError("");
+    try {
+      const res = await api.put<ProductImage[]>(`/products/${productId}/images/order`, {
+        image_ids: reordered.map((img) => img.id),
+      });
+      onChange(res.data);
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal mengurutkan gambar");
+    } finally {
+      setBusyId(null);
+    }
+  };
+
+  const handleDelete = async (image: ProductImage) => {
+    if (!confirm("Hapus gambar ini dari produk?")) return;
+    setBusyId(image.id);
+    setError("");
+    try {
+      await api.delete(`/products/${productId}/images/${image.id}`);
+      const res = await api.get<ProductImage[]>(`/products/${productId}/images`);
+      onChange(res.data);
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal menghapus gambar");
+    } finally {
+      setBusyId(null);
+    }
+  };
+
+  return (
+    <div className="border p-4 rounded-lg bg-default-50 space-y-3">
+      <div>
+        <h2 className="text-lg font-semibold">Galeri Gambar</h2>
+        <p className="text-xs text-default-500">
+          Gambar utama yang tampil di kartu produk, keranjang &amp; order.
+        </p>
+      </div>
+
+      {images.length === 0 && <p className="text-default-400 text-sm">Belom ada gambar</p>}
+
+      <div className="grid grid-cols-2 sm:grid-cols-3 gap-3">
+        {images.map((img, i) => (
+          <div key={img.id} className="border rounded-lg p-2 space-y-2 bg-background">
+            <Image src={img.thumbnail_url || img.url} alt={img.alt_text} className="aspect-square object-cover rounded" />
+            {img.is_primary && <p className="text-xs font-bold text-primary">Gambar Utama</p>}
+            <div className="flex flex-wrap gap-1">
+              <Button size="sm" variant="light" isIconOnly isDisabled={i === 0 || busyId !== null} onPress={() => handleMove(i, -1)}>
+                &larr;
+              </Button>
+              <Button size="sm" variant="light" isIconOnly isDisabled={i === images.length - 1 || busyId !== null} onPress={() => handleMove(i, 1)}>
+                &rarr;
+              </Button>
+              {!img.is_primary && (
+                <Button size="sm" variant="flat" color="primary" isLoading={busyId === img.id} onPress={() => handlePrimary(img)}>
+                  Jadiin Utama
+                </Button>
+              )}
+              <Button size="sm" variant="light" color="danger" isDisabled={busyId === img.id} onPress={() => handleDelete(img)}>
+                Hapus
+              </Button>
+            </div>
+          </div>
+        ))}
+      </div>
+
+      <div className="flex flex-wrap items-end gap-2 pt-2 border-t">
+        <Input
+          size="sm"
+          type="file"
+          accept="image/*"
+          className="flex-1 min-w-[160px]"
+          onChange={(e) => setFile(e.target.files?.[0] ?? null)}
+        />
+        <Input size="sm" label="Alt text (opsional)" className="flex-1 min-w-[140px]" value={altText} onChange={(e) => setAltText(e.target.value)} />
+        <Button size="sm" color="primary" isLoading={busyId === "new"} isDisabled={!file} onPress={handleAdd}>
+          Tambah
+        </Button>
+      </div>
+
+      {error && <p className="text-danger text-sm">{error}</p>}
+    </div>
+  );
+};
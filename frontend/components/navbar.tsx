@@ -124,6 +124,7 @@ export const Navbar = () => {
                           <div className="flex-grow">
                             <p className="font-semibold truncate">
                               {item.Product.name}
+                              {item.Variant && ` (${item.Variant.name})`}
                             </p>
                             <p className="text-xs text-default-500">
                               Qty: {item.quantity}
@@ -185,6 +186,9 @@ export const Navbar = () => {
                 <DropdownItem as={NextLink} href="/orders" key="orders">
                   Pesanan Saya
                 </DropdownItem>
+                <DropdownItem as={NextLink} href="/wishlist" key="wishlist">
+                  Wishlist
+                </DropdownItem>
                 {user?.role === "seller" ? (
                   <DropdownItem
                     as={NextLink}
@@ -196,6 +200,12 @@ export const Navbar = () => {
                   </DropdownItem>
                 ) : null}
 
+                {user?.role === "user" ? (
+                  <DropdownItem as={NextLink} href="/seller/apply" key="seller-apply">
+                    Mulai Jualan
+                  </DropdownItem>
+                ) : null}
+
                 {user?.role === "admin" ? (
                   <DropdownItem
                     as={NextLink}
@@ -214,7 +224,17 @@ export const Navbar = () => {
             </Dropdown>
           </div>
         ) : (
-          <div className="flex gap-2">
+          <div className="flex items-center gap-2">
+            {/* Keranjang tamu, dipindah ke akun waktu login */}
+            <Button as={NextLink} href="/cart" variant="light" isIconOnly aria-label="Keranjang">
+              <Badge
+                content={totalCartItems}
+                color="danger"
+                isInvisible={totalCartItems === 0}
+              >
+                <FiShoppingCart size={20} />
+              </Badge>
+            </Button>
             <Button
               as={NextLink}
               href="/login"
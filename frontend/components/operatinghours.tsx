@@ -0,0 +1,55 @@
+"use client";
+
+import React from "react";
+import { Switch } from "@heroui/switch";
+import { Input } from "@heroui/input";
+import { OperatingHour } from "@/types";
+
+export const DAY_NAMES = ["Minggu", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu"];
+
+// Editor jam buka toko: nyalain hari yang buka, isi jam buka & tutupnya.
+export const OperatingHoursEditor = ({
+  value,
+  onChange,
+}: {
+  value: OperatingHour[];
+  onChange: (hours: OperatingHour[]) => void;
+}) => {
+  const hourFor = (day: number) => value.find((oh) => oh.day === day);
+
+  const toggleDay = (day: number, open: boolean) => {
+    if (open) {
+      onChange([...value, { day, open: "08:00", close: "17:00" }]);
+    } else {
+      onChange(value.filter((oh) => oh.day !== day));
+    }
+  };
+
+  const setTime = (day: number, field: "open" | "close", time: string) => {
+    onChange(value.map((oh) => (oh.day === day ? { ...oh, [field]: time } : oh)));
+  };
+
+  return (
+    <div className="space-y-2">
+      {DAY_NAMES.map((name, day) => {
+        const hour = hourFor(day);
+        return (
+          <div key={day} className="flex items-center gap-3">
+            <Switch size="sm" isSelected={!!hour} onValueChange={(open) => toggleDay(day, open)} className="w-32">
+              {name}
+            </Switch>
+            {hour ? (
+              <>
+                <Input size="sm" type="time" aria-label={`Buka ${name}`} value={hour.open} onChange={(e) => setTime(day, "open", e.target.value)} className="max-w-[8rem]" />
+                <span>-</span>
+                <Input size="sm" type="time" aria-label={`Tutup ${name}`} value={hour.close} onChange={(e) => setTime(day, "close", e.target.value)} className="max-w-[8rem]" />
+              </>
+            ) : (
+              <span className="text-sm text-default-400">Tutup</span>
+            )}
+          </div>
+        );
+      })}
+    </div>
+  );
+};
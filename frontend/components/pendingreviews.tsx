@@ -0,0 +1,129 @@
+"use client";
+
+import React, { useEffect, useState } from "react";
+import NextImage from "next/image";
+import NextLink from "next/link";
+import { Button } from "@heroui/button";
+import { Input, Textarea } from "@heroui/input";
+import api from "@/libs/api";
+import { ReviewableItem } from "@/types";
+
+const MAX_PHOTOS = 5;
+
+// Form ulasan buat satu barang dari pesanan yang udah selesai.
+const ReviewForm = ({ item, onSubmitted }: { item: ReviewableItem; onSubmitted: () => void }) => {
+  const [rating, setRating] = useState(5);
+  const [comment, setComment] = useState("");
+  const [files, setFiles] = useState<File[]>([]);
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState("");
+
+  const handleFiles = (e: React.ChangeEvent<HTMLInputElement>) => {
+    if (e.target.files) {
+      setFiles(Array.from(e.target.files).slice(0, MAX_PHOTOS));
+    }
+  };
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setLoading(true);
+    setError("");
+    try {
+      const photos: string[] = [];
+      for (const file of files) {
+        const formData = new FormData();
+        formData.append("image", file);
+        const uploadResponse = await api.post("/upload/image", formData, {
+          headers: { "Content-Type": "multipart/form-data" },
+        });
+        photos.push(uploadResponse.data.imageUrl);
+      }
+      await api.post(`/products/${item.product_id}/reviews`, {
+        order_item_id: item.order_item_id,
+        rating,
+        comment,
+        photos,
+      });
+      onSubmitted();
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal ngirim ulasan");
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <form onSubmit={handleSubmit} className="space-y-3 mt-3">
+      <div className="flex gap-1 text-2xl">
+        {[1, 2, 3, 4, 5].map((star) => (
+          <button key={star} type="button" onClick={() => setRating(star)} className={star <= rating ? "text-warning" : "text-default-300"}>
+            ★
+          </button>
+        ))}
+      </div>
+      <Textarea label="Ceritain pengalaman kamu" value={comment} onChange={(e) => setComment(e.target.value)} />
+      <Input type="file" accept="image/*" multiple onChange={handleFiles} description={`Opsional, maks ${MAX_PHOTOS} foto`} />
+      {error && <p className="text-danger text-sm">{error}</p>}
+      <Button type="submit" color="primary" size="sm" isLoading={loading}>
+        Kirim Ulasan
+      </Button>
+    </form>
+  );
+};
+
+// Daftar barang yang udah diterima tapi belum diulas.
+export const PendingReviews = () => {
+  const [items, setItems] = useState<ReviewableItem[]>([]);
+  const [openId, setOpenId] = useState<number | null>(null);
+
+  const fetchItems = () => {
+    api
+      .get<ReviewableItem[]>("/me/reviewable-items")
+      .then((res) => setItems(res.data))
+      .catch((err) => console.error("Gagal ambil barang yang bisa diulas:", err));
+  };
+
+  useEffect(() => {
+    fetchItems();
+  }, []);
+
+  if (items.length === 0) return null;
+
+  return (
+    <div className="mb-8 p-4 border rounded-lg bg-content1 space-y-4">
+      <h2 className="text-xl font-bold">Menunggu Ulasan ({items.length})</h2>
+      {items.map((item) => (
+        <div key={item.order_item_id} className="border-b last:border-b-0 pb-4 last:pb-0">
+          <div className="flex items-center gap-4">
+            <div className="relative w-14 h-14 rounded-md overflow-hidden border shrink-0">
+              {item.thumbnail_url && (
+                <NextImage src={item.thumbnail_url} alt={item.product_name} fill style={{ objectFit: "cover" }} unoptimized={true} />
+              )}
+            </div>
+            <div className="flex-grow">
+              <NextLink href={`/products/${item.product_slug}`} className="font-semibold hover:underline">
+                {item.product_name}
+              </NextLink>
+              <p className="text-sm text-default-500">
+                TELUHUB-{item.order_id}
+                {item.variant_name && ` · ${item.variant_name}`}
+              </p>
+            </div>
+            <Button size="sm" variant="flat" color="primary" onPress={() => setOpenId(openId === item.order_item_id ? null : item.order_item_id)}>
+              {openId === item.order_item_id ? "Tutup" : "Beri Ulasan"}
+            </Button>
+          </div>
+          {openId === item.order_item_id && (
+            <ReviewForm
+              item={item}
+              onSubmitted={() => {
+                setOpenId(null);
+                fetchItems();
+              }}
+            />
+          )}
+        </div>
+      ))}
+    </div>
+  );
+};
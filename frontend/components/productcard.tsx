@@ -19,7 +19,7 @@ export const ProductCard = ({ product }: { product: Product }) => {
     addToCart(product.id, 1);
   };
 
-  const currentStock = liveStock !== null ? liveStock : product.stock;
+  const currentStock = liveStock !== null ? liveStock : (product.available_stock ?? product.stock);
 
   return (
     <div className="group rounded-lg border bg-card text-card-foreground shadow-sm flex flex-col justify-between hover:shadow-md transition-shadow duration-300">
@@ -33,7 +33,7 @@ export const ProductCard = ({ product }: { product: Product }) => {
             </div>
 
             <NextImage
-              src={product.image_url}
+              src={product.thumbnail_url || product.image_url}
               alt={product.name}
               fill
               className="object-cover transition-transform duration-300 group-hover:scale-105"
@@ -0,0 +1,166 @@
+"use client";
+
+import React, { useEffect, useState } from "react";
+import NextImage from "next/image";
+import { Avatar } from "@heroui/avatar";
+import { Button } from "@heroui/button";
+import { Input } from "@heroui/input";
+import { Spinner } from "@heroui/spinner";
+import api from "@/libs/api";
+import { Review, ReviewListResponse } from "@/types";
+
+export const Stars = ({ rating }: { rating: number }) => (
+  <span className="text-warning">
+    {"★".repeat(Math.round(rating))}
+    <span className="text-default-300">{"★".repeat(5 - Math.round(rating))}</span>
+  </span>
+);
+
+// Daftar ulasan produk + ringkasan rating. Seller pemilik produk bisa bales sekali per ulasan.
+export const ProductReviews = ({ productId, canReply }: { productId: number; canReply: boolean }) => {
+  const [reviews, setReviews] = useState<Review[]>([]);
+  const [summary, setSummary] = useState<ReviewListResponse["summary"]>();
+  const [nextCursor, setNextCursor] = useState<string | undefined>();
+  const [ratingFilter, setRatingFilter] = useState<number | null>(null);
+  const [loading, setLoading] = useState(true);
+  const [loadingMore, setLoadingMore] = useState(false);
+  const [replies, setReplies] = useState<Record<number, string>>({});
+  const [replyingId, setReplyingId] = useState<number | null>(null);
+  const [error, setError] = useState("");
+
+  useEffect(() => {
+    setLoading(true);
+    api
+      .get<ReviewListResponse>(`/products/${productId}/reviews`, { params: { rating: ratingFilter || undefined } })
+      .then((res) => {
+        setReviews(res.data.data);
+        if (res.data.summary && ratingFilter === null) setSummary(res.data.summary);
+        setNextCursor(res.data.next_cursor);
+      })
+      .catch((err) => console.error("Gagal ambil ulasan:", err))
+      .finally(() => setLoading(false));
+  }, [productId, ratingFilter]);
+
+  const loadMore = async () => {
+    if (!nextCursor) return;
+    try {
+      setLoadingMore(true);
+      const res = await api.get<ReviewListResponse>(`/products/${productId}/reviews`, {
+        params: { rating: ratingFilter || undefined, cursor: nextCursor },
+      });
+      setReviews((prev) => [...prev, ...res.data.data]);
+      setNextCursor(res.data.next_cursor);
+    } catch (err) {
+      console.error("Gagal ambil ulasan berikutnya:", err);
+    } finally {
+      setLoadingMore(false);
+    }
+  };
+
+  const sendReply = async (review: Review) => {
+    setReplyingId(review.id);
+    setError("");
+    try {
+      const res = await api.post<Review>(`/products/${productId}/reviews/${review.id}/reply`, { reply: replies[review.id] || "" });
+      setReviews((prev) => prev.map((r) => (r.id === review.id ? res.data : r)));
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal ngirim balasan");
+    } finally {
+      setReplyingId(null);
+    }
+  };
+
+  return (
+    <div className="mt-12 space-y-6">
+      <h2 className="text-2xl font-bold">Ulasan Pembeli</h2>
+
+      {summary && summary.count > 0 && (
+        <div className="flex flex-col md:flex-row gap-6 p-4 border rounded-lg bg-content1">
+          <div className="text-center md:w-40">
+            <p className="text-4xl font-bold">{summary.average.toFixed(1)}</p>
+            <Stars rating={summary.average} />
+            <p className="text-sm text-default-500">{summary.count} ulasan</p>
+          </div>
+          <div className="flex-1 space-y-1">
+            {[5, 4, 3, 2, 1].map((star) => (
+              <button
+                key={star}
+                type="button"
+                onClick={() => setRatingFilter(ratingFilter === star ? null : star)}
+                className={`flex items-center gap-2 w-full text-sm ${ratingFilter === star ? "font-bold text-primary" : ""}`}
+              >
+                <span className="w-8">{star} ★</span>
+                <div className="flex-1 h-2 rounded bg-default-200 overflow-hidden">
+                  <div className="h-full bg-warning" style={{ width: `${((summary.distribution[star] || 0) / summary.count) * 100}%` }} />
+                </div>
+                <span className="w-8 text-right">{summary.distribution[star] || 0}</span>
+              </button>
+            ))}
+          </div>
+        </div>
+      )}
+
+      {error && <p className="text-danger text-sm">{error}</p>}
+
+      {loading ? (
+        <div className="text-center p-4"><Spinner size="sm" /></div>
+      ) : reviews.length === 0 ? (
+        <p className="text-default-500">Belum ada ulasan{ratingFilter ? ` bintang ${ratingFilter}` : ""}.</p>
+      ) : (
+        <div className="space-y-4">
+          {reviews.map((review) => (
+            <div key={review.id} className="p-4 border rounded-lg space-y-2">
+              <div className="flex items-center gap-3">
+                <Avatar size="sm" src={review.reviewer.profile_image_url} name={review.reviewer.username.charAt(0).toUpperCase()} />
+                <div>
+                  <p className="font-semibold text-sm">{review.reviewer.username}</p>
+                  <p className="text-xs text-default-500">
+                    <Stars rating={review.rating} /> · {new Date(review.created_at).toLocaleDateString("id-ID")}
+                    {review.variant_name && ` · Varian: ${review.variant_name}`}
+                  </p>
+                </div>
+              </div>
+              {review.comment && <p className="whitespace-pre-wrap">{review.comment}</p>}
+              {review.photos.length > 0 && (
+                <div className="flex gap-2 flex-wrap">
+                  {review.photos.map((photo) => (
+                    <a key={photo.id} href={photo.url} target="_blank" rel="noreferrer" className="relative w-20 h-20 rounded-md overflow-hidden border">
+                      <NextImage src={photo.thumbnail_url || photo.url} alt="Foto ulasan" fill style={{ objectFit: "cover" }} unoptimized={true} />
+                    </a>
+                  ))}
+                </div>
+              )}
+              {review.seller_reply ? (
+                <div className="ml-6 p-3 rounded-md bg-default-100 text-sm">
+                  <p className="font-semibold">Balasan Penjual</p>
+                  <p className="whitespace-pre-wrap">{review.seller_reply}</p>
+                </div>
+              ) : (
+                canReply && (
+                  <div className="ml-6 flex gap-2 items-end">
+                    <Input
+                      size="sm"
+                      label="Balas ulasan"
+                      value={replies[review.id] || ""}
+                      onChange={(e) => setReplies((r) => ({ ...r, [review.id]: e.target.value }))}
+                    />
+                    <Button size="sm" color="primary" isLoading={replyingId === review.id} onPress={() => sendReply(review)}>
+                      Kirim
+                    </Button>
+                  </div>
+                )
+              )}
+            </div>
+          ))}
+          {nextCursor && (
+            <div className="flex justify-center">
+              <Button variant="flat" isLoading={loadingMore} onPress={loadMore}>
+                Muat Ulasan Lainnya
+              </Button>
+            </div>
+          )}
+        </div>
+      )}
+    </div>
+  );
+};
@@ -0,0 +1,112 @@
+"use client";
+
+import React, { useEffect, useState } from "react";
+import { Button } from "@heroui/button";
+import { Input } from "@heroui/input";
+import { Select, SelectItem } from "@heroui/select";
+import { Spinner } from "@heroui/spinner";
+import api from "@/libs/api";
+import { Review, ReviewListResponse } from "@/types";
+import { Stars } from "@/components/productreviews";
+
+// Moderasi ulasan buat admin: sembunyiin ulasan kasar (wajib alasan) atau tampilin lagi.
+export const ReviewModeration = () => {
+  const [reviews, setReviews] = useState<Review[]>([]);
+  const [filter, setFilter] = useState("false");
+  const [nextCursor, setNextCursor] = useState<string | undefined>();
+  const [loading, setLoading] = useState(true);
+  const [busyId, setBusyId] = useState<number | null>(null);
+  const [reasons, setReasons] = useState<Record<number, string>>({});
+  const [error, setError] = useState("");
+
+  const fetchReviews = async (cursor?: string) => {
+    try {
+      const res = await api.get<ReviewListResponse>("/admin/reviews", {
+        params: { hidden: filter === "all" ? undefined : filter, cursor },
+      });
+      setReviews((prev) => (cursor ? [...prev, ...res.data.data] : res.data.data));
+      setNextCursor(res.data.next_cursor);
+    } catch (err) {
+      console.error(err);
+      setError("Gagal mengambil ulasan");
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  useEffect(() => {
+    setLoading(true);
+    fetchReviews();
+  }, [filter]);
+
+  const setVisibility = async (review: Review, hidden: boolean) => {
+    setBusyId(review.id);
+    setError("");
+    try {
+      const res = await api.patch<Review>(`/admin/reviews/${review.id}/visibility`, { hidden, reason: reasons[review.id] || "" });
+      setReviews((prev) => prev.map((r) => (r.id === review.id ? res.data : r)));
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal mengubah status ulasan");
+    } finally {
+      setBusyId(null);
+    }
+  };
+
+  return (
+    <div className="space-y-4 mb-8">
+      <div className="flex justify-between items-center">
+        <h2 className="text-2xl font-bold">Moderasi Ulasan</h2>
+        <Select aria-label="Filter ulasan" className="max-w-xs" selectedKeys={[filter]} onChange={(e) => e.target.value && setFilter(e.target.value)}>
+          <SelectItem key="false">Tampil</SelectItem>
+          <SelectItem key="true">Disembunyiin</SelectItem>
+          <SelectItem key="all">Semua</SelectItem>
+        </Select>
+      </div>
+      {error && <p className="text-danger text-sm">{error}</p>}
+
+      {loading ? (
+        <div className="text-center p-4"><Spinner size="sm" /></div>
+      ) : reviews.length === 0 ? (
+        <p className="text-default-500">Gak ada ulasan.</p>
+      ) : (
+        reviews.map((review) => (
+          <div key={review.id} className="p-4 border rounded-lg bg-content1 space-y-2">
+            <p className="text-sm text-default-500">
+              <Stars rating={review.rating} /> · {review.reviewer.username} · Produk #{review.product_id} ·{" "}
+              {new Date(review.created_at).toLocaleString("id-ID")}
+            </p>
+            {review.comment && <p className="whitespace-pre-wrap">{review.comment}</p>}
+            {review.is_hidden ? (
+              <div className="flex justify-between items-center gap-2">
+                <p className="text-sm text-danger">Disembunyiin: {review.hidden_reason}</p>
+                <Button size="sm" variant="flat" isLoading={busyId === review.id} onPress={() => setVisibility(review, false)}>
+                  Tampilin Lagi
+                </Button>
+              </div>
+            ) : (
+              <div className="flex gap-2 items-end">
+                <Input
+                  size="sm"
+                  label="Alasan disembunyiin"
+                  value={reasons[review.id] || ""}
+                  onChange={(e) => setReasons((r) => ({ ...r, [review.id]: e.target.value }))}
+                  className="flex-1"
+                />
+                <Button size="sm" color="danger" variant="flat" isLoading={busyId === review.id} onPress={() => setVisibility(review, true)}>
+                  Sembunyiin
+                </Button>
+              </div>
+            )}
+          </div>
+        ))
+      )}
+      {nextCursor && (
+        <div className="flex justify-center">
+          <Button variant="flat" onPress={() => fetchReviews(nextCursor)}>
+            Muat Lainnya
+          </Button>
+        </div>
+      )}
+    </div>
+  );
+};
@@ -0,0 +1,102 @@
+"use client";
+
+import React, { useEffect, useState } from "react";
+import { Button } from "@heroui/button";
+import { Input } from "@heroui/input";
+import { Spinner } from "@heroui/spinner";
+import api from "@/libs/api";
+import { OrderFulfilment } from "@/types";
+
+interface Sale {
+  id:         number;
+  order_id:   number;
+  status:     string;
+  buyer:      { username: string };
+  fulfilment: OrderFulfilment;
+}
+
+const activeStatuses = ["paid", "processing", "shipped"];
+
+// Penjualan yang belum sampai ke buyer: seller isi nomor resi (kurir) atau
+// masukin kode serah terima dari buyer (ketemuan / ambil sendiri).
+export const SalesFulfilment = () => {
+  const [sales, setSales] = useState<Sale[]>([]);
+  const [inputs, setInputs] = useState<Record<number, string>>({});
+  const [busyId, setBusyId] = useState<number | null>(null);
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState("");
+
+  useEffect(() => {
+    api
+      .get<Sale[]>("/me/sales")
+      .then((res) => setSales(res.data.filter((s) => activeStatuses.includes(s.status))))
+      .catch(() => setError("Gagal mengambil data penjualan"))
+      .finally(() => setLoading(false));
+  }, []);
+
+  const submit = async (sale: Sale) => {
+    const value = (inputs[sale.id] ?? "").trim();
+    if (!value) return;
+    setBusyId(sale.id);
+    setError("");
+    try {
+      if (sale.fulfilment.type === "courier") {
+        const res = await api.put<Sale>(`/me/sales/${sale.id}/fulfilment`, { tracking_number: value });
+        setSales((prev) => prev.map((s) => (s.id === sale.id ? res.data : s)));
+      } else {
+        await api.post(`/me/sales/${sale.id}/handover`, { code: value });
+        setSales((prev) => prev.filter((s) => s.id !== sale.id));
+      }
+      setInputs((prev) => ({ ...prev, [sale.id]: "" }));
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal menyimpan data pengiriman");
+    } finally {
+      setBusyId(null);
+    }
+  };
+
+  return (
+    <div className="mb-8 p-4 border rounded-lg bg-content1">
+      <h2 className="text-xl font-bold mb-4">Pesanan yang Harus Dikirim</h2>
+
+      {error && <p className="text-danger text-sm mb-2">{error}</p>}
+
+      {loading ? (
+        <Spinner size="sm" />
+      ) : sales.length === 0 ? (
+        <p className="text-default-500 text-sm">Gak ada pesanan yang nunggu dikirim.</p>
+      ) : (
+        <div className="space-y-2">
+          {sales.map((sale) => (
+            <div key={sale.id} className="flex flex-col md:flex-row md:items-center justify-between gap-3 p-3 border rounded-md">
+              <div className="text-sm">
+                <p className="font-semibold">
+                  ORDER-{sale.order_id} · {sale.buyer.username} · {sale.status}
+                </p>
+                <p className="text-default-500">
+                  {sale.fulfilment.name}
+                  {sale.fulfilment.meetup_point && ` · ${sale.fulfilment.meetup_point}`}
+                </p>
+                {sale.fulfilment.shipping_address && (
+                  <p className="text-default-500 whitespace-pre-wrap">{sale.fulfilment.shipping_address}</p>
+                )}
+                {sale.fulfilment.tracking_number && <p>No. resi: {sale.fulfilment.tracking_number}</p>}
+              </div>
+              <div className="flex gap-2 items-center">
+                <Input
+                  size="sm"
+                  label={sale.fulfilment.type === "courier" ? "Nomor resi" : "Kode dari buyer"}
+                  value={inputs[sale.id] ?? ""}
+                  onChange={(e) => setInputs((prev) => ({ ...prev, [sale.id]: e.target.value }))}
+                />
+                <Button size="sm" color="primary" isLoading={busyId === sale.id} onPress={() => submit(sale)}>
+                  {sale.fulfilment.type === "courier" ? "Simpan" : "Konfirmasi"}
+                </Button>
+              </div>
+            </div>
+          ))}
+        </div>
+      )}
+    </div>
+  );
+};
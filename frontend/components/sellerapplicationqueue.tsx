@@ -0,0 +1,100 @@
+"use client";
+
+import React, { useEffect, useState } from "react";
+import NextImage from "next/image";
+import { Button } from "@heroui/button";
+import { Input } from "@heroui/input";
+import { Spinner } from "@heroui/spinner";
+import api from "@/libs/api";
+import { SellerApplication } from "@/types";
+
+// Antrian pengajuan seller buat admin: setujui langsung, tolak wajib pake alasan.
+export const SellerApplicationQueue = ({ onReviewed }: { onReviewed?: () => void }) => {
+  const [applications, setApplications] = useState<SellerApplication[]>([]);
+  const [loading, setLoading] = useState(true);
+  const [busyId, setBusyId] = useState<number | null>(null);
+  const [reasons, setReasons] = useState<Record<number, string>>({});
+  const [error, setError] = useState("");
+
+  const fetchQueue = async () => {
+    try {
+      const res = await api.get<SellerApplication[]>("/admin/seller-applications");
+      setApplications(res.data);
+    } catch (err) {
+      console.error(err);
+      setError("Gagal mengambil antrian pengajuan seller");
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  useEffect(() => {
+    fetchQueue();
+  }, []);
+
+  const review = async (app: SellerApplication, action: "approve" | "reject") => {
+    setBusyId(app.id);
+    setError("");
+    try {
+      await api.post(`/admin/seller-applications/${app.id}/${action}`, action === "reject" ? { reason: reasons[app.id] || "" } : {});
+      await fetchQueue();
+      onReviewed?.();
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal mereview pengajuan");
+    } finally {
+      setBusyId(null);
+    }
+  };
+
+  if (loading) {
+    return <div className="text-center p-4"><Spinner size="sm" /></div>;
+  }
+
+  return (
+    <div className="space-y-4 mb-8">
+      <h2 className="text-2xl font-bold">Pengajuan Seller ({applications.length})</h2>
+      {error && <p className="text-danger text-sm">{error}</p>}
+      {applications.length === 0 && <p className="text-default-500">Gak ada pengajuan yang nunggu direview.</p>}
+
+      {applications.map((app) => (
+        <div key={app.id} className="p-4 border rounded-lg bg-content1 space-y-3">
+          <div className="flex justify-between gap-4">
+            <div>
+              <h3 className="text-lg font-semibold">{app.store_name}</h3>
+              <p className="text-sm text-default-500">
+                {app.applicant?.username} ({app.applicant?.email}) · NIM {app.student_id} ·{" "}
+                {new Date(app.created_at).toLocaleString("id-ID")}
+              </p>
+            </div>
+          </div>
+          {app.description && <p className="text-sm whitespace-pre-wrap">{app.description}</p>}
+          <div className="flex flex-wrap gap-3">
+            {app.documents.map((doc) => (
+              <a key={doc.id} href={doc.url} target="_blank" rel="noreferrer" className="flex flex-col items-center gap-1 w-24">
+                <div className="relative w-24 h-24 rounded-md overflow-hidden border">
+                  <NextImage src={doc.url} alt={doc.label} fill style={{ objectFit: "cover" }} unoptimized={true} />
+                </div>
+                <span className="text-xs truncate w-full text-center">{doc.label}</span>
+              </a>
+            ))}
+          </div>
+          <div className="flex gap-2 items-end">
+            <Input
+              size="sm"
+              label="Alasan penolakan"
+              value={reasons[app.id] || ""}
+              onChange={(e) => setReasons((r) => ({ ...r, [app.id]: e.target.value }))}
+              className="flex-1"
+            />
+            <Button size="sm" color="danger" variant="flat" isLoading={busyId === app.id} onPress={() => review(app, "reject")}>
+              Tolak
+            </Button>
+            <Button size="sm" color="success" isLoading={busyId === app.id} onPress={() => review(app, "approve")}>
+              Setujui
+            </Button>
+          </div>
+        </div>
+      ))}
+    </div>
+  );
+};
@@ -0,0 +1,82 @@
+"use client";
+
+import React, { useEffect, useState } from "react";
+import { Button } from "@heroui/button";
+import { Chip } from "@heroui/chip";
+import { Spinner } from "@heroui/spinner";
+import api from "@/libs/api";
+import { Session } from "@/types";
+
+// Daftar device yang lagi login, bisa di-logout satu-satu atau semua selain yang ini.
+export const SessionList = () => {
+  const [sessions, setSessions] = useState<Session[]>([]);
+  const [loading, setLoading] = useState(true);
+  const [busy, setBusy] = useState<number | "all" | null>(null);
+  const [error, setError] = useState("");
+
+  const fetchSessions = async () => {
+    try {
+      const res = await api.get<Session[]>("/me/sessions");
+      setSessions(res.data);
+    } catch (err) {
+      console.error(err);
+      setError("Gagal mengambil daftar session");
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  useEffect(() => {
+    fetchSessions();
+  }, []);
+
+  const revoke = async (id: number | "all") => {
+    setBusy(id);
+    setError("");
+    try {
+      await api.delete(id === "all" ? "/me/sessions" : `/me/sessions/${id}`);
+      await fetchSessions();
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal logout session");
+    } finally {
+      setBusy(null);
+    }
+  };
+
+  if (loading) {
+    return <div className="text-center p-4"><Spinner size="sm" /></div>;
+  }
+
+  return (
+    <div className="space-y-3 p-6 border rounded-lg bg-content1">
+      <div className="flex items-center justify-between">
+        <h2 className="text-xl font-semibold">Session Login</h2>
+        {sessions.length > 1 && (
+          <Button size="sm" color="danger" variant="flat" isLoading={busy === "all"} onPress={() => revoke("all")}>
+            Logout Device Lain
+          </Button>
+        )}
+      </div>
+
+      {error && <p className="text-danger text-sm">{error}</p>}
+
+      {sessions.map((s) => (
+        <div key={s.id} className="flex items-center justify-between gap-4 border-b last:border-b-0 pb-2">
+          <div className="min-w-0">
+            <p className="text-sm truncate">{s.user_agent || "Device tidak dikenal"}</p>
+            <p className="text-xs text-default-500">
+              {s.ip_address} · terakhir aktif {new Date(s.last_used_at).toLocaleString("id-ID")}
+            </p>
+          </div>
+          {s.current ? (
+            <Chip size="sm" color="success" variant="flat">Device ini</Chip>
+          ) : (
+            <Button size="sm" color="danger" variant="light" isLoading={busy === s.id} onPress={() => revoke(s.id)}>
+              Logout
+            </Button>
+          )}
+        </div>
+      ))}
+    </div>
+  );
+};
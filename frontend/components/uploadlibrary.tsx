@@ -0,0 +1,100 @@
+"use client";
+
+import React, { useEffect, useState } from "react";
+import NextImage from "next/image";
+import { Button } from "@heroui/button";
+import { Progress } from "@heroui/progress";
+import { Spinner } from "@heroui/spinner";
+import api from "@/libs/api";
+import { UploadList } from "@/types";
+
+const formatMB = (bytes: number) => `${(bytes / (1024 * 1024)).toFixed(1)} MB`;
+
+// Daftar gambar yang pernah diupload user + pemakaian kuota. Yang masih dipake gak bisa dihapus.
+export const UploadLibrary = ({ refreshKey = 0 }: { refreshKey?: number }) => {
+  const [list, setList] = useState<UploadList | null>(null);
+  const [loading, setLoading] = useState(true);
+  const [deletingId, setDeletingId] = useState<number | null>(null);
+  const [error, setError] = useState("");
+
+  const fetchUploads = async () => {
+    try {
+      const res = await api.get<UploadList>("/me/uploads");
+      setList(res.data);
+    } catch (err) {
+      console.error(err);
+      setError("Gagal mengambil daftar upload");
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  useEffect(() => {
+    fetchUploads();
+  }, [refreshKey]);
+
+  const handleDelete = async (id: number) => {
+    setDeletingId(id);
+    setError("");
+    try {
+      await api.delete(`/me/uploads/${id}`);
+      await fetchUploads();
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal menghapus upload");
+    } finally {
+      setDeletingId(null);
+    }
+  };
+
+  if (loading) {
+    return <div className="text-center p-4"><Spinner size="sm" /></div>;
+  }
+  if (!list) {
+    return <p className="text-danger text-center">{error}</p>;
+  }
+
+  return (
+    <div className="space-y-3 p-6 border rounded-lg bg-content1">
+      <h2 className="text-xl font-semibold">Upload Saya</h2>
+      {list.quota_bytes > 0 ? (
+        <Progress
+          size="sm"
+          label="Kuota upload"
+          valueLabel={`${formatMB(list.used_bytes)} / ${formatMB(list.quota_bytes)}`}
+          value={Math.min(100, (list.used_bytes / list.quota_bytes) * 100)}
+          showValueLabel
+          color={list.used_bytes >= list.quota_bytes ? "danger" : "primary"}
+        />
+      ) : (
+        <p className="text-sm text-default-500">Terpakai {formatMB(list.used_bytes)} (tanpa batas)</p>
+      )}
+
+      {error && <p className="text-danger text-sm">{error}</p>}
+
+      {list.data.length === 0 ? (
+        <p className="text-sm text-default-500">Belum ada gambar yang diupload.</p>
+      ) : (
+        <div className="grid grid-cols-3 sm:grid-cols-4 gap-3">
+          {list.data.map((u) => (
+            <div key={u.id} className="flex flex-col gap-1">
+              <div className="relative aspect-square rounded-md overflow-hidden border">
+                <NextImage src={u.thumbnail_url || u.url} alt="" fill style={{ objectFit: "cover" }} unoptimized={true} />
+              </div>
+              <span className="text-xs text-default-500">{formatMB(u.size)}</span>
+              <Button
+                size="sm"
+                color="danger"
+                variant="light"
+                isDisabled={u.in_use}
+                isLoading={deletingId === u.id}
+                onPress={() => handleDelete(u.id)}
+              >
+                {u.in_use ? "Dipakai" : "Hapus"}
+              </Button>
+            </div>
+          ))}
+        </div>
+      )}
+    </div>
+  );
+};
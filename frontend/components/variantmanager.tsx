@@ -0,0 +1,138 @@
+"use client";
+
+import React, { useState } from "react";
+import { Input } from "@heroui/input";
+import { Button } from "@heroui/button";
+import api from "@/libs/api";
+import { ProductVariant } from "@/types";
+
+interface VariantManagerProps {
+  productId: number | string;
+  variants: ProductVariant[];
+  onChange: (variants: ProductVariant[]) => void;
+}
+
+// Kelola varian (ukuran/warna) produk: tambah, ubah harga/stok, hapus.
+// Tiap perubahan langsung dikirim ke backend, gak nunggu tombol "Simpan Perubahan".
+export const VariantManager = ({ productId, variants, onChange }: VariantManagerProps) => {
+  const [name, setName] = useState("");
+  const [sku, setSku] = useState("");
+  const [price, setPrice] = useState("");
+  const [stock, setStock] = useState("");
+  const [busyId, setBusyId] = useState<number | "new" | null>(null);
+  const [error, setError] = useState("");
+
+  const handleAdd = async () => {
+    setBusyId("new");
+    setError("");
+    try {
+      const res = await api.post<ProductVariant>(`/products/${productId}/variants`, {
+        name,
+        sku,
+        price: Number(price),
+        stock: Number(stock),
+      });
+      onChange([...variants, res.data]);
+      setName("");
+      setSku("");
+      setPrice("");
+      setStock("");
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal menambah varian");
+    } finally {
+      setBusyId(null);
+    }
+  };
+
+  const handleUpdate = async (variant: ProductVariant) => {
+    setBusyId(variant.id);
+    setError("");
+    try {
+      const res = await api.put<ProductVariant>(`/products/${productId}/variants/${variant.id}`, {
+        name: variant.name,
+        sku: variant.sku,
+        price: Number(variant.price),
+        stock: Number(variant.stock),
+      });
+      onChange(variants.map((v) => (v.id === variant.id ? res.data : v)));
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal mengupdate varian");
+    } finally {
+      setBusyId(null);
+    }
+  };
+
+  const handleDelete = async (variant: ProductVariant) => {
+    if (!confirm(`Hapus varian ${variant.name}?`)) return;
+    setBusyId(variant.id);
+    setError("");
+    try {
+      await api.delete(`/products/${productId}/variants/${variant.id}`);
+      onChange(variants.filter((v) => v.id !== variant.id));
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal menghapus varian");
+    } finally {
+      setBusyId(null);
+    }
+  };
+
+  const setField = (id: number, field: "price" | "stock", value: string) => {
+    onChange(variants.map((v) => (v.id === id ? { ...v, [field]: Number(value) } : v)));
+  };
+
+  return (
+    <div className="border p-4 rounded-lg bg-default-50 space-y-3">
+      <div>
+        <h2 className="text-lg font-semibold">Varian</h2>
+        <p className="text-xs text-default-500">
+          Kalau ada varian, harga &amp; stok produk otomatis diitung dari variannya.
+        </p>
+      </div>
+
+      {variants.map((v) => (
+        <div key={v.id} className="flex flex-wrap items-end gap-2">
+          <div className="flex-1 min-w-[120px]">
+            <p className="font-semibold">{v.name}</p>
+            <p className="text-xs text-default-400">
+              {v.sku} · di-hold {v.reserved_stock}
+            </p>
+          </div>
+          <Input
+            size="sm"
+            type="number"
+            label="Harga"
+            className="w-32"
+            value={String(v.price)}
+            onChange={(e) => setField(v.id, "price", e.target.value)}
+          />
+          <Input
+            size="sm"
+            type="number"
+            label="Stok"
+            className="w-24"
+            value={String(v.stock)}
+            onChange={(e) => setField(v.id, "stock", e.target.value)}
+          />
+          <Button size="sm" color="primary" variant="flat" isLoading={busyId === v.id} onPress={() => handleUpdate(v)}>
+            Simpan
+          </Button>
+          <Button size="sm" color="danger" variant="light" isDisabled={busyId === v.id} onPress={() => handleDelete(v)}>
+            Hapus
+          </Button>
+        </div>
+      ))}
+
+      <div className="flex flex-wrap items-end gap-2 pt-2 border-t">
+        <Input size="sm" label="Nama (mis. XL)" className="flex-1 min-w-[120px]" value={name} onChange={(e) => setName(e.target.value)} />
+        <Input size="sm" label="SKU (opsional)" className="w-36" value={sku} onChange={(e) => setSku(e.target.value)} />
+        <Input size="sm" type="number" label="Harga" className="w-32" value={price} onChange={(e) => setPrice(e.target.value)} />
+        <Input size="sm" type="number" label="Stok" className="w-24" value={stock} onChange={(e) => setStock(e.target.value)} />
+        <Button size="sm" color="primary" isLoading={busyId === "new"} isDisabled={!name || !price} onPress={handleAdd}>
+          Tambah
+        </Button>
+      </div>
+
+      {error && <p className="text-danger text-sm">{error}</p>}
+    </div>
+  );
+};
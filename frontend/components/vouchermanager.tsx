@@ -0,0 +1,148 @@
+"use client";
+
+import React, { useEffect, useState } from "react";
+import { Button } from "@heroui/button";
+import { Input } from "@heroui/input";
+import { Select, SelectItem } from "@heroui/select";
+import { Switch } from "@heroui/switch";
+import { Spinner } from "@heroui/spinner";
+import api from "@/libs/api";
+import { Voucher, VoucherType } from "@/types";
+
+const emptyForm = {
+  code: "",
+  description: "",
+  type: "percentage" as VoucherType,
+  value: "",
+  min_spend: "",
+  max_discount: "",
+  usage_limit: "",
+  per_user_limit: "1",
+  starts_at: "",
+  ends_at: "",
+  seller_id: "",
+};
+
+const describe = (v: Voucher) => {
+  const amount = v.type === "percentage" ? `${v.value}%` : `Rp ${v.value.toLocaleString("id-ID")}`;
+  const parts = [`Potongan ${amount}`];
+  if (v.type === "percentage" && v.max_discount > 0) parts.push(`maks Rp ${v.max_discount.toLocaleString("id-ID")}`);
+  if (v.min_spend > 0) parts.push(`min belanja Rp ${v.min_spend.toLocaleString("id-ID")}`);
+  parts.push(`dipakai ${v.used_count}${v.usage_limit > 0 ? `/${v.usage_limit}` : ""}`);
+  if (v.ends_at) parts.push(`s.d. ${new Date(v.ends_at).toLocaleString("id-ID")}`);
+  return parts.join(" · ");
+};
+
+// Kelola voucher: seller ngatur voucher tokonya (/me/vouchers), admin semua voucher (/admin/vouchers).
+export const VoucherManager = ({ admin = false }: { admin?: boolean }) => {
+  const endpoint = admin ? "/admin/vouchers" : "/me/vouchers";
+  const [vouchers, setVouchers] = useState<Voucher[]>([]);
+  const [form, setForm] = useState(emptyForm);
+  const [loading, setLoading] = useState(true);
+  const [saving, setSaving] = useState(false);
+  const [error, setError] = useState("");
+
+  useEffect(() => {
+    api
+      .get<Voucher[]>(endpoint)
+      .then((res) => setVouchers(res.data))
+      .catch(() => setError("Gagal mengambil voucher"))
+      .finally(() => setLoading(false));
+  }, [endpoint]);
+
+  const set = (field: keyof typeof emptyForm) => (e: React.ChangeEvent<HTMLInputElement>) =>
+    setForm((f) => ({ ...f, [field]: e.target.value }));
+
+  const handleCreate = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setSaving(true);
+    setError("");
+    try {
+      const res = await api.post<Voucher>(endpoint, {
+        code: form.code,
+        description: form.description,
+        type: form.type,
+        value: Number(form.value),
+        min_spend: Number(form.min_spend || 0),
+        max_discount: Number(form.max_discount || 0),
+        usage_limit: Number(form.usage_limit || 0),
+        per_user_limit: Number(form.per_user_limit || 0),
+        starts_at: form.starts_at ? new Date(form.starts_at).toISOString() : undefined,
+        ends_at: form.ends_at ? new Date(form.ends_at).toISOString() : undefined,
+        seller_id: admin && form.seller_id ? Number(form.seller_id) : undefined,
+      });
+      setVouchers((prev) => [res.data, ...prev]);
+      setForm(emptyForm);
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal menyimpan voucher");
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  const toggleActive = async (v: Voucher, isActive: boolean) => {
+    setError("");
+    try {
+      const res = await api.put<Voucher>(`${endpoint}/${v.id}`, { is_active: isActive });
+      setVouchers((prev) => prev.map((item) => (item.id === v.id ? res.data : item)));
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal mengubah voucher");
+    }
+  };
+
+  return (
+    <div className="mb-8 p-4 border rounded-lg bg-content1 space-y-4">
+      <h2 className="text-xl font-bold">{admin ? "Voucher" : "Voucher Toko"}</h2>
+
+      <form onSubmit={handleCreate} className="grid grid-cols-1 md:grid-cols-4 gap-3">
+        <Input size="sm" label="Kode" value={form.code} onChange={(e) => setForm((f) => ({ ...f, code: e.target.value.toUpperCase() }))} isRequired />
+        <Select
+          size="sm"
+          label="Tipe"
+          selectedKeys={[form.type]}
+          onChange={(e) => setForm((f) => ({ ...f, type: (e.target.value || "percentage") as VoucherType }))}
+        >
+          <SelectItem key="percentage">Persen</SelectItem>
+          <SelectItem key="fixed">Nominal</SelectItem>
+        </Select>
+        <Input size="sm" type="number" label={form.type === "percentage" ? "Potongan (%)" : "Potongan (Rp)"} value={form.value} onChange={set("value")} isRequired />
+        <Input size="sm" type="number" label="Maks potongan (Rp)" value={form.max_discount} onChange={set("max_discount")} isDisabled={form.type === "fixed"} />
+        <Input size="sm" type="number" label="Min belanja (Rp)" value={form.min_spend} onChange={set("min_spend")} />
+        <Input size="sm" type="number" label="Kuota total (0 = bebas)" value={form.usage_limit} onChange={set("usage_limit")} />
+        <Input size="sm" type="number" label="Maks per user (0 = bebas)" value={form.per_user_limit} onChange={set("per_user_limit")} />
+        {admin && <Input size="sm" type="number" label="ID seller (kosong = platform)" value={form.seller_id} onChange={set("seller_id")} />}
+        <Input size="sm" type="datetime-local" label="Mulai" placeholder=" " value={form.starts_at} onChange={set("starts_at")} />
+        <Input size="sm" type="datetime-local" label="Selesai" placeholder=" " value={form.ends_at} onChange={set("ends_at")} />
+        <Input size="sm" label="Deskripsi" value={form.description} onChange={set("description")} className="md:col-span-2" />
+        <Button type="submit" color="primary" isLoading={saving} className="md:col-span-4 md:w-fit">
+          Bikin Voucher
+        </Button>
+      </form>
+
+      {error && <p className="text-danger text-sm">{error}</p>}
+
+      {loading ? (
+        <div className="text-center p-4"><Spinner size="sm" /></div>
+      ) : vouchers.length === 0 ? (
+        <p className="text-default-500 text-sm">Belum ada voucher.</p>
+      ) : (
+        <div className="space-y-2">
+          {vouchers.map((v) => (
+            <div key={v.id} className="flex items-center justify-between gap-4 border-b last:border-b-0 pb-2">
+              <div>
+                <p className="font-semibold">
+                  {v.code}
+                  {admin && <span className="ml-2 text-xs text-default-500">{v.seller_id ? `Seller #${v.seller_id}` : "Platform"}</span>}
+                </p>
+                <p className="text-sm text-default-500">{describe(v)}</p>
+              </div>
+              <Switch size="sm" isSelected={v.is_active} onValueChange={(isActive) => toggleActive(v, isActive)}>
+                Aktif
+              </Switch>
+            </div>
+          ))}
+        </div>
+      )}
+    </div>
+  );
+};
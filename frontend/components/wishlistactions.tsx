@@ -0,0 +1,83 @@
+"use client";
+
+import React, { useEffect, useState } from "react";
+import { useRouter } from "next/navigation";
+import { Button } from "@heroui/button";
+import { FiBell, FiHeart } from "react-icons/fi";
+import api from "@/libs/api";
+import { useAuth } from "@/contexts/AuthContext";
+import { StockAlert, WishlistItem } from "@/types";
+
+// Tombol simpen ke wishlist + "kabari saya" buat produk/varian yang lagi habis.
+export const WishlistActions = ({ productId, variantId, outOfStock }: { productId: number; variantId: number | null; outOfStock: boolean }) => {
+  const { user } = useAuth();
+  const router = useRouter();
+  const [wishlisted, setWishlisted] = useState(false);
+  const [alerts, setAlerts] = useState<StockAlert[]>([]);
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState("");
+
+  useEffect(() => {
+    if (!user) return;
+    api
+      .get<WishlistItem[]>("/me/wishlist")
+      .then((res) => setWishlisted(res.data.some((item) => item.product_id === productId)))
+      .catch((err) => console.error("Gagal ambil wishlist:", err));
+    api
+      .get<StockAlert[]>("/me/stock-alerts")
+      .then((res) => setAlerts(res.data.filter((a) => a.product_id === productId)))
+      .catch((err) => console.error("Gagal ambil pengingat stok:", err));
+  }, [user, productId]);
+
+  const subscribed = alerts.some((a) => a.variant_id === (variantId ?? 0));
+
+  const toggleWishlist = async () => {
+    if (!user) return router.push("/login");
+    setError("");
+    try {
+      if (wishlisted) {
+        await api.delete(`/me/wishlist/${productId}`);
+      } else {
+        await api.post("/me/wishlist", { product_id: productId });
+      }
+      setWishlisted(!wishlisted);
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal update wishlist");
+    }
+  };
+
+  const toggleAlert = async () => {
+    if (!user) return router.push("/login");
+    setLoading(true);
+    setError("");
+    try {
+      if (subscribed) {
+        await api.delete(`/products/${productId}/stock-alert`, { params: { variant_id: variantId ?? 0 } });
+        setAlerts((prev) => prev.filter((a) => a.variant_id !== (variantId ?? 0)));
+      } else {
+        const res = await api.post<StockAlert>(`/products/${productId}/stock-alert`, { variant_id: variantId ?? 0 });
+        setAlerts((prev) => [...prev, res.data]);
+      }
+    } catch (err: any) {
+      setError(err.response?.data?.error || "Gagal update pengingat stok");
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div className="space-y-2">
+      <div className="flex flex-wrap gap-2">
+        <Button variant={wishlisted ? "solid" : "bordered"} color="danger" startContent={<FiHeart />} onPress={toggleWishlist}>
+          {wishlisted ? "Ada di Wishlist" : "Simpan ke Wishlist"}
+        </Button>
+        {(outOfStock || subscribed) && (
+          <Button variant={subscribed ? "flat" : "bordered"} color="warning" startContent={<FiBell />} isLoading={loading} onPress={toggleAlert}>
+            {subscribed ? "Batal Kabari Saya" : "Kabari Saya Kalau Ada Stok"}
+          </Button>
+        )}
+      </div>
+      {error && <p className="text-danger text-sm">{error}</p>}
+    </div>
+  );
+};
@@ -7,7 +7,7 @@ import React, {
   useEffect,
   ReactNode,
 } from "react";
-import api from "@/libs/api";
+import api, { saveTokens, clearTokens, saveCartToken } from "@/libs/api";
 import { useRouter } from "next/navigation";
 import { User, Cart } from "@/types";
 import axios from "axios";
@@ -15,7 +15,8 @@ import axios from "axios";
 interface AuthContextType {
   user: User | null;
   isAuthenticated: boolean;
-  login: (email: string, password: string) => Promise<boolean>;
+  // Gagal login balikin pesan error dari server (plus code, misal "email_not_verified")
+  login: (email: string, password: string) => Promise<{ ok: boolean; error?: string; code?: string }>;
   logout: () => Promise<void>;
   loading: boolean;
   refreshUser: () => Promise<void>;
@@ -23,7 +24,7 @@ interface AuthContextType {
 
   cart: Cart | null;
   loadingCart: boolean;
-  addToCart: (productId: number, quantity: number) => Promise<boolean>;
+  addToCart: (productId: number, quantity: number, variantId?: number) => Promise<boolean>;
   updateCartQuantity: (cartItemId: number, newQuantity: number) => Promise<boolean>;
   removeCartItem: (cartItemId: number) => Promise<boolean>;
 }
@@ -40,8 +41,15 @@ export const AuthProvider = ({ children }: { children: ReactNode }) => {
 
   const router = useRouter();
 
+  // Belum login = keranjang tamu (/guest-cart), isinya digabung ke keranjang user waktu login
   const fetchCart = async () => {
     try {
+      if (!isAuthenticated) {
+        const response = await api.get("/guest-cart");
+        saveCartToken(response.data.cart_token);
+        setCart(response.data);
+        return;
+      }
       const response = await api.get("/cart");
       setCart(response.data || null);
     } catch (error) {
@@ -61,8 +69,7 @@ export const AuthProvider = ({ children }: { children: ReactNode }) => {
           setUser(response.data);
           setIsAuthenticated(true);
         } catch (err) {
-          localStorage.removeItem("token");
-          delete api.defaults.headers.common['Authorization'];
+          clearTokens();
           setUser(null);
           setIsAuthenticated(false);
         }
@@ -76,34 +83,40 @@ export const AuthProvider = ({ children }: { children: ReactNode }) => {
   }, []);
 
   useEffect(() => {
+    if (loading) return;
     if (isAuthenticated) {
       console.log("User terautentikasi, mengambil data keranjang...");
-      fetchCart();
-    } else {
-      setCart(null);
     }
-  }, [isAuthenticated]);
+    fetchCart();
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [isAuthenticated, loading]);
 
-  const login = async (email: string, password: string): Promise<boolean> => {
+  const login = async (email: string, password: string): Promise<{ ok: boolean; error?: string; code?: string }> => {
     try {
       setLoading(true);
       const response = await api.post("/auth/login", { email, password });
 
-      const { token, user } = response.data;
-      localStorage.setItem("token", token);
-
-      api.defaults.headers.common['Authorization'] = `Bearer ${token}`;
+      const { user, cart_merge } = response.data;
+      saveTokens(response.data);
+      // Keranjang tamu udah dipindah ke keranjang user (token dikirim lewat header X-Cart-Token)
+      saveCartToken(undefined);
+      if (cart_merge?.adjusted?.length) {
+        alert(
+          "Sebagian isi keranjang disesuaikan:\n" +
+            cart_merge.adjusted.map((a: { name: string; message: string }) => `${a.name}: ${a.message}`).join("\n")
+        );
+      }
 
       setUser(user);
       setIsAuthenticated(true);
 
       router.push("/");
-      return true;
-    } catch (error) {
+      return { ok: true };
+    } catch (error: any) {
       console.error("Login gagal:", error);
       setUser(null);
       setIsAuthenticated(false);
-      return false;
+      return { ok: false, error: error.response?.data?.error, code: error.response?.data?.code };
     } finally {
       setLoading(false);
     }
@@ -112,8 +125,12 @@ export const AuthProvider = ({ children }: { children: ReactNode }) => {
 const logout = async () => {
     try {
       setLoading(true);
-      localStorage.removeItem("token");
-      delete api.defaults.headers.common['Authorization'];
+      const refreshToken = localStorage.getItem("refresh_token");
+      if (refreshToken) {
+        // Revoke session di server, gagal pun tetep logout di sini
+        await api.post("/auth/logout", { refresh_token: refreshToken }).catch(() => {});
+      }
+      clearTokens();
       
       setUser(null);
       setIsAuthenticated(false);
@@ -126,11 +143,16 @@ const logout = async () => {
     }
   };
 
-  const addToCart = async (productId: number, quantity: number): Promise<boolean> => {
-    if (!isAuthenticated) { router.push("/login"); return false; }
+  const addToCart = async (productId: number, quantity: number, variantId?: number): Promise<boolean> => {
     setLoadingCart(true);
     try {
-      await api.post("/cart/items", { product_id: productId, quantity: quantity });
+      if (!isAuthenticated) {
+        const response = await api.post("/guest-cart/items", { product_id: productId, variant_id: variantId, quantity: quantity });
+        saveCartToken(response.data.cart_token);
+        setCart(response.data);
+        return true;
+      }
+      await api.post("/cart/items", { product_id: productId, variant_id: variantId, quantity: quantity });
       await fetchCart();
       return true;
     } catch (error: any) {
@@ -145,7 +167,7 @@ const logout = async () => {
     if (newQuantity <= 0) { return removeCartItem(cartItemId); }
     setLoadingCart(true);
     try {
-      await api.put(`/cart/items/${cartItemId}`, { quantity: newQuantity });
+      await api.put(`${isAuthenticated ? "/cart" : "/guest-cart"}/items/${cartItemId}`, { quantity: newQuantity });
       await fetchCart();
       return true;
     } catch (error: any) {
@@ -159,7 +181,7 @@ const logout = async () => {
   const removeCartItem = async (cartItemId: number): Promise<boolean> => {
     setLoadingCart(true);
     try {
-      await api.delete(`/cart/items/${cartItemId}`);
+      await api.delete(`${isAuthenticated ? "/cart" : "/guest-cart"}/items/${cartItemId}`);
       await fetchCart();
       return true;
     } catch (error: any) {
@@ -178,8 +200,7 @@ const logout = async () => {
     } catch (err) {
       setUser(null);
       setIsAuthenticated(false);
-      localStorage.removeItem("token");
-      delete api.defaults.headers.common['Authorization'];
+      clearTokens();
     }
   };
 
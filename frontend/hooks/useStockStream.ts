@@ -10,23 +10,26 @@ console.log("🔗 Connecting gRPC to:", GRPC_URL);
 
 const grpcClient = new StockServiceClient(GRPC_URL, null, null);
 
-export const useStockStream = (productId: number | null) => {
+// variantId kosong = stok total produk, diisi = cuma stok varian itu
+export const useStockStream = (productId: number | null, variantId?: number | null) => {
   const [liveStock, setLiveStock] = useState<number | null>(null);
 
   useEffect(() => {
     if (!productId) return;
+    setLiveStock(null);
 
-    console.log(`[gRPC-Web] Mulai 'nonton' stok ID: ${productId} via ${GRPC_URL}`);
+    console.log(`[gRPC-Web] Mulai 'nonton' stok ID: ${productId} varian: ${variantId || "-"} via ${GRPC_URL}`);
     
     const request = new TrackStockRequest();
     request.setProductId(productId);
+    request.setVariantId(variantId || 0);
 
     const stream = grpcClient.trackStock(request, {});
 
     stream.on("data", (response: StockUpdateResponse) => {
-      const newStock = response.getNewStock();
-      console.log(`[gRPC-Web] DAPET SIARAN! Stok baru: ${newStock}`);
-      setLiveStock(newStock);
+      const available = response.getAvailable();
+      console.log(`[gRPC-Web] DAPET SIARAN! Stok tersedia: ${available} (di-hold: ${response.getReserved()})`);
+      setLiveStock(available);
     });
 
     stream.on("error", (err : any) => {
@@ -42,7 +45,7 @@ export const useStockStream = (productId: number | null) => {
       stream.cancel(); 
     };
 
-  }, [productId]);
+  }, [productId, variantId]);
 
   return liveStock;
 };
\ No newline at end of file
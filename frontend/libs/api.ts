@@ -10,6 +10,11 @@ api.interceptors.request.use(
     if (token) {
       config.headers.Authorization = `Bearer ${token}`;
     }
+    // Keranjang tamu: dipake /guest-cart dan digabung ke keranjang user waktu login/daftar
+    const cartToken = localStorage.getItem("cart_token");
+    if (cartToken) {
+      config.headers["X-Cart-Token"] = cartToken;
+    }
     return config;
   },
   (error) => {
@@ -17,4 +22,60 @@ api.interceptors.request.use(
   }
 );
 
-export default api;
\ No newline at end of file
+// Access token umurnya pendek. Kalau kena 401, tuker refresh token sekali lalu ulang request-nya.
+// Beberapa request yang gagal barengan nunggu satu refresh yang sama.
+let refreshing: Promise<string | null> | null = null;
+
+export const saveTokens = (data: { access_token?: string; token?: string; refresh_token?: string }) => {
+  const access = data.access_token || data.token;
+  if (access) localStorage.setItem("token", access);
+  if (data.refresh_token) localStorage.setItem("refresh_token", data.refresh_token);
+  if (access) api.defaults.headers.common["Authorization"] = `Bearer ${access}`;
+};
+
+export const saveCartToken = (cartToken?: string) => {
+  if (cartToken) localStorage.setItem("cart_token", cartToken);
+  else localStorage.removeItem("cart_token");
+};
+
+export const clearTokens = () => {
+  localStorage.removeItem("token");
+  localStorage.removeItem("refresh_token");
+  delete api.defaults.headers.common["Authorization"];
+};
+
+const refreshAccessToken = async (): Promise<string | null> => {
+  const refreshToken = localStorage.getItem("refresh_token");
+  if (!refreshToken) return null;
+  try {
+    const res = await axios.post(`${process.env.NEXT_PUBLIC_API_BASE_URL}/auth/refresh`, {
+      refresh_token: refreshToken,
+    });
+    saveTokens(res.data);
+    return res.data.access_token;
+  } catch {
+    clearTokens();
+    return null;
+  }
+};
+
+api.interceptors.response.use(
+  (response) => response,
+  async (error) => {
+    const original = error.config;
+    if (error.response?.status !== 401 || !original || original._retried || original.url?.startsWith("/auth/")) {
+      return Promise.reject(error);
+    }
+    original._retried = true;
+
+    refreshing = refreshing || refreshAccessToken().finally(() => { refreshing = null; });
+    const token = await refreshing;
+    if (!token) {
+      return Promise.reject(error);
+    }
+    original.headers.Authorization = `Bearer ${token}`;
+    return api(original);
+  }
+);
+
+export default api;
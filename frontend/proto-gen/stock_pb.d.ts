@@ -6,6 +6,9 @@ export class TrackStockRequest extends jspb.Message {
   getProductId(): number;
   setProductId(value: number): TrackStockRequest;
 
+  getVariantId(): number;
+  setVariantId(value: number): TrackStockRequest;
+
   serializeBinary(): Uint8Array;
   toObject(includeInstance?: boolean): TrackStockRequest.AsObject;
   static toObject(includeInstance: boolean, msg: TrackStockRequest): TrackStockRequest.AsObject;
@@ -17,6 +20,7 @@ export class TrackStockRequest extends jspb.Message {
 export namespace TrackStockRequest {
   export type AsObject = {
     productId: number,
+    variantId: number,
   }
 }
 
@@ -27,6 +31,15 @@ export class StockUpdateResponse extends jspb.Message {
   getNewStock(): number;
   setNewStock(value: number): StockUpdateResponse;
 
+  getAvailable(): number;
+  setAvailable(value: number): StockUpdateResponse;
+
+  getReserved(): number;
+  setReserved(value: number): StockUpdateResponse;
+
+  getVariantId(): number;
+  setVariantId(value: number): StockUpdateResponse;
+
   serializeBinary(): Uint8Array;
   toObject(includeInstance?: boolean): StockUpdateResponse.AsObject;
   static toObject(includeInstance: boolean, msg: StockUpdateResponse): StockUpdateResponse.AsObject;
@@ -39,6 +52,9 @@ export namespace StockUpdateResponse {
   export type AsObject = {
     productId: number,
     newStock: number,
+    available: number,
+    reserved: number,
+    variantId: number,
   }
 }
 
@@ -97,7 +97,8 @@ proto.teluhub.stock.TrackStockRequest.prototype.toObject = function(opt_includeI
  */
 proto.teluhub.stock.TrackStockRequest.toObject = function(includeInstance, msg) {
   var f, obj = {
-productId: jspb.Message.getFieldWithDefault(msg, 1, 0)
+productId: jspb.Message.getFieldWithDefault(msg, 1, 0),
+variantId: jspb.Message.getFieldWithDefault(msg, 2, 0)
   };
 
   if (includeInstance) {
@@ -138,6 +139,10 @@ proto.teluhub.stock.TrackStockRequest.deserializeBinaryFromReader = function(msg
       var value = /** @type {number} */ (reader.readUint32());
       msg.setProductId(value);
       break;
+    case 2:
+      var value = /** @type {number} */ (reader.readUint32());
+      msg.setVariantId(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -174,6 +179,13 @@ proto.teluhub.stock.TrackStockRequest.serializeBinaryToWriter = function(message
       f
     );
   }
+  f = message.getVariantId();
+  if (f !== 0) {
+    writer.writeUint32(
+      2,
+      f
+    );
+  }
 };
 
 
@@ -195,6 +207,24 @@ proto.teluhub.stock.TrackStockRequest.prototype.setProductId = function(value) {
 };
 
 
+/**
+ * optional uint32 variant_id = 2;
+ * @return {number}
+ */
+proto.teluhub.stock.TrackStockRequest.prototype.getVariantId = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 2, 0));
+};
+
+
+/**
+ * @param {number} value
+ * @return {!proto.teluhub.stock.TrackStockRequest} returns this
+ */
+proto.teluhub.stock.TrackStockRequest.prototype.setVariantId = function(value) {
+  return jspb.Message.setProto3IntField(this, 2, value);
+};
+
+
 
 
 
@@ -228,7 +258,10 @@ proto.teluhub.stock.StockUpdateResponse.prototype.toObject = function(opt_includ
 proto.teluhub.stock.StockUpdateResponse.toObject = function(includeInstance, msg) {
   var f, obj = {
 productId: jspb.Message.getFieldWithDefault(msg, 1, 0),
-newStock: jspb.Message.getFieldWithDefault(msg, 2, 0)
+newStock: jspb.Message.getFieldWithDefault(msg, 2, 0),
+available: jspb.Message.getFieldWithDefault(msg, 3, 0),
+reserved: jspb.Message.getFieldWithDefault(msg, 4, 0),
+variantId: jspb.Message.getFieldWithDefault(msg, 5, 0)
   };
 
   if (includeInstance) {
@@ -273,6 +306,18 @@ proto.teluhub.stock.StockUpdateResponse.deserializeBinaryFromReader = function(m
       var value = /** @type {number} */ (reader.readInt32());
       msg.setNewStock(value);
       break;
+    case 3:
+      var value = /** @type {number} */ (reader.readInt32());
+      msg.setAvailable(value);
+      break;
+    case 4:
+      var value = /** @type {number} */ (reader.readInt32());
+      msg.setReserved(value);
+      break;
+    case 5:
+      var value = /** @type {number} */ (reader.readUint32());
+      msg.setVariantId(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -316,6 +361,27 @@ proto.teluhub.stock.StockUpdateResponse.serializeBinaryToWriter = function(messa
       f
     );
   }
+  f = message.getAvailable();
+  if (f !== 0) {
+    writer.writeInt32(
+      3,
+      f
+    );
+  }
+  f = message.getReserved();
+  if (f !== 0) {
+    writer.writeInt32(
+      4,
+      f
+    );
+  }
+  f = message.getVariantId();
+  if (f !== 0) {
+    writer.writeUint32(
+      5,
+      f
+    );
+  }
 };
 
 
@@ -355,4 +421,58 @@ proto.teluhub.stock.StockUpdateResponse.prototype.setNewStock = function(value)
 };
 
 
+/**
+ * optional int32 available = 3;
+ * @return {number}
+ */
+proto.teluhub.stock.StockUpdateResponse.prototype.getAvailable = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 3, 0));
+};
+
+
+/**
+ * @param {number} value
+ * @return {!proto.teluhub.stock.StockUpdateResponse} returns this
+ */
+proto.teluhub.stock.StockUpdateResponse.prototype.setAvailable = function(value) {
+  return jspb.Message.setProto3IntField(this, 3, value);
+};
+
+
+/**
+ * optional int32 reserved = 4;
+ * @return {number}
+ */
+proto.teluhub.stock.StockUpdateResponse.prototype.getReserved = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 4, 0));
+};
+
+
+/**
+ * @param {number} value
+ * @return {!proto.teluhub.stock.StockUpdateResponse} returns this
+ */
+proto.teluhub.stock.StockUpdateResponse.prototype.setReserved = function(value) {
+  return jspb.Message.setProto3IntField(this, 4, value);
+};
+
+
+/**
+ * optional uint32 variant_id = 5;
+ * @return {number}
+ */
+proto.teluhub.stock.StockUpdateResponse.prototype.getVariantId = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 5, 0));
+};
+
+
+/**
+ * @param {number} value
+ * @return {!proto.teluhub.stock.StockUpdateResponse} returns this
+ */
+proto.teluhub.stock.StockUpdateResponse.prototype.setVariantId = function(value) {
+  return jspb.Message.setProto3IntField(this, 5, value);
+};
+
+
 goog.object.extend(exports, proto.teluhub.stock);
@@ -4,7 +4,7 @@ export type IconSvgProps = SVGProps<SVGSVGElement> & {
   size?: number;
 };
 
-export type NotificationType = "order" | "chat" | "info";
+export type NotificationType = "order" | "chat" | "info" | "seller" | "review" | "stock";
 
 export interface Notification {
   id: number;
@@ -36,10 +36,145 @@ export interface Product {
   description: string;
   price:       number;
   stock:       number;
+  available_stock?: number;
+  reserved_stock?:  number;
+  sold_count?:  number;
+  rating_average?: number;
+  rating_count?:   number;
   image_url:   string;
+  thumbnail_url?: string; // Versi kecil image_url buat kartu produk
+  weight_grams?: number;  // Berat per item buat ongkir kurir
   seller:      SellerResponse;
   category_id: number;
   category: Category;
+  variants?: ProductVariant[];
+  images?: ProductImage[];
+}
+
+// Satu foto di galeri produk, urut sesuai position
+export interface ProductImage {
+  id:         number;
+  url:        string;
+  thumbnail_url?: string;
+  medium_url?:    string;
+  alt_text:   string;
+  position:   number;
+  is_primary: boolean;
+}
+
+// Pilihan produk (ukuran/warna) dengan harga & stok sendiri
+export interface ProductVariant {
+  id:       number;
+  sku:      string;
+  name:     string;
+  options:  Record<string, string>;
+  price:    number;
+  stock:    number;
+  available_stock: number;
+  reserved_stock:  number;
+}
+
+// Envelope GET /products (paginasi pake cursor)
+export interface ProductListResponse {
+  data:         Product[];
+  total:        number;
+  limit:        number;
+  next_cursor?: string;
+  has_more:     boolean;
+}
+
+export interface ReviewPhoto {
+  id:            number;
+  url:           string;
+  thumbnail_url: string;
+  medium_url:    string;
+}
+
+export interface Review {
+  id:                 number;
+  product_id:         number;
+  order_item_id:      number;
+  rating:             number;
+  comment:            string;
+  variant_name?:      string;
+  reviewer:           { id: number; username: string; profile_image_url?: string };
+  photos:             ReviewPhoto[];
+  seller_reply?:      string;
+  seller_replied_at?: string;
+  is_hidden:          boolean;
+  hidden_reason?:     string;
+  created_at:         string;
+  updated_at:         string;
+}
+
+// Envelope GET /products/:id/reviews, summary cuma ada di halaman pertama
+export interface ReviewListResponse {
+  data:         Review[];
+  summary?:     { average: number; count: number; distribution: Record<number, number> };
+  next_cursor?: string;
+  has_more:     boolean;
+}
+
+// Barang dari pesanan selesai yang belum diulas
+export interface ReviewableItem {
+  order_item_id: number;
+  order_id:      number;
+  product_id:    number;
+  product_name:  string;
+  product_slug:  string;
+  thumbnail_url: string;
+  variant_name?: string;
+  purchased_at:  string;
+}
+
+export type VoucherType = "percentage" | "fixed";
+
+export interface Voucher {
+  id:             number;
+  code:           string;
+  description:    string;
+  type:           VoucherType;
+  value:          number;
+  min_spend:      number;
+  max_discount:   number; // 0 = gak dibatasi
+  usage_limit:    number; // 0 = gak dibatasi
+  per_user_limit: number;
+  used_count:     number;
+  seller_id?:     number; // Kosong = voucher platform
+  starts_at?:     string;
+  ends_at?:       string;
+  is_active:      boolean;
+  created_at:     string;
+}
+
+// Hasil cek voucher ke isi keranjang
+export interface VoucherCheck {
+  code:              string;
+  description:       string;
+  subtotal:          number;
+  eligible_subtotal: number;
+  discount_amount:   number;
+  total:             number;
+}
+
+// Produk yang disimpen user di wishlist
+export interface WishlistItem {
+  id:           number;
+  product_id:   number;
+  added_at:     string;
+  alert_active: boolean;
+  product:      Product;
+}
+
+// Langganan "kabari saya kalau stok ada lagi" yang masih aktif
+export interface StockAlert {
+  id:            number;
+  product_id:    number;
+  variant_id:    number; // 0 = varian apa aja
+  product_name:  string;
+  product_slug:  string;
+  variant_name?: string;
+  created_at:    string;
 }
 
 export interface User {
@@ -48,19 +183,99 @@ export interface User {
   email:    string;
   role:     string;
   profile_image_url?: string;
+  email_verified?: boolean;
+}
+
+export interface CartWarning {
+  type:       "price_up" | "price_down" | "insufficient_stock" | "unavailable";
+  message:    string;
+  old_price?: number;
+  new_price?: number;
+  available?: number;
 }
 
 export interface CartItem {
-  id:         number;
-  product_id: number;
-  quantity:   number;
-  Product:    Product;
+  id:           number;
+  product_id:   number;
+  variant_id?:  number | null;
+  quantity:     number;
+  price_at_add: number;
+  available:    number;
+  unavailable:  boolean;
+  warnings:     CartWarning[];
+  Product:      Product;
+  Variant?:     { id: number; sku: string; name: string };
+}
+
+export interface CartChange {
+  cart_item_id: number;
+  name:         string;
+  action:       "removed" | "quantity_reduced" | "price_updated";
+  message:      string;
 }
 
 export interface Cart {
-  id:         number;
-  user_id:    number;
-  CartItems:  CartItem[];
+  id:           number;
+  user_id:      number;
+  CartItems:    CartItem[];
+  has_warnings: boolean;
+  changes?:     CartChange[];
+  cart_token?:  string; // Cuma keranjang tamu (/guest-cart)
+}
+
+export interface CartMergeResult {
+  merged:   number;
+  adjusted: { product_id: number; name: string; message: string }[];
+}
+
+export interface SellerApplicationDocument {
+  id:    number;
+  url:   string;
+  label: string;
+}
+
+export interface SellerApplication {
+  id:                number;
+  user_id:           number;
+  store_name:        string;
+  description:       string;
+  student_id:        string;
+  status:            "pending" | "approved" | "rejected";
+  rejection_reason?: string;
+  reviewed_at?:      string;
+  created_at:        string;
+  documents:         SellerApplicationDocument[];
+  applicant?:        User;
+}
+
+export interface Session {
+  id:           number;
+  user_agent:   string;
+  ip_address:   string;
+  created_at:   string;
+  last_used_at: string;
+  expires_at:   string;
+  current:      boolean;
+}
+
+export interface Upload {
+  id:            number;
+  url:           string;
+  thumbnail_url: string;
+  medium_url:    string;
+  size:          number;
+  hash:          string;
+  content_type:  string;
+  width:         number;
+  height:        number;
+  in_use:        boolean;
+  created_at:    string;
+}
+
+export interface UploadList {
+  data:        Upload[];
+  used_bytes:  number;
+  quota_bytes: number;
 }
 
 export interface PublicProfile {
@@ -69,6 +284,38 @@ export interface PublicProfile {
   role:     string;
   profile_image_url?: string;
   joined_at: string;
+  store_slug?: string;
+}
+
+// Jam buka satu hari, day ngikutin Date.getDay() (0 = Minggu)
+export interface OperatingHour {
+  day:   number;
+  open:  string;
+  close: string;
+}
+
+export interface StoreStats {
+  product_count:         number;
+  sold_count:            number;
+  average_rating:        number | null;
+  rating_count:          number;
+  response_time_minutes: number | null;
+}
+
+export interface Store {
+  id:              number;
+  seller_id:       number;
+  name:            string;
+  slug:            string;
+  description:     string;
+  banner_url:      string;
+  pickup_location: string;
+  shipping_origin: string;
+  operating_hours: OperatingHour[];
+  is_open:         boolean;
+  created_at:      string;
+  seller:          PublicProfile;
+  stats?:          StoreStats;
 }
 
 export interface OrderProduct {
@@ -82,14 +329,146 @@ export interface OrderItem {
   id:           number;
   quantity:     number;
   price_at_time: number;
+  variant_id?:  number;
+  variant_name?: string;
   Product:      OrderProduct;
 }
 
 export interface Order {
   id:           number;
   total_amount: number;
+  discount_amount?: number;
+  voucher_code?: string;
+  shipping_total?: number;
   status:       string;
   created_at:   string;
   OrderItems:   OrderItem[];
+  seller_orders?: SellerOrder[];
+}
+
+// Bagian order milik satu seller, lengkap sama pengirimannya
+export interface SellerOrder {
+  id:              number;
+  seller_id:       number;
+  seller_name:     string;
+  status:          string;
+  subtotal:        number;
+  discount_amount: number;
+  fulfilment:      OrderFulfilment;
+  OrderItems:      OrderItem[];
+}
+
+export type FulfilmentType = "meetup" | "pickup" | "courier";
+export type FeeType = "flat" | "weight";
+
+// Info pengiriman yang disalin ke sub-order waktu checkout
+export interface OrderFulfilment {
+  method_id?:        number;
+  type:              FulfilmentType;
+  name:              string;
+  shipping_fee:      number;
+  shipping_address?: string;
+  meetup_point?:     string;
+  tracking_number?:  string;
+  handover_code?:    string; // Cuma muncul di sisi buyer sebelum serah terima
+  handed_over_at?:   string;
+}
+
+export interface WeightRate {
+  max_grams: number;
+  fee:       number;
+}
+
+// Metode pengiriman seller (id 0 = default ketemuan di titik ambil toko)
+export interface FulfilmentMethod {
+  id:           number;
+  seller_id:    number;
+  type:         FulfilmentType;
+  name:         string;
+  description:  string;
+  location:     string;
+  fee_type:     FeeType;
+  flat_fee:     number;
+  weight_rates: WeightRate[] | null;
+  is_active:    boolean;
+}
+
+export interface Address {
+  id:             number;
+  label:          string;
+  recipient_name: string;
+  phone:          string;
+  street:         string;
+  city:           string;
+  postal_code:    string;
+  notes:          string;
+  is_default:     boolean;
+}
+
+export interface CheckoutSellerOptions {
+  seller_id:    number;
+  seller_name:  string;
+  weight_grams: number;
+  options: {
+    method:    FulfilmentMethod;
+    fee:       number;
+    available: boolean;
+  }[];
+}
+
+export interface CheckoutOptions {
+  sellers:   CheckoutSellerOptions[];
+  addresses: Address[];
+}
+
+// Pilihan pengiriman buyer buat satu seller, dikirim ke POST /checkout
+export interface FulfilmentSelection {
+  seller_id:   number;
+  method_id:   number;
+  address_id?: number;
+}
+
+// Body POST /checkout & /checkout/preview: items kosong = seluruh keranjang, buy_now = beli langsung
+export interface CheckoutRequest {
+  voucher_code?: string;
+  fulfilment?:   FulfilmentSelection[];
+  items?:        { cart_item_id: number; quantity?: number }[];
+  buy_now?:      { product_id: number; variant_id?: number; quantity: number };
+}
+
+export interface CheckoutProblem {
+  type:          "stock" | "variant" | "unavailable" | "price" | "voucher" | "fulfilment";
+  message:       string;
+  cart_item_id?: number;
+  product_id?:   number;
+  seller_id?:    number;
+}
+
+export interface CheckoutPreview {
+  items: {
+    cart_item_id?:   number;
+    product_id:      number;
+    variant_id?:     number;
+    name:            string;
+    quantity:        number;
+    price:           number;
+    subtotal:        number;
+    discount_amount: number;
+    available:       number;
+  }[];
+  sellers: (CheckoutSellerOptions & {
+    method_id:    number;
+    method_name?: string;
+    type?:        FulfilmentType;
+    shipping_fee: number;
+  })[];
+  addresses:       Address[];
+  subtotal:        number;
+  discount_amount: number;
+  voucher_code?:   string;
+  shipping_total:  number;
+  total:           number;
+  problems:        CheckoutProblem[];
+  can_checkout:    boolean;
 }
 